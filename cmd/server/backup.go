@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// backupManager implements the backup tool on top of the database manager,
+// adding restored backups like the add_connection tool
+type backupManager struct {
+	dir   string
+	admin *connectionAdmin
+}
+
+// Backup writes a copy of a SQLite connection into the backup directory
+func (b backupManager) Backup(ctx context.Context, id string) (mcp.BackupInfo, error) {
+	backup, err := dbtools.BackupDatabase(ctx, id, b.dir)
+	if err != nil {
+		return mcp.BackupInfo{}, err
+	}
+	return toBackupInfo(backup), nil
+}
+
+// ListBackups returns the backups of a connection, newest first
+func (b backupManager) ListBackups(id string) ([]mcp.BackupInfo, error) {
+	backups, err := db.ListBackups(id, b.dir)
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]mcp.BackupInfo, 0, len(backups))
+	for _, backup := range backups {
+		infos = append(infos, toBackupInfo(backup))
+	}
+	return infos, nil
+}
+
+// Restore copies a backup and adds a connection opening the copy. The copy is
+// deleted again when the connection cannot be added.
+func (b backupManager) Restore(ctx context.Context, id, file, newID string) error {
+	conn, err := dbtools.RestoreBackup(id, file, newID, b.dir)
+	if err != nil {
+		return err
+	}
+	if err := b.admin.add(ctx, conn, false); err != nil {
+		if removeErr := os.Remove(conn.DatabasePath); removeErr != nil {
+			logger.Error("Failed to remove restored copy %s: %v", conn.DatabasePath, removeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// toBackupInfo converts a backup for the backup tool
+func toBackupInfo(backup db.Backup) mcp.BackupInfo {
+	return mcp.BackupInfo{
+		Database:  backup.ID,
+		File:      backup.File,
+		SizeBytes: backup.Size,
+		Integrity: backup.Integrity,
+		CreatedAt: backup.CreatedAt,
+	}
+}
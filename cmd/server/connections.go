@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/config"
+	"github.com/FreePeak/db-mcp-server/internal/costguard"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// connectionAdmin implements the connection management tools on top of the
+// database manager, keeping tools, limits and the config file in step.
+// Connections are saved as they were supplied, the manager applies the
+// connection policies and resolves SQLite paths again when it loads them.
+type connectionAdmin struct {
+	reloader *configReloader
+	supplied map[string]db.DatabaseConnectionConfig // connections added by the tools, as supplied
+}
+
+// AddConnection adds a connection and registers its tools
+func (a *connectionAdmin) AddConnection(ctx context.Context, connection map[string]interface{}, persist bool) error {
+	conn, err := a.decode(connection, nil)
+	if err != nil {
+		return err
+	}
+	return a.add(ctx, conn, persist)
+}
+
+// add adds a decoded connection and registers its tools
+func (a *connectionAdmin) add(ctx context.Context, supplied db.DatabaseConnectionConfig, persist bool) error {
+	r := a.reloader
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	conn := a.resolve(supplied)
+	if err := dbtools.AddDatabase(conn); err != nil {
+		return err
+	}
+	a.setConnection(conn, supplied)
+	r.syncTools(ctx, db.ConfigDiff{Added: []string{conn.ID}})
+
+	if persist {
+		if err := config.SaveConnection(r.cfg.ConfigPath, supplied); err != nil {
+			return fmt.Errorf("connection %s was added but not saved: %w", conn.ID, err)
+		}
+	}
+	return nil
+}
+
+// UpdateConnection applies changes to the settings of a connection and
+// reconnects it
+func (a *connectionAdmin) UpdateConnection(ctx context.Context, id string, changes map[string]interface{}, persist bool) error {
+	r := a.reloader
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	supplied, err := a.merge(id, changes)
+	if err != nil {
+		return err
+	}
+	conn := a.resolve(supplied)
+	if err := dbtools.UpdateDatabase(conn); err != nil {
+		return err
+	}
+	a.setConnection(conn, supplied)
+	r.syncTools(ctx, db.ConfigDiff{Changed: []string{conn.ID}})
+
+	if persist {
+		if err := config.SaveConnection(r.cfg.ConfigPath, supplied); err != nil {
+			return fmt.Errorf("connection %s was updated but not saved: %w", conn.ID, err)
+		}
+	}
+	return nil
+}
+
+// RemoveConnection closes a connection and removes its tools
+func (a *connectionAdmin) RemoveConnection(ctx context.Context, id string, persist bool) error {
+	r := a.reloader
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := dbtools.RemoveDatabase(id); err != nil {
+		return err
+	}
+	a.deleteConnection(id)
+	r.syncTools(ctx, db.ConfigDiff{Removed: []string{id}})
+
+	if persist {
+		if err := config.DeleteConnection(r.cfg.ConfigPath, id); err != nil {
+			return fmt.Errorf("connection %s was removed but not saved: %w", id, err)
+		}
+	}
+	return nil
+}
+
+// TestConnection opens and pings a connection without adding it
+func (a *connectionAdmin) TestConnection(ctx context.Context, id string, connection map[string]interface{}) (time.Duration, error) {
+	var conn db.DatabaseConnectionConfig
+	var err error
+	if id != "" {
+		conn, err = a.merge(id, connection)
+	} else {
+		conn, err = a.decode(connection, nil)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	if err := db.TestConnection(ctx, a.resolve(conn)); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// decode converts tool parameters into a connection, rejecting unknown
+// settings and secret references other than those kept from previous
+func (a *connectionAdmin) decode(connection map[string]interface{}, previous *db.DatabaseConnectionConfig) (db.DatabaseConnectionConfig, error) {
+	var conn db.DatabaseConnectionConfig
+
+	data, err := json.Marshal(connection)
+	if err != nil {
+		return conn, fmt.Errorf("invalid connection: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&conn); err != nil {
+		return conn, fmt.Errorf("invalid connection: %w", err)
+	}
+
+	if err := db.CheckSecretRefs(conn, previous); err != nil {
+		return conn, err
+	}
+	if conn.CostGuard != nil {
+		if err := costguard.Limits(*conn.CostGuard).Validate(); err != nil {
+			return conn, fmt.Errorf("connection %s: %w", conn.ID, err)
+		}
+	}
+	return conn, nil
+}
+
+// resolve resolves the SQLite paths of a supplied connection like the config
+// file loader
+func (a *connectionAdmin) resolve(conn db.DatabaseConnectionConfig) db.DatabaseConnectionConfig {
+	config.ResolveSQLitePath(&conn, filepath.Dir(a.reloader.cfg.ConfigPath))
+	return conn
+}
+
+// merge applies changes on top of the supplied settings of an existing
+// connection
+func (a *connectionAdmin) merge(id string, changes map[string]interface{}) (db.DatabaseConnectionConfig, error) {
+	current, err := a.current(id)
+	if err != nil {
+		return current, err
+	}
+	if changedID, ok := changes["id"]; ok && changedID != id {
+		return current, fmt.Errorf("connection id cannot be changed, remove and add the connection instead")
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return current, fmt.Errorf("failed to encode connection %s: %w", id, err)
+	}
+	connection := make(map[string]interface{})
+	if err := json.Unmarshal(data, &connection); err != nil {
+		return current, fmt.Errorf("failed to encode connection %s: %w", id, err)
+	}
+	for key, value := range changes {
+		connection[key] = value
+	}
+	return a.decode(connection, &current)
+}
+
+// current returns the settings of an existing connection as they were
+// supplied: from the config file, or from the tool that added it. Connections
+// configured elsewhere, such as by -db-config, fall back to the settings the
+// manager uses.
+func (a *connectionAdmin) current(id string) (db.DatabaseConnectionConfig, error) {
+	conn, err := dbtools.GetRawDatabaseConfig(id)
+	if err != nil {
+		return conn, err
+	}
+	if saved, found, err := config.ReadConnection(a.reloader.cfg.ConfigPath, id); err == nil && found {
+		return saved, nil
+	}
+	if supplied, ok := a.supplied[id]; ok {
+		return supplied, nil
+	}
+	return conn, nil
+}
+
+// setConnection records a connection in the server config and updates its
+// limits
+func (a *connectionAdmin) setConnection(conn, supplied db.DatabaseConnectionConfig) {
+	if a.supplied == nil {
+		a.supplied = make(map[string]db.DatabaseConnectionConfig)
+	}
+	a.supplied[conn.ID] = supplied
+
+	cfg := a.reloader.cfg
+	if cfg.MultiDBConfig == nil {
+		cfg.MultiDBConfig = &db.MultiDBConfig{}
+	}
+
+	connections := cfg.MultiDBConfig.Connections
+	replaced := false
+	for i := range connections {
+		if connections[i].ID == conn.ID {
+			connections[i] = conn
+			replaced = true
+		}
+	}
+	if !replaced {
+		cfg.MultiDBConfig.Connections = append(connections, conn)
+	}
+	a.reloader.applyLimits()
+}
+
+// deleteConnection removes a connection from the server config and its limits
+func (a *connectionAdmin) deleteConnection(id string) {
+	delete(a.supplied, id)
+
+	cfg := a.reloader.cfg
+	if cfg.MultiDBConfig == nil {
+		return
+	}
+
+	connections := cfg.MultiDBConfig.Connections[:0]
+	for _, conn := range cfg.MultiDBConfig.Connections {
+		if conn.ID != id {
+			connections = append(connections, conn)
+		}
+	}
+	cfg.MultiDBConfig.Connections = connections
+	a.reloader.applyLimits()
+}
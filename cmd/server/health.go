@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// healthReporter reports the health monitor's view of the connections to
+// the list_databases tool
+type healthReporter struct{}
+
+// DatabaseHealth returns the health of a connection
+func (healthReporter) DatabaseHealth(id string) (mcp.DatabaseHealth, bool) {
+	health, err := dbtools.GetDatabaseHealth(id)
+	if err != nil {
+		return mcp.DatabaseHealth{}, false
+	}
+	return mcp.DatabaseHealth{
+		State:               health.State,
+		Latency:             health.Latency,
+		LastCheck:           health.LastCheck,
+		LastError:           health.LastError,
+		ConsecutiveFailures: health.ConsecutiveFailures,
+	}, true
+}
+
+// databaseStatus is the health of a connection as served on /healthz. Errors
+// are left out since the endpoint is not authenticated.
+type databaseStatus struct {
+	ID                  string     `json:"id"`
+	Type                string     `json:"type"`
+	State               string     `json:"state"`
+	LatencyMs           int64      `json:"latency_ms"`
+	LastCheck           *time.Time `json:"last_check,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+}
+
+// healthHandler serves the health of the connections. The status is "ok"
+// when every connected database is healthy, "degraded" when some are not and
+// "down" when none is reachable, which is also reported as 503.
+func healthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		status := "ok"
+		connected, down := 0, 0
+		databases := make([]databaseStatus, 0)
+		for _, health := range dbtools.ListDatabaseHealth() {
+			entry := databaseStatus{
+				ID:                  health.ID,
+				Type:                health.Type,
+				State:               health.State,
+				LatencyMs:           health.Latency.Milliseconds(),
+				ConsecutiveFailures: health.ConsecutiveFailures,
+			}
+			if !health.LastCheck.IsZero() {
+				lastCheck := health.LastCheck
+				entry.LastCheck = &lastCheck
+			}
+			databases = append(databases, entry)
+
+			switch health.State {
+			case db.HealthNotConnected:
+				continue
+			case db.HealthDown:
+				down++
+				status = "degraded"
+			case db.HealthDegraded:
+				status = "degraded"
+			}
+			connected++
+		}
+
+		code := http.StatusOK
+		if connected > 0 && down == connected {
+			status = "down"
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"status":    status,
+			"databases": databases,
+		})
+	})
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// labelProvider describes the connections to the tools, with the connection
+// policies applied
+type labelProvider struct{}
+
+// DatabaseLabels returns the labels of a connection
+func (labelProvider) DatabaseLabels(id string) (mcp.DatabaseLabels, bool) {
+	cfg, err := dbtools.GetRawDatabaseConfig(id)
+	if err != nil {
+		return mcp.DatabaseLabels{}, false
+	}
+	return mcp.DatabaseLabels{
+		Description: cfg.Description,
+		Environment: cfg.Environment,
+		Tags:        cfg.Tags,
+		ReadOnly:    cfg.ReadOnly,
+	}, true
+}
@@ -16,16 +16,27 @@ import (
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/FreePeak/cortex/pkg/server"
 
+	"github.com/FreePeak/db-mcp-server/internal/audit"
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+	"github.com/FreePeak/db-mcp-server/internal/breaker"
 	"github.com/FreePeak/db-mcp-server/internal/config"
+	"github.com/FreePeak/db-mcp-server/internal/costguard"
+	"github.com/FreePeak/db-mcp-server/internal/delivery/gateway"
 	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp"
+	"github.com/FreePeak/db-mcp-server/internal/domain"
 	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/internal/metrics"
+	"github.com/FreePeak/db-mcp-server/internal/ratelimit"
 	"github.com/FreePeak/db-mcp-server/internal/repository"
 	"github.com/FreePeak/db-mcp-server/internal/usecase"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
 	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
 	pkgLogger "github.com/FreePeak/db-mcp-server/pkg/logger"
 )
@@ -60,6 +71,150 @@ func findConfigFile() string {
 	return defaultConfigFile
 }
 
+// splitList splits a comma-separated flag value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// connectionRateLimits collects the per-connection rate limit overrides
+func connectionRateLimits(cfg *config.Config) map[string]ratelimit.Limit {
+	limits := make(map[string]ratelimit.Limit)
+	if cfg.MultiDBConfig == nil {
+		return limits
+	}
+	for _, conn := range cfg.MultiDBConfig.Connections {
+		if conn.RateLimit != nil {
+			limits[conn.ID] = ratelimit.Limit(*conn.RateLimit)
+		}
+	}
+	return limits
+}
+
+// connectionCostGuards collects the per-connection cost guard thresholds
+func connectionCostGuards(cfg *config.Config) (map[string]costguard.Limits, error) {
+	limits := make(map[string]costguard.Limits)
+	if cfg.MultiDBConfig == nil {
+		return limits, nil
+	}
+	for _, conn := range cfg.MultiDBConfig.Connections {
+		if conn.CostGuard == nil {
+			continue
+		}
+		limit := costguard.Limits(*conn.CostGuard)
+		if err := limit.Validate(); err != nil {
+			return nil, fmt.Errorf("connection %s: %w", conn.ID, err)
+		}
+		limits[conn.ID] = limit
+	}
+	return limits, nil
+}
+
+// registerSecretProviders registers the command-based secret providers of the config file
+func registerSecretProviders(cfg *config.Config) {
+	for scheme, provider := range cfg.SecretProviders {
+		db.RegisterSecretProvider(scheme, &db.CommandSecretProvider{
+			Command: provider.Command,
+			Timeout: time.Duration(provider.TimeoutSeconds) * time.Second,
+		})
+		logger.Info("Registered secret provider %s (%s)", scheme, strings.Join(provider.Command, " "))
+	}
+}
+
+// configPollInterval is how often the config file is checked for changes
+const configPollInterval = 2 * time.Second
+
+// configReloader applies changes of the config file to the running server
+type configReloader struct {
+	mu       sync.Mutex // serializes reloads and runtime connection changes
+	cfg      *config.Config
+	registry *mcp.ToolRegistry
+	limiter  *ratelimit.Limiter // nil when rate limiting is disabled
+	guard    *costguard.Guard
+	breaker  *breaker.Breaker // nil when the circuit breaker is disabled
+	notify   func()           // tells connected clients that the tool list changed
+}
+
+// reload re-reads the database connections and updates tools and limits to match
+func (r *configReloader) reload(ctx context.Context) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	diff, err := dbtools.ReloadDatabases(r.cfg.ConfigPath)
+	if err != nil {
+		logger.Error("Failed to reload %s, keeping the current connections: %v", r.cfg.ConfigPath, err)
+		return
+	}
+
+	if connections, err := config.LoadConnections(r.cfg.ConfigPath); err == nil {
+		r.cfg.MultiDBConfig = connections
+		r.applyLimits()
+	}
+
+	if diff.Empty() {
+		logger.Info("Reloaded %s, database connections are unchanged", r.cfg.ConfigPath)
+		return
+	}
+	logger.Info("Reloaded %s: added %v, removed %v, changed %v", r.cfg.ConfigPath, diff.Added, diff.Removed, diff.Changed)
+	r.syncTools(ctx, diff)
+}
+
+// applyLimits updates the rate limits and cost guard thresholds from the
+// current connections
+func (r *configReloader) applyLimits() {
+	if r.limiter != nil {
+		r.limiter.SetConnectionLimits(connectionRateLimits(r.cfg))
+	}
+	if costGuards, err := connectionCostGuards(r.cfg); err != nil {
+		logger.Error("Invalid cost guard configuration, keeping the current one: %v", err)
+	} else {
+		r.guard.SetLimits(costGuards)
+	}
+}
+
+// syncTools registers and removes tools after connections changed and tells
+// clients about it
+func (r *configReloader) syncTools(ctx context.Context, diff db.ConfigDiff) {
+	// Reconfigured connections start with a closed breaker
+	if r.breaker != nil {
+		for _, id := range append(append([]string{}, diff.Removed...), diff.Changed...) {
+			r.breaker.Forget(id)
+		}
+	}
+	if err := r.registry.SyncDatabases(ctx, diff.Added, diff.Removed, diff.Changed); err != nil {
+		logger.Warn("Warning: error updating tools after reload: %v", err)
+	}
+	if r.notify != nil {
+		r.notify()
+	}
+}
+
+// watch reloads the configuration whenever the config file changes or the
+// process receives SIGHUP
+func (r *configReloader) watch(ctx context.Context) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	changes := config.WatchFile(ctx, r.cfg.ConfigPath, configPollInterval)
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-hangup:
+				r.reload(ctx)
+			case <-changes:
+				r.reload(ctx)
+			}
+		}
+	}()
+}
+
 func main() {
 	// Parse command-line arguments
 	configFile := flag.String("c", "config.json", "Database configuration file")
@@ -72,6 +227,11 @@ func main() {
 	lazyLoading := flag.Bool("lazy-loading", false, "Enable lazy loading: connections established on first use (recommended for 10+ databases)")
 	logDir := flag.String("log-dir", "", "Directory for log files (default: ./logs in current directory)")
 	unifiedTools := flag.Bool("unified-tools", false, "Register unified tools with database parameter instead of per-database tools")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file for SSE transport (enables HTTPS)")
+	tlsKey := flag.String("tls-key", "", "TLS private key file for SSE transport")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA bundle for verifying client certificates (enables mutual TLS)")
+	onlyTags := flag.String("only-tags", "", "Only use connections with one of these comma-separated tags")
+	excludeEnv := flag.String("exclude-env", "", "Ignore connections in these comma-separated environments, such as prod")
 	flag.Parse()
 
 	// Initialize logger with custom log directory
@@ -133,6 +293,11 @@ func main() {
 	dbConfig := &dbtools.Config{
 		ConfigFile:  cfg.ConfigPath,
 		LazyLoading: *lazyLoading,
+		Filter: db.ConnectionFilter{
+			OnlyTags:            splitList(*onlyTags),
+			ExcludeEnvironments: splitList(*excludeEnv),
+		},
+		Policies: cfg.Policies,
 	}
 
 	// Ensure database configuration exists
@@ -141,11 +306,26 @@ func main() {
 		logger.Info("Lazy loading enabled: database connections will be established on first use")
 	}
 
+	// Secret providers must be known before connections are validated
+	registerSecretProviders(cfg)
+
 	// Try to initialize database from config
 	if err := dbtools.InitDatabase(dbConfig); err != nil {
 		logger.Warn("Warning: Failed to initialize database: %v", err)
 	}
 
+	// Ping connections periodically and reconnect those that are down
+	if err := dbtools.StartHealthMonitor(cfg.Health); err != nil {
+		logger.Warn("Warning: failed to start the health monitor: %v", err)
+	}
+
+	// Close idle connections and cap the open ones, reopening them on next use
+	if cfg.LazyLoading.Enabled() && !*lazyLoading {
+		logger.Warn("Warning: the lazy_loading settings are ignored without -lazy-loading")
+	} else if err := dbtools.StartEviction(cfg.LazyLoading); err != nil {
+		logger.Warn("Warning: failed to start connection eviction: %v", err)
+	}
+
 	// Set up signal handling for clean shutdown
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
@@ -158,9 +338,115 @@ func main() {
 	)
 
 	// Set up Clean Architecture layers
-	dbRepo := repository.NewDatabaseRepository()
+	var dbRepo domain.DatabaseRepository = repository.NewDatabaseRepository()
+
+	// Fail fast on databases that are down instead of waiting for connect timeouts
+	var circuitBreaker *breaker.Breaker
+	if cfg.CircuitBreaker.Enabled {
+		if err := cfg.CircuitBreaker.Validate(); err != nil {
+			logger.Error("Invalid circuit breaker configuration: %v", err)
+			os.Exit(1)
+		}
+		circuitBreaker = breaker.New(cfg.CircuitBreaker)
+		dbRepo = breaker.NewRepository(dbRepo, circuitBreaker)
+		logger.Info("Circuit breaker enabled")
+	}
+
 	dbUseCase := usecase.NewDatabaseUseCase(dbRepo)
 	toolRegistry := mcp.NewToolRegistry(mcpServer, *unifiedTools)
+	if circuitBreaker != nil {
+		toolRegistry.EnableCircuitBreaker(circuitBreaker)
+	}
+
+	// Record every tool invocation when auditing is enabled
+	if cfg.Audit.Enabled {
+		auditLogger, err := audit.New(cfg.Audit, dbRepo)
+		if err != nil {
+			logger.Warn("Warning: failed to initialize audit log: %v", err)
+		} else {
+			toolRegistry.EnableAudit(auditLogger)
+			defer func() {
+				if err := auditLogger.Close(); err != nil {
+					logger.Error("Error closing audit log: %v", err)
+				}
+			}()
+			logger.Info("Audit logging enabled")
+		}
+	}
+
+	// Authenticate SSE clients and restrict them to their token's scope
+	var authenticator *auth.Authenticator
+	sessions := auth.NewSessionStore()
+	if cfg.Auth.Enabled {
+		if cfg.TransportMode != "sse" {
+			logger.Info("Authentication only applies to the SSE transport, ignoring it for %s", cfg.TransportMode)
+		} else {
+			authenticator, err = auth.New(cfg.Auth)
+			if err != nil {
+				logger.Error("Failed to initialize authentication: %v", err)
+				os.Exit(1)
+			}
+			toolRegistry.EnableAuth(sessions)
+			logger.Info("Authentication enabled for the SSE transport")
+		}
+	}
+
+	// Limit the rate and concurrency of tool calls per session and per database
+	var limiter *ratelimit.Limiter
+	if cfg.RateLimit.Enabled {
+		limiter = ratelimit.New(cfg.RateLimit, connectionRateLimits(cfg))
+		toolRegistry.EnableRateLimit(limiter)
+		logger.Info("Rate limiting enabled")
+	}
+
+	// Explain queries first on connections with a cost guard
+	costGuards, err := connectionCostGuards(cfg)
+	if err != nil {
+		logger.Error("Invalid cost guard configuration: %v", err)
+		os.Exit(1)
+	}
+	// The guard is always installed so that a reloaded config can add thresholds
+	guard := costguard.New(costGuards, dbRepo)
+	toolRegistry.EnableCostGuard(guard)
+	if len(costGuards) > 0 {
+		logger.Info("Cost guard enabled for %d connection(s)", len(costGuards))
+	}
+
+	// The administrative tools are only checked by the auth middleware, so
+	// without authentication they need the explicit admin_tools opt-in
+	adminTools := authenticator != nil || cfg.AdminTools
+	if adminTools && authenticator == nil {
+		logger.Warn("Administrative tools enabled by admin_tools without authentication, any client can use them")
+	}
+
+	// Reload database connections when the config file changes, and let
+	// administrators change them through the connection tools
+	reloader := &configReloader{cfg: cfg, registry: toolRegistry, limiter: limiter, guard: guard, breaker: circuitBreaker}
+	admin := &connectionAdmin{reloader: reloader}
+	if adminTools {
+		toolRegistry.EnableConnectionAdmin(admin)
+	}
+
+	// Back up SQLite databases and restore backups as new connections
+	if adminTools {
+		toolRegistry.EnableBackups(backupManager{dir: cfg.Backup.Dir, admin: admin})
+	}
+
+	// Search the FTS5 tables of SQLite databases
+	toolRegistry.EnableSearch(fullTextSearch{})
+
+	// Show the health of each connection in list_databases
+	toolRegistry.EnableHealth(healthReporter{})
+
+	// Describe connections by their labels and keep tools from writing to
+	// read-only connections
+	toolRegistry.EnableLabels(labelProvider{})
+
+	// Report and tune connection pools through the pool tools and /metrics
+	if adminTools {
+		toolRegistry.EnablePools(poolManager{})
+	}
+	registerPoolMetrics()
 
 	// Set the database use case in the tool registry
 	ctx := context.Background()
@@ -219,21 +505,21 @@ func main() {
 		}
 	}
 
-	// Create a session store to track valid sessions
-	sessions := make(map[string]bool)
-
-	// Create a default session for easier testing
-	defaultSessionID := "default-session"
-	sessions[defaultSessionID] = true
-	logger.Info("Created default session: %s", defaultSessionID)
-
 	// Handle transport mode
 	switch cfg.TransportMode {
 	case "sse":
 		logger.Info("Starting SSE server on port %d", cfg.ServerPort)
 
+		// Serve HTTPS when a certificate is configured
+		var tlsConfig *gateway.TLSConfig
+		scheme := "http"
+		if *tlsCert != "" || *tlsKey != "" || *tlsClientCA != "" {
+			tlsConfig = &gateway.TLSConfig{CertFile: *tlsCert, KeyFile: *tlsKey, ClientCAFile: *tlsClientCA}
+			scheme = "https"
+		}
+
 		// Configure base URL with explicit protocol
-		baseURL := fmt.Sprintf("http://%s:%d", *serverHost, cfg.ServerPort)
+		baseURL := fmt.Sprintf("%s://%s:%d", scheme, *serverHost, cfg.ServerPort)
 		logger.Info("Using base URL: %s", baseURL)
 
 		// Set logging mode based on configuration
@@ -245,36 +531,78 @@ func main() {
 				logger.Warn("Warning: failed to set MCP_DISABLE_LOGGING env: %v", err)
 			}
 		}
-		// Set the server address
-		mcpServer.SetAddress(fmt.Sprintf(":%d", cfg.ServerPort))
+		// The MCP server is served by the gateway in-process, without a
+		// port of its own; clients can only reach it through the gateway
+		upstream, err := mcp.HTTPHandler(mcpServer)
+		if err != nil {
+			logger.Error("Failed to set up the MCP server: %v", err)
+			os.Exit(1)
+		}
+
+		gw, err := gateway.New(gateway.Config{
+			Addr:          fmt.Sprintf(":%d", cfg.ServerPort),
+			Upstream:      upstream,
+			Metrics:       metrics.Default.Handler(),
+			Health:        healthHandler(),
+			TLS:           tlsConfig,
+			Authenticator: authenticator,
+			Sessions:      sessions,
+			Tools:         toolRegistry,
+		})
+		if err != nil {
+			logger.Error("Failed to create HTTP gateway: %v", err)
+			os.Exit(1)
+		}
+		if *tlsClientCA != "" {
+			logger.Info("Mutual TLS enabled: clients must present a certificate signed by %s", *tlsClientCA)
+		}
+
+		reloader.notify = gw.NotifyToolsChanged
+		reloader.watch(ctx)
 
 		// Start the server
 		errCh := make(chan error, 1)
 		go func() {
 			logger.Info("Starting server...")
-			errCh <- mcpServer.ServeHTTP()
+			errCh <- gw.ListenAndServe()
 		}()
 
+		// Reload TLS certificates on SIGHUP; the config file is reloaded by the watcher
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+
 		// Wait for interrupt or error
-		select {
-		case err := <-errCh:
-			logger.Error("Server error: %v", err)
-			os.Exit(1)
-		case <-stop:
-			logger.Info("Shutting down server...")
+		for stopped := false; !stopped; {
+			select {
+			case err := <-errCh:
+				logger.Error("Server error: %v", err)
+				os.Exit(1)
+			case <-reload:
+				if !gw.TLSEnabled() {
+					continue
+				}
+				if err := gw.ReloadTLS(); err != nil {
+					logger.Error("Failed to reload TLS certificates, keeping the current ones: %v", err)
+				} else {
+					logger.Info("Reloaded TLS certificates")
+				}
+			case <-stop:
+				stopped = true
+				logger.Info("Shutting down server...")
 
-			// Create shutdown context
-			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer shutdownCancel()
+				// Create shutdown context
+				shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
 
-			// Shutdown the server
-			if err := mcpServer.Shutdown(shutdownCtx); err != nil {
-				logger.Error("Error during server shutdown: %v", err)
-			}
+				// Shutdown the gateway and the MCP server behind it
+				if err := gw.Shutdown(shutdownCtx); err != nil {
+					logger.Error("Error during server shutdown: %v", err)
+				}
+				shutdownCancel()
 
-			// Close database connections
-			if err := dbtools.CloseDatabase(); err != nil {
-				logger.Error("Error closing database connections: %v", err)
+				// Close database connections
+				if err := dbtools.CloseDatabase(); err != nil {
+					logger.Error("Error closing database connections: %v", err)
+				}
 			}
 		}
 
@@ -311,6 +639,9 @@ func main() {
 			}()
 		}
 
+		// stdio clients are not notified of tool changes, removed tools fail when called
+		reloader.watch(ctx)
+
 		// Critical: Use ServeStdio WITHOUT any console output to stdout
 		if err := mcpServer.ServeStdio(); err != nil {
 			// Log error to stderr only - never stdout
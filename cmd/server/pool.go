@@ -0,0 +1,110 @@
+package main
+
+import (
+	"database/sql"
+
+	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp"
+	"github.com/FreePeak/db-mcp-server/internal/metrics"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// poolManager implements the pool tools on top of the database manager
+type poolManager struct{}
+
+// PoolStats returns the pool of a connection, or of all connections
+func (poolManager) PoolStats(id string) ([]mcp.PoolStats, error) {
+	if id == "" {
+		all := dbtools.ListPoolStats()
+		stats := make([]mcp.PoolStats, 0, len(all))
+		for _, s := range all {
+			stats = append(stats, toPoolStats(s))
+		}
+		return stats, nil
+	}
+	s, err := dbtools.GetPoolStats(id)
+	if err != nil {
+		return nil, err
+	}
+	return []mcp.PoolStats{toPoolStats(s)}, nil
+}
+
+// TunePool changes the pool limits of a connection
+func (poolManager) TunePool(id string, settings mcp.PoolSettings) (mcp.PoolStats, error) {
+	s, err := dbtools.TunePool(id, db.PoolSettings{
+		MaxOpenConns:    settings.MaxOpenConns,
+		MaxIdleConns:    settings.MaxIdleConns,
+		ConnMaxLifetime: settings.ConnMaxLifetimeSeconds,
+	})
+	if err != nil {
+		return mcp.PoolStats{}, err
+	}
+	return toPoolStats(s), nil
+}
+
+// toPoolStats converts the pool statistics of a connection for the pool tools
+func toPoolStats(s db.PoolStats) mcp.PoolStats {
+	stats := fromDBStats(s.DBStats)
+	stats.Database = s.ID
+	stats.Type = s.Type
+	stats.Connected = s.Connected
+	stats.MaxOpenConns = s.MaxOpenConns
+	stats.MaxIdleConns = s.MaxIdleConns
+	stats.ConnMaxLifetimeSeconds = int(s.ConnMaxLifetime.Seconds())
+	for _, replica := range s.Replicas {
+		replicaStats := fromDBStats(replica.DBStats)
+		replicaStats.Address = replica.Address
+		replicaStats.Connected = true
+		stats.Replicas = append(stats.Replicas, replicaStats)
+	}
+	return stats
+}
+
+// fromDBStats converts the statistics of a database/sql pool
+func fromDBStats(s sql.DBStats) mcp.PoolStats {
+	return mcp.PoolStats{
+		OpenConnections:   s.OpenConnections,
+		InUse:             s.InUse,
+		Idle:              s.Idle,
+		WaitCount:         s.WaitCount,
+		WaitDurationMs:    s.WaitDuration.Milliseconds(),
+		MaxIdleClosed:     s.MaxIdleClosed,
+		MaxIdleTimeClosed: s.MaxIdleTimeClosed,
+		MaxLifetimeClosed: s.MaxLifetimeClosed,
+	}
+}
+
+// registerPoolMetrics exports the pools of the connected databases. Replica
+// pools are reported by the pool_stats tool only.
+func registerPoolMetrics() {
+	collect := func(value func(s sql.DBStats) float64) func() map[string]float64 {
+		return func() map[string]float64 {
+			values := make(map[string]float64)
+			for _, s := range dbtools.ListPoolStats() {
+				if s.Connected {
+					values[s.ID] = value(s.DBStats)
+				}
+			}
+			return values
+		}
+	}
+
+	metrics.Default.GaugeFunc("mcp_db_pool_max_open_connections", "Maximum open connections of the pool", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	metrics.Default.GaugeFunc("mcp_db_pool_open_connections", "Open connections, in use and idle", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	metrics.Default.GaugeFunc("mcp_db_pool_in_use_connections", "Connections in use", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	metrics.Default.GaugeFunc("mcp_db_pool_idle_connections", "Idle connections", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	metrics.Default.CounterFunc("mcp_db_pool_wait_count_total", "Connections waited for", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	metrics.Default.CounterFunc("mcp_db_pool_wait_duration_seconds_total", "Time spent waiting for connections", "database",
+		collect(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+	metrics.Default.CounterFunc("mcp_db_pool_max_idle_closed_total", "Connections closed because of max idle connections", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.MaxIdleClosed) }))
+	metrics.Default.CounterFunc("mcp_db_pool_max_idle_time_closed_total", "Connections closed because of max idle time", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.MaxIdleTimeClosed) }))
+	metrics.Default.CounterFunc("mcp_db_pool_max_lifetime_closed_total", "Connections closed because of max lifetime", "database",
+		collect(func(s sql.DBStats) float64 { return float64(s.MaxLifetimeClosed) }))
+}
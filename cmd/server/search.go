@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+
+	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// fullTextSearch implements the search tool on top of the database manager
+type fullTextSearch struct{}
+
+// SearchTables returns the FTS5 tables of a SQLite connection
+func (fullTextSearch) SearchTables(ctx context.Context, id string) ([]mcp.SearchTable, error) {
+	tables, err := dbtools.FTSTables(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]mcp.SearchTable, 0, len(tables))
+	for _, table := range tables {
+		result = append(result, mcp.SearchTable(table))
+	}
+	return result, nil
+}
+
+// Search runs a full-text query against an FTS5 table
+func (fullTextSearch) Search(ctx context.Context, id string, req mcp.SearchRequest) ([]mcp.SearchMatch, error) {
+	matches, err := dbtools.Search(ctx, id, db.SearchRequest(req))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]mcp.SearchMatch, 0, len(matches))
+	for _, match := range matches {
+		result = append(result, mcp.SearchMatch(match))
+	}
+	return result, nil
+}
+
+// CreateSearchIndex creates an FTS5 table over text columns of a table
+func (fullTextSearch) CreateSearchIndex(ctx context.Context, id, table string, columns []string) (mcp.SearchTable, error) {
+	index, err := dbtools.CreateFTSIndex(ctx, id, table, columns)
+	if err != nil {
+		return mcp.SearchTable{}, err
+	}
+	return mcp.SearchTable(index), nil
+}
@@ -0,0 +1,38 @@
+-- Event warehouse sample for the ClickHouse test environment
+
+CREATE TABLE IF NOT EXISTS events_test.page_views
+(
+    site_id    UInt32,
+    event_time DateTime,
+    user_id    UInt64,
+    path       String,
+    referrer   Nullable(String),
+    duration   UInt32 DEFAULT 0
+)
+ENGINE = MergeTree
+PARTITION BY toYYYYMM(event_time)
+ORDER BY (site_id, event_time);
+
+CREATE TABLE IF NOT EXISTS events_test.daily_views
+(
+    site_id UInt32,
+    day     Date,
+    views   UInt64
+)
+ENGINE = SummingMergeTree
+ORDER BY (site_id, day);
+
+INSERT INTO events_test.page_views (site_id, event_time, user_id, path, referrer, duration)
+SELECT
+    number % 5 + 1,
+    now() - toIntervalMinute(number),
+    number % 1000,
+    concat('/page/', toString(number % 50)),
+    if(number % 3 = 0, NULL, 'https://example.com/'),
+    number % 300
+FROM numbers(100000);
+
+INSERT INTO events_test.daily_views
+SELECT site_id, toDate(event_time), count()
+FROM events_test.page_views
+GROUP BY site_id, toDate(event_time);
@@ -0,0 +1,186 @@
+// Package audit records every MCP tool invocation so operators can review
+// which statements agents ran against the managed databases.
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// Config holds the audit subsystem configuration (the "audit" section of config.json)
+type Config struct {
+	Enabled bool `json:"enabled"`
+
+	// File sink: JSON Lines written to a size-rotated file
+	File       string `json:"file,omitempty"`
+	MaxSizeMB  int    `json:"max_size_mb,omitempty"` // rotate once the file exceeds this size (default 50)
+	MaxBackups int    `json:"max_backups,omitempty"` // number of rotated files to keep (default 5)
+
+	// Table sink: rows inserted into a table of one of the managed databases
+	Database string `json:"database,omitempty"` // ID of the connection that holds the audit table
+	Table    string `json:"table,omitempty"`    // audit table name (default mcp_audit_log)
+
+	// Masking options
+	MaskParams       bool     `json:"mask_params,omitempty"`       // replace every statement parameter with ***
+	MaskLiterals     bool     `json:"mask_literals,omitempty"`     // replace every string literal in SQL text with '***'
+	SensitiveColumns []string `json:"sensitive_columns,omitempty"` // statements naming these columns have all literals and parameters masked
+}
+
+// Entry is a single audited tool invocation
+type Entry struct {
+	Time         time.Time     `json:"time"`
+	SessionID    string        `json:"session_id,omitempty"`
+	Tool         string        `json:"tool"`
+	Database     string        `json:"database,omitempty"`
+	SQL          string        `json:"sql,omitempty"`
+	Params       []interface{} `json:"params,omitempty"`
+	DurationMS   float64       `json:"duration_ms"`
+	RowsReturned int64         `json:"rows_returned"`
+	RowsAffected int64         `json:"rows_affected"`
+	Error        string        `json:"error,omitempty"`
+}
+
+// Filter selects entries returned by Search
+type Filter struct {
+	Since      time.Time
+	SessionID  string
+	Tool       string
+	Database   string
+	Contains   string // substring of the SQL text
+	ErrorsOnly bool
+	Limit      int
+}
+
+// Sink persists audit entries. Sinks are used concurrently and do their own
+// locking, so that a slow sink does not hold up the others.
+type Sink interface {
+	Write(ctx context.Context, entry Entry) error
+	Close() error
+}
+
+// Searcher is implemented by sinks that can read back recent entries
+type Searcher interface {
+	Search(ctx context.Context, filter Filter) ([]Entry, error)
+}
+
+// DatabaseProvider gives the table sink access to the managed databases
+type DatabaseProvider interface {
+	GetDatabase(id string) (domain.Database, error)
+	GetDatabaseType(id string) (string, error)
+}
+
+// defaultSearchLimit caps the number of entries returned when no limit is given
+const defaultSearchLimit = 50
+
+// Logger masks and fans out audit entries to the configured sinks
+type Logger struct {
+	sinks  []Sink
+	masker *Masker
+}
+
+// New creates an audit logger from configuration
+func New(cfg Config, provider DatabaseProvider) (*Logger, error) {
+	l := &Logger{masker: NewMasker(cfg)}
+
+	if cfg.File != "" {
+		sink, err := NewFileSink(cfg.File, cfg.MaxSizeMB, cfg.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		l.sinks = append(l.sinks, sink)
+	}
+
+	if cfg.Database != "" {
+		if provider == nil {
+			return nil, fmt.Errorf("audit table sink requires a database provider")
+		}
+		sink, err := NewTableSink(provider, cfg.Database, cfg.Table)
+		if err != nil {
+			_ = l.Close()
+			return nil, err
+		}
+		l.sinks = append(l.sinks, sink)
+	}
+
+	if len(l.sinks) == 0 {
+		return nil, fmt.Errorf("audit is enabled but neither a file nor a database is configured")
+	}
+
+	return l, nil
+}
+
+// NewWithSinks creates an audit logger writing to the given sinks
+func NewWithSinks(masker *Masker, sinks ...Sink) *Logger {
+	if masker == nil {
+		masker = NewMasker(Config{})
+	}
+	return &Logger{masker: masker, sinks: sinks}
+}
+
+// Record masks the entry and writes it to every sink.
+// Sink failures are logged rather than returned so auditing never fails a tool call.
+func (l *Logger) Record(ctx context.Context, entry Entry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	entry.Params = l.masker.MaskParams(entry.SQL, entry.Params)
+	entry.SQL = l.masker.MaskSQL(entry.SQL)
+
+	for _, sink := range l.sinks {
+		if err := sink.Write(ctx, entry); err != nil {
+			logger.Error("Failed to write audit entry to %T: %v", sink, err)
+		}
+	}
+}
+
+// Search returns recent entries matching the filter, newest first
+func (l *Logger) Search(ctx context.Context, filter Filter) ([]Entry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultSearchLimit
+	}
+
+	for _, sink := range l.sinks {
+		if searcher, ok := sink.(Searcher); ok {
+			return searcher.Search(ctx, filter)
+		}
+	}
+	return nil, fmt.Errorf("no configured audit sink supports searching")
+}
+
+// Close closes all sinks
+func (l *Logger) Close() error {
+	var firstErr error
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Matches reports whether the entry satisfies the filter
+func (f Filter) Matches(entry Entry) bool {
+	if !f.Since.IsZero() && entry.Time.Before(f.Since) {
+		return false
+	}
+	if f.SessionID != "" && entry.SessionID != f.SessionID {
+		return false
+	}
+	if f.Tool != "" && entry.Tool != f.Tool {
+		return false
+	}
+	if f.Database != "" && entry.Database != f.Database {
+		return false
+	}
+	if f.Contains != "" && !containsFold(entry.SQL, f.Contains) {
+		return false
+	}
+	if f.ErrorsOnly && entry.Error == "" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,171 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaskerSensitiveColumns(t *testing.T) {
+	masker := NewMasker(Config{})
+
+	masked := masker.MaskSQL("UPDATE users SET password = 'hunter2' WHERE name = 'bob'")
+	assert.Equal(t, "UPDATE users SET password = '***' WHERE name = '***'", masked)
+
+	masked = masker.MaskSQL("SELECT * FROM keys WHERE api_key LIKE 'abc%'")
+	assert.Equal(t, "SELECT * FROM keys WHERE api_key LIKE '***'", masked)
+
+	// Values are not next to their column in INSERT and multi-column SET
+	masked = masker.MaskSQL("INSERT INTO users (name, password) VALUES ('bob', 'hunter2')")
+	assert.Equal(t, "INSERT INTO users (name, password) VALUES ('***', '***')", masked)
+
+	masked = masker.MaskSQL("UPDATE users SET (name, token) = ('bob', 'abc'), note = 'x' WHERE id = 1")
+	assert.Equal(t, "UPDATE users SET (name, token) = ('***', '***'), note = '***' WHERE id = 1", masked)
+
+	masked = masker.MaskSQL("UPDATE users SET name = 'bob', passwd = 'hunter2'")
+	assert.Equal(t, "UPDATE users SET name = '***', passwd = '***'", masked)
+
+	// Other statements, and sensitive names inside literals, are kept
+	masked = masker.MaskSQL("SELECT * FROM users WHERE note = 'reset password' AND password_hint = 'pet'")
+	assert.Equal(t, "SELECT * FROM users WHERE note = 'reset password' AND password_hint = 'pet'", masked)
+
+	// Parameters are masked with the statement's literals, others only with mask_params
+	params := []interface{}{"bob", "hunter2"}
+	assert.Equal(t, []interface{}{"***", "***"}, masker.MaskParams("INSERT INTO users (name, password) VALUES (?, ?)", params))
+	assert.Equal(t, params, masker.MaskParams("INSERT INTO users (name, email) VALUES (?, ?)", params))
+}
+
+func TestMaskerAllLiteralsAndParams(t *testing.T) {
+	masker := NewMasker(Config{MaskParams: true, MaskLiterals: true})
+
+	masked := masker.MaskSQL("SELECT * FROM t WHERE a = 'it''s' AND b = 'x'")
+	assert.Equal(t, "SELECT * FROM t WHERE a = '***' AND b = '***'", masked)
+
+	assert.Equal(t, []interface{}{"***", "***"}, masker.MaskParams("SELECT ?, ?", []interface{}{"secret", 42}))
+}
+
+func TestFilterMatches(t *testing.T) {
+	now := time.Now()
+	entry := Entry{Time: now, SessionID: "s1", Tool: "query_db1", Database: "db1", SQL: "SELECT * FROM Orders"}
+
+	assert.True(t, Filter{}.Matches(entry))
+	assert.True(t, Filter{Contains: "orders", Database: "db1"}.Matches(entry))
+	assert.False(t, Filter{Tool: "execute_db1"}.Matches(entry))
+	assert.False(t, Filter{ErrorsOnly: true}.Matches(entry))
+	assert.False(t, Filter{Since: now.Add(time.Minute)}.Matches(entry))
+}
+
+func TestFileSinkRotationAndSearch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+
+	sink, err := NewFileSink(path, 1, 2)
+	require.NoError(t, err)
+	defer func() { _ = sink.Close() }()
+
+	// Force rotation after every entry by shrinking the size limit
+	sink.maxSize = 10
+
+	ctx := context.Background()
+	base := time.Now().UTC()
+	for i := 0; i < 4; i++ {
+		require.NoError(t, sink.Write(ctx, Entry{
+			Time:     base.Add(time.Duration(i) * time.Second),
+			Tool:     "query_db1",
+			Database: "db1",
+			SQL:      "SELECT " + string(rune('a'+i)),
+		}))
+	}
+
+	// Only the current file and two backups are kept
+	_, err = os.Stat(path + ".2")
+	assert.NoError(t, err)
+	_, err = os.Stat(path + ".3")
+	assert.True(t, os.IsNotExist(err))
+
+	entries, err := sink.Search(ctx, Filter{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "SELECT d", entries[0].SQL)
+	assert.Equal(t, "SELECT b", entries[2].SQL)
+
+	entries, err = sink.Search(ctx, Filter{Contains: "select c", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestLoggerRecordMasksEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	auditLogger, err := New(Config{Enabled: true, File: path, MaskParams: true}, nil)
+	require.NoError(t, err)
+	defer func() { _ = auditLogger.Close() }()
+
+	ctx := context.Background()
+	auditLogger.Record(ctx, Entry{
+		SessionID: "session-1",
+		Tool:      "execute_db1",
+		Database:  "db1",
+		SQL:       "INSERT INTO users (name, password) VALUES (?, ?)",
+		Params:    []interface{}{"bob", "hunter2"},
+		Error:     "constraint violation",
+	})
+
+	entries, err := auditLogger.Search(ctx, Filter{ErrorsOnly: true})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []interface{}{"***", "***"}, entries[0].Params)
+	assert.Equal(t, "session-1", entries[0].SessionID)
+	assert.False(t, entries[0].Time.IsZero())
+}
+
+// blockingSink holds every write until it is released
+type blockingSink struct {
+	writing chan struct{}
+	release chan struct{}
+}
+
+func (s blockingSink) Write(context.Context, Entry) error {
+	s.writing <- struct{}{}
+	<-s.release
+	return nil
+}
+
+func (s blockingSink) Close() error { return nil }
+
+func TestLoggerSlowSinkDoesNotBlockSearch(t *testing.T) {
+	fileSink, err := NewFileSink(filepath.Join(t.TempDir(), "audit.jsonl"), 0, 0)
+	require.NoError(t, err)
+	slow := blockingSink{writing: make(chan struct{}), release: make(chan struct{})}
+	auditLogger := NewWithSinks(nil, fileSink, slow)
+	defer func() { _ = auditLogger.Close() }()
+
+	ctx := context.Background()
+	done := make(chan struct{})
+	go func() {
+		auditLogger.Record(ctx, Entry{Tool: "query_db1", SQL: "SELECT 1"})
+		close(done)
+	}()
+	<-slow.writing
+
+	// The file sink is searchable while the slow sink still writes
+	entries, err := auditLogger.Search(ctx, Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "query_db1", entries[0].Tool)
+
+	close(slow.release)
+	<-done
+}
+
+func TestNewRequiresSink(t *testing.T) {
+	_, err := New(Config{Enabled: true}, nil)
+	assert.Error(t, err)
+
+	_, err = New(Config{Enabled: true, Database: "db1"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Default rotation settings for the file sink
+const (
+	defaultMaxSizeMB  = 50
+	defaultMaxBackups = 5
+)
+
+// FileSink writes audit entries as JSON Lines to a size-rotated file.
+// Rotated files are named <file>.1 (newest) to <file>.<max_backups> (oldest).
+type FileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex // guards the file, its size and rotation
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens (or creates) the audit file
+func NewFileSink(path string, maxSizeMB, maxBackups int) (*FileSink, error) {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	s := &FileSink{
+		path:       path,
+		maxSize:    int64(maxSizeMB) * 1024 * 1024,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// open opens the current audit file in append mode
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log %s: %w", s.path, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat audit log %s: %w", s.path, err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// Write appends an entry, rotating the file first if it would grow past the size limit
+func (s *FileSink) Write(_ context.Context, entry Entry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts the backups by one and starts a new audit file
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close audit log for rotation: %w", err)
+	}
+
+	// Drop the oldest backup, then shift the rest: file.N-1 -> file.N ... file -> file.1
+	_ = os.Remove(s.backupPath(s.maxBackups))
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if _, err := os.Stat(s.backupPath(i)); err == nil {
+			if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil {
+				return fmt.Errorf("failed to rotate audit log: %w", err)
+			}
+		}
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate audit log: %w", err)
+	}
+
+	return s.open()
+}
+
+// backupPath returns the path of the n-th rotated file
+func (s *FileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
+
+// Search scans the current file and its backups for matching entries, newest first
+func (s *FileSink) Search(_ context.Context, filter Filter) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.file.Sync(); err != nil {
+		return nil, fmt.Errorf("failed to flush audit log: %w", err)
+	}
+
+	var results []Entry
+	paths := []string{s.path}
+	for i := 1; i <= s.maxBackups; i++ {
+		paths = append(paths, s.backupPath(i))
+	}
+
+	for _, path := range paths {
+		entries, err := readEntries(path, filter)
+		if err != nil {
+			if os.IsNotExist(err) {
+				break
+			}
+			return nil, err
+		}
+
+		// Entries within a file are oldest first
+		for i := len(entries) - 1; i >= 0; i-- {
+			results = append(results, entries[i])
+			if len(results) >= filter.Limit {
+				return results, nil
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// readEntries reads the matching entries of a single audit file
+func readEntries(path string, filter Filter) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = file.Close() }()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Close closes the audit file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,85 @@
+package audit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// maskedValue replaces sensitive values in audit entries
+const maskedValue = "***"
+
+// defaultSensitiveColumns are masked even when no sensitive_columns are configured
+var defaultSensitiveColumns = []string{"password", "passwd", "secret", "token", "api_key"}
+
+// stringLiteral matches single-quoted SQL string literals, including escaped quotes
+var stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// Masker hides sensitive values in SQL text and statement parameters
+type Masker struct {
+	maskParams      bool
+	maskLiterals    bool
+	sensitiveColumn *regexp.Regexp
+}
+
+// NewMasker creates a masker from the audit configuration
+func NewMasker(cfg Config) *Masker {
+	columns := cfg.SensitiveColumns
+	if len(columns) == 0 {
+		columns = defaultSensitiveColumns
+	}
+
+	quoted := make([]string, 0, len(columns))
+	for _, col := range columns {
+		if col = strings.TrimSpace(col); col != "" {
+			quoted = append(quoted, regexp.QuoteMeta(col))
+		}
+	}
+
+	m := &Masker{
+		maskParams:   cfg.MaskParams,
+		maskLiterals: cfg.MaskLiterals,
+	}
+	if len(quoted) > 0 {
+		m.sensitiveColumn = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	}
+	return m
+}
+
+// sensitive reports whether a statement names a sensitive column. Values
+// cannot be matched to columns reliably, as in INSERT ... VALUES or an UPDATE
+// setting several columns, so such statements are masked as a whole.
+func (m *Masker) sensitive(sql string) bool {
+	return m.sensitiveColumn != nil && m.sensitiveColumn.MatchString(stringLiteral.ReplaceAllString(sql, "''"))
+}
+
+// MaskSQL masks string literals in a SQL statement according to the
+// configuration: all of them with mask_literals, or when the statement names
+// a sensitive column
+func (m *Masker) MaskSQL(sql string) string {
+	if sql == "" {
+		return sql
+	}
+	if m.maskLiterals || m.sensitive(sql) {
+		return stringLiteral.ReplaceAllString(sql, "'"+maskedValue+"'")
+	}
+	return sql
+}
+
+// MaskParams masks the parameters of a statement according to the
+// configuration: all of them with mask_params, or when the statement names a
+// sensitive column
+func (m *Masker) MaskParams(sql string, params []interface{}) []interface{} {
+	if len(params) == 0 || !m.maskParams && !m.sensitive(sql) {
+		return params
+	}
+	masked := make([]interface{}, len(params))
+	for i := range params {
+		masked[i] = maskedValue
+	}
+	return masked
+}
+
+// containsFold reports whether substr is within s, ignoring case
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,252 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+)
+
+// defaultTable is the audit table used when none is configured
+const defaultTable = "mcp_audit_log"
+
+// validTableName matches plain or schema-qualified table names
+var validTableName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// auditColumns lists the audit table columns in insert order
+var auditColumns = []string{
+	"event_time", "session_id", "tool", "database_id", "sql_text", "params",
+	"duration_ms", "rows_returned", "rows_affected", "error_text",
+}
+
+// createTableStatements holds the DDL used to create the audit table per database type
+var createTableStatements = map[string]string{
+	"postgres": `CREATE TABLE IF NOT EXISTS %s (
+		id BIGSERIAL PRIMARY KEY, event_time TIMESTAMPTZ NOT NULL, session_id TEXT, tool TEXT NOT NULL,
+		database_id TEXT, sql_text TEXT, params TEXT, duration_ms DOUBLE PRECISION,
+		rows_returned BIGINT, rows_affected BIGINT, error_text TEXT)`,
+	"mysql": `CREATE TABLE IF NOT EXISTS %s (
+		id BIGINT AUTO_INCREMENT PRIMARY KEY, event_time DATETIME(6) NOT NULL, session_id VARCHAR(255), tool VARCHAR(255) NOT NULL,
+		database_id VARCHAR(255), sql_text LONGTEXT, params LONGTEXT, duration_ms DOUBLE,
+		rows_returned BIGINT, rows_affected BIGINT, error_text TEXT)`,
+	"sqlite": `CREATE TABLE IF NOT EXISTS %s (
+		id INTEGER PRIMARY KEY AUTOINCREMENT, event_time TIMESTAMP NOT NULL, session_id TEXT, tool TEXT NOT NULL,
+		database_id TEXT, sql_text TEXT, params TEXT, duration_ms REAL,
+		rows_returned INTEGER, rows_affected INTEGER, error_text TEXT)`,
+}
+
+// TableSink writes audit entries into a table of one of the managed
+// databases. Inserts run concurrently on the pool of the connection.
+type TableSink struct {
+	provider DatabaseProvider
+	dbID     string
+	dbType   string
+	table    string
+}
+
+// NewTableSink creates the sink and makes sure the audit table exists.
+// Oracle tables must be created up front since Oracle lacks CREATE TABLE IF NOT EXISTS.
+func NewTableSink(provider DatabaseProvider, dbID, table string) (*TableSink, error) {
+	if table == "" {
+		table = defaultTable
+	}
+	if !validTableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid audit table name: %s", table)
+	}
+
+	dbType, err := provider.GetDatabaseType(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("audit database %s: %w", dbID, err)
+	}
+
+	s := &TableSink{provider: provider, dbID: dbID, dbType: dbType, table: table}
+
+	if ddl, ok := createTableStatements[dbType]; ok {
+		db, err := provider.GetDatabase(dbID)
+		if err != nil {
+			return nil, fmt.Errorf("audit database %s: %w", dbID, err)
+		}
+		if _, err := db.Exec(context.Background(), fmt.Sprintf(ddl, table)); err != nil {
+			return nil, fmt.Errorf("failed to create audit table %s: %w", table, err)
+		}
+	} else {
+		logger.Info("Audit table %s on %s (%s) must already exist", table, dbID, dbType)
+	}
+
+	return s, nil
+}
+
+// placeholder returns the n-th (1-based) bind placeholder for the database type
+func (s *TableSink) placeholder(n int) string {
+	if d, ok := dialect.Lookup(s.dbType); ok {
+		return d.Placeholder(n)
+	}
+	return "?"
+}
+
+// database returns the connection holding the audit table
+func (s *TableSink) database() (domain.Database, error) {
+	return s.provider.GetDatabase(s.dbID)
+}
+
+// Write inserts an entry into the audit table
+func (s *TableSink) Write(ctx context.Context, entry Entry) error {
+	db, err := s.database()
+	if err != nil {
+		return err
+	}
+
+	params := ""
+	if len(entry.Params) > 0 {
+		encoded, err := json.Marshal(entry.Params)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit params: %w", err)
+		}
+		params = string(encoded)
+	}
+
+	placeholders := make([]string, len(auditColumns))
+	for i := range auditColumns {
+		placeholders[i] = s.placeholder(i + 1)
+	}
+
+	statement := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		s.table, strings.Join(auditColumns, ", "), strings.Join(placeholders, ", "))
+
+	// Use a detached context so a cancelled tool call still gets audited
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+
+	_, err = db.Exec(writeCtx, statement,
+		entry.Time, entry.SessionID, entry.Tool, entry.Database, entry.SQL, params,
+		entry.DurationMS, entry.RowsReturned, entry.RowsAffected, entry.Error)
+	return err
+}
+
+// Search queries the audit table for matching entries, newest first
+func (s *TableSink) Search(ctx context.Context, filter Filter) ([]Entry, error) {
+	db, err := s.database()
+	if err != nil {
+		return nil, err
+	}
+
+	var conditions []string
+	var args []interface{}
+	addCondition := func(format string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(format, s.placeholder(len(args))))
+	}
+
+	if !filter.Since.IsZero() {
+		addCondition("event_time >= %s", filter.Since)
+	}
+	if filter.SessionID != "" {
+		addCondition("session_id = %s", filter.SessionID)
+	}
+	if filter.Tool != "" {
+		addCondition("tool = %s", filter.Tool)
+	}
+	if filter.Database != "" {
+		addCondition("database_id = %s", filter.Database)
+	}
+	if filter.Contains != "" {
+		addCondition("LOWER(sql_text) LIKE %s", "%"+strings.ToLower(filter.Contains)+"%")
+	}
+	if filter.ErrorsOnly {
+		conditions = append(conditions, "error_text IS NOT NULL AND error_text <> ''")
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", strings.Join(auditColumns, ", "), s.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY event_time DESC"
+	if s.dbType == "oracle" {
+		query += fmt.Sprintf(" FETCH FIRST %d ROWS ONLY", filter.Limit)
+	} else {
+		query += fmt.Sprintf(" LIMIT %d", filter.Limit)
+	}
+
+	rows, err := db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit table: %w", err)
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			logger.Error("error closing rows: %v", closeErr)
+		}
+	}()
+
+	var entries []Entry
+	for rows.Next() {
+		var eventTime, sessionID, tool, database, sqlText, params, errText interface{}
+		var duration float64
+		var rowsReturned, rowsAffected int64
+		if err := rows.Scan(&eventTime, &sessionID, &tool, &database, &sqlText, &params,
+			&duration, &rowsReturned, &rowsAffected, &errText); err != nil {
+			return nil, fmt.Errorf("failed to scan audit row: %w", err)
+		}
+
+		entry := Entry{
+			Time:         toTime(eventTime),
+			SessionID:    toString(sessionID),
+			Tool:         toString(tool),
+			Database:     toString(database),
+			SQL:          toString(sqlText),
+			DurationMS:   duration,
+			RowsReturned: rowsReturned,
+			RowsAffected: rowsAffected,
+			Error:        toString(errText),
+		}
+		if encoded := toString(params); encoded != "" {
+			_ = json.Unmarshal([]byte(encoded), &entry.Params)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading audit rows: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Close is a no-op: the connection is owned by the database manager
+func (s *TableSink) Close() error {
+	return nil
+}
+
+// toString converts a scanned column value to a string
+func toString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(val)
+	case string:
+		return val
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// toTime converts a scanned column value to a time, accepting drivers that return text
+func toTime(v interface{}) time.Time {
+	switch val := v.(type) {
+	case time.Time:
+		return val
+	case []byte, string:
+		str := toString(val)
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999"} {
+			if t, err := time.Parse(layout, str); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
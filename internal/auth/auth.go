@@ -0,0 +1,232 @@
+// Package auth authenticates callers of the HTTP transport with bearer tokens
+// (static tokens or JWTs) and describes which databases and tool kinds each
+// caller may use.
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrUnauthenticated is returned when a request carries no valid credentials
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Config holds the authentication configuration (the "auth" section of config.json)
+type Config struct {
+	Enabled bool          `json:"enabled"`
+	Tokens  []TokenConfig `json:"tokens,omitempty"`
+	JWT     *JWTConfig    `json:"jwt,omitempty"`
+
+	// ClientCerts maps verified TLS client certificates to scopes (requires -tls-client-ca)
+	ClientCerts []ClientCertConfig `json:"client_certs,omitempty"`
+}
+
+// TokenConfig describes a static bearer token and its scope
+type TokenConfig struct {
+	Name      string   `json:"name"`                // caller name used in logs and the audit log
+	Token     string   `json:"token"`               // the bearer token itself
+	Databases []string `json:"databases,omitempty"` // allowed database IDs (empty or "*" for all)
+	Tools     []string `json:"tools,omitempty"`     // allowed tool kinds such as query or schema (empty or "*" for all)
+	Admin     bool     `json:"admin,omitempty"`     // allows administrative tools such as audit_search
+}
+
+// ClientCertConfig describes the scope granted to a TLS client certificate
+type ClientCertConfig struct {
+	Subject   string   `json:"subject"`             // certificate common name or full subject, e.g. "CN=agent,O=Acme"
+	Databases []string `json:"databases,omitempty"` // allowed database IDs (empty or "*" for all)
+	Tools     []string `json:"tools,omitempty"`     // allowed tool kinds (empty or "*" for all)
+	Admin     bool     `json:"admin,omitempty"`     // allows administrative tools such as audit_search
+}
+
+// Principal is an authenticated caller and its scope
+type Principal struct {
+	Subject   string
+	Databases []string
+	Tools     []string
+	Admin     bool
+}
+
+// AllowsDatabase reports whether the principal may use the given database
+func (p *Principal) AllowsDatabase(dbID string) bool {
+	return allows(p.Databases, dbID)
+}
+
+// AllowsTool reports whether the principal may use tools of the given kind
+func (p *Principal) AllowsTool(kind string) bool {
+	return allows(p.Tools, kind)
+}
+
+// allows matches a value against a scope list where an empty list or "*" means everything
+func allows(scope []string, value string) bool {
+	if len(scope) == 0 {
+		return true
+	}
+	for _, s := range scope {
+		if s == "*" || s == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator validates bearer tokens
+type Authenticator struct {
+	tokens []staticToken
+	jwt    *jwtVerifier
+	certs  []ClientCertConfig
+}
+
+// staticToken is a configured token, stored as a hash so comparisons take constant time
+type staticToken struct {
+	hash      [32]byte
+	principal Principal
+}
+
+// New creates an authenticator from the configuration
+func New(cfg Config) (*Authenticator, error) {
+	a := &Authenticator{}
+
+	for i, t := range cfg.Tokens {
+		if t.Token == "" {
+			return nil, fmt.Errorf("auth token %d (%s) has an empty token", i, t.Name)
+		}
+		name := t.Name
+		if name == "" {
+			name = fmt.Sprintf("token-%d", i+1)
+		}
+		a.tokens = append(a.tokens, staticToken{
+			hash: sha256.Sum256([]byte(t.Token)),
+			principal: Principal{
+				Subject:   name,
+				Databases: t.Databases,
+				Tools:     t.Tools,
+				Admin:     t.Admin,
+			},
+		})
+	}
+
+	if cfg.JWT != nil {
+		verifier, err := newJWTVerifier(*cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		a.jwt = verifier
+	}
+
+	for i, c := range cfg.ClientCerts {
+		if c.Subject == "" {
+			return nil, fmt.Errorf("auth client certificate %d has an empty subject", i)
+		}
+	}
+	a.certs = cfg.ClientCerts
+
+	if len(a.tokens) == 0 && a.jwt == nil && len(a.certs) == 0 {
+		return nil, errors.New("auth is enabled but no tokens, jwt or client certificate settings are configured")
+	}
+
+	return a, nil
+}
+
+// Authenticate returns the principal for a bearer token
+func (a *Authenticator) Authenticate(token string) (*Principal, error) {
+	if token == "" {
+		return nil, ErrUnauthenticated
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	var match *Principal
+	for i := range a.tokens {
+		// Compare against every token so timing does not reveal which one matched
+		if subtle.ConstantTimeCompare(hash[:], a.tokens[i].hash[:]) == 1 {
+			match = &a.tokens[i].principal
+		}
+	}
+	if match != nil {
+		principal := *match
+		return &principal, nil
+	}
+
+	if a.jwt != nil && strings.Count(token, ".") == 2 {
+		principal, err := a.jwt.verify(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+		}
+		return principal, nil
+	}
+
+	return nil, ErrUnauthenticated
+}
+
+// AuthenticateRequest returns the principal for an HTTP request, preferring a
+// verified client certificate with a configured subject over a bearer token
+func (a *Authenticator) AuthenticateRequest(r *http.Request) (*Principal, error) {
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.PeerCertificates) > 0 {
+		if principal := a.AuthenticateCertificate(r.TLS.PeerCertificates[0]); principal != nil {
+			return principal, nil
+		}
+	}
+	return a.Authenticate(BearerToken(r))
+}
+
+// AuthenticateCertificate returns the principal for a verified client
+// certificate, or nil when its subject is not configured
+func (a *Authenticator) AuthenticateCertificate(cert *x509.Certificate) *Principal {
+	for _, c := range a.certs {
+		if c.Subject == cert.Subject.CommonName || c.Subject == cert.Subject.String() {
+			return &Principal{
+				Subject:   c.Subject,
+				Databases: c.Databases,
+				Tools:     c.Tools,
+				Admin:     c.Admin,
+			}
+		}
+	}
+	return nil
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer <token>" header
+func BearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// SessionStore binds MCP session IDs to the principal that opened them
+type SessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*Principal
+}
+
+// NewSessionStore creates an empty session store
+func NewSessionStore() *SessionStore {
+	return &SessionStore{sessions: make(map[string]*Principal)}
+}
+
+// Bind associates a session with a principal
+func (s *SessionStore) Bind(sessionID string, principal *Principal) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[sessionID] = principal
+}
+
+// Unbind forgets a session
+func (s *SessionStore) Unbind(sessionID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, sessionID)
+}
+
+// Principal returns the principal bound to a session, or nil
+func (s *SessionStore) Principal(sessionID string) *Principal {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.sessions[sessionID]
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticTokens(t *testing.T) {
+	a, err := New(Config{Enabled: true, Tokens: []TokenConfig{
+		{Name: "reporting", Token: "r-token", Databases: []string{"analytics"}, Tools: []string{"query", "schema"}},
+		{Name: "ops", Token: "o-token", Admin: true},
+	}})
+	require.NoError(t, err)
+
+	principal, err := a.Authenticate("r-token")
+	require.NoError(t, err)
+	assert.Equal(t, "reporting", principal.Subject)
+	assert.True(t, principal.AllowsDatabase("analytics"))
+	assert.False(t, principal.AllowsDatabase("billing"))
+	assert.True(t, principal.AllowsTool("query"))
+	assert.False(t, principal.AllowsTool("execute"))
+
+	principal, err = a.Authenticate("o-token")
+	require.NoError(t, err)
+	assert.True(t, principal.Admin)
+	assert.True(t, principal.AllowsDatabase("billing"))
+
+	_, err = a.Authenticate("nope")
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+	_, err = a.Authenticate("")
+	assert.True(t, errors.Is(err, ErrUnauthenticated))
+}
+
+func TestJWTHS256(t *testing.T) {
+	a, err := New(Config{Enabled: true, JWT: &JWTConfig{Algorithm: "HS256", Secret: "s3cret", Issuer: "idp"}})
+	require.NoError(t, err)
+
+	sign := func(claims jwt.MapClaims, secret string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return token
+	}
+
+	valid := sign(jwt.MapClaims{
+		"sub":       "alice",
+		"iss":       "idp",
+		"exp":       time.Now().Add(time.Hour).Unix(),
+		"databases": []string{"db1"},
+		"tools":     "query",
+	}, "s3cret")
+	principal, err := a.Authenticate(valid)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", principal.Subject)
+	assert.Equal(t, []string{"db1"}, principal.Databases)
+	assert.Equal(t, []string{"query"}, principal.Tools)
+
+	// Wrong signature, expired, missing expiry and wrong issuer are all rejected
+	for _, token := range []string{
+		sign(jwt.MapClaims{"sub": "alice", "iss": "idp", "exp": time.Now().Add(time.Hour).Unix()}, "other"),
+		sign(jwt.MapClaims{"sub": "alice", "iss": "idp", "exp": time.Now().Add(-time.Hour).Unix()}, "s3cret"),
+		sign(jwt.MapClaims{"sub": "alice", "iss": "idp"}, "s3cret"),
+		sign(jwt.MapClaims{"sub": "alice", "iss": "evil", "exp": time.Now().Add(time.Hour).Unix()}, "s3cret"),
+	} {
+		_, err := a.Authenticate(token)
+		assert.True(t, errors.Is(err, ErrUnauthenticated))
+	}
+}
+
+func TestNewValidatesConfig(t *testing.T) {
+	_, err := New(Config{Enabled: true})
+	assert.Error(t, err)
+
+	_, err = New(Config{Enabled: true, Tokens: []TokenConfig{{Name: "empty"}}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Enabled: true, JWT: &JWTConfig{Algorithm: "none"}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Enabled: true, JWT: &JWTConfig{Algorithm: "RS256"}})
+	assert.Error(t, err)
+}
+
+func TestBearerToken(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "/sse", nil)
+	assert.Empty(t, BearerToken(r))
+
+	r.Header.Set("Authorization", "Bearer abc")
+	assert.Equal(t, "abc", BearerToken(r))
+
+	r.Header.Set("Authorization", "Basic abc")
+	assert.Empty(t, BearerToken(r))
+}
+
+func TestAuthenticateCertificate(t *testing.T) {
+	a, err := New(Config{Enabled: true, ClientCerts: []ClientCertConfig{
+		{Subject: "agent", Tools: []string{"query"}},
+		{Subject: "CN=ops,O=Acme", Admin: true},
+	}})
+	require.NoError(t, err)
+
+	principal := a.AuthenticateCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "agent"}})
+	require.NotNil(t, principal)
+	assert.Equal(t, []string{"query"}, principal.Tools)
+
+	principal = a.AuthenticateCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "ops", Organization: []string{"Acme"}}})
+	require.NotNil(t, principal)
+	assert.True(t, principal.Admin)
+
+	assert.Nil(t, a.AuthenticateCertificate(&x509.Certificate{Subject: pkix.Name{CommonName: "stranger"}}))
+}
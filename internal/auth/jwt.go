@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig holds the settings for validating JWT bearer tokens
+type JWTConfig struct {
+	Algorithm     string `json:"algorithm"`                 // HS256 or RS256
+	Secret        string `json:"secret,omitempty"`          // shared secret for HS256
+	PublicKeyFile string `json:"public_key_file,omitempty"` // PEM encoded RSA public key for RS256
+	Issuer        string `json:"issuer,omitempty"`          // required "iss" claim, if set
+	Audience      string `json:"audience,omitempty"`        // required "aud" claim, if set
+
+	// Claim names holding the caller's scope (default "databases", "tools" and "admin")
+	DatabasesClaim string `json:"databases_claim,omitempty"`
+	ToolsClaim     string `json:"tools_claim,omitempty"`
+	AdminClaim     string `json:"admin_claim,omitempty"`
+}
+
+// jwtVerifier validates JWTs and maps their claims to a principal
+type jwtVerifier struct {
+	cfg    JWTConfig
+	key    interface{}
+	parser *jwt.Parser
+}
+
+// newJWTVerifier loads the signing key for the configured algorithm
+func newJWTVerifier(cfg JWTConfig) (*jwtVerifier, error) {
+	if cfg.DatabasesClaim == "" {
+		cfg.DatabasesClaim = "databases"
+	}
+	if cfg.ToolsClaim == "" {
+		cfg.ToolsClaim = "tools"
+	}
+	if cfg.AdminClaim == "" {
+		cfg.AdminClaim = "admin"
+	}
+
+	var key interface{}
+	switch cfg.Algorithm {
+	case "HS256":
+		if cfg.Secret == "" {
+			return nil, errors.New("jwt algorithm HS256 requires a secret")
+		}
+		key = []byte(cfg.Secret)
+	case "RS256":
+		if cfg.PublicKeyFile == "" {
+			return nil, errors.New("jwt algorithm RS256 requires public_key_file")
+		}
+		pemData, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read jwt public key: %w", err)
+		}
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse jwt public key: %w", err)
+		}
+		key = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q (use HS256 or RS256)", cfg.Algorithm)
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{cfg.Algorithm}),
+		jwt.WithExpirationRequired(),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &jwtVerifier{cfg: cfg, key: key, parser: jwt.NewParser(opts...)}, nil
+}
+
+// verify validates a token and returns the principal described by its claims
+func (v *jwtVerifier) verify(tokenString string) (*Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(tokenString, claims, func(*jwt.Token) (interface{}, error) {
+		return v.key, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, errors.New("token has no subject")
+	}
+
+	admin, _ := claims[v.cfg.AdminClaim].(bool)
+	return &Principal{
+		Subject:   subject,
+		Databases: stringList(claims[v.cfg.DatabasesClaim]),
+		Tools:     stringList(claims[v.cfg.ToolsClaim]),
+		Admin:     admin,
+	}, nil
+}
+
+// stringList converts a claim holding a string or a list of strings
+func stringList(claim interface{}) []string {
+	switch v := claim.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,305 @@
+// Package breaker stops tool calls from waiting on databases that are down.
+// A circuit breaker per database connection opens after consecutive
+// connection failures, rejects calls immediately while open, and lets a
+// single probe through once the open period has passed.
+package breaker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/internal/metrics"
+)
+
+// Breaker states
+const (
+	StateClosed   = "closed"    // calls pass
+	StateOpen     = "open"      // calls are rejected until the open period has passed
+	StateHalfOpen = "half_open" // one probe call is let through
+)
+
+// Default breaker settings
+const (
+	defaultFailureThreshold = 5
+	defaultOpenDuration     = 30 * time.Second
+)
+
+// Config holds the circuit breaker configuration (the "circuit_breaker" section of config.json)
+type Config struct {
+	Enabled          bool `json:"enabled"`
+	FailureThreshold int  `json:"failure_threshold,omitempty"` // consecutive connection failures that open the breaker (default 5)
+	OpenSeconds      int  `json:"open_seconds,omitempty"`      // how long the breaker stays open before a probe (default 30)
+}
+
+// Validate checks the breaker settings
+func (c Config) Validate() error {
+	if c.FailureThreshold < 0 || c.OpenSeconds < 0 {
+		return fmt.Errorf("circuit breaker settings must not be negative")
+	}
+	return nil
+}
+
+// UnavailableError is returned for calls rejected while a breaker is open
+type UnavailableError struct {
+	Database   string
+	Failures   int
+	LastError  string
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *UnavailableError) Error() string {
+	return fmt.Sprintf("database %s unavailable: circuit breaker open after %d consecutive connection failures, retry after %s (last error: %s)",
+		e.Database, e.Failures, e.RetryAfter.Round(time.Second), e.LastError)
+}
+
+var (
+	rejectedTotal = metrics.Default.Counter("mcp_circuit_breaker_rejected_total",
+		"Database calls rejected by an open circuit breaker", "database")
+	openedTotal = metrics.Default.Counter("mcp_circuit_breaker_opened_total",
+		"Times a circuit breaker opened", "database")
+)
+
+// circuit is the breaker state of one database
+type circuit struct {
+	state     string
+	failures  int
+	lastError string
+	openedAt  time.Time
+	probing   bool // a half-open probe is running
+}
+
+// Breaker holds the circuit breakers of all database connections
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	open      time.Duration
+	circuits  map[string]*circuit
+	now       func() time.Time
+}
+
+// New creates a breaker and exports its states as the mcp_circuit_breaker_state
+// gauge (0 closed, 1 half open, 2 open)
+func New(cfg Config) *Breaker {
+	b := &Breaker{
+		threshold: cfg.FailureThreshold,
+		open:      time.Duration(cfg.OpenSeconds) * time.Second,
+		circuits:  make(map[string]*circuit),
+		now:       time.Now,
+	}
+	if b.threshold <= 0 {
+		b.threshold = defaultFailureThreshold
+	}
+	if b.open <= 0 {
+		b.open = defaultOpenDuration
+	}
+	metrics.Default.GaugeFunc("mcp_circuit_breaker_state",
+		"Circuit breaker state per database (0 closed, 1 half open, 2 open)", "database", b.gaugeValues)
+	return b
+}
+
+// circuit returns the state of a database, creating it if needed; callers hold b.mu
+func (b *Breaker) circuit(dbID string) *circuit {
+	c, ok := b.circuits[dbID]
+	if !ok {
+		c = &circuit{state: StateClosed}
+		b.circuits[dbID] = c
+	}
+	return c
+}
+
+// Check rejects a call to a database whose breaker is open, without taking
+// the half-open probe. Use it before work that precedes the database call,
+// such as opening a lazily loaded connection.
+func (b *Breaker) Check(dbID string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.reject(dbID, b.circuit(dbID))
+}
+
+// Acquire admits a call to a database. While the breaker is open calls are
+// rejected with an UnavailableError; once the open period has passed a single
+// probe is admitted. On success the returned done function must be called
+// with the call's error.
+func (b *Breaker) Acquire(dbID string) (func(error), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(dbID)
+	if err := b.reject(dbID, c); err != nil {
+		return nil, err
+	}
+	probe := false
+	if c.state != StateClosed {
+		c.state = StateHalfOpen
+		c.probing = true
+		probe = true
+	}
+
+	var once sync.Once
+	return func(err error) {
+		once.Do(func() { b.record(dbID, err, probe) })
+	}, nil
+}
+
+// Report records the outcome of work done outside Acquire, such as opening a
+// connection. Only connection failures are recorded.
+func (b *Breaker) Report(dbID string, err error) {
+	if IsConnectionError(err) {
+		b.record(dbID, err, false)
+	}
+}
+
+// reject returns an UnavailableError while a breaker is open or its probe is
+// running; callers hold b.mu
+func (b *Breaker) reject(dbID string, c *circuit) error {
+	var retryAfter time.Duration
+	switch {
+	case c.state == StateOpen:
+		retryAfter = c.openedAt.Add(b.open).Sub(b.now())
+		if retryAfter <= 0 {
+			return nil
+		}
+	case c.state == StateHalfOpen && c.probing:
+		retryAfter = time.Second
+	default:
+		return nil
+	}
+	rejectedTotal.Inc(dbID)
+	return &UnavailableError{Database: dbID, Failures: c.failures, LastError: c.lastError, RetryAfter: retryAfter}
+}
+
+// record updates a breaker with the outcome of a call
+func (b *Breaker) record(dbID string, err error, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(dbID)
+	if probe {
+		c.probing = false
+	}
+
+	switch {
+	case IsConnectionError(err):
+		c.failures++
+		c.lastError = err.Error()
+		if c.state == StateHalfOpen || (c.state == StateClosed && c.failures >= b.threshold) {
+			if c.state == StateClosed {
+				logger.Warn("Circuit breaker for database %s opened after %d consecutive connection failures: %v", dbID, c.failures, err)
+			}
+			c.state = StateOpen
+			c.openedAt = b.now()
+			openedTotal.Inc(dbID)
+		}
+	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		// Cancelled calls say nothing about the database
+	default:
+		if c.state != StateClosed {
+			logger.Info("Circuit breaker for database %s closed, the database is reachable again", dbID)
+		}
+		c.state = StateClosed
+		c.failures = 0
+		c.lastError = ""
+	}
+}
+
+// State returns the breaker state of a database
+func (b *Breaker) State(dbID string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[dbID]
+	if !ok {
+		return StateClosed
+	}
+	if c.state == StateOpen && !b.now().Before(c.openedAt.Add(b.open)) {
+		return StateHalfOpen
+	}
+	return c.state
+}
+
+// Forget drops the state of a database, for connections that were removed
+// or reconfigured
+func (b *Breaker) Forget(dbID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, dbID)
+}
+
+// gaugeValues returns the numeric state of each database for the metrics gauge
+func (b *Breaker) gaugeValues() map[string]float64 {
+	b.mu.Lock()
+	ids := make([]string, 0, len(b.circuits))
+	for id := range b.circuits {
+		ids = append(ids, id)
+	}
+	b.mu.Unlock()
+
+	values := make(map[string]float64, len(ids))
+	for _, id := range ids {
+		switch b.State(id) {
+		case StateOpen:
+			values[id] = 2
+		case StateHalfOpen:
+			values[id] = 1
+		default:
+			values[id] = 0
+		}
+	}
+	return values
+}
+
+// connectionErrorMessages are fragments of driver errors that mean the
+// database could not be reached, as opposed to errors in a statement
+var connectionErrorMessages = []string{
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"no such host",
+	"i/o timeout",
+	"network is unreachable",
+	"no route to host",
+	"bad connection",
+	"server has gone away",                             // MySQL
+	"lost connection to mysql server",                  // MySQL
+	"the database system is starting",                  // PostgreSQL
+	"the database system is shutting",                  // PostgreSQL
+	"terminating connection due to",                    // PostgreSQL
+	"failed to connect",                                // manager errors for lazily opened connections
+	"failed to open ssh tunnel",                        // manager errors for tunneled connections
+	"ora-12541", "ora-12543", "ora-03113", "ora-03114", // Oracle listener and lost connection
+}
+
+// IsConnectionError reports whether an error means the database could not be
+// reached. Errors in statements, such as syntax errors or constraint
+// violations, are not connection errors, and neither are cancelled calls.
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return true
+	}
+	message := strings.ToLower(err.Error())
+	for _, fragment := range connectionErrorMessages {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
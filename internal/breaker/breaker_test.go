@@ -0,0 +1,176 @@
+package breaker
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestBreaker(cfg Config) (*Breaker, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b := New(cfg)
+	b.now = clock.now
+	return b, clock
+}
+
+var errRefused = &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+// call runs a call with the given outcome through the breaker
+func call(b *Breaker, dbID string, outcome error) error {
+	done, err := b.Acquire(dbID)
+	if err != nil {
+		return err
+	}
+	done(outcome)
+	return nil
+}
+
+func TestBreakerOpensAndProbes(t *testing.T) {
+	b, clock := newTestBreaker(Config{Enabled: true, FailureThreshold: 3, OpenSeconds: 10})
+
+	// Statement errors and successes keep the breaker closed
+	require.NoError(t, call(b, "pg", errors.New("syntax error at or near \"SELEC\"")))
+	require.NoError(t, call(b, "pg", errRefused))
+	require.NoError(t, call(b, "pg", nil))
+	assert.Equal(t, StateClosed, b.State("pg"))
+
+	// Consecutive connection failures open it
+	for i := 0; i < 3; i++ {
+		require.NoError(t, call(b, "pg", errRefused))
+	}
+	assert.Equal(t, StateOpen, b.State("pg"))
+	assert.Equal(t, StateClosed, b.State("mysql"))
+
+	err := call(b, "pg", nil)
+	var unavailable *UnavailableError
+	require.True(t, errors.As(err, &unavailable))
+	assert.Equal(t, 3, unavailable.Failures)
+	assert.Equal(t, 10*time.Second, unavailable.RetryAfter)
+	assert.Contains(t, err.Error(), "database pg unavailable")
+	assert.Contains(t, err.Error(), "connection refused")
+	assert.Error(t, b.Check("pg"))
+
+	// After the open period a single probe is let through
+	clock.t = clock.t.Add(10 * time.Second)
+	assert.Equal(t, StateHalfOpen, b.State("pg"))
+	assert.NoError(t, b.Check("pg"))
+	probe, err := b.Acquire("pg")
+	require.NoError(t, err)
+	assert.Error(t, call(b, "pg", nil), "only one probe runs at a time")
+
+	// A failed probe opens the breaker again
+	probe(errRefused)
+	assert.Equal(t, StateOpen, b.State("pg"))
+
+	// A successful probe closes it
+	clock.t = clock.t.Add(10 * time.Second)
+	require.NoError(t, call(b, "pg", nil))
+	assert.Equal(t, StateClosed, b.State("pg"))
+	assert.Equal(t, 0.0, b.gaugeValues()["pg"])
+}
+
+func TestBreakerIgnoresCancelledCalls(t *testing.T) {
+	b, clock := newTestBreaker(Config{Enabled: true, FailureThreshold: 1, OpenSeconds: 1})
+	require.NoError(t, call(b, "pg", errRefused))
+	clock.t = clock.t.Add(time.Second)
+
+	// A cancelled probe neither closes nor opens the breaker
+	require.NoError(t, call(b, "pg", context.Canceled))
+	assert.Equal(t, StateHalfOpen, b.State("pg"))
+	require.NoError(t, call(b, "pg", nil))
+	assert.Equal(t, StateClosed, b.State("pg"))
+
+	b.Report("pg", errors.New("duplicate key value"))
+	assert.Equal(t, StateClosed, b.State("pg"))
+	b.Report("pg", fmt.Errorf("failed to connect to database pg: %w", errRefused))
+	assert.Equal(t, StateOpen, b.State("pg"))
+	b.Forget("pg")
+	assert.Equal(t, StateClosed, b.State("pg"))
+}
+
+func TestIsConnectionError(t *testing.T) {
+	assert.True(t, IsConnectionError(errRefused))
+	assert.True(t, IsConnectionError(fmt.Errorf("query failed: %w", driver.ErrBadConn)))
+	assert.True(t, IsConnectionError(errors.New("Error 2006: MySQL server has gone away")))
+	assert.True(t, IsConnectionError(errors.New("pq: the database system is starting up")))
+	assert.True(t, IsConnectionError(errors.New("ORA-12541: TNS:no listener")))
+	assert.False(t, IsConnectionError(nil))
+	assert.False(t, IsConnectionError(context.DeadlineExceeded))
+	assert.False(t, IsConnectionError(errors.New("no such table: users")))
+}
+
+// fakeRepository returns a fixed database or error
+type fakeRepository struct {
+	domain.DatabaseRepository
+	database domain.Database
+	err      error
+	calls    int
+}
+
+func (r *fakeRepository) GetDatabase(string) (domain.Database, error) {
+	r.calls++
+	return r.database, r.err
+}
+
+// failingDatabase fails every call with its error
+type failingDatabase struct{ err error }
+
+func (d failingDatabase) Query(context.Context, string, ...interface{}) (domain.Rows, error) {
+	return nil, d.err
+}
+
+func (d failingDatabase) QueryPrepared(context.Context, string, ...interface{}) (domain.Rows, error) {
+	return nil, d.err
+}
+
+func (d failingDatabase) Exec(context.Context, string, ...interface{}) (domain.Result, error) {
+	return nil, d.err
+}
+
+func (d failingDatabase) Begin(context.Context, *domain.TxOptions) (domain.Tx, error) {
+	return nil, d.err
+}
+
+func TestRepository(t *testing.T) {
+	b, _ := newTestBreaker(Config{Enabled: true, FailureThreshold: 2})
+	inner := &fakeRepository{database: failingDatabase{err: errRefused}}
+	repo := NewRepository(inner, b)
+
+	database, err := repo.GetDatabase("pg")
+	require.NoError(t, err)
+	_, err = database.Query(context.Background(), "SELECT 1")
+	assert.ErrorIs(t, err, errRefused)
+	_, err = database.Exec(context.Background(), "DELETE FROM t")
+	assert.ErrorIs(t, err, errRefused)
+
+	// The open breaker fails fast without asking the repository
+	var unavailable *UnavailableError
+	_, err = database.Begin(context.Background(), nil)
+	assert.True(t, errors.As(err, &unavailable))
+	_, err = repo.GetDatabase("pg")
+	assert.True(t, errors.As(err, &unavailable))
+	assert.Equal(t, 1, inner.calls)
+
+	// Failures to open a connection count too
+	inner.err = fmt.Errorf("failed to connect to database mysql: %w", errRefused)
+	for i := 0; i < 2; i++ {
+		_, err = repo.GetDatabase("mysql")
+		assert.ErrorIs(t, err, errRefused)
+	}
+	_, err = repo.GetDatabase("mysql")
+	assert.True(t, errors.As(err, &unavailable))
+}
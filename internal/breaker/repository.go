@@ -0,0 +1,96 @@
+package breaker
+
+import (
+	"context"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// Repository is a database repository whose databases are guarded by a breaker
+type Repository struct {
+	domain.DatabaseRepository
+	breaker *Breaker
+}
+
+// NewRepository guards the databases of repo with a breaker
+func NewRepository(repo domain.DatabaseRepository, breaker *Breaker) *Repository {
+	return &Repository{DatabaseRepository: repo, breaker: breaker}
+}
+
+// GetDatabase rejects databases whose breaker is open before connecting to
+// them, and guards the calls made on the returned database
+func (r *Repository) GetDatabase(id string) (domain.Database, error) {
+	if err := r.breaker.Check(id); err != nil {
+		return nil, err
+	}
+	database, err := r.DatabaseRepository.GetDatabase(id)
+	if err != nil {
+		r.breaker.Report(id, err)
+		return nil, err
+	}
+	return &guardedDatabase{Database: database, id: id, breaker: r.breaker}, nil
+}
+
+// GetReadDatabase is GetDatabase for reads, which may go to a replica
+func (r *Repository) GetReadDatabase(id string) (domain.Database, error) {
+	if err := r.breaker.Check(id); err != nil {
+		return nil, err
+	}
+	database, err := r.DatabaseRepository.GetReadDatabase(id)
+	if err != nil {
+		r.breaker.Report(id, err)
+		return nil, err
+	}
+	return &guardedDatabase{Database: database, id: id, breaker: r.breaker}, nil
+}
+
+// guardedDatabase passes calls through a breaker
+type guardedDatabase struct {
+	domain.Database
+	id      string
+	breaker *Breaker
+}
+
+// Query runs a query unless the breaker is open
+func (d *guardedDatabase) Query(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
+	done, err := d.breaker.Acquire(d.id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.Database.Query(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+// QueryPrepared runs a prepared query unless the breaker is open
+func (d *guardedDatabase) QueryPrepared(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
+	done, err := d.breaker.Acquire(d.id)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := d.Database.QueryPrepared(ctx, query, args...)
+	done(err)
+	return rows, err
+}
+
+// Exec runs a statement unless the breaker is open
+func (d *guardedDatabase) Exec(ctx context.Context, statement string, args ...interface{}) (domain.Result, error) {
+	done, err := d.breaker.Acquire(d.id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := d.Database.Exec(ctx, statement, args...)
+	done(err)
+	return result, err
+}
+
+// Begin starts a transaction unless the breaker is open
+func (d *guardedDatabase) Begin(ctx context.Context, opts *domain.TxOptions) (domain.Tx, error) {
+	done, err := d.breaker.Acquire(d.id)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := d.Database.Begin(ctx, opts)
+	done(err)
+	return tx, err
+}
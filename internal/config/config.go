@@ -10,7 +10,11 @@ import (
 
 	"github.com/joho/godotenv"
 
+	"github.com/FreePeak/db-mcp-server/internal/audit"
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+	"github.com/FreePeak/db-mcp-server/internal/breaker"
 	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/internal/ratelimit"
 	"github.com/FreePeak/db-mcp-server/pkg/db"
 )
 
@@ -23,6 +27,49 @@ type Config struct {
 	MultiDBConfig  *db.MultiDBConfig // New multi-database config
 	ConfigPath     string            // Path to the configuration file
 	DisableLogging bool              // When true, disables logging in stdio/SSE transport
+	Audit          audit.Config      // Audit log settings from the "audit" section of the config file
+	Auth           auth.Config       // SSE transport authentication from the "auth" section of the config file
+	RateLimit      ratelimit.Config  // Tool call limits from the "rate_limit" section of the config file
+	Health         db.HealthConfig   // Connection health monitor settings from the "health" section of the config file
+	CircuitBreaker breaker.Config    // Per-connection circuit breaker from the "circuit_breaker" section of the config file
+	LazyLoading    db.EvictionConfig // Idle eviction and open connection cap from the "lazy_loading" section of the config file
+	Backup         db.BackupConfig   // SQLite backup directory from the "backup" section of the config file
+
+	// AdminTools registers the administrative tools, such as add_connection,
+	// without authentication. Without it they are only registered when
+	// authentication is enabled, which restricts them to administrators.
+	AdminTools bool
+
+	// Connection defaults by environment and tag from the "policies" section of the config file
+	Policies []db.ConnectionPolicy
+
+	// Command-based secret providers from the "secret_providers" section of the config file, by scheme
+	SecretProviders map[string]SecretCommand
+}
+
+// serverSettings holds the server-level sections of the configuration file
+// that sit next to the "connections" list
+type serverSettings struct {
+	Audit          audit.Config      `json:"audit"`
+	Auth           auth.Config       `json:"auth"`
+	RateLimit      ratelimit.Config  `json:"rate_limit"`
+	Health         db.HealthConfig   `json:"health"`
+	CircuitBreaker breaker.Config    `json:"circuit_breaker"`
+	LazyLoading    db.EvictionConfig `json:"lazy_loading"`
+	Backup         db.BackupConfig   `json:"backup"`
+	AdminTools     bool              `json:"admin_tools"`
+
+	Policies []db.ConnectionPolicy `json:"policies"`
+
+	SecretProviders map[string]SecretCommand `json:"secret_providers"`
+}
+
+// SecretCommand configures a secret provider that runs a command, such as
+// ["pass", "show"] or ["vault", "kv", "get", "-field=password"]. The secret
+// reference is appended as the last argument.
+type SecretCommand struct {
+	Command        []string `json:"command"`
+	TimeoutSeconds int      `json:"timeout_seconds,omitempty"`
 }
 
 // DatabaseConfig holds database configuration (legacy support)
@@ -123,6 +170,26 @@ func LoadConfig(logDir string) (*Config, error) {
 		resolveSQLitePaths(&multiDBConfig, configDir)
 
 		config.MultiDBConfig = &multiDBConfig
+
+		var settings serverSettings
+		if err := json.Unmarshal(configData, &settings); err != nil {
+			return nil, fmt.Errorf("failed to parse server settings in %s: %w", config.ConfigPath, err)
+		}
+		config.Audit = settings.Audit
+		config.Auth = settings.Auth
+		config.RateLimit = settings.RateLimit
+		config.Health = settings.Health
+		config.CircuitBreaker = settings.CircuitBreaker
+		config.LazyLoading = settings.LazyLoading
+		config.Backup = settings.Backup
+		config.AdminTools = settings.AdminTools
+		config.Policies = settings.Policies
+		config.SecretProviders = settings.SecretProviders
+
+		// Resolve the JWT public key path like SQLite paths
+		if jwt := config.Auth.JWT; jwt != nil && jwt.PublicKeyFile != "" && !filepath.IsAbs(jwt.PublicKeyFile) {
+			jwt.PublicKeyFile = filepath.Join(configDir, jwt.PublicKeyFile)
+		}
 	} else {
 		logger.Info("Warning: Config file not found at %s, using environment variables", config.ConfigPath)
 		// If no JSON config found, create a single connection config from environment variables
@@ -141,23 +208,75 @@ func LoadConfig(logDir string) (*Config, error) {
 		}
 	}
 
+	// Keep SQLite backups next to the config file unless configured otherwise
+	if config.Backup.Dir == "" {
+		config.Backup.Dir = "backups"
+	}
+	config.Backup.Dir = resolvePath(config.Backup.Dir, filepath.Dir(config.ConfigPath))
+
+	// Default the audit file to the log directory when no sink is configured
+	if config.Audit.Enabled && config.Audit.File == "" && config.Audit.Database == "" {
+		auditDir := logDir
+		if auditDir == "" {
+			auditDir = "logs"
+		}
+		config.Audit.File = filepath.Join(auditDir, "audit.jsonl")
+	}
+
 	return config, nil
 }
 
-// resolveSQLitePaths resolves SQLite database_path values to absolute paths
-// relative to the config file directory
+// resolveSQLitePaths resolves SQLite and DuckDB database_path values to
+// absolute paths relative to the config file directory
 func resolveSQLitePaths(multiDBConfig *db.MultiDBConfig, configDir string) {
 	if multiDBConfig == nil {
 		return
 	}
 
 	for i := range multiDBConfig.Connections {
-		conn := &multiDBConfig.Connections[i]
-		if conn.Type == "sqlite" && conn.DatabasePath != "" && !filepath.IsAbs(conn.DatabasePath) {
-			// Resolve relative path against config file directory
-			conn.DatabasePath = filepath.Join(configDir, conn.DatabasePath)
+		ResolveSQLitePath(&multiDBConfig.Connections[i], configDir)
+	}
+}
+
+// ResolveSQLitePath resolves a relative SQLite or DuckDB database_path, and
+// the paths of attached databases, against the config file directory
+func ResolveSQLitePath(conn *db.DatabaseConnectionConfig, configDir string) {
+	if !db.IsFileDatabase(conn.Type) {
+		return
+	}
+	conn.DatabasePath = resolvePath(conn.DatabasePath, configDir)
+
+	if len(conn.Attach) > 0 {
+		attach := make(map[string]string, len(conn.Attach))
+		for name, path := range conn.Attach {
+			attach[name] = resolvePath(path, configDir)
 		}
+		conn.Attach = attach
+	}
+}
+
+// resolvePath resolves a relative database file path
+func resolvePath(path, configDir string) string {
+	if path == "" || path == ":memory:" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(configDir, path)
+}
+
+// LoadConnections reads the database connections from a config file,
+// resolving SQLite paths like LoadConfig does
+func LoadConnections(configPath string) (*db.MultiDBConfig, error) {
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	var multiDBConfig db.MultiDBConfig
+	if err := json.Unmarshal(configData, &multiDBConfig); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
 	}
+	resolveSQLitePaths(&multiDBConfig, filepath.Dir(configPath))
+	return &multiDBConfig, nil
 }
 
 // getEnv gets an environment variable or returns a default value
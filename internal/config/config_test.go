@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/FreePeak/db-mcp-server/pkg/db"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -82,6 +83,8 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "", config.DBConfig.User)
 	assert.Equal(t, "", config.DBConfig.Password)
 	assert.Equal(t, "", config.DBConfig.Name)
+	assert.Equal(t, filepath.Join(filepath.Dir(config.ConfigPath), "backups"), config.Backup.Dir)
+	assert.False(t, config.AdminTools)
 
 	// Test with custom environment variables
 	err = os.Setenv("SERVER_PORT", "8080")
@@ -141,3 +144,28 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "testpass", config.DBConfig.Password)
 	assert.Equal(t, "testdb", config.DBConfig.Name)
 }
+
+func TestResolveSQLitePath(t *testing.T) {
+	configDir := filepath.Join("etc", "db-mcp")
+
+	sqlite := db.DatabaseConnectionConfig{Type: "sqlite", DatabasePath: "data/app.db"}
+	ResolveSQLitePath(&sqlite, configDir)
+	assert.Equal(t, filepath.Join(configDir, "data", "app.db"), sqlite.DatabasePath)
+
+	duck := db.DatabaseConnectionConfig{Type: "duckdb", DatabasePath: "analytics.duckdb"}
+	ResolveSQLitePath(&duck, configDir)
+	assert.Equal(t, filepath.Join(configDir, "analytics.duckdb"), duck.DatabasePath)
+
+	attached := db.DatabaseConnectionConfig{Type: "sqlite", DatabasePath: "app.db",
+		Attach: map[string]string{"archive": "archive/2023.db", "shared": "/srv/shared.db"}}
+	ResolveSQLitePath(&attached, configDir)
+	assert.Equal(t, map[string]string{"archive": filepath.Join(configDir, "archive", "2023.db"), "shared": "/srv/shared.db"}, attached.Attach)
+
+	memory := db.DatabaseConnectionConfig{Type: "duckdb", DatabasePath: ":memory:"}
+	ResolveSQLitePath(&memory, configDir)
+	assert.Equal(t, ":memory:", memory.DatabasePath)
+
+	server := db.DatabaseConnectionConfig{Type: "postgres", DatabasePath: "ignored"}
+	ResolveSQLitePath(&server, configDir)
+	assert.Equal(t, "ignored", server.DatabasePath)
+}
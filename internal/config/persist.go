@@ -0,0 +1,209 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// ReadConnection returns a connection from the config file as it is written
+// there, without resolving SQLite paths
+func ReadConnection(configPath, id string) (db.DatabaseConnectionConfig, bool, error) {
+	var conn db.DatabaseConnectionConfig
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return conn, false, fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+	var multiDBConfig db.MultiDBConfig
+	if err := json.Unmarshal(configData, &multiDBConfig); err != nil {
+		return conn, false, fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+	for _, conn := range multiDBConfig.Connections {
+		if conn.ID == id {
+			return conn, true, nil
+		}
+	}
+	return conn, false, nil
+}
+
+// SaveConnection writes a connection to the config file, replacing the
+// connection with the same ID or appending it. Other connections and
+// sections of the file are kept as they are.
+func SaveConnection(configPath string, conn db.DatabaseConnectionConfig) error {
+	data, err := json.Marshal(conn)
+	if err != nil {
+		return fmt.Errorf("failed to encode connection %s: %w", conn.ID, err)
+	}
+
+	return rewriteConnections(configPath, func(connections []json.RawMessage) ([]json.RawMessage, error) {
+		for i, existing := range connections {
+			if connectionID(existing) == conn.ID {
+				connections[i] = data
+				return connections, nil
+			}
+		}
+		return append(connections, data), nil
+	})
+}
+
+// DeleteConnection removes a connection from the config file
+func DeleteConnection(configPath, id string) error {
+	return rewriteConnections(configPath, func(connections []json.RawMessage) ([]json.RawMessage, error) {
+		for i, existing := range connections {
+			if connectionID(existing) == id {
+				return append(connections[:i], connections[i+1:]...), nil
+			}
+		}
+		return nil, fmt.Errorf("connection %s not found in %s", id, configPath)
+	})
+}
+
+// rewriteConnections applies update to the "connections" list of the config
+// file and writes the file back atomically. Only the list is rewritten, the
+// rest of the file keeps its content and layout.
+func rewriteConnections(configPath string, update func([]json.RawMessage) ([]json.RawMessage, error)) error {
+	info, err := os.Stat(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read config file %s: %w", configPath, err)
+	}
+
+	start, end, err := connectionsSpan(configData)
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", configPath, err)
+	}
+
+	var connections []json.RawMessage
+	if start < end {
+		if err := json.Unmarshal(configData[start:end], &connections); err != nil {
+			return fmt.Errorf("failed to parse connections in %s: %w", configPath, err)
+		}
+	}
+
+	original := append([]json.RawMessage(nil), connections...)
+	connections, err = update(connections)
+	if err != nil {
+		return err
+	}
+
+	var output []byte
+	output = append(output, configData[:start]...)
+	if start < end {
+		output = append(output, encodeConnections(connections, original, indentAt(configData, start))...)
+	} else {
+		// No list yet, add one as the first section
+		rest := bytes.TrimLeft(configData[start:], " \t\r\n")
+		indent := indentAt(configData, len(configData)-len(rest))
+		if indent == "" || rest[0] == '}' {
+			indent = "  "
+		}
+		output = append(output, "\n"+indent+`"connections": `...)
+		output = append(output, encodeConnections(connections, nil, indent)...)
+		if rest[0] == '}' {
+			output = append(output, '\n')
+		} else {
+			output = append(output, ',')
+		}
+	}
+	output = append(output, configData[end:]...)
+
+	// Write a temporary file next to the config and rename it, so the file
+	// watcher never sees a partially written config
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), filepath.Base(configPath)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(output); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	if err := os.Rename(tmp.Name(), configPath); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", configPath, err)
+	}
+	return nil
+}
+
+// connectionID returns the "id" field of an encoded connection
+func connectionID(raw json.RawMessage) string {
+	var conn struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(raw, &conn)
+	return conn.ID
+}
+
+// connectionsSpan returns the offsets of the "connections" list in a config
+// file. Without a list, start and end are both the offset just after the
+// opening brace.
+func connectionsSpan(configData []byte) (int, int, error) {
+	decoder := json.NewDecoder(bytes.NewReader(configData))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return 0, 0, fmt.Errorf("config is not a JSON object")
+	}
+	open := int(decoder.InputOffset())
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return 0, 0, err
+		}
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return 0, 0, err
+		}
+		if token == "connections" {
+			end := int(decoder.InputOffset())
+			return end - len(value), end, nil
+		}
+	}
+	return open, open, nil
+}
+
+// indentAt returns the indentation of the line containing offset
+func indentAt(data []byte, offset int) string {
+	line := data[bytes.LastIndexByte(data[:offset], '\n')+1:]
+	return string(line[:len(line)-len(bytes.TrimLeft(line, " \t"))])
+}
+
+// encodeConnections encodes the connections list for a line indented by
+// indent. Entries taken unchanged from the file keep their layout.
+func encodeConnections(connections, original []json.RawMessage, indent string) []byte {
+	if len(connections) == 0 {
+		return []byte("[]")
+	}
+	unchanged := make(map[string]bool, len(original))
+	for _, raw := range original {
+		unchanged[string(raw)] = true
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("[\n")
+	for i, raw := range connections {
+		buf.WriteString(indent + "  ")
+		if unchanged[string(raw)] || json.Indent(&buf, raw, indent+"  ", "  ") != nil {
+			buf.Write(raw)
+		}
+		if i < len(connections)-1 {
+			buf.WriteByte(',')
+		}
+		buf.WriteByte('\n')
+	}
+	buf.WriteString(indent + "]")
+	return buf.Bytes()
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+func TestSaveAndDeleteConnection(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"connections": [{"id": "main", "type": "postgres", "host": "db", "custom": "kept"}],
+		"rate_limit": {"enabled": true}
+	}`), 0640))
+
+	scratch := db.DatabaseConnectionConfig{ID: "scratch", Type: "sqlite", DatabasePath: "/tmp/restore.db"}
+	require.NoError(t, SaveConnection(path, scratch))
+
+	scratch.ReadOnly = true
+	require.NoError(t, SaveConnection(path, scratch))
+
+	connections, err := LoadConnections(path)
+	require.NoError(t, err)
+	require.Len(t, connections.Connections, 2)
+	assert.Equal(t, "main", connections.Connections[0].ID)
+	assert.Equal(t, "scratch", connections.Connections[1].ID)
+	assert.True(t, connections.Connections[1].ReadOnly)
+
+	// Other sections and unknown fields survive the rewrite
+	var sections map[string]interface{}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &sections))
+	assert.Contains(t, sections, "rate_limit")
+	assert.Contains(t, string(data), `"custom": "kept"`)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	require.NoError(t, DeleteConnection(path, "scratch"))
+	assert.Error(t, DeleteConnection(path, "scratch"))
+
+	connections, err = LoadConnections(path)
+	require.NoError(t, err)
+	require.Len(t, connections.Connections, 1)
+	assert.Equal(t, "main", connections.Connections[0].ID)
+}
+
+func TestSaveConnectionKeepsLayout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	original := `{
+  "rate_limit": {"enabled": true, "requests_per_second": 5},
+  "connections": [
+    {"id": "main", "type": "postgres", "host": "db"}
+  ],
+  "audit": {
+    "enabled": true
+  }
+}
+`
+	require.NoError(t, os.WriteFile(path, []byte(original), 0600))
+
+	scratch := db.DatabaseConnectionConfig{ID: "scratch", Type: "sqlite", DatabasePath: "scratch.db"}
+	require.NoError(t, SaveConnection(path, scratch))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "rate_limit": {"enabled": true, "requests_per_second": 5},
+  "connections": [
+    {"id": "main", "type": "postgres", "host": "db"},
+    {
+      "id": "scratch",
+      "type": "sqlite",
+      "host": "",
+      "port": 0,
+      "user": "",
+      "password": "",
+      "name": "",
+      "database_path": "scratch.db"
+    }
+  ],
+  "audit": {
+    "enabled": true
+  }
+}
+`, string(data))
+
+	// The connection is saved as supplied, the SQLite path is not resolved
+	saved, found, err := ReadConnection(path, "scratch")
+	require.NoError(t, err)
+	require.True(t, found)
+	assert.Equal(t, "scratch.db", saved.DatabasePath)
+
+	require.NoError(t, DeleteConnection(path, "scratch"))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
+
+func TestSaveConnectionAddsList(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte("{\n  \"admin_tools\": true\n}\n"), 0600))
+
+	require.NoError(t, SaveConnection(path, db.DatabaseConnectionConfig{ID: "main", Type: "sqlite", DatabasePath: "main.db"}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var sections map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &sections))
+	assert.Equal(t, true, sections["admin_tools"])
+
+	connections, err := LoadConnections(path)
+	require.NoError(t, err)
+	require.Len(t, connections.Connections, 1)
+	assert.Equal(t, "main", connections.Connections[0].ID)
+}
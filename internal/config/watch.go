@@ -0,0 +1,55 @@
+package config
+
+import (
+	"context"
+	"os"
+	"time"
+)
+
+// WatchFile polls a file and sends on the returned channel whenever its
+// modification time or size changes, until ctx is done. Changes that happen
+// while a previous one has not been received are merged.
+func WatchFile(ctx context.Context, path string, interval time.Duration) <-chan struct{} {
+	changes := make(chan struct{}, 1)
+
+	go func() {
+		last := statFile(path)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				current := statFile(path)
+				if current == last {
+					continue
+				}
+				last = current
+				select {
+				case changes <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+
+	return changes
+}
+
+// fileState identifies a version of a file
+type fileState struct {
+	modTime time.Time
+	size    int64
+	exists  bool
+}
+
+// statFile returns the current state of a file
+func statFile(path string) fileState {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{modTime: info.ModTime(), size: info.Size(), exists: true}
+}
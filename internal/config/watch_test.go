@@ -0,0 +1,33 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"connections": []}`), 0600))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := WatchFile(ctx, path, 10*time.Millisecond)
+
+	select {
+	case <-changes:
+		t.Fatal("unexpected change before the file was written")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"connections": [{"id": "db1"}]}`), 0600))
+	select {
+	case <-changes:
+	case <-time.After(2 * time.Second):
+		t.Fatal("change was not detected")
+	}
+}
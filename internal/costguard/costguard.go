@@ -0,0 +1,239 @@
+// Package costguard asks the query planner for the estimated cost of a
+// SELECT before it runs and stops queries whose estimate exceeds the
+// thresholds configured for their database connection.
+package costguard
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// Actions taken when a query exceeds its thresholds
+const (
+	ActionReject  = "reject"  // the query is refused
+	ActionConfirm = "confirm" // the query only runs when the caller confirms it
+)
+
+// Limits holds the cost thresholds of one connection. Zero values disable
+// the corresponding check.
+type Limits struct {
+	MaxCost float64 `json:"max_cost,omitempty"` // planner cost units of the whole plan
+	MaxRows float64 `json:"max_rows,omitempty"` // estimated rows of any plan node
+	Action  string  `json:"action,omitempty"`   // reject (default) or confirm
+
+	// FailOpen runs queries unchecked when they cannot be planned, instead
+	// of rejecting them
+	FailOpen bool `json:"fail_open,omitempty"`
+}
+
+// isZero reports whether the limits disable every check
+func (l Limits) isZero() bool {
+	return l.MaxCost <= 0 && l.MaxRows <= 0
+}
+
+// Validate checks the action is one the guard understands
+func (l Limits) Validate() error {
+	switch l.Action {
+	case "", ActionReject, ActionConfirm:
+		return nil
+	default:
+		return fmt.Errorf("invalid cost guard action %q (expected %q or %q)", l.Action, ActionReject, ActionConfirm)
+	}
+}
+
+// PlanNode is one step of an execution plan with its estimates
+type PlanNode struct {
+	Operation string  `json:"operation"`
+	Object    string  `json:"object,omitempty"`
+	Cost      float64 `json:"cost"`
+	Rows      float64 `json:"rows"`
+	children  []*PlanNode
+}
+
+// String renders the node for error messages
+func (n *PlanNode) String() string {
+	label := n.Operation
+	if n.Object != "" {
+		label += " on " + n.Object
+	}
+	return fmt.Sprintf("%s (cost %.0f, rows %.0f)", label, n.Cost, n.Rows)
+}
+
+// Plan is the planner's estimate for a whole query
+type Plan struct {
+	Cost float64
+	Rows float64 // largest row estimate of any node
+	Root *PlanNode
+}
+
+// Error is returned when a query's estimate exceeds the configured limits
+type Error struct {
+	Database    string
+	Limits      Limits
+	Plan        *Plan
+	Nodes       []*PlanNode       // the plan nodes responsible for the estimate
+	Suggestions map[string]string // issues found in the SQL text, with suggestions
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "query estimate exceeds the cost guard for database %s:", e.Database)
+	if e.Limits.MaxCost > 0 && e.Plan.Cost > e.Limits.MaxCost {
+		fmt.Fprintf(&b, " cost %.0f > %.0f", e.Plan.Cost, e.Limits.MaxCost)
+	}
+	if e.Limits.MaxRows > 0 && e.Plan.Rows > e.Limits.MaxRows {
+		fmt.Fprintf(&b, " rows %.0f > %.0f", e.Plan.Rows, e.Limits.MaxRows)
+	}
+
+	if len(e.Nodes) > 0 {
+		b.WriteString("\nOffending plan nodes:")
+		for _, node := range e.Nodes {
+			b.WriteString("\n- " + node.String())
+		}
+	}
+
+	if len(e.Suggestions) > 0 {
+		b.WriteString("\nSuggestions:")
+		issues := make([]string, 0, len(e.Suggestions))
+		for issue := range e.Suggestions {
+			issues = append(issues, issue)
+		}
+		sort.Strings(issues)
+		for _, issue := range issues {
+			fmt.Fprintf(&b, "\n- %s: %s", issue, e.Suggestions[issue])
+		}
+	}
+
+	if e.Limits.Action == ActionConfirm {
+		b.WriteString("\nRewrite the query, or call the tool again with confirm=true to run it anyway.")
+	} else {
+		b.WriteString("\nRewrite the query to reduce its cost.")
+	}
+	return b.String()
+}
+
+// Guard checks queries against per-connection limits
+type Guard struct {
+	mu     sync.RWMutex
+	limits map[string]Limits
+	repo   domain.DatabaseRepository
+}
+
+// New creates a guard for the connections that have limits
+func New(limits map[string]Limits, repo domain.DatabaseRepository) *Guard {
+	return &Guard{limits: limits, repo: repo}
+}
+
+// SetLimits replaces the per-connection limits
+func (g *Guard) SetLimits(limits map[string]Limits) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.limits = limits
+}
+
+// Check explains a query and returns an *Error if its estimate exceeds the
+// connection's limits. Queries that are not SELECTs, connections without
+// limits and databases without a supported EXPLAIN are let through, as are
+// confirmed queries on connections whose action is "confirm". Batches of
+// several statements are rejected, as the statements after the first would
+// run while the guard explains them. A query that cannot be planned is
+// rejected, unless the limits fail open.
+func (g *Guard) Check(ctx context.Context, dbID, query string, params []interface{}, confirmed bool) error {
+	g.mu.RLock()
+	limits, ok := g.limits[dbID]
+	g.mu.RUnlock()
+	if !ok || limits.isZero() || !isSelect(query) {
+		return nil
+	}
+	if confirmed && limits.Action == ActionConfirm {
+		return nil
+	}
+
+	dbType, err := g.repo.GetDatabaseType(dbID)
+	if err != nil {
+		return unplanned(dbID, limits, err)
+	}
+	explainer, syntax := explainerFor(dbType)
+	if explainer == nil {
+		logger.Debug("Cost guard: EXPLAIN is not supported for %s database %s", dbType, dbID)
+		return nil
+	}
+	if len(syntax.Statements(query)) > 1 {
+		return fmt.Errorf("cost guard for database %s: run one statement at a time", dbID)
+	}
+
+	database, err := g.repo.GetReadDatabase(dbID)
+	if err != nil {
+		return unplanned(dbID, limits, err)
+	}
+	plan, err := explainer(ctx, database, query, params)
+	if err != nil {
+		return unplanned(dbID, limits, err)
+	}
+
+	return evaluate(dbID, query, limits, plan)
+}
+
+// unplanned handles a query that could not be explained: it is rejected,
+// or runs unchecked when the limits fail open
+func unplanned(dbID string, limits Limits, err error) error {
+	if limits.FailOpen {
+		logger.Warn("Cost guard: failed to explain query on %s, running it unchecked: %v", dbID, err)
+		return nil
+	}
+	return fmt.Errorf("cost guard for database %s could not plan the query: %w", dbID, err)
+}
+
+// evaluate compares a plan with the limits
+func evaluate(dbID, query string, limits Limits, plan *Plan) error {
+	costExceeded := limits.MaxCost > 0 && plan.Cost > limits.MaxCost
+	rowsExceeded := limits.MaxRows > 0 && plan.Rows > limits.MaxRows
+	if !costExceeded && !rowsExceeded {
+		return nil
+	}
+
+	return &Error{
+		Database:    dbID,
+		Limits:      limits,
+		Plan:        plan,
+		Nodes:       offendingNodes(plan.Root, limits),
+		Suggestions: dbtools.NewSQLIssueDetector().DetectIssues(query),
+	}
+}
+
+// offendingNodes returns the deepest nodes exceeding a limit: a node whose
+// children stay within the limits is where the cost comes from
+func offendingNodes(node *PlanNode, limits Limits) []*PlanNode {
+	if node == nil {
+		return nil
+	}
+	var nodes []*PlanNode
+	for _, child := range node.children {
+		nodes = append(nodes, offendingNodes(child, limits)...)
+	}
+	if len(nodes) > 0 {
+		return nodes
+	}
+	if (limits.MaxCost > 0 && node.Cost > limits.MaxCost) || (limits.MaxRows > 0 && node.Rows > limits.MaxRows) {
+		return []*PlanNode{node}
+	}
+	return nil
+}
+
+// leadingComments matches SQL comments before the first keyword
+var leadingComments = regexp.MustCompile(`^(\s*(--[^\n]*\n|/\*.*?\*/))*\s*`)
+
+// isSelect reports whether a query is a SELECT or a WITH ... SELECT
+func isSelect(query string) bool {
+	query = strings.ToLower(leadingComments.ReplaceAllString(query, ""))
+	return strings.HasPrefix(query, "select") || strings.HasPrefix(query, "with")
+}
@@ -0,0 +1,216 @@
+package costguard
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+const postgresPlan = `[{"Plan": {"Node Type": "Hash Join", "Total Cost": 25000.5, "Plan Rows": 1000,
+	"Plans": [
+		{"Node Type": "Seq Scan", "Relation Name": "orders", "Total Cost": 18000, "Plan Rows": 2000000},
+		{"Node Type": "Index Scan", "Relation Name": "customers", "Index Name": "customers_pkey", "Total Cost": 8.3, "Plan Rows": 1}
+	]}}]`
+
+const mysqlPlan = `{"query_block": {"select_id": 1, "cost_info": {"query_cost": "120500.25"},
+	"nested_loop": [
+		{"table": {"table_name": "orders", "access_type": "ALL", "rows_examined_per_scan": 1000000,
+			"rows_produced_per_join": 1000000, "cost_info": {"prefix_cost": "100500.00"}}},
+		{"table": {"table_name": "customers", "access_type": "eq_ref", "rows_examined_per_scan": 1,
+			"rows_produced_per_join": 1000000, "cost_info": {"prefix_cost": "120500.25"}}}
+	]}}`
+
+// fakeRepo serves a single database whose queries return a fixed value
+type fakeRepo struct {
+	dbType string
+	db     *fakeDB
+}
+
+func newFakeRepo(dbType, out string) *fakeRepo {
+	return &fakeRepo{dbType: dbType, db: &fakeDB{out: out}}
+}
+
+func (r *fakeRepo) GetDatabase(string) (domain.Database, error)     { return r.db, nil }
+func (r *fakeRepo) GetReadDatabase(string) (domain.Database, error) { return r.db, nil }
+func (r *fakeRepo) ListDatabases() []string                         { return []string{"db1"} }
+func (r *fakeRepo) GetDatabaseType(string) (string, error)          { return r.dbType, nil }
+func (r *fakeRepo) IsLazyLoading() bool                             { return false }
+func (r *fakeRepo) SupportsTransactions(string) bool                { return true }
+func (r *fakeRepo) InspectDatabase(_ string, fn func(domain.Database) error) error {
+	return fn(r.db)
+}
+
+// fakeDB records queries and answers each with a single text value, or
+// fails them with err
+type fakeDB struct {
+	out      string
+	err      error
+	queries  []string
+	prepared []string
+}
+
+func (d *fakeDB) Query(_ context.Context, query string, _ ...interface{}) (domain.Rows, error) {
+	d.queries = append(d.queries, query)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &fakeRows{value: []byte(d.out)}, nil
+}
+
+func (d *fakeDB) QueryPrepared(_ context.Context, query string, _ ...interface{}) (domain.Rows, error) {
+	d.prepared = append(d.prepared, query)
+	if d.err != nil {
+		return nil, d.err
+	}
+	return &fakeRows{value: []byte(d.out)}, nil
+}
+
+func (d *fakeDB) Exec(context.Context, string, ...interface{}) (domain.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (d *fakeDB) Begin(context.Context, *domain.TxOptions) (domain.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type fakeRows struct {
+	value []byte
+	read  bool
+}
+
+func (r *fakeRows) Close() error               { return nil }
+func (r *fakeRows) Columns() ([]string, error) { return []string{"QUERY PLAN"}, nil }
+func (r *fakeRows) Err() error                 { return nil }
+
+func (r *fakeRows) Next() bool {
+	next := !r.read
+	r.read = true
+	return next
+}
+
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	*dest[0].(*interface{}) = r.value
+	return nil
+}
+
+func TestParsePostgresPlan(t *testing.T) {
+	plan, err := parsePostgresPlan(postgresPlan)
+	require.NoError(t, err)
+	assert.Equal(t, 25000.5, plan.Cost)
+	assert.Equal(t, float64(2000000), plan.Rows)
+	require.Len(t, plan.Root.children, 2)
+	assert.Equal(t, "customers", plan.Root.children[1].Object)
+
+	_, err = parsePostgresPlan("not json")
+	assert.Error(t, err)
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	plan, err := parseMySQLPlan(mysqlPlan)
+	require.NoError(t, err)
+	assert.Equal(t, 120500.25, plan.Cost)
+	assert.Equal(t, float64(1000000), plan.Rows)
+	require.Len(t, plan.Root.children, 2)
+	assert.Equal(t, "orders", plan.Root.children[0].Object)
+	assert.Equal(t, "ALL", plan.Root.children[0].Operation)
+}
+
+func TestBuildOraclePlan(t *testing.T) {
+	plan, err := buildOraclePlan([]oraclePlanRow{
+		{id: 0, parentID: -1, operation: "SELECT STATEMENT", cost: 900, cardinality: 10},
+		{id: 1, parentID: 0, operation: "TABLE ACCESS", options: "FULL", object: "ORDERS", cost: 880, cardinality: 500000},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, float64(900), plan.Cost)
+	assert.Equal(t, float64(500000), plan.Rows)
+	assert.Equal(t, "TABLE ACCESS FULL on ORDERS (cost 880, rows 500000)", plan.Root.children[0].String())
+}
+
+func TestGuardRejectsExpensiveQueries(t *testing.T) {
+	repo := newFakeRepo("postgres", postgresPlan)
+	guard := New(map[string]Limits{"db1": {MaxCost: 10000}}, repo)
+	ctx := context.Background()
+
+	err := guard.Check(ctx, "db1", "SELECT * FROM orders JOIN customers USING (customer_id)", nil, false)
+	var costErr *Error
+	require.True(t, errors.As(err, &costErr))
+	assert.Equal(t, []string{"EXPLAIN (FORMAT JSON) SELECT * FROM orders JOIN customers USING (customer_id)"}, repo.db.prepared)
+	assert.Empty(t, repo.db.queries)
+
+	// The scan is responsible for the cost, not the join above it
+	require.Len(t, costErr.Nodes, 1)
+	assert.Equal(t, "orders", costErr.Nodes[0].Object)
+	assert.Contains(t, costErr.Suggestions, "select-star")
+	assert.Contains(t, err.Error(), "cost 25000 > 10000")
+	assert.Contains(t, err.Error(), "Seq Scan on orders")
+	assert.NotContains(t, err.Error(), "confirm=true")
+
+	// Confirmation does not override a reject action
+	assert.Error(t, guard.Check(ctx, "db1", "SELECT * FROM orders", nil, true))
+
+	// Within limits, other statements and other connections pass
+	assert.NoError(t, New(map[string]Limits{"db1": {MaxCost: 50000}}, repo).Check(ctx, "db1", "SELECT 1", nil, false))
+	assert.NoError(t, guard.Check(ctx, "db1", "DELETE FROM orders", nil, false))
+	assert.NoError(t, guard.Check(ctx, "db2", "SELECT * FROM orders", nil, false))
+}
+
+func TestGuardConfirmAction(t *testing.T) {
+	repo := newFakeRepo("mysql", mysqlPlan)
+	guard := New(map[string]Limits{"db1": {MaxRows: 1000, Action: ActionConfirm}}, repo)
+	ctx := context.Background()
+
+	query := "/* report */ SELECT id FROM orders JOIN customers ON customers.id = orders.customer_id"
+	err := guard.Check(ctx, "db1", query, nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rows 1000000 > 1000")
+	assert.Contains(t, err.Error(), "confirm=true")
+
+	assert.NoError(t, guard.Check(ctx, "db1", query, nil, true))
+}
+
+func TestGuardSkipsUnsupportedDatabases(t *testing.T) {
+	repo := newFakeRepo("sqlite", "")
+	guard := New(map[string]Limits{"db1": {MaxCost: 1}}, repo)
+
+	assert.NoError(t, guard.Check(context.Background(), "db1", "SELECT * FROM t", nil, false))
+	assert.Empty(t, repo.db.queries)
+	assert.Empty(t, repo.db.prepared)
+}
+
+func TestGuardRejectsBatches(t *testing.T) {
+	repo := newFakeRepo("postgres", postgresPlan)
+	guard := New(map[string]Limits{"db1": {MaxCost: 50000}}, repo)
+	ctx := context.Background()
+
+	err := guard.Check(ctx, "db1", "SELECT 1; DELETE FROM orders", nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "one statement")
+	assert.Empty(t, repo.db.prepared)
+
+	// Semicolons in strings and trailing ones do not make a batch
+	assert.NoError(t, guard.Check(ctx, "db1", "SELECT ';' FROM orders;", nil, false))
+}
+
+func TestGuardFailOpen(t *testing.T) {
+	repo := newFakeRepo("postgres", "")
+	repo.db.err = errors.New("syntax error")
+	ctx := context.Background()
+
+	err := New(map[string]Limits{"db1": {MaxCost: 1}}, repo).Check(ctx, "db1", "SELECT * FROM t", nil, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "could not plan")
+
+	guard := New(map[string]Limits{"db1": {MaxCost: 1, FailOpen: true}}, repo)
+	assert.NoError(t, guard.Check(ctx, "db1", "SELECT * FROM t", nil, false))
+}
+
+func TestLimitsValidate(t *testing.T) {
+	assert.NoError(t, Limits{}.Validate())
+	assert.NoError(t, Limits{Action: ActionConfirm}.Validate())
+	assert.Error(t, Limits{Action: "warn"}.Validate())
+}
@@ -0,0 +1,302 @@
+package costguard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+)
+
+// explainer obtains the planner's estimate for a query
+type explainer func(ctx context.Context, db domain.Database, query string, params []interface{}) (*Plan, error)
+
+// explainers plan queries in each explain style of the dialects
+var explainers = map[dialect.ExplainStyle]explainer{
+	dialect.ExplainPostgresJSON:    explainPostgres,
+	dialect.ExplainMySQLJSON:       explainMySQL,
+	dialect.ExplainOraclePlanTable: explainOracle,
+}
+
+// explainerFor returns the explainer for a database type, or nil if the
+// type has none, and the syntax of its queries
+func explainerFor(dbType string) (explainer, dialect.Syntax) {
+	d, ok := dialect.Lookup(dbType)
+	if !ok {
+		return nil, dialect.Syntax{}
+	}
+	return explainers[d.Explain()], d.Syntax()
+}
+
+// explainPostgres runs EXPLAIN (FORMAT JSON), which plans without executing.
+// It is prepared, as lib/pq would otherwise send a query without parameters
+// as a simple query, which runs every statement of a batch.
+func explainPostgres(ctx context.Context, db domain.Database, query string, params []interface{}) (*Plan, error) {
+	out, err := queryText(db.QueryPrepared(ctx, "EXPLAIN (FORMAT JSON) "+query, params...))
+	if err != nil {
+		return nil, err
+	}
+	return parsePostgresPlan(out)
+}
+
+// explainMySQL runs EXPLAIN FORMAT=JSON. The driver only sends batches
+// when the multiStatements option is set.
+func explainMySQL(ctx context.Context, db domain.Database, query string, params []interface{}) (*Plan, error) {
+	out, err := queryText(db.Query(ctx, "EXPLAIN FORMAT=JSON "+query, params...))
+	if err != nil {
+		return nil, err
+	}
+	return parseMySQLPlan(out)
+}
+
+// oracleStatementSeq makes plan table statement IDs unique within the process
+var oracleStatementSeq atomic.Int64
+
+// explainOracle fills the plan table with EXPLAIN PLAN and reads it back.
+// Bind variables are planned without their values.
+func explainOracle(ctx context.Context, db domain.Database, query string, _ []interface{}) (*Plan, error) {
+	statementID := fmt.Sprintf("mcp_%d_%d", time.Now().UnixNano(), oracleStatementSeq.Add(1))
+	if _, err := db.Exec(ctx, fmt.Sprintf("EXPLAIN PLAN SET STATEMENT_ID = '%s' FOR %s", statementID, query)); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, err := db.Exec(ctx, "DELETE FROM plan_table WHERE statement_id = :1", statementID); err != nil {
+			logger.Warn("Cost guard: failed to clean up plan table: %v", err)
+		}
+	}()
+
+	rows, err := db.Query(ctx, `SELECT id, parent_id, operation, options, object_name, cost, cardinality
+		FROM plan_table WHERE statement_id = :1 ORDER BY id`, statementID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			logger.Error("error closing rows: %v", closeErr)
+		}
+	}()
+
+	var planRows []oraclePlanRow
+	for rows.Next() {
+		var r oraclePlanRow
+		var parentID, cost, cardinality *float64
+		var options, object *string
+		if err := rows.Scan(&r.id, &parentID, &r.operation, &options, &object, &cost, &cardinality); err != nil {
+			return nil, err
+		}
+		r.parentID = -1
+		if parentID != nil {
+			r.parentID = int(*parentID)
+		}
+		if options != nil {
+			r.options = *options
+		}
+		if object != nil {
+			r.object = *object
+		}
+		if cost != nil {
+			r.cost = *cost
+		}
+		if cardinality != nil {
+			r.cardinality = *cardinality
+		}
+		planRows = append(planRows, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return buildOraclePlan(planRows)
+}
+
+// queryText reads the single text value returned by a query
+func queryText(rows domain.Rows, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if closeErr := rows.Close(); closeErr != nil {
+			logger.Error("error closing rows: %v", closeErr)
+		}
+	}()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return "", fmt.Errorf("EXPLAIN returned no rows")
+	}
+	var value interface{}
+	if err := rows.Scan(&value); err != nil {
+		return "", err
+	}
+	switch v := value.(type) {
+	case []byte:
+		return string(v), nil
+	case string:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unexpected EXPLAIN output of type %T", value)
+	}
+}
+
+// postgresNode is a node of PostgreSQL's JSON plan
+type postgresNode struct {
+	NodeType     string         `json:"Node Type"`
+	RelationName string         `json:"Relation Name"`
+	IndexName    string         `json:"Index Name"`
+	TotalCost    float64        `json:"Total Cost"`
+	PlanRows     float64        `json:"Plan Rows"`
+	Plans        []postgresNode `json:"Plans"`
+}
+
+// parsePostgresPlan parses the output of EXPLAIN (FORMAT JSON)
+func parsePostgresPlan(out string) (*Plan, error) {
+	var explained []struct {
+		Plan postgresNode `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(out), &explained); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if len(explained) == 0 {
+		return nil, fmt.Errorf("empty plan")
+	}
+
+	plan := &Plan{}
+	var convert func(n postgresNode) *PlanNode
+	convert = func(n postgresNode) *PlanNode {
+		node := &PlanNode{Operation: n.NodeType, Object: n.RelationName, Cost: n.TotalCost, Rows: n.PlanRows}
+		if node.Object == "" {
+			node.Object = n.IndexName
+		}
+		if n.PlanRows > plan.Rows {
+			plan.Rows = n.PlanRows
+		}
+		for _, child := range n.Plans {
+			node.children = append(node.children, convert(child))
+		}
+		return node
+	}
+	plan.Root = convert(explained[0].Plan)
+	plan.Cost = plan.Root.Cost
+	return plan, nil
+}
+
+// parseMySQLPlan parses the output of EXPLAIN FORMAT=JSON. Tables can be
+// nested anywhere below query_block (nested_loop, ordering_operation, ...),
+// so the document is walked generically.
+func parseMySQLPlan(out string) (*Plan, error) {
+	var doc struct {
+		QueryBlock map[string]interface{} `json:"query_block"`
+	}
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse plan: %w", err)
+	}
+	if doc.QueryBlock == nil {
+		return nil, fmt.Errorf("plan has no query_block")
+	}
+
+	plan := &Plan{}
+	root := &PlanNode{Operation: "query_block"}
+	if costInfo, ok := doc.QueryBlock["cost_info"].(map[string]interface{}); ok {
+		root.Cost = number(costInfo["query_cost"])
+	}
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch value := v.(type) {
+		case map[string]interface{}:
+			if table, ok := value["table"].(map[string]interface{}); ok {
+				node := &PlanNode{Object: fmt.Sprint(table["table_name"])}
+				node.Operation, _ = table["access_type"].(string)
+				node.Rows = number(table["rows_examined_per_scan"])
+				if produced := number(table["rows_produced_per_join"]); produced > node.Rows {
+					node.Rows = produced
+				}
+				if costInfo, ok := table["cost_info"].(map[string]interface{}); ok {
+					node.Cost = number(costInfo["prefix_cost"])
+				}
+				if node.Rows > plan.Rows {
+					plan.Rows = node.Rows
+				}
+				root.children = append(root.children, node)
+			}
+			for _, child := range value {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				walk(child)
+			}
+		}
+	}
+	walk(doc.QueryBlock)
+
+	// Map iteration order is random; keep nodes in a stable order
+	sort.SliceStable(root.children, func(i, j int) bool { return root.children[i].Cost < root.children[j].Cost })
+	root.Rows = plan.Rows
+	plan.Root = root
+	plan.Cost = root.Cost
+	return plan, nil
+}
+
+// number reads a JSON number that MySQL may encode as a string
+func number(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(n, 64)
+		return f
+	default:
+		return 0
+	}
+}
+
+// oraclePlanRow is a row of Oracle's plan table
+type oraclePlanRow struct {
+	id          int
+	parentID    int
+	operation   string
+	options     string
+	object      string
+	cost        float64
+	cardinality float64
+}
+
+// buildOraclePlan assembles plan table rows into a tree
+func buildOraclePlan(rows []oraclePlanRow) (*Plan, error) {
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("plan table has no rows for the statement")
+	}
+
+	plan := &Plan{}
+	nodes := make(map[int]*PlanNode, len(rows))
+	for _, r := range rows {
+		operation := r.operation
+		if r.options != "" {
+			operation += " " + r.options
+		}
+		nodes[r.id] = &PlanNode{Operation: operation, Object: r.object, Cost: r.cost, Rows: r.cardinality}
+		if r.cardinality > plan.Rows {
+			plan.Rows = r.cardinality
+		}
+	}
+	for _, r := range rows {
+		if parent, ok := nodes[r.parentID]; ok {
+			parent.children = append(parent.children, nodes[r.id])
+		} else if plan.Root == nil {
+			plan.Root = nodes[r.id]
+		}
+	}
+	if plan.Root == nil {
+		return nil, fmt.Errorf("plan table has no root operation")
+	}
+	plan.Cost = plan.Root.Cost
+	return plan, nil
+}
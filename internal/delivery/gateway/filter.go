@@ -0,0 +1,171 @@
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// filterToolsList removes tools the caller may not see from a JSON-RPC
+// tools/list result. Other messages are returned unchanged.
+func filterToolsList(message []byte, visible func(name string) bool) []byte {
+	var response map[string]interface{}
+	if err := json.Unmarshal(message, &response); err != nil {
+		return message
+	}
+
+	result, ok := response["result"].(map[string]interface{})
+	if !ok {
+		return message
+	}
+	toolList, ok := result["tools"].([]interface{})
+	if !ok {
+		return message
+	}
+
+	filtered := make([]interface{}, 0, len(toolList))
+	for _, item := range toolList {
+		tool, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if name, _ := tool["name"].(string); visible(name) {
+			filtered = append(filtered, tool)
+		}
+	}
+	result["tools"] = filtered
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return message
+	}
+	return data
+}
+
+// filterJSONBody applies filterToolsList to a buffered JSON response body
+func filterJSONBody(resp *http.Response, visible func(name string) bool) error {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if err := resp.Body.Close(); err != nil {
+		return err
+	}
+
+	body = filterToolsList(bytes.TrimSpace(body), visible)
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return nil
+}
+
+// eventStreamFilter applies filterToolsList to the data lines of an SSE stream,
+// passing each line on as soon as it is complete. Notifications sent to the
+// stream are inserted between events.
+type eventStreamFilter struct {
+	body    io.ReadCloser
+	visible func(name string) bool
+	lines   chan []byte
+	done    chan struct{}
+	err     error // read error of the body, set before lines is closed
+
+	notifications chan []byte
+	queued        [][]byte
+	atBoundary    bool // the last line passed on ended an event
+	pending       []byte
+	closeOnce     sync.Once
+}
+
+// newEventStreamFilter wraps an SSE response body
+func newEventStreamFilter(body io.ReadCloser, visible func(name string) bool) *eventStreamFilter {
+	f := &eventStreamFilter{
+		body:          body,
+		visible:       visible,
+		lines:         make(chan []byte),
+		done:          make(chan struct{}),
+		notifications: make(chan []byte, 16),
+	}
+	go f.readLines()
+	return f
+}
+
+// readLines reads the body line by line until it fails or the filter is closed
+func (f *eventStreamFilter) readLines() {
+	defer close(f.lines)
+	reader := bufio.NewReader(f.body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			select {
+			case f.lines <- line:
+			case <-f.done:
+				return
+			}
+		}
+		if err != nil {
+			f.err = err
+			return
+		}
+	}
+}
+
+// notify queues a JSON-RPC message to be sent as an event, dropping it if
+// the client is not keeping up
+func (f *eventStreamFilter) notify(message []byte) {
+	select {
+	case f.notifications <- message:
+	default:
+	}
+}
+
+// Read implements io.Reader
+func (f *eventStreamFilter) Read(p []byte) (int, error) {
+	for len(f.pending) == 0 {
+		if f.atBoundary && len(f.queued) > 0 {
+			f.pending = []byte("event: message\ndata: " + string(f.queued[0]) + "\n\n")
+			f.queued = f.queued[1:]
+			break
+		}
+
+		select {
+		case line, ok := <-f.lines:
+			if !ok {
+				return 0, f.err
+			}
+			f.pending = f.filterLine(line)
+			f.atBoundary = len(bytes.TrimSpace(line)) == 0
+		case message := <-f.notifications:
+			f.queued = append(f.queued, message)
+		}
+	}
+
+	n := copy(p, f.pending)
+	f.pending = f.pending[n:]
+	return n, nil
+}
+
+// filterLine rewrites "data:" lines that carry a tools/list result
+func (f *eventStreamFilter) filterLine(line []byte) []byte {
+	const prefix = "data: "
+	if !bytes.HasPrefix(line, []byte(prefix)) {
+		return line
+	}
+
+	payload := bytes.TrimRight(line[len(prefix):], "\r\n")
+	filtered := filterToolsList(payload, f.visible)
+
+	out := make([]byte, 0, len(prefix)+len(filtered)+1)
+	out = append(out, prefix...)
+	out = append(out, filtered...)
+	return append(out, '\n')
+}
+
+// Close implements io.Closer
+func (f *eventStreamFilter) Close() error {
+	f.closeOnce.Do(func() { close(f.done) })
+	return f.body.Close()
+}
@@ -0,0 +1,318 @@
+// Package gateway provides the public HTTP front end of the SSE transport.
+// It reverse-proxies to the MCP server, served in-process on an in-memory
+// listener, and adds what the MCP server does not provide itself, such as
+// authentication.
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// ToolFilter decides which tools are listed for a caller. The principal is
+// nil when authentication is disabled.
+type ToolFilter interface {
+	ToolVisible(principal *auth.Principal, name string) bool
+}
+
+// Config holds the gateway settings
+type Config struct {
+	Addr     string       // public listen address, e.g. ":9092"
+	Upstream http.Handler // the MCP server, only reachable through the gateway
+
+	// Metrics is served on /metrics when set
+	Metrics http.Handler
+
+	// Health is served on /healthz when set, without authentication so that
+	// load balancers and orchestrators can probe it
+	Health http.Handler
+
+	// TLS; leave nil to serve plain HTTP
+	TLS *TLSConfig
+
+	// Tools filters tools/list results when set
+	Tools ToolFilter
+
+	// Authentication; leave Authenticator nil to disable it
+	Authenticator *auth.Authenticator
+	Sessions      *auth.SessionStore
+}
+
+// Gateway is the public HTTP server in front of the MCP server
+type Gateway struct {
+	cfg      Config
+	proxy    *httputil.ReverseProxy
+	server   *http.Server
+	upstream *http.Server // serves the MCP server to the proxy, in memory
+	certs    *certReloader
+
+	mu      sync.Mutex
+	streams map[*eventStreamFilter]struct{} // open SSE streams
+}
+
+// principalKey is the request context key holding the authenticated principal
+type principalKey struct{}
+
+// New creates a gateway for the given configuration
+func New(cfg Config) (*Gateway, error) {
+	if cfg.Authenticator != nil && (cfg.Sessions == nil || cfg.Tools == nil) {
+		return nil, errors.New("gateway authentication requires a session store and a tool filter")
+	}
+
+	if cfg.Upstream == nil {
+		return nil, errors.New("gateway requires an upstream MCP server")
+	}
+
+	g := &Gateway{cfg: cfg, streams: make(map[*eventStreamFilter]struct{})}
+
+	// The MCP server gets no port of its own, which other local users
+	// could reach without going through authentication and TLS
+	listener := newPipeListener()
+	g.upstream = &http.Server{Handler: cfg.Upstream, ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = g.upstream.Serve(listener) }()
+
+	g.proxy = httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: listener.Addr().String()})
+	g.proxy.Transport = &http.Transport{DialContext: listener.DialContext}
+	// Flush immediately so SSE events are not buffered
+	g.proxy.FlushInterval = -1
+	g.proxy.ModifyResponse = g.modifyResponse
+
+	g.server = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           g.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	if cfg.TLS != nil {
+		var err error
+		g.certs, err = newCertReloader(*cfg.TLS)
+		if err != nil {
+			_ = g.upstream.Close()
+			return nil, err
+		}
+		g.server.TLSConfig = g.certs.tlsConfig()
+	}
+	return g, nil
+}
+
+// Handler returns the HTTP handler of the gateway
+func (g *Gateway) Handler() http.Handler {
+	mux := http.NewServeMux()
+	if g.cfg.Health != nil {
+		mux.Handle("/healthz", g.cfg.Health)
+	}
+	if g.cfg.Authenticator == nil {
+		if g.cfg.Metrics != nil {
+			mux.Handle("/metrics", g.cfg.Metrics)
+		}
+		mux.Handle("/", g.proxy)
+		return mux
+	}
+
+	if g.cfg.Metrics != nil {
+		mux.HandleFunc("/metrics", g.requireAdmin(g.cfg.Metrics.ServeHTTP))
+	}
+	mux.HandleFunc("/sse", g.handleSSE)
+	mux.HandleFunc("/events", g.requireAuth(g.proxy.ServeHTTP))
+	mux.HandleFunc("/message", g.handleMessage)
+	mux.Handle("/status", g.proxy)
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSONRPCError(w, http.StatusNotFound, -32601,
+			"Direct JSON-RPC is disabled when authentication is enabled; use the SSE transport")
+	})
+	return mux
+}
+
+// ListenAndServe starts the gateway, over TLS when certificates are configured
+func (g *Gateway) ListenAndServe() error {
+	if g.certs != nil {
+		return g.server.ListenAndServeTLS("", "")
+	}
+	return g.server.ListenAndServe()
+}
+
+// TLSEnabled reports whether the gateway serves HTTPS
+func (g *Gateway) TLSEnabled() bool {
+	return g.certs != nil
+}
+
+// ReloadTLS reloads the certificate files, keeping the current ones on error
+func (g *Gateway) ReloadTLS() error {
+	if g.certs == nil {
+		return nil
+	}
+	return g.certs.Reload()
+}
+
+// Shutdown gracefully stops the gateway and then the MCP server behind it
+func (g *Gateway) Shutdown(ctx context.Context) error {
+	err := g.server.Shutdown(ctx)
+	return errors.Join(err, g.upstream.Shutdown(ctx))
+}
+
+// authenticate resolves the caller of a request, writing a 401 response on failure
+func (g *Gateway) authenticate(w http.ResponseWriter, r *http.Request) *auth.Principal {
+	principal, err := g.cfg.Authenticator.AuthenticateRequest(r)
+	if err != nil {
+		logger.Warn("Rejected unauthenticated %s request from %s: %v", r.URL.Path, r.RemoteAddr, err)
+		w.Header().Set("WWW-Authenticate", `Bearer realm="db-mcp-server"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil
+	}
+	return principal
+}
+
+// requireAuth wraps a handler so that it only runs for authenticated callers
+func (g *Gateway) requireAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if g.authenticate(w, r) == nil {
+			return
+		}
+		next(w, r)
+	}
+}
+
+// requireAdmin wraps a handler so that it only runs for administrators
+func (g *Gateway) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := g.authenticate(w, r)
+		if principal == nil {
+			return
+		}
+		if !principal.Admin {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleSSE opens an event stream with a server-assigned session bound to the caller
+func (g *Gateway) handleSSE(w http.ResponseWriter, r *http.Request) {
+	principal := g.authenticate(w, r)
+	if principal == nil {
+		return
+	}
+
+	// Never let clients pick their own session ID, it would let them
+	// attach to a session opened by someone else
+	sessionID, err := newSessionID()
+	if err != nil {
+		http.Error(w, "Failed to create session", http.StatusInternalServerError)
+		return
+	}
+	g.cfg.Sessions.Bind(sessionID, principal)
+	defer g.cfg.Sessions.Unbind(sessionID)
+	logger.Info("Opened SSE session %s for %s", sessionID, principal.Subject)
+
+	query := r.URL.Query()
+	query.Set("session", sessionID)
+	r.URL.RawQuery = query.Encode()
+
+	g.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+}
+
+// handleMessage forwards a JSON-RPC message after checking that the caller owns the session
+func (g *Gateway) handleMessage(w http.ResponseWriter, r *http.Request) {
+	principal := g.authenticate(w, r)
+	if principal == nil {
+		return
+	}
+
+	owner := g.cfg.Sessions.Principal(r.URL.Query().Get("sessionId"))
+	if owner == nil || owner.Subject != principal.Subject {
+		writeJSONRPCError(w, http.StatusForbidden, -32602, "Invalid session ID")
+		return
+	}
+
+	// The scope of the session applies, as that is what tool calls are checked against
+	g.proxy.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, owner)))
+}
+
+// modifyResponse hides tools the caller may not see from tools/list results
+// and tracks SSE streams so notifications can be sent to them
+func (g *Gateway) modifyResponse(resp *http.Response) error {
+	principal, _ := resp.Request.Context().Value(principalKey{}).(*auth.Principal)
+	if principal == nil && g.cfg.Authenticator != nil {
+		return nil
+	}
+
+	visible := func(name string) bool {
+		return g.cfg.Tools == nil || g.cfg.Tools.ToolVisible(principal, name)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	switch {
+	case strings.HasPrefix(contentType, "text/event-stream"):
+		resp.Body = g.trackStream(newEventStreamFilter(resp.Body, visible))
+	case strings.HasPrefix(contentType, "application/json") && g.cfg.Tools != nil:
+		return filterJSONBody(resp, visible)
+	}
+	return nil
+}
+
+// trackedStream removes an SSE stream from the gateway when it is closed
+type trackedStream struct {
+	*eventStreamFilter
+	g *Gateway
+}
+
+// Close implements io.Closer
+func (s trackedStream) Close() error {
+	s.g.mu.Lock()
+	delete(s.g.streams, s.eventStreamFilter)
+	s.g.mu.Unlock()
+	return s.eventStreamFilter.Close()
+}
+
+// trackStream registers an SSE stream for notifications
+func (g *Gateway) trackStream(f *eventStreamFilter) trackedStream {
+	g.mu.Lock()
+	g.streams[f] = struct{}{}
+	g.mu.Unlock()
+	return trackedStream{eventStreamFilter: f, g: g}
+}
+
+// toolsListChanged is the MCP notification telling clients to list tools again
+var toolsListChanged = []byte(`{"jsonrpc":"2.0","method":"notifications/tools/list_changed"}`)
+
+// NotifyToolsChanged tells every connected SSE client that the tool list changed
+func (g *Gateway) NotifyToolsChanged() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for stream := range g.streams {
+		stream.notify(toolsListChanged)
+	}
+}
+
+// newSessionID returns a random session identifier
+func newSessionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// writeJSONRPCError writes a JSON-RPC error response without a request ID
+func writeJSONRPCError(w http.ResponseWriter, status int, code int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      nil,
+		"error":   map[string]interface{}{"code": code, "message": message},
+	})
+}
@@ -0,0 +1,252 @@
+package gateway
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+)
+
+// prefixFilter shows tools whose name starts with one of the principal's databases
+type prefixFilter struct{}
+
+func (prefixFilter) ToolVisible(principal *auth.Principal, name string) bool {
+	for _, db := range principal.Databases {
+		if strings.HasSuffix(name, "_"+db) {
+			return true
+		}
+	}
+	return false
+}
+
+const toolsListResponse = `{"jsonrpc":"2.0","id":1,"result":{"tools":[{"name":"query_db1"},{"name":"query_db2"}]}}`
+
+func TestFilterToolsList(t *testing.T) {
+	visible := func(name string) bool { return name == "query_db1" }
+
+	filtered := string(filterToolsList([]byte(toolsListResponse), visible))
+	assert.Contains(t, filtered, "query_db1")
+	assert.NotContains(t, filtered, "query_db2")
+
+	// Other messages pass through untouched
+	other := `{"jsonrpc":"2.0","id":2,"result":{"content":[]}}`
+	assert.Equal(t, other, string(filterToolsList([]byte(other), visible)))
+}
+
+func TestEventStreamFilter(t *testing.T) {
+	stream := "event: endpoint\ndata: /message?sessionId=abc\n\n" +
+		"event: message\ndata: " + toolsListResponse + "\n\n"
+
+	filter := newEventStreamFilter(io.NopCloser(strings.NewReader(stream)), func(name string) bool {
+		return name == "query_db2"
+	})
+	out, err := io.ReadAll(filter)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(out), "data: /message?sessionId=abc\n\n")
+	assert.Contains(t, string(out), "query_db2")
+	assert.NotContains(t, string(out), "query_db1")
+}
+
+func newTestGateway(t *testing.T) (*Gateway, *auth.SessionStore, *string) {
+	var upstreamQuery string
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamQuery = r.URL.RawQuery
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, toolsListResponse)
+	})
+
+	authenticator, err := auth.New(auth.Config{Enabled: true, Tokens: []auth.TokenConfig{
+		{Name: "alice", Token: "alice-token", Databases: []string{"db1"}},
+		{Name: "bob", Token: "bob-token", Databases: []string{"db2"}},
+	}})
+	require.NoError(t, err)
+
+	sessions := auth.NewSessionStore()
+	g, err := New(Config{
+		Upstream:      upstream,
+		Authenticator: authenticator,
+		Sessions:      sessions,
+		Tools:         prefixFilter{},
+	})
+	require.NoError(t, err)
+	return g, sessions, &upstreamQuery
+}
+
+func TestGatewayRequiresBearerToken(t *testing.T) {
+	g, _, _ := newTestGateway(t)
+
+	rec := httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/sse", nil))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("WWW-Authenticate"))
+
+	// Direct JSON-RPC bypasses sessions and is refused
+	req := httptest.NewRequest(http.MethodPost, "/jsonrpc", strings.NewReader(`{}`))
+	req.Header.Set("Authorization", "Bearer alice-token")
+	rec = httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestGatewayAssignsSessions(t *testing.T) {
+	g, sessions, upstreamQuery := newTestGateway(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/sse?session=stolen", nil)
+	req.Header.Set("Authorization", "Bearer alice-token")
+	rec := httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, req)
+
+	assert.NotContains(t, *upstreamQuery, "stolen")
+	assert.Contains(t, *upstreamQuery, "session=")
+	// The session is released when the stream ends
+	assert.Nil(t, sessions.Principal(strings.TrimPrefix(*upstreamQuery, "session=")))
+}
+
+func TestGatewayMessageChecksSessionOwner(t *testing.T) {
+	g, sessions, _ := newTestGateway(t)
+	sessions.Bind("s1", &auth.Principal{Subject: "alice", Databases: []string{"db1"}})
+
+	post := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/message?sessionId=s1", strings.NewReader(`{}`))
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		g.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := post("bob-token")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+
+	rec = post("alice-token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "query_db1")
+	assert.NotContains(t, rec.Body.String(), "query_db2")
+}
+
+func TestGatewayMetricsRequireAdmin(t *testing.T) {
+	metricsHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "mcp_test 1\n")
+	})
+
+	authenticator, err := auth.New(auth.Config{Enabled: true, Tokens: []auth.TokenConfig{
+		{Name: "agent", Token: "agent-token"},
+		{Name: "ops", Token: "ops-token", Admin: true},
+	}})
+	require.NoError(t, err)
+	g, err := New(Config{
+		Upstream:      http.NotFoundHandler(),
+		Metrics:       metricsHandler,
+		Authenticator: authenticator,
+		Sessions:      auth.NewSessionStore(),
+		Tools:         prefixFilter{},
+	})
+	require.NoError(t, err)
+
+	get := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		g.Handler().ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("").Code)
+	assert.Equal(t, http.StatusForbidden, get("agent-token").Code)
+	rec := get("ops-token")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "mcp_test 1\n", rec.Body.String())
+
+	// Without authentication metrics are served to anyone
+	open, err := New(Config{Upstream: http.NotFoundHandler(), Metrics: metricsHandler})
+	require.NoError(t, err)
+	rec = httptest.NewRecorder()
+	open.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestGatewayNotifiesToolsChanged(t *testing.T) {
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "event: endpoint\ndata: /message?sessionId=abc\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	g, err := New(Config{Upstream: upstream})
+	require.NoError(t, err)
+	front := httptest.NewServer(g.Handler())
+	t.Cleanup(front.Close)
+
+	resp, err := http.Get(front.URL + "/sse")
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+	reader := bufio.NewReader(resp.Body)
+
+	readEvent := func() string {
+		var event strings.Builder
+		for {
+			line, err := reader.ReadString('\n')
+			require.NoError(t, err)
+			if line == "\n" {
+				return event.String()
+			}
+			event.WriteString(line)
+		}
+	}
+
+	assert.Contains(t, readEvent(), "sessionId=abc")
+	g.NotifyToolsChanged()
+	assert.Equal(t, "event: message\ndata: "+string(toolsListChanged)+"\n", readEvent())
+}
+
+func TestGatewayHealthIsPublic(t *testing.T) {
+	healthHandler := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"status":"ok"}`)
+	})
+
+	authenticator, err := auth.New(auth.Config{Enabled: true, Tokens: []auth.TokenConfig{
+		{Name: "agent", Token: "agent-token"},
+	}})
+	require.NoError(t, err)
+	g, err := New(Config{
+		Upstream:      http.NotFoundHandler(),
+		Health:        healthHandler,
+		Authenticator: authenticator,
+		Sessions:      auth.NewSessionStore(),
+		Tools:         prefixFilter{},
+	})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `{"status":"ok"}`, rec.Body.String())
+}
+
+func TestGatewayStopsUpstreamOnShutdown(t *testing.T) {
+	g, err := New(Config{Upstream: http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, "upstream")
+	})})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "upstream", rec.Body.String())
+
+	require.NoError(t, g.Shutdown(context.Background()))
+	rec = httptest.NewRecorder()
+	g.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+}
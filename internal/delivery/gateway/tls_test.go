@@ -0,0 +1,168 @@
+package gateway
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+)
+
+// testCert is a generated certificate and key
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// newTestCert creates a certificate signed by parent, or a self-signed CA when parent is nil
+func newTestCert(t *testing.T, commonName string, parent *testCert) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &testCert{cert: cert, key: key, der: der}
+}
+
+// writePEM writes the certificate and key of c to dir and returns their paths
+func (c *testCert) writePEM(t *testing.T, dir, name string) (string, string) {
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.der}), 0600))
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certPath, keyPath
+}
+
+// tlsKeyPair converts c into a client certificate
+func (c *testCert) tlsKeyPair() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{c.der}, PrivateKey: c.key}
+}
+
+func TestMutualTLSWithCertificateScopes(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	caPath, _ := ca.writePEM(t, dir, "ca")
+	certPath, keyPath := newTestCert(t, "server-1", ca).writePEM(t, dir, "server")
+
+	upstream := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, toolsListResponse)
+	})
+
+	authenticator, err := auth.New(auth.Config{Enabled: true, ClientCerts: []auth.ClientCertConfig{
+		{Subject: "reporting-agent", Databases: []string{"db1"}},
+	}})
+	require.NoError(t, err)
+
+	g, err := New(Config{
+		Upstream:      upstream,
+		TLS:           &TLSConfig{CertFile: certPath, KeyFile: keyPath, ClientCAFile: caPath},
+		Authenticator: authenticator,
+		Sessions:      auth.NewSessionStore(),
+		Tools:         prefixFilter{},
+	})
+	require.NoError(t, err)
+	assert.True(t, g.TLSEnabled())
+
+	server := httptest.NewUnstartedServer(g.Handler())
+	server.TLS = g.server.TLSConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+			ServerName:   "localhost",
+		}}}
+	}
+
+	// Clients without a certificate fail the handshake
+	_, err = client().Get(server.URL + "/sse")
+	assert.Error(t, err)
+
+	// A mapped certificate authenticates without a bearer token
+	agent := newTestCert(t, "reporting-agent", ca)
+	resp, err := client(agent.tlsKeyPair()).Get(server.URL + "/sse")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// A valid certificate without a mapping still needs a token
+	other := newTestCert(t, "someone-else", ca)
+	resp, err = client(other.tlsKeyPair()).Get(server.URL + "/sse")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+
+	// Reloading picks up a replaced server certificate
+	newTestCert(t, "server-2", ca).writePEM(t, dir, "server")
+	require.NoError(t, g.ReloadTLS())
+	conn, err := tls.Dial("tcp", strings.TrimPrefix(server.URL, "https://"), &tls.Config{
+		RootCAs:      roots,
+		Certificates: []tls.Certificate{agent.tlsKeyPair()},
+		ServerName:   "localhost",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "server-2", conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+	require.NoError(t, conn.Close())
+}
+
+func TestReloadKeepsCertificateOnError(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCert(t, "test-ca", nil)
+	certPath, keyPath := newTestCert(t, "server-1", ca).writePEM(t, dir, "server")
+
+	reloader, err := newCertReloader(TLSConfig{CertFile: certPath, KeyFile: keyPath})
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(certPath, []byte("garbage"), 0600))
+	assert.Error(t, reloader.Reload())
+	assert.NotNil(t, reloader.cert)
+
+	_, err = newCertReloader(TLSConfig{CertFile: certPath})
+	assert.Error(t, err)
+}
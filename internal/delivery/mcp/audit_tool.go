@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/audit"
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// AuditMiddleware records every tool invocation in the audit log
+func AuditMiddleware(auditLogger *audit.Logger) ToolMiddleware {
+	return func(call ToolCall, next server.ToolHandler) server.ToolHandler {
+		return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+			ctx, stats := domain.WithExecutionStats(ctx)
+
+			start := time.Now()
+			response, err := next(ctx, request)
+			duration := time.Since(start)
+
+			entry := audit.Entry{
+				Time:         start.UTC(),
+				SessionID:    sessionID(request),
+				Tool:         call.Name,
+				Database:     call.Database(request),
+				DurationMS:   float64(duration.Microseconds()) / 1000.0,
+				RowsReturned: stats.RowsReturned(),
+				RowsAffected: stats.RowsAffected(),
+			}
+			entry.SQL, entry.Params = auditedSQL(stats, request)
+			if err != nil {
+				entry.Error = err.Error()
+			}
+
+			auditLogger.Record(ctx, entry)
+			return response, err
+		}
+	}
+}
+
+// auditedSQL returns the statements executed for a call, falling back to the
+// SQL passed in the request when the call failed before reaching the database
+func auditedSQL(stats *domain.ExecutionStats, request server.ToolCallRequest) (string, []interface{}) {
+	statements := stats.Statements()
+	if len(statements) > 0 {
+		sqlTexts := make([]string, 0, len(statements))
+		var params []interface{}
+		for _, stmt := range statements {
+			sqlTexts = append(sqlTexts, stmt.SQL)
+			params = append(params, stmt.Params...)
+		}
+		return strings.Join(sqlTexts, ";\n"), params
+	}
+
+	params, _ := request.Parameters["params"].([]interface{})
+	for _, key := range []string{"query", "statement", "sql"} {
+		if sqlText, ok := request.Parameters[key].(string); ok && sqlText != "" {
+			return sqlText, params
+		}
+	}
+	return "", nil
+}
+
+//------------------------------------------------------------------------------
+// AuditSearchTool implementation
+//------------------------------------------------------------------------------
+
+// AuditSearchTool lets administrators query recent audited activity
+type AuditSearchTool struct {
+	BaseToolType
+	auditLogger *audit.Logger
+}
+
+// NewAuditSearchTool creates a new audit search tool type
+func NewAuditSearchTool(auditLogger *audit.Logger) *AuditSearchTool {
+	return &AuditSearchTool{
+		BaseToolType: BaseToolType{
+			name:        "audit_search",
+			description: "Search the audit log of recent tool invocations",
+		},
+		auditLogger: auditLogger,
+	}
+}
+
+// CreateTool creates an audit search tool
+func (t *AuditSearchTool) CreateTool(name string, _ string) interface{} {
+	return tools.NewTool(
+		name,
+		tools.WithDescription(t.description),
+		tools.WithString("since",
+			tools.Description("Only entries newer than this RFC3339 timestamp or duration (e.g. 1h, 30m)"),
+		),
+		tools.WithString("session_id",
+			tools.Description("Only entries from this MCP session"),
+		),
+		tools.WithString("tool",
+			tools.Description("Only entries for this tool name"),
+		),
+		tools.WithString("database_id",
+			tools.Description("Only entries for this database ID"),
+		),
+		tools.WithString("contains",
+			tools.Description("Only entries whose SQL contains this text (case-insensitive)"),
+		),
+		tools.WithBoolean("errors_only",
+			tools.Description("Only entries that ended with an error"),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Maximum number of entries to return (default 50)"),
+		),
+	)
+}
+
+// CreateUnifiedTool creates a unified audit search tool (no database parameter needed)
+func (t *AuditSearchTool) CreateUnifiedTool(name string, _ []string) interface{} {
+	return t.CreateTool(name, "")
+}
+
+// HandleRequest handles audit search tool requests
+func (t *AuditSearchTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, _ string, _ UseCaseProvider) (interface{}, error) {
+	filter, err := parseAuditFilter(request.Parameters, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := t.auditLogger.Search(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("audit search failed: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return createTextResponse("No matching audit entries."), nil
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format audit entries: %w", err)
+	}
+
+	resp := createTextResponse(fmt.Sprintf("Found %d audit entries (newest first):\n\n%s", len(entries), data))
+	return addMetadata(resp, "count", len(entries)), nil
+}
+
+// parseAuditFilter builds an audit filter from tool parameters
+func parseAuditFilter(params map[string]interface{}, now time.Time) (audit.Filter, error) {
+	var filter audit.Filter
+
+	if since, ok := params["since"].(string); ok && since != "" {
+		if d, err := time.ParseDuration(since); err == nil {
+			filter.Since = now.Add(-d)
+		} else if t, err := time.Parse(time.RFC3339, since); err == nil {
+			filter.Since = t
+		} else {
+			return filter, fmt.Errorf("since must be an RFC3339 timestamp or a duration such as 1h")
+		}
+	}
+
+	filter.SessionID, _ = params["session_id"].(string)
+	filter.Tool, _ = params["tool"].(string)
+	filter.Database, _ = params["database_id"].(string)
+	filter.Contains, _ = params["contains"].(string)
+	filter.ErrorsOnly, _ = params["errors_only"].(bool)
+
+	if limit, ok := params["limit"].(float64); ok && limit > 0 {
+		filter.Limit = int(limit)
+	}
+
+	return filter, nil
+}
+
+// EnableAudit records every tool registered afterwards in the audit log and
+// registers the audit_search tool
+func (tr *ToolRegistry) EnableAudit(auditLogger *audit.Logger) {
+	tr.factory.Register(NewAuditSearchTool(auditLogger))
+	tr.Use(AuditMiddleware(auditLogger))
+}
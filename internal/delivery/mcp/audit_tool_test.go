@@ -0,0 +1,122 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/audit"
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// memorySink keeps audit entries in memory for tests
+type memorySink struct {
+	entries []audit.Entry
+}
+
+func (s *memorySink) Write(_ context.Context, entry audit.Entry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func (s *memorySink) Search(_ context.Context, filter audit.Filter) ([]audit.Entry, error) {
+	var results []audit.Entry
+	for i := len(s.entries) - 1; i >= 0; i-- {
+		if filter.Matches(s.entries[i]) {
+			results = append(results, s.entries[i])
+		}
+	}
+	return results, nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func TestAuditMiddlewareRecordsExecutedStatements(t *testing.T) {
+	sink := &memorySink{}
+	auditLogger := audit.NewWithSinks(nil, sink)
+
+	handler := func(ctx context.Context, _ server.ToolCallRequest) (interface{}, error) {
+		stats := domain.ExecutionStatsFromContext(ctx)
+		stats.AddStatement("SELECT * FROM users WHERE id = ?", []interface{}{"7"})
+		stats.AddRowsReturned(1)
+		return createTextResponse("ok"), nil
+	}
+
+	call := ToolCall{Name: "query", Kind: "query"}
+	wrapped := AuditMiddleware(auditLogger)(call, handler)
+
+	_, err := wrapped(context.Background(), server.ToolCallRequest{
+		Name:       "query",
+		Parameters: map[string]interface{}{"database": "db1", "query": "SELECT * FROM users WHERE id = ?"},
+		Session:    &types.ClientSession{ID: "session-1"},
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.entries, 1)
+	entry := sink.entries[0]
+	assert.Equal(t, "session-1", entry.SessionID)
+	assert.Equal(t, "query", entry.Tool)
+	assert.Equal(t, "db1", entry.Database)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", entry.SQL)
+	assert.Equal(t, []interface{}{"7"}, entry.Params)
+	assert.Equal(t, int64(1), entry.RowsReturned)
+	assert.Empty(t, entry.Error)
+}
+
+func TestAuditMiddlewareRecordsErrors(t *testing.T) {
+	sink := &memorySink{}
+	auditLogger := audit.NewWithSinks(nil, sink)
+
+	handler := func(_ context.Context, _ server.ToolCallRequest) (interface{}, error) {
+		return nil, errors.New("database unavailable")
+	}
+
+	wrapped := AuditMiddleware(auditLogger)(ToolCall{Name: "execute_db1", Kind: "execute", DatabaseID: "db1"}, handler)
+	_, err := wrapped(context.Background(), server.ToolCallRequest{
+		Name:       "execute_db1",
+		Parameters: map[string]interface{}{"statement": "DELETE FROM logs"},
+	})
+	require.Error(t, err)
+
+	require.Len(t, sink.entries, 1)
+	assert.Equal(t, "DELETE FROM logs", sink.entries[0].SQL)
+	assert.Equal(t, "db1", sink.entries[0].Database)
+	assert.Equal(t, "database unavailable", sink.entries[0].Error)
+}
+
+func TestAuditSearchTool(t *testing.T) {
+	sink := &memorySink{}
+	auditLogger := audit.NewWithSinks(nil, sink)
+	auditLogger.Record(context.Background(), audit.Entry{Tool: "query_db1", Database: "db1", SQL: "SELECT 1"})
+	auditLogger.Record(context.Background(), audit.Entry{Tool: "query_db2", Database: "db2", SQL: "SELECT 2"})
+
+	tool := NewAuditSearchTool(auditLogger)
+	resp, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{
+		Name:       "audit_search",
+		Parameters: map[string]interface{}{"database_id": "db2", "since": "1h"},
+	}, "", nil)
+	require.NoError(t, err)
+
+	respMap, ok := resp.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, 1, respMap["metadata"].(map[string]interface{})["count"])
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	filter, err := parseAuditFilter(map[string]interface{}{"since": "30m", "limit": float64(5), "errors_only": true}, now)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(-30*time.Minute), filter.Since)
+	assert.Equal(t, 5, filter.Limit)
+	assert.True(t, filter.ErrorsOnly)
+
+	_, err = parseAuditFilter(map[string]interface{}{"since": "yesterday"}, now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FreePeak/cortex/pkg/server"
+
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+)
+
+// adminToolKinds are tool kinds only available to administrators when
+// authentication is enabled
+var adminToolKinds = map[string]bool{
+	"audit_search":      true,
+	"list":              true, // lists the server's filesystem
+	"add_connection":    true,
+	"update_connection": true,
+	"remove_connection": true,
+	"test_connection":   true,
+	"pool_stats":        true,
+	"tune_pool":         true,
+	"backup":            true, // writes files on the server and adds connections
+}
+
+// authorizeTool checks whether a principal may see a tool at all
+func authorizeTool(principal *auth.Principal, call ToolCall) error {
+	if adminToolKinds[call.Kind] && !principal.Admin {
+		return fmt.Errorf("tool %s requires administrator access", call.Name)
+	}
+	if !principal.AllowsTool(call.Kind) {
+		return fmt.Errorf("tool %s is not allowed for %s", call.Name, principal.Subject)
+	}
+	if call.DatabaseID != "" && !principal.AllowsDatabase(call.DatabaseID) {
+		return fmt.Errorf("database %s is not allowed for %s", call.DatabaseID, principal.Subject)
+	}
+	return nil
+}
+
+// AuthMiddleware rejects tool calls that fall outside the scope of the
+// principal bound to the caller's session
+func AuthMiddleware(sessions *auth.SessionStore) ToolMiddleware {
+	return func(call ToolCall, next server.ToolHandler) server.ToolHandler {
+		return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+			principal := sessions.Principal(sessionID(request))
+			if principal == nil {
+				return nil, fmt.Errorf("%w: no authenticated session for tool %s", auth.ErrUnauthenticated, call.Name)
+			}
+			if err := authorizeTool(principal, call); err != nil {
+				return nil, err
+			}
+			// Unified tools receive the target database as a parameter
+			if database := call.Database(request); database != "" && !principal.AllowsDatabase(database) {
+				return nil, fmt.Errorf("database %s is not allowed for %s", database, principal.Subject)
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// EnableAuth rejects calls outside the caller's scope for every tool
+// registered afterwards
+func (tr *ToolRegistry) EnableAuth(sessions *auth.SessionStore) {
+	tr.Use(AuthMiddleware(sessions))
+}
+
+// ToolVisible reports whether a tool is registered and should be listed for
+// a principal, which is nil when authentication is disabled
+func (tr *ToolRegistry) ToolVisible(principal *auth.Principal, name string) bool {
+	tr.mu.RLock()
+	call, ok := tr.calls[name]
+	tr.mu.RUnlock()
+	if !ok {
+		return false
+	}
+	return principal == nil || authorizeTool(principal, call) == nil
+}
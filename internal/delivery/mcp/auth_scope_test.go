@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	sessions := auth.NewSessionStore()
+	sessions.Bind("reader", &auth.Principal{Subject: "reader", Databases: []string{"db1"}, Tools: []string{"query"}})
+
+	handler := func(context.Context, server.ToolCallRequest) (interface{}, error) {
+		return createTextResponse("ok"), nil
+	}
+	call := func(session string, toolCall ToolCall, params map[string]interface{}) error {
+		wrapped := AuthMiddleware(sessions)(toolCall, handler)
+		_, err := wrapped(context.Background(), server.ToolCallRequest{
+			Name:       toolCall.Name,
+			Parameters: params,
+			Session:    &types.ClientSession{ID: session},
+		})
+		return err
+	}
+
+	assert.NoError(t, call("reader", ToolCall{Name: "query_db1", Kind: "query", DatabaseID: "db1"}, nil))
+	assert.Error(t, call("reader", ToolCall{Name: "query_db2", Kind: "query", DatabaseID: "db2"}, nil))
+	assert.Error(t, call("reader", ToolCall{Name: "execute_db1", Kind: "execute", DatabaseID: "db1"}, nil))
+	assert.Error(t, call("reader", ToolCall{Name: "audit_search", Kind: "audit_search"}, nil))
+
+	// Unified tools are checked against the database parameter
+	unified := ToolCall{Name: "query", Kind: "query"}
+	assert.NoError(t, call("reader", unified, map[string]interface{}{"database": "db1"}))
+	assert.Error(t, call("reader", unified, map[string]interface{}{"database": "db2"}))
+
+	err := call("unknown", ToolCall{Name: "query_db1", Kind: "query", DatabaseID: "db1"}, nil)
+	assert.True(t, errors.Is(err, auth.ErrUnauthenticated))
+}
+
+func TestToolVisible(t *testing.T) {
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	ctx := context.Background()
+	handler := func(context.Context, server.ToolCallRequest) (interface{}, error) {
+		return createTextResponse("ok"), nil
+	}
+	for _, call := range []ToolCall{
+		{Name: "query_db1", Kind: "query", DatabaseID: "db1"},
+		{Name: "query_db2", Kind: "query", DatabaseID: "db2"},
+		{Name: "list_databases", Kind: "list_databases"},
+		{Name: "audit_search", Kind: "audit_search"},
+	} {
+		tool := NewQueryTool().CreateTool(call.Name, call.DatabaseID)
+		assert.NoError(t, tr.addTool(ctx, call, tool, handler))
+	}
+
+	reader := &auth.Principal{Subject: "reader", Databases: []string{"db1"}}
+	assert.True(t, tr.ToolVisible(reader, "query_db1"))
+	assert.False(t, tr.ToolVisible(reader, "query_db2"))
+	assert.True(t, tr.ToolVisible(reader, "list_databases"))
+	assert.False(t, tr.ToolVisible(reader, "audit_search"))
+	assert.False(t, tr.ToolVisible(reader, "unregistered"))
+
+	admin := &auth.Principal{Subject: "admin", Admin: true}
+	assert.True(t, tr.ToolVisible(admin, "audit_search"))
+}
+
+func TestGetToolTypeExactNameFirst(t *testing.T) {
+	factory := NewToolTypeFactory()
+
+	toolType, ok := factory.GetToolType("list_databases")
+	assert.True(t, ok)
+	assert.Equal(t, "list_databases", toolType.GetName())
+
+	toolType, ok = factory.GetToolType("query_mydb")
+	assert.True(t, ok)
+	assert.Equal(t, "query", toolType.GetName())
+}
@@ -0,0 +1,177 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// BackupInfo describes a backup file of a database
+type BackupInfo struct {
+	Database  string    `json:"database"`
+	File      string    `json:"file"`
+	SizeBytes int64     `json:"size_bytes"`
+	Integrity string    `json:"integrity,omitempty"` // result of PRAGMA integrity_check, "ok" when intact
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BackupManager backs up SQLite databases into the backup directory and
+// restores backups as new connections
+type BackupManager interface {
+	Backup(ctx context.Context, dbID string) (BackupInfo, error)
+	ListBackups(dbID string) ([]BackupInfo, error)
+	// Restore copies a backup, the newest when file is empty, and adds a
+	// connection with newID opening the copy
+	Restore(ctx context.Context, dbID, file, newID string) error
+}
+
+// backupEngines are the connection types the backup tool supports
+var backupEngines = map[string]bool{"sqlite": true}
+
+//------------------------------------------------------------------------------
+// BackupTool implementation
+//------------------------------------------------------------------------------
+
+// BackupTool takes consistent copies of SQLite databases, lists them and
+// restores them as new connections
+type BackupTool struct {
+	BaseToolType
+	backups BackupManager
+}
+
+// NewBackupTool creates a backup tool type
+func NewBackupTool(backups BackupManager) *BackupTool {
+	return &BackupTool{
+		BaseToolType: BaseToolType{
+			name:        "backup",
+			description: "Back up, list backups of, or restore a backup of a SQLite database",
+		},
+		backups: backups,
+	}
+}
+
+// backupOptions are the parameters shared by the per-database and unified tools
+func backupOptions() []tools.ToolOption {
+	return []tools.ToolOption{
+		tools.WithString("action",
+			tools.Description("backup (default) writes a copy with VACUUM INTO and checks its integrity, list shows the backups, restore adds a connection opening a copy of a backup"),
+		),
+		tools.WithString("file",
+			tools.Description("Backup file name to restore, as shown by list; the newest backup when omitted"),
+		),
+		tools.WithString("connection_id",
+			tools.Description("ID of the connection to restore into, required for restore"),
+		),
+	}
+}
+
+// CreateTool creates a backup tool
+func (t *BackupTool) CreateTool(name string, dbID string) interface{} {
+	options := append([]tools.ToolOption{tools.WithDescription(t.GetDescription(dbID))}, backupOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// CreateUnifiedTool creates a unified backup tool with database parameter
+func (t *BackupTool) CreateUnifiedTool(name string, dbList []string) interface{} {
+	options := append([]tools.ToolOption{
+		tools.WithDescription(t.GetUnifiedDescription(dbList)),
+		tools.WithString("database",
+			tools.Description("Database ID to use"),
+			tools.Required(),
+		),
+	}, backupOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// HandleRequest handles backup tool requests
+func (t *BackupTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	if dbType, err := useCase.GetDatabaseType(dbID); err != nil {
+		return nil, err
+	} else if !backupEngines[dbType] {
+		return nil, fmt.Errorf("backups are only supported for SQLite databases, %s is %s", dbID, dbType)
+	}
+
+	action := getStringParam(request.Parameters, "action")
+	switch action {
+	case "", "backup":
+		backup, err := t.backups.Backup(ctx, dbID)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Database %s backed up to %s (integrity: %s)", dbID, backup.File, backup.Integrity)
+		heading := fmt.Sprintf("Backed up %s to %s (%d bytes), integrity check: %s", dbID, backup.File, backup.SizeBytes, backup.Integrity)
+		return backupResponse(heading, []BackupInfo{backup})
+
+	case "list":
+		backups, err := t.backups.ListBackups(dbID)
+		if err != nil {
+			return nil, err
+		}
+		return backupResponse(fmt.Sprintf("%d backup(s) of %s, newest first:", len(backups), dbID), backups)
+
+	case "restore":
+		newID := getStringParam(request.Parameters, "connection_id")
+		if newID == "" {
+			return nil, fmt.Errorf("connection_id parameter is required for restore")
+		}
+		file := getStringParam(request.Parameters, "file")
+		if err := t.backups.Restore(ctx, dbID, file, newID); err != nil {
+			return nil, err
+		}
+		logger.Info("Backup %q of %s restored as connection %s", file, dbID, newID)
+		return createTextResponse(fmt.Sprintf("Restored a backup of %s as connection %s (not saved to the config file).", dbID, newID)), nil
+	}
+
+	return nil, fmt.Errorf("unknown backup action %s (use backup, list or restore)", action)
+}
+
+// backupResponse formats backups as JSON below a heading
+func backupResponse(heading string, backups []BackupInfo) (interface{}, error) {
+	if backups == nil {
+		backups = []BackupInfo{}
+	}
+	data, err := json.MarshalIndent(backups, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format backups: %w", err)
+	}
+	resp := createTextResponse(fmt.Sprintf("%s\n\n%s", heading, data))
+	return addMetadata(resp, "count", len(backups)), nil
+}
+
+// EnableBackups registers the backup tool of SQLite databases
+func (tr *ToolRegistry) EnableBackups(backups BackupManager) {
+	tr.factory.Register(NewBackupTool(backups))
+}
+
+// registerBackupTool registers the backup_<dbID> tool when backups are
+// enabled and the engine of the database supports them
+func (tr *ToolRegistry) registerBackupTool(ctx context.Context, dbID, dbType string) error {
+	if _, ok := tr.factory.toolTypes["backup"]; !ok || !backupEngines[dbType] {
+		return nil
+	}
+	return tr.registerTool(ctx, "backup", fmt.Sprintf("backup_%s", dbID), dbID)
+}
+
+// registerUnifiedBackupTool registers the unified backup tool when backups
+// are enabled and one of the databases supports them
+func (tr *ToolRegistry) registerUnifiedBackupTool(ctx context.Context, dbList []string) error {
+	if _, ok := tr.factory.toolTypes["backup"]; !ok {
+		return nil
+	}
+	var supported []string
+	for _, dbID := range dbList {
+		if dbType, err := tr.databaseUseCase.GetDatabaseType(dbID); err == nil && backupEngines[dbType] {
+			supported = append(supported, dbID)
+		}
+	}
+	if len(supported) == 0 {
+		return nil
+	}
+	return tr.registerUnifiedTool(ctx, "backup", "backup", supported)
+}
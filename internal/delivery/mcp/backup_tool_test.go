@@ -0,0 +1,96 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackups keeps backups in memory for tests
+type fakeBackups struct {
+	backups  []BackupInfo
+	restored map[string]string
+}
+
+func (b *fakeBackups) Backup(_ context.Context, dbID string) (BackupInfo, error) {
+	backup := BackupInfo{Database: dbID, File: "/backups/" + dbID + "-20260101T000000.000Z.db", SizeBytes: 8192, Integrity: "ok",
+		CreatedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+	b.backups = append(b.backups, backup)
+	return backup, nil
+}
+
+func (b *fakeBackups) ListBackups(string) ([]BackupInfo, error) {
+	return b.backups, nil
+}
+
+func (b *fakeBackups) Restore(_ context.Context, dbID, file, newID string) error {
+	if len(b.backups) == 0 {
+		return errors.New("no backups of " + dbID)
+	}
+	b.restored[newID] = file
+	return nil
+}
+
+func TestBackupTool(t *testing.T) {
+	useCase := new(MockDatabaseUseCase)
+	useCase.On("GetDatabaseType", "lite").Return("sqlite", nil)
+	useCase.On("GetDatabaseType", "pg").Return("postgres", nil)
+
+	backups := &fakeBackups{restored: map[string]string{}}
+	tool := NewBackupTool(backups)
+	ctx := context.Background()
+	handle := func(dbID string, params map[string]interface{}) (string, error) {
+		response, err := tool.HandleRequest(ctx, server.ToolCallRequest{Name: "backup_" + dbID, Parameters: params}, dbID, useCase)
+		if err != nil {
+			return "", err
+		}
+		return response.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string), nil
+	}
+
+	// Restoring needs a backup and a connection ID
+	_, err := handle("lite", map[string]interface{}{"action": "restore", "connection_id": "lite_copy"})
+	assert.Error(t, err)
+	_, err = handle("lite", map[string]interface{}{"action": "restore"})
+	assert.Error(t, err)
+
+	text, err := handle("lite", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Contains(t, text, "integrity check: ok")
+	assert.Contains(t, text, `"size_bytes": 8192`)
+
+	text, err = handle("lite", map[string]interface{}{"action": "list"})
+	require.NoError(t, err)
+	assert.Contains(t, text, "1 backup(s) of lite")
+
+	_, err = handle("lite", map[string]interface{}{"action": "restore", "connection_id": "lite_copy", "file": "lite-20260101T000000.000Z.db"})
+	require.NoError(t, err)
+	assert.Equal(t, "lite-20260101T000000.000Z.db", backups.restored["lite_copy"])
+
+	// Other engines and actions are rejected
+	_, err = handle("pg", map[string]interface{}{})
+	assert.Error(t, err)
+	_, err = handle("lite", map[string]interface{}{"action": "drop"})
+	assert.Error(t, err)
+}
+
+func TestBackupToolRegistration(t *testing.T) {
+	useCase := new(MockDatabaseUseCase)
+	useCase.On("ListDatabases").Return([]string{"lite", "app"})
+	useCase.On("GetDatabaseType", "lite").Return("sqlite", nil)
+	useCase.On("GetDatabaseType", "app").Return("postgres", nil)
+	useCase.On("IsLazyLoading").Return(true)
+	useCase.On("ListExtensions", mock.Anything, mock.Anything).Return([]string{}, nil)
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	tr.EnableBackups(&fakeBackups{restored: map[string]string{}})
+	require.NoError(t, tr.RegisterAllTools(context.Background(), useCase))
+
+	assert.True(t, tr.ToolVisible(nil, "backup_lite"))
+	assert.False(t, tr.ToolVisible(nil, "backup_app"))
+}
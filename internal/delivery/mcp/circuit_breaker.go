@@ -0,0 +1,31 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/FreePeak/cortex/pkg/server"
+
+	"github.com/FreePeak/db-mcp-server/internal/breaker"
+)
+
+// CircuitBreakerMiddleware reports the circuit breaker state of the targeted
+// database in the metadata of tool responses. Calls are rejected by the
+// breaker itself, which guards the database repository.
+func CircuitBreakerMiddleware(b *breaker.Breaker) ToolMiddleware {
+	return func(call ToolCall, next server.ToolHandler) server.ToolHandler {
+		return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+			resp, err := next(ctx, request)
+			dbID := call.Database(request)
+			if response, ok := resp.(map[string]interface{}); ok && dbID != "" {
+				addMetadata(response, "circuit_breaker", b.State(dbID))
+			}
+			return resp, err
+		}
+	}
+}
+
+// EnableCircuitBreaker reports circuit breaker states in the responses of
+// tools registered afterwards
+func (tr *ToolRegistry) EnableCircuitBreaker(b *breaker.Breaker) {
+	tr.Use(CircuitBreakerMiddleware(b))
+}
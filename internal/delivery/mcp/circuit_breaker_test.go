@@ -0,0 +1,38 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/breaker"
+)
+
+func TestCircuitBreakerMiddleware(t *testing.T) {
+	b := breaker.New(breaker.Config{Enabled: true, FailureThreshold: 1})
+	done, err := b.Acquire("db1")
+	require.NoError(t, err)
+	done(errors.New("dial tcp 10.0.0.1:5432: connect: connection refused"))
+
+	handler := CircuitBreakerMiddleware(b)(ToolCall{Name: "query", Kind: "query"},
+		func(context.Context, server.ToolCallRequest) (interface{}, error) {
+			return createTextResponse("ok"), nil
+		})
+
+	resp, err := handler(context.Background(), server.ToolCallRequest{Parameters: map[string]interface{}{"database": "db1"}})
+	require.NoError(t, err)
+	assert.Equal(t, breaker.StateOpen, resp.(map[string]interface{})["metadata"].(map[string]interface{})["circuit_breaker"])
+
+	resp, err = handler(context.Background(), server.ToolCallRequest{Parameters: map[string]interface{}{"database": "db2"}})
+	require.NoError(t, err)
+	assert.Equal(t, breaker.StateClosed, resp.(map[string]interface{})["metadata"].(map[string]interface{})["circuit_breaker"])
+
+	// Tools without a database get no breaker state
+	resp, err = handler(context.Background(), server.ToolCallRequest{})
+	require.NoError(t, err)
+	assert.Nil(t, resp.(map[string]interface{})["metadata"])
+}
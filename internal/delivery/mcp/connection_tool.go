@@ -0,0 +1,205 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// ConnectionAdmin changes the server's database connections at runtime.
+// Connections are passed as JSON objects in the format of the "connections"
+// list of the config file. When persist is set the config file is updated too.
+type ConnectionAdmin interface {
+	AddConnection(ctx context.Context, connection map[string]interface{}, persist bool) error
+	UpdateConnection(ctx context.Context, id string, changes map[string]interface{}, persist bool) error
+	RemoveConnection(ctx context.Context, id string, persist bool) error
+	// TestConnection opens and pings a connection without adding it. An id
+	// tests an existing connection, with connection overriding its settings.
+	TestConnection(ctx context.Context, id string, connection map[string]interface{}) (time.Duration, error)
+}
+
+// connectionToolKinds are the tool types that manage connections
+var connectionToolKinds = []string{"add_connection", "update_connection", "remove_connection", "test_connection"}
+
+//------------------------------------------------------------------------------
+// ConnectionTool implementation
+//------------------------------------------------------------------------------
+
+// ConnectionTool lets administrators add, update, remove and test database
+// connections without restarting the server
+type ConnectionTool struct {
+	BaseToolType
+	admin ConnectionAdmin
+}
+
+// NewConnectionTool creates a connection management tool type for one of
+// connectionToolKinds
+func NewConnectionTool(name string, admin ConnectionAdmin) *ConnectionTool {
+	descriptions := map[string]string{
+		"add_connection":    "Add a database connection and register its tools",
+		"update_connection": "Change the settings of a database connection and reconnect it",
+		"remove_connection": "Close a database connection and remove its tools",
+		"test_connection":   "Check that a database connection can be opened, without adding it",
+	}
+	return &ConnectionTool{
+		BaseToolType: BaseToolType{
+			name:        name,
+			description: descriptions[name],
+		},
+		admin: admin,
+	}
+}
+
+// CreateTool creates a connection management tool
+func (t *ConnectionTool) CreateTool(name string, _ string) interface{} {
+	options := []tools.ToolOption{tools.WithDescription(t.description)}
+
+	switch t.name {
+	case "add_connection":
+		options = append(options,
+			tools.WithObject("connection",
+				tools.Description("Connection settings as in the connections list of the config file, including id and type"),
+				tools.Required(),
+			),
+		)
+	case "update_connection":
+		options = append(options,
+			tools.WithString("id",
+				tools.Description("ID of the connection to change"),
+				tools.Required(),
+			),
+			tools.WithObject("connection",
+				tools.Description("Settings to change, as in the connections list of the config file; other settings are kept"),
+				tools.Required(),
+			),
+		)
+	case "remove_connection":
+		options = append(options,
+			tools.WithString("id",
+				tools.Description("ID of the connection to remove"),
+				tools.Required(),
+			),
+		)
+	case "test_connection":
+		options = append(options,
+			tools.WithString("id",
+				tools.Description("ID of an existing connection to test"),
+			),
+			tools.WithObject("connection",
+				tools.Description("Connection settings to test, or settings overriding those of id"),
+			),
+		)
+	}
+
+	if t.name != "test_connection" {
+		options = append(options,
+			tools.WithBoolean("persist",
+				tools.Description("Also write the change to the config file (default false)"),
+			),
+		)
+	}
+
+	return tools.NewTool(name, options...)
+}
+
+// CreateUnifiedTool creates a unified connection management tool (no database parameter needed)
+func (t *ConnectionTool) CreateUnifiedTool(name string, _ []string) interface{} {
+	return t.CreateTool(name, "")
+}
+
+// HandleRequest handles connection management tool requests
+func (t *ConnectionTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, _ string, _ UseCaseProvider) (interface{}, error) {
+	id, _ := request.Parameters["id"].(string)
+	connection, _ := request.Parameters["connection"].(map[string]interface{})
+	persist, _ := request.Parameters["persist"].(bool)
+
+	switch t.name {
+	case "add_connection":
+		if connection == nil {
+			return nil, fmt.Errorf("connection parameter is required")
+		}
+		id, _ = connection["id"].(string)
+		if err := t.admin.AddConnection(ctx, connection, persist); err != nil {
+			return nil, err
+		}
+		logger.Info("Connection %s added at runtime (persisted: %t)", id, persist)
+		return createTextResponse(fmt.Sprintf("Added connection %s%s.", id, persistedSuffix(persist))), nil
+
+	case "update_connection":
+		if id == "" || connection == nil {
+			return nil, fmt.Errorf("id and connection parameters are required")
+		}
+		if err := t.admin.UpdateConnection(ctx, id, connection, persist); err != nil {
+			return nil, err
+		}
+		logger.Info("Connection %s updated at runtime (persisted: %t)", id, persist)
+		return createTextResponse(fmt.Sprintf("Updated connection %s%s.", id, persistedSuffix(persist))), nil
+
+	case "remove_connection":
+		if id == "" {
+			return nil, fmt.Errorf("id parameter is required")
+		}
+		if err := t.admin.RemoveConnection(ctx, id, persist); err != nil {
+			return nil, err
+		}
+		logger.Info("Connection %s removed at runtime (persisted: %t)", id, persist)
+		return createTextResponse(fmt.Sprintf("Removed connection %s%s.", id, persistedSuffix(persist))), nil
+
+	case "test_connection":
+		if id == "" && connection == nil {
+			return nil, fmt.Errorf("id or connection parameter is required")
+		}
+		elapsed, err := t.admin.TestConnection(ctx, id, connection)
+		if err != nil {
+			return nil, fmt.Errorf("connection test failed: %w", err)
+		}
+		resp := createTextResponse(fmt.Sprintf("Connection succeeded in %s.", elapsed.Round(time.Millisecond)))
+		return addMetadata(resp, "elapsed_ms", elapsed.Milliseconds()), nil
+	}
+
+	return nil, fmt.Errorf("unknown connection tool %s", t.name)
+}
+
+// persistedSuffix describes whether a change was written to the config file
+func persistedSuffix(persist bool) string {
+	if persist {
+		return " and saved it to the config file"
+	}
+	return " (not saved to the config file)"
+}
+
+// EnableConnectionAdmin registers the connection management tools
+func (tr *ToolRegistry) EnableConnectionAdmin(admin ConnectionAdmin) {
+	for _, kind := range connectionToolKinds {
+		tr.factory.Register(NewConnectionTool(kind, admin))
+	}
+}
+
+// registerConnectionTools registers the connection management tools when enabled
+func (tr *ToolRegistry) registerConnectionTools(ctx context.Context) {
+	for _, kind := range connectionToolKinds {
+		if _, ok := tr.factory.toolTypes[kind]; !ok {
+			continue
+		}
+		if err := tr.registerTool(ctx, kind, kind, ""); err != nil {
+			logger.Error("Error registering %s tool: %v", kind, err)
+		} else {
+			logger.Info("Successfully registered tool %s", kind)
+		}
+	}
+}
+
+// isConnectionTool reports whether a tool type manages connections
+func isConnectionTool(kind string) bool {
+	for _, connectionKind := range connectionToolKinds {
+		if kind == connectionKind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/auth"
+)
+
+// recordingAdmin records connection changes for tests
+type recordingAdmin struct {
+	calls []string
+	err   error
+}
+
+func (a *recordingAdmin) AddConnection(_ context.Context, connection map[string]interface{}, persist bool) error {
+	a.calls = append(a.calls, "add "+connection["id"].(string)+persistFlag(persist))
+	return a.err
+}
+
+func (a *recordingAdmin) UpdateConnection(_ context.Context, id string, _ map[string]interface{}, persist bool) error {
+	a.calls = append(a.calls, "update "+id+persistFlag(persist))
+	return a.err
+}
+
+func (a *recordingAdmin) RemoveConnection(_ context.Context, id string, persist bool) error {
+	a.calls = append(a.calls, "remove "+id+persistFlag(persist))
+	return a.err
+}
+
+func (a *recordingAdmin) TestConnection(_ context.Context, id string, _ map[string]interface{}) (time.Duration, error) {
+	a.calls = append(a.calls, "test "+id)
+	return 12 * time.Millisecond, a.err
+}
+
+func persistFlag(persist bool) string {
+	if persist {
+		return " persist"
+	}
+	return ""
+}
+
+func TestConnectionTools(t *testing.T) {
+	admin := &recordingAdmin{}
+	ctx := context.Background()
+	handle := func(kind string, params map[string]interface{}) (interface{}, error) {
+		return NewConnectionTool(kind, admin).HandleRequest(ctx, server.ToolCallRequest{Name: kind, Parameters: params}, "", nil)
+	}
+
+	_, err := handle("add_connection", map[string]interface{}{
+		"connection": map[string]interface{}{"id": "restored", "type": "sqlite", "database_path": "/tmp/restored.db"},
+		"persist":    true,
+	})
+	require.NoError(t, err)
+
+	_, err = handle("update_connection", map[string]interface{}{
+		"id":         "restored",
+		"connection": map[string]interface{}{"read_only": true},
+	})
+	require.NoError(t, err)
+
+	response, err := handle("test_connection", map[string]interface{}{"id": "restored"})
+	require.NoError(t, err)
+	assert.Contains(t, response.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"], "12ms")
+
+	_, err = handle("remove_connection", map[string]interface{}{"id": "restored"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"add restored persist", "update restored", "test restored", "remove restored"}, admin.calls)
+
+	// Missing parameters and admin errors are reported
+	_, err = handle("remove_connection", map[string]interface{}{})
+	assert.Error(t, err)
+	admin.err = errors.New("connection refused")
+	_, err = handle("test_connection", map[string]interface{}{"id": "restored"})
+	assert.ErrorContains(t, err, "connection refused")
+}
+
+func TestConnectionToolsRegistration(t *testing.T) {
+	useCase := new(MockDatabaseUseCase)
+	useCase.On("ListDatabases").Return([]string{})
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	tr.EnableConnectionAdmin(&recordingAdmin{})
+	require.NoError(t, tr.RegisterAllTools(context.Background(), useCase))
+
+	// Without databases the connection tools are registered next to the mock tools
+	assert.True(t, tr.ToolVisible(nil, "add_connection"))
+	assert.True(t, tr.ToolVisible(nil, "mock_query"))
+	assert.False(t, tr.ToolVisible(nil, "mock_add_connection"))
+
+	// Only administrators may use them
+	assert.False(t, tr.ToolVisible(&auth.Principal{Subject: "analyst"}, "add_connection"))
+	assert.True(t, tr.ToolVisible(&auth.Principal{Subject: "dba", Admin: true}, "add_connection"))
+}
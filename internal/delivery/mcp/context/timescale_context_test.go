@@ -27,6 +27,11 @@ func (m *MockDatabaseUseCase) GetDatabaseType(dbID string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+// SupportsTransactions reports that the mock databases support transactions
+func (m *MockDatabaseUseCase) SupportsTransactions(dbID string) bool {
+	return true
+}
+
 // ExecuteQuery mocks the ExecuteQuery method
 func (m *MockDatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error) {
 	args := m.Called(ctx, dbID, query, params)
@@ -57,6 +62,13 @@ func (m *MockDatabaseUseCase) IsLazyLoading() bool {
 	return args.Bool(0)
 }
 
+// ListExtensions mocks the ListExtensions method
+func (m *MockDatabaseUseCase) ListExtensions(ctx context.Context, dbID string) ([]string, error) {
+	args := m.Called(ctx, dbID)
+	extensions, _ := args.Get(0).([]string)
+	return extensions, args.Error(1)
+}
+
 func TestTimescaleDBContextProvider(t *testing.T) {
 	// Create a mock use case provider
 	mockUseCase := new(MockDatabaseUseCase)
@@ -0,0 +1,34 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/FreePeak/cortex/pkg/server"
+
+	"github.com/FreePeak/db-mcp-server/internal/costguard"
+)
+
+// CostGuardMiddleware explains queries before the query tool runs them and
+// rejects those whose estimate exceeds the connection's cost guard
+func CostGuardMiddleware(guard *costguard.Guard) ToolMiddleware {
+	return func(call ToolCall, next server.ToolHandler) server.ToolHandler {
+		if call.Kind != "query" {
+			return next
+		}
+		return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+			query, _ := request.Parameters["query"].(string)
+			params, _ := request.Parameters["params"].([]interface{})
+			confirmed, _ := request.Parameters["confirm"].(bool)
+			if err := guard.Check(ctx, call.Database(request), query, params, confirmed); err != nil {
+				return nil, err
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// EnableCostGuard checks the planner estimate of queries run through query
+// tools registered afterwards
+func (tr *ToolRegistry) EnableCostGuard(guard *costguard.Guard) {
+	tr.Use(CostGuardMiddleware(guard))
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/costguard"
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+const expensivePlan = `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "events", "Total Cost": 90000, "Plan Rows": 5000000}}]`
+
+// planRepo answers every query on db1 with a fixed EXPLAIN output
+type planRepo struct{ explains int }
+
+func (r *planRepo) GetDatabase(string) (domain.Database, error)     { return planDB{r}, nil }
+func (r *planRepo) GetReadDatabase(string) (domain.Database, error) { return planDB{r}, nil }
+func (r *planRepo) ListDatabases() []string                         { return []string{"db1"} }
+func (r *planRepo) GetDatabaseType(string) (string, error)          { return "postgres", nil }
+func (r *planRepo) IsLazyLoading() bool                             { return false }
+func (r *planRepo) SupportsTransactions(string) bool                { return true }
+func (r *planRepo) InspectDatabase(_ string, fn func(domain.Database) error) error {
+	return fn(planDB{r})
+}
+
+type planDB struct{ repo *planRepo }
+
+func (d planDB) Query(context.Context, string, ...interface{}) (domain.Rows, error) {
+	d.repo.explains++
+	return &planRows{}, nil
+}
+
+func (d planDB) QueryPrepared(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
+	return d.Query(ctx, query, args...)
+}
+
+func (d planDB) Exec(context.Context, string, ...interface{}) (domain.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (d planDB) Begin(context.Context, *domain.TxOptions) (domain.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type planRows struct{ read bool }
+
+func (r *planRows) Close() error               { return nil }
+func (r *planRows) Columns() ([]string, error) { return []string{"QUERY PLAN"}, nil }
+func (r *planRows) Err() error                 { return nil }
+
+func (r *planRows) Next() bool {
+	next := !r.read
+	r.read = true
+	return next
+}
+
+func (r *planRows) Scan(dest ...interface{}) error {
+	*dest[0].(*interface{}) = expensivePlan
+	return nil
+}
+
+func TestCostGuardMiddleware(t *testing.T) {
+	repo := &planRepo{}
+	guard := costguard.New(map[string]costguard.Limits{"db1": {MaxCost: 1000, Action: costguard.ActionConfirm}}, repo)
+	ok := func(context.Context, server.ToolCallRequest) (interface{}, error) {
+		return createTextResponse("ok"), nil
+	}
+
+	query := CostGuardMiddleware(guard)(ToolCall{Name: "query", Kind: "query"}, ok)
+	request := server.ToolCallRequest{Parameters: map[string]interface{}{
+		"database": "db1",
+		"query":    "SELECT * FROM events",
+	}}
+
+	_, err := query(context.Background(), request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Seq Scan on events")
+
+	request.Parameters["confirm"] = true
+	_, err = query(context.Background(), request)
+	assert.NoError(t, err)
+
+	// Only the query tool is guarded
+	execute := CostGuardMiddleware(guard)(ToolCall{Name: "execute_db1", Kind: "execute", DatabaseID: "db1"}, ok)
+	explains := repo.explains
+	_, err = execute(context.Background(), server.ToolCallRequest{Parameters: map[string]interface{}{"statement": "SELECT 1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, explains, repo.explains)
+}
@@ -0,0 +1,47 @@
+package mcp
+
+import (
+	"fmt"
+	"time"
+)
+
+// DatabaseHealth is the health of a database connection
+type DatabaseHealth struct {
+	State               string // healthy, degraded, down or not_connected
+	Latency             time.Duration
+	LastCheck           time.Time
+	LastError           string
+	ConsecutiveFailures int
+}
+
+// HealthReporter reports the health of database connections
+type HealthReporter interface {
+	DatabaseHealth(dbID string) (DatabaseHealth, bool)
+}
+
+// EnableHealth adds the health of each database to the list_databases tool
+func (tr *ToolRegistry) EnableHealth(reporter HealthReporter) {
+	tr.listDatabasesTool().health = reporter
+}
+
+// listDatabasesTool returns the registered list_databases tool type
+func (tr *ToolRegistry) listDatabasesTool() *ListDatabasesTool {
+	if tool, ok := tr.factory.toolTypes["list_databases"].(*ListDatabasesTool); ok {
+		return tool
+	}
+	tool := NewListDatabasesTool()
+	tr.factory.Register(tool)
+	return tool
+}
+
+// describeHealth formats the health of a database for list_databases
+func describeHealth(health DatabaseHealth) string {
+	switch {
+	case health.LastError != "":
+		return fmt.Sprintf("%s, %d failed checks, last error: %s", health.State, health.ConsecutiveFailures, health.LastError)
+	case !health.LastCheck.IsZero():
+		return fmt.Sprintf("%s, ping %s", health.State, health.Latency.Round(time.Millisecond))
+	default:
+		return health.State
+	}
+}
@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticHealth reports fixed health states
+type staticHealth map[string]DatabaseHealth
+
+func (h staticHealth) DatabaseHealth(dbID string) (DatabaseHealth, bool) {
+	health, ok := h[dbID]
+	return health, ok
+}
+
+func TestListDatabasesHealth(t *testing.T) {
+	useCase := new(MockUseCaseProvider)
+	useCase.On("ListDatabases").Return([]string{"pg", "mysql", "lazy"})
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	tr.EnableHealth(staticHealth{
+		"pg":    {State: "healthy", Latency: 3 * time.Millisecond, LastCheck: time.Now()},
+		"mysql": {State: "down", LastCheck: time.Now(), LastError: "connection refused", ConsecutiveFailures: 4},
+		"lazy":  {State: "not_connected"},
+	})
+
+	toolType, ok := tr.factory.GetToolType("list_databases")
+	require.True(t, ok)
+	resp, err := toolType.HandleRequest(context.Background(), server.ToolCallRequest{}, "", useCase)
+	require.NoError(t, err)
+
+	result := resp.(map[string]interface{})
+	text := result["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "1. pg (healthy, ping 3ms)")
+	assert.Contains(t, text, "2. mysql (down, 4 failed checks, last error: connection refused)")
+	assert.Contains(t, text, "3. lazy (not_connected)")
+
+	health := result["metadata"].(map[string]interface{})["health"].(map[string]interface{})
+	assert.Equal(t, "down", health["mysql"].(map[string]interface{})["state"])
+
+	// Without a reporter only the names are listed
+	resp, err = NewListDatabasesTool().HandleRequest(context.Background(), server.ToolCallRequest{}, "", useCase)
+	require.NoError(t, err)
+	text = resp.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "1. pg\n")
+}
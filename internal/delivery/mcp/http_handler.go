@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"unsafe"
+
+	"github.com/FreePeak/cortex/pkg/server"
+)
+
+// cortexModule is the module of the MCP server
+const cortexModule = "github.com/FreePeak/cortex"
+
+// cortexVersion is the cortex version whose internals HTTPHandler reads.
+// go.mod pins it; check the layout again before changing both.
+const cortexVersion = "v1.0.5"
+
+// HTTPHandler returns the HTTP handler of the MCP server's SSE transport, so
+// that it can be served in-process instead of on a port of its own. The
+// server only offers ServeHTTP, which listens on a TCP address reachable by
+// any local user, so the handler is taken from the HTTP server it builds.
+// Tools added to the server later are served by the handler as well.
+//
+// Cortex has no public accessor for the handler, so it is read from
+// unexported fields of the pinned version. Other versions are refused.
+func HTTPHandler(mcpServer *server.MCPServer) (http.Handler, error) {
+	if version := cortexBuildVersion(); version != "" && version != cortexVersion {
+		return nil, fmt.Errorf("unsupported MCP server version: built with %s %s, the HTTP transport supports %s",
+			cortexModule, version, cortexVersion)
+	}
+	builder, err := unexportedField(reflect.ValueOf(mcpServer).Elem(), "builder")
+	if err != nil {
+		return nil, err
+	}
+	build := builder.MethodByName("BuildMCPServer")
+	if !build.IsValid() || build.Type().NumIn() != 0 || build.Type().NumOut() != 1 {
+		return nil, errors.New("unsupported MCP server version: cannot build its HTTP server")
+	}
+	restServer := build.Call(nil)[0]
+	if restServer.Kind() != reflect.Pointer || restServer.IsNil() {
+		return nil, errors.New("unsupported MCP server version: cannot build its HTTP server")
+	}
+	field, err := unexportedField(restServer.Elem(), "httpServer")
+	if err != nil {
+		return nil, err
+	}
+	httpServer, ok := field.Interface().(*http.Server)
+	if !ok || httpServer == nil || httpServer.Handler == nil {
+		return nil, errors.New("unsupported MCP server version: no HTTP handler")
+	}
+	return httpServer.Handler, nil
+}
+
+// unexportedField returns a usable copy of an unexported struct field
+func unexportedField(v reflect.Value, name string) (reflect.Value, error) {
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, errors.New("unsupported MCP server version: unexpected type " + v.Type().String())
+	}
+	field := v.FieldByName(name)
+	if !field.IsValid() || !field.CanAddr() {
+		return reflect.Value{}, errors.New("unsupported MCP server version: no field " + name)
+	}
+	return reflect.NewAt(field.Type(), unsafe.Pointer(field.UnsafeAddr())).Elem(), nil
+}
+
+// cortexBuildVersion returns the cortex version the binary was built with,
+// or "" if the build information is not available
+func cortexBuildVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, dep := range info.Deps {
+		if dep.Path == cortexModule {
+			if dep.Replace != nil {
+				return dep.Replace.Version
+			}
+			return dep.Version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	mcpServer := server.NewMCPServer("test", "1.0.0", nil)
+	handler, err := HTTPHandler(mcpServer)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	var status map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+	assert.Equal(t, "test", status["name"])
+
+	// Tools added after the handler was taken are listed too
+	tool := &types.Tool{Name: "late_tool", Description: "Added later"}
+	require.NoError(t, mcpServer.AddTool(context.Background(), tool,
+		func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+			return nil, nil
+		}))
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "late_tool")
+}
+
+// TestHTTPHandlerCortexLayout fails when cortex no longer has the unexported
+// fields and methods HTTPHandler reads, such as after a version bump
+func TestHTTPHandlerCortexLayout(t *testing.T) {
+	assert.Equal(t, cortexVersion, cortexBuildVersion(), "go.mod and cortexVersion disagree")
+
+	builder, ok := reflect.TypeOf(server.MCPServer{}).FieldByName("builder")
+	require.True(t, ok, "server.MCPServer has no builder field")
+	build, ok := builder.Type.MethodByName("BuildMCPServer")
+	require.True(t, ok, "the builder has no BuildMCPServer method")
+	require.Equal(t, 1, build.Type.NumIn(), "BuildMCPServer takes arguments")
+	require.Equal(t, 1, build.Type.NumOut(), "BuildMCPServer returns more than the server")
+
+	restServer := build.Type.Out(0)
+	require.Equal(t, reflect.Pointer, restServer.Kind())
+	httpServer, ok := restServer.Elem().FieldByName("httpServer")
+	require.True(t, ok, "the HTTP server of cortex has no httpServer field")
+	assert.Equal(t, reflect.TypeOf(&http.Server{}), httpServer.Type)
+}
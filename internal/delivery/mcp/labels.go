@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+)
+
+// DatabaseLabels describes a database connection for listings and tool
+// descriptions
+type DatabaseLabels struct {
+	Description string
+	Environment string
+	Tags        []string
+	ReadOnly    bool
+}
+
+// LabelProvider returns the labels of database connections
+type LabelProvider interface {
+	DatabaseLabels(dbID string) (DatabaseLabels, bool)
+}
+
+// ReadOnlyMiddleware keeps tools from writing to read-only databases: the
+// execute tool and search index creation are rejected and transactions are
+// begun read-only. Statements of other tools, such as query, are left to the
+// read-only sessions of the connection, see dialect.Dialect.DSN.
+func ReadOnlyMiddleware(labels LabelProvider) ToolMiddleware {
+	return func(call ToolCall, next server.ToolHandler) server.ToolHandler {
+		if call.Kind != "execute" && call.Kind != "transaction" && call.Kind != "search" {
+			return next
+		}
+		return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+			database := call.Database(request)
+			l, ok := labels.DatabaseLabels(database)
+			if !ok || !l.ReadOnly {
+				return next(ctx, request)
+			}
+			switch call.Kind {
+			case "execute":
+				return nil, fmt.Errorf("database %s is read-only", database)
+			case "search":
+				if getStringParam(request.Parameters, "action") == "create_index" {
+					return nil, fmt.Errorf("database %s is read-only", database)
+				}
+			case "transaction":
+				params := make(map[string]interface{}, len(request.Parameters)+1)
+				for key, value := range request.Parameters {
+					params[key] = value
+				}
+				params["readOnly"] = true
+				request.Parameters = params
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// EnableLabels shows the description, environment and tags of each database
+// in list_databases and the unified tool descriptions, and enforces read-only
+// connections for every tool registered afterwards
+func (tr *ToolRegistry) EnableLabels(labels LabelProvider) {
+	tr.labels = labels
+	tr.listDatabasesTool().labels = labels
+	tr.Use(ReadOnlyMiddleware(labels))
+}
+
+// describeLabels formats the labels of a database, such as
+// "Billing primary; prod, read-only, #billing #core"
+func describeLabels(l DatabaseLabels) string {
+	var parts, attributes []string
+	if l.Description != "" {
+		parts = append(parts, l.Description)
+	}
+	if l.Environment != "" {
+		attributes = append(attributes, l.Environment)
+	}
+	if l.ReadOnly {
+		attributes = append(attributes, "read-only")
+	}
+	if len(l.Tags) > 0 {
+		attributes = append(attributes, "#"+strings.Join(l.Tags, " #"))
+	}
+	if len(attributes) > 0 {
+		parts = append(parts, strings.Join(attributes, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// describeDatabases adds the labels of each database to a list of database
+// IDs for unified tool descriptions
+func (tr *ToolRegistry) describeDatabases(dbList []string) []string {
+	if tr.labels == nil {
+		return dbList
+	}
+	described := make([]string, 0, len(dbList))
+	for _, id := range dbList {
+		if l, ok := tr.labels.DatabaseLabels(id); ok {
+			if text := describeLabels(l); text != "" {
+				id = fmt.Sprintf("%s (%s)", id, text)
+			}
+		}
+		described = append(described, id)
+	}
+	return described
+}
@@ -0,0 +1,86 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticLabels reports fixed database labels
+type staticLabels map[string]DatabaseLabels
+
+func (l staticLabels) DatabaseLabels(dbID string) (DatabaseLabels, bool) {
+	labels, ok := l[dbID]
+	return labels, ok
+}
+
+func TestReadOnlyMiddleware(t *testing.T) {
+	labels := staticLabels{"prod": {ReadOnly: true}, "dev": {}}
+	var received map[string]interface{}
+	next := func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+		received = request.Parameters
+		return "ok", nil
+	}
+	middleware := ReadOnlyMiddleware(labels)
+	ctx := context.Background()
+
+	_, err := middleware(ToolCall{Kind: "execute", DatabaseID: "prod"}, next)(ctx, server.ToolCallRequest{})
+	assert.EqualError(t, err, "database prod is read-only")
+
+	_, err = middleware(ToolCall{Kind: "execute"}, next)(ctx, server.ToolCallRequest{Parameters: map[string]interface{}{"database": "dev"}})
+	assert.NoError(t, err)
+
+	// Transactions on read-only databases are begun read-only without
+	// changing the caller's parameters
+	params := map[string]interface{}{"action": "begin"}
+	_, err = middleware(ToolCall{Kind: "transaction", DatabaseID: "prod"}, next)(ctx, server.ToolCallRequest{Parameters: params})
+	require.NoError(t, err)
+	assert.Equal(t, true, received["readOnly"])
+	assert.NotContains(t, params, "readOnly")
+
+	_, err = middleware(ToolCall{Kind: "query", DatabaseID: "prod"}, next)(ctx, server.ToolCallRequest{})
+	assert.NoError(t, err)
+
+	// Read-only databases can be searched but not indexed
+	_, err = middleware(ToolCall{Kind: "search", DatabaseID: "prod"}, next)(ctx, server.ToolCallRequest{Parameters: map[string]interface{}{"query": "disk"}})
+	assert.NoError(t, err)
+	_, err = middleware(ToolCall{Kind: "search", DatabaseID: "prod"}, next)(ctx, server.ToolCallRequest{Parameters: map[string]interface{}{"action": "create_index"}})
+	assert.EqualError(t, err, "database prod is read-only")
+}
+
+func TestDescribeLabels(t *testing.T) {
+	assert.Equal(t, "Billing primary; prod, read-only, #billing #core", describeLabels(DatabaseLabels{
+		Description: "Billing primary", Environment: "prod", Tags: []string{"billing", "core"}, ReadOnly: true,
+	}))
+	assert.Equal(t, "dev", describeLabels(DatabaseLabels{Environment: "dev"}))
+	assert.Equal(t, "", describeLabels(DatabaseLabels{}))
+}
+
+func TestListDatabasesLabels(t *testing.T) {
+	useCase := new(MockUseCaseProvider)
+	useCase.On("ListDatabases").Return([]string{"billing", "scratch"})
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	tr.EnableLabels(staticLabels{
+		"billing": {Description: "Billing primary", Environment: "prod", Tags: []string{"billing"}, ReadOnly: true},
+	})
+	assert.Equal(t, []string{"billing (Billing primary; prod, read-only, #billing)", "scratch"},
+		tr.describeDatabases([]string{"billing", "scratch"}))
+
+	toolType, ok := tr.factory.GetToolType("list_databases")
+	require.True(t, ok)
+	resp, err := toolType.HandleRequest(context.Background(), server.ToolCallRequest{}, "", useCase)
+	require.NoError(t, err)
+
+	result := resp.(map[string]interface{})
+	text := result["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "1. billing (Billing primary; prod, read-only, #billing)")
+	assert.Contains(t, text, "2. scratch\n")
+
+	labels := result["metadata"].(map[string]interface{})["labels"].(map[string]interface{})
+	assert.Equal(t, "prod", labels["billing"].(map[string]interface{})["environment"])
+	assert.NotContains(t, labels, "scratch")
+}
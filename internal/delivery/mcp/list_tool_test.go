@@ -46,11 +46,23 @@ func (m *MockUseCaseProvider) GetDatabaseType(dbID string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+// SupportsTransactions reports that the mock databases support transactions
+func (m *MockUseCaseProvider) SupportsTransactions(dbID string) bool {
+	return true
+}
+
 func (m *MockUseCaseProvider) IsLazyLoading() bool {
 	args := m.Called()
 	return args.Bool(0)
 }
 
+// ListExtensions mocks the ListExtensions method
+func (m *MockUseCaseProvider) ListExtensions(ctx context.Context, dbID string) ([]string, error) {
+	args := m.Called(ctx, dbID)
+	extensions, _ := args.Get(0).([]string)
+	return extensions, args.Error(1)
+}
+
 func TestListDirectoryTool(t *testing.T) {
 	// Create a temporary directory for testing
 	tempDir, err := os.MkdirTemp("", "list_tool_test")
@@ -23,6 +23,11 @@ func (m *MockDatabaseUseCase) GetDatabaseType(dbID string) (string, error) {
 	return args.String(0), args.Error(1)
 }
 
+// SupportsTransactions reports that the mock databases support transactions
+func (m *MockDatabaseUseCase) SupportsTransactions(dbID string) bool {
+	return true
+}
+
 // ExecuteQuery mocks the ExecuteQuery method
 func (m *MockDatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error) {
 	args := m.Called(ctx, dbID, query, params)
@@ -52,3 +57,10 @@ func (m *MockDatabaseUseCase) IsLazyLoading() bool {
 	args := m.Called()
 	return args.Bool(0)
 }
+
+// ListExtensions mocks the ListExtensions method
+func (m *MockDatabaseUseCase) ListExtensions(ctx context.Context, dbID string) ([]string, error) {
+	args := m.Called(ctx, dbID)
+	extensions, _ := args.Get(0).([]string)
+	return extensions, args.Error(1)
+}
@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// postgresExtensionTools are the tool types registered for PostgreSQL
+// databases with an extension installed, by extension name. TimescaleDB
+// tools are registered separately.
+var postgresExtensionTools = map[string]string{
+	"pg_stat_statements": "top_queries",
+	"vector":             "vector_search", // pgvector
+	"postgis":            "spatial_query",
+}
+
+// isExtensionTool reports whether a tool type belongs to an extension
+func isExtensionTool(kind string) bool {
+	for _, extensionKind := range postgresExtensionTools {
+		if kind == extensionKind {
+			return true
+		}
+	}
+	return false
+}
+
+// postgresExtensions returns the extensions installed in a PostgreSQL
+// database. With lazy loading they are detected on a temporary connection.
+// Detection failures are logged and leave the set empty.
+func (tr *ToolRegistry) postgresExtensions(ctx context.Context, dbID string) map[string]bool {
+	names, err := tr.databaseUseCase.ListExtensions(ctx, dbID)
+	if err != nil {
+		logger.Warn("Failed to detect extensions of database %s: %v", dbID, err)
+		return map[string]bool{}
+	}
+	extensions := make(map[string]bool, len(names))
+	for _, name := range names {
+		extensions[name] = true
+	}
+	logger.Info("Extensions installed in database %s: %v", dbID, names)
+	return extensions
+}
+
+// extensionNames returns the names of a set of extensions in order
+func extensionNames(extensions map[string]bool) []string {
+	names := make([]string, 0, len(extensions))
+	for name := range extensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// registerExtensionTools registers the <tooltype>_<dbID> tools of the
+// installed extensions and returns the number of failed registrations
+func (tr *ToolRegistry) registerExtensionTools(ctx context.Context, dbID string, extensions map[string]bool) int {
+	registrationErrors := 0
+	for _, extension := range extensionNames(extensions) {
+		kind, ok := postgresExtensionTools[extension]
+		if !ok {
+			continue
+		}
+		toolName := fmt.Sprintf("%s_%s", kind, dbID)
+		if err := tr.registerTool(ctx, kind, toolName, dbID); err != nil {
+			logger.Error("Error registering %s tool %s: %v", extension, toolName, err)
+			registrationErrors++
+		} else {
+			logger.Info("%s extension detected, registered tool %s", extension, toolName)
+		}
+	}
+	return registrationErrors
+}
+
+// registerUnifiedExtensionTools registers a unified tool for each extension,
+// limited to the databases with the extension, and returns the number of
+// failed registrations
+func (tr *ToolRegistry) registerUnifiedExtensionTools(ctx context.Context, extensionDBs map[string][]string) int {
+	extensions := make([]string, 0, len(extensionDBs))
+	for extension := range extensionDBs {
+		extensions = append(extensions, extension)
+	}
+	sort.Strings(extensions)
+
+	registrationErrors := 0
+	for _, extension := range extensions {
+		kind, ok := postgresExtensionTools[extension]
+		if !ok {
+			continue
+		}
+		if err := tr.registerUnifiedTool(ctx, kind, kind, extensionDBs[extension]); err != nil {
+			logger.Error("Error registering unified %s tool: %v", extension, err)
+			registrationErrors++
+		} else {
+			logger.Info("%s extension detected, registered unified tool %s", extension, kind)
+		}
+	}
+	return registrationErrors
+}
+
+// qualifiedIdentifier quotes a table name that may be qualified with its
+// schema, such as public.items
+func qualifiedIdentifier(name string) (string, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) > 2 {
+		return "", fmt.Errorf("invalid table name %q", name)
+	}
+	for i, part := range parts {
+		parts[i] = sanitizeIdentifier(part)
+		if parts[i] == "" {
+			return "", fmt.Errorf("invalid table name %q", name)
+		}
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// requiredTable quotes the required table parameter
+func requiredTable(params map[string]interface{}) (string, error) {
+	name := getStringParam(params, "table")
+	if name == "" {
+		return "", fmt.Errorf("table parameter is required")
+	}
+	return qualifiedIdentifier(name)
+}
+
+// requiredIdentifier quotes a required column name parameter
+func requiredIdentifier(params map[string]interface{}, key string) (string, error) {
+	value := sanitizeIdentifier(getStringParam(params, key))
+	if value == "" {
+		return "", fmt.Errorf("%s parameter is required", key)
+	}
+	return value, nil
+}
+
+// limitParam returns the limit parameter, between 1 and 1000, or the default
+func limitParam(params map[string]interface{}, defaultLimit int) int {
+	limit := getIntParam(params, "limit")
+	if limit <= 0 {
+		return defaultLimit
+	}
+	if limit > 1000 {
+		return 1000
+	}
+	return limit
+}
@@ -0,0 +1,140 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtensionToolRegistration(t *testing.T) {
+	for _, unified := range []bool{false, true} {
+		useCase := new(MockDatabaseUseCase)
+		useCase.On("ListDatabases").Return([]string{"pg", "lite"})
+		useCase.On("GetDatabaseType", "pg").Return("postgres", nil)
+		useCase.On("GetDatabaseType", "lite").Return("sqlite", nil)
+		useCase.On("IsLazyLoading").Return(true)
+		useCase.On("ListExtensions", mock.Anything, "pg").Return([]string{"pg_stat_statements", "plpgsql", "vector"}, nil)
+
+		tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), unified)
+		require.NoError(t, tr.RegisterAllTools(context.Background(), useCase))
+
+		suffix := "_pg"
+		if unified {
+			suffix = ""
+		}
+		assert.True(t, tr.ToolVisible(nil, "top_queries"+suffix))
+		assert.True(t, tr.ToolVisible(nil, "vector_search"+suffix))
+		assert.False(t, tr.ToolVisible(nil, "spatial_query"+suffix))
+		assert.False(t, tr.ToolVisible(nil, "timescaledb_timeseries_query"+suffix))
+		assert.False(t, tr.ToolVisible(nil, "top_queries_lite"))
+	}
+}
+
+// capturedQuery is a query run by a tool
+type capturedQuery struct {
+	query  string
+	params []interface{}
+}
+
+// recordingUseCase records the queries of a tool and fails them with errs
+// in order
+type recordingUseCase struct {
+	MockDatabaseUseCase
+	queries []capturedQuery
+	errs    []error
+}
+
+func (u *recordingUseCase) ExecuteQuery(_ context.Context, _ string, query string, params []interface{}) (string, error) {
+	u.queries = append(u.queries, capturedQuery{query: query, params: params})
+	if len(u.queries) <= len(u.errs) {
+		return "", u.errs[len(u.queries)-1]
+	}
+	return "Results:\n\n", nil
+}
+
+func handleExtensionTool(tool ToolType, params map[string]interface{}, errs ...error) ([]capturedQuery, error) {
+	useCase := &recordingUseCase{errs: errs}
+	_, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{Parameters: params}, "pg", useCase)
+	return useCase.queries, err
+}
+
+func TestTopQueriesTool(t *testing.T) {
+	queries, err := handleExtensionTool(NewTopQueriesTool(), map[string]interface{}{"order_by": "calls", "limit": float64(5)})
+	require.NoError(t, err)
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0].query, "round(total_exec_time::numeric, 2) AS total_ms")
+	assert.Contains(t, queries[0].query, "ORDER BY calls DESC")
+	assert.Equal(t, []interface{}{5}, queries[0].params)
+
+	// Servers before PostgreSQL 13 have the old timing columns
+	queries, err = handleExtensionTool(NewTopQueriesTool(), map[string]interface{}{}, errors.New(`column "total_exec_time" does not exist`))
+	require.NoError(t, err)
+	require.Len(t, queries, 2)
+	assert.Contains(t, queries[1].query, "round(total_time::numeric, 2) AS total_ms")
+	assert.Contains(t, queries[1].query, "ORDER BY total_ms DESC")
+	assert.Equal(t, []interface{}{10}, queries[1].params)
+
+	_, err = handleExtensionTool(NewTopQueriesTool(), map[string]interface{}{"order_by": "query"})
+	assert.Error(t, err)
+}
+
+func TestVectorSearchTool(t *testing.T) {
+	queries, err := handleExtensionTool(NewVectorSearchTool(), map[string]interface{}{
+		"table": "public.doc-store", "column": "embedding", "vector": []interface{}{0.5, 1.0, -2.0}, "metric": "cosine",
+	})
+	require.NoError(t, err)
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0].query, `FROM public."doc-store" t`)
+	assert.Contains(t, queries[0].query, "ORDER BY t.embedding <=> $1::vector")
+	assert.Equal(t, []interface{}{"[0.5,1,-2]", 10, "embedding"}, queries[0].params)
+
+	queries, err = handleExtensionTool(NewVectorSearchTool(), map[string]interface{}{
+		"table": "docs", "column": "embedding", "vector": "[1,2]", "select": []interface{}{"id", "title"}, "limit": float64(3),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, queries[0].query, "SELECT t.id, t.title, t.embedding <-> $1::vector AS distance")
+	assert.Equal(t, []interface{}{"[1,2]", 3}, queries[0].params)
+
+	for _, params := range []map[string]interface{}{
+		{"table": "docs", "column": "embedding"},
+		{"table": "docs", "column": "embedding", "vector": []interface{}{"a"}},
+		{"table": "docs", "column": "embedding", "vector": "[1]", "metric": "hamming"},
+		{"table": "a.b.c", "column": "embedding", "vector": "[1]"},
+		{"column": "embedding", "vector": "[1]"},
+	} {
+		_, err := handleExtensionTool(NewVectorSearchTool(), params)
+		assert.Error(t, err, "params: %v", params)
+	}
+
+	queries, err = handleExtensionTool(NewVectorSearchTool(), map[string]interface{}{"action": "columns"})
+	require.NoError(t, err)
+	assert.Equal(t, vectorColumnsSQL, queries[0].query)
+}
+
+func TestSpatialQueryTool(t *testing.T) {
+	queries, err := handleExtensionTool(NewSpatialQueryTool(), map[string]interface{}{
+		"action": "bbox", "table": "places", "column": "geom",
+		"min_x": 13.3, "min_y": 52.4, "max_x": 13.5, "max_y": 52.6,
+	})
+	require.NoError(t, err)
+	require.Len(t, queries, 1)
+	assert.Contains(t, queries[0].query, "WHERE t.geom && ST_MakeEnvelope($1, $2, $3, $4, $5)")
+	assert.Equal(t, []interface{}{13.3, 52.4, 13.5, 52.6, 4326, 20, "geom"}, queries[0].params)
+
+	queries, err = handleExtensionTool(NewSpatialQueryTool(), map[string]interface{}{
+		"action": "nearby", "table": "places", "column": "geom", "x": 13.4, "y": 52.5, "radius": 500.0, "limit": float64(5),
+	})
+	require.NoError(t, err)
+	assert.Contains(t, queries[0].query, "ST_DWithin(ST_Transform(t.geom::geometry, 4326)::geography, p.point, $3)")
+	assert.Equal(t, []interface{}{13.4, 52.5, 500.0, 5, "geom"}, queries[0].params)
+
+	_, err = handleExtensionTool(NewSpatialQueryTool(), map[string]interface{}{"action": "nearby", "table": "places", "column": "geom", "x": 13.4})
+	assert.Error(t, err)
+	_, err = handleExtensionTool(NewSpatialQueryTool(), map[string]interface{}{"table": "places"})
+	assert.Error(t, err)
+}
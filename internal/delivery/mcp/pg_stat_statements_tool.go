@@ -0,0 +1,115 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+)
+
+// topQueriesOrder maps the order_by parameter of the top queries tool to the
+// pg_stat_statements column it sorts by
+var topQueriesOrder = map[string]string{
+	"total_time": "total_ms",
+	"mean_time":  "mean_ms",
+	"calls":      "calls",
+	"rows":       "rows",
+}
+
+// pgStatStatementsTimes are the timing columns of pg_stat_statements, renamed
+// in PostgreSQL 13, newest first
+var pgStatStatementsTimes = [][2]string{
+	{"total_exec_time", "mean_exec_time"},
+	{"total_time", "mean_time"},
+}
+
+//------------------------------------------------------------------------------
+// TopQueriesTool implementation
+//------------------------------------------------------------------------------
+
+// TopQueriesTool reports the most expensive statements recorded by the
+// pg_stat_statements extension
+type TopQueriesTool struct {
+	BaseToolType
+}
+
+// NewTopQueriesTool creates a top queries tool type
+func NewTopQueriesTool() *TopQueriesTool {
+	return &TopQueriesTool{
+		BaseToolType: BaseToolType{
+			name:        "top_queries",
+			description: "Show the most expensive queries recorded by pg_stat_statements, with calls, total and mean time, and rows",
+		},
+	}
+}
+
+// topQueriesOptions are the parameters shared by the per-database and unified tools
+func topQueriesOptions() []tools.ToolOption {
+	return []tools.ToolOption{
+		tools.WithString("order_by",
+			tools.Description("Sort by total_time (default), mean_time, calls or rows"),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Maximum number of queries (default 10)"),
+		),
+	}
+}
+
+// CreateTool creates a top queries tool
+func (t *TopQueriesTool) CreateTool(name string, dbID string) interface{} {
+	options := append([]tools.ToolOption{tools.WithDescription(t.GetDescription(dbID))}, topQueriesOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// CreateUnifiedTool creates a unified top queries tool with database parameter
+func (t *TopQueriesTool) CreateUnifiedTool(name string, dbList []string) interface{} {
+	options := append([]tools.ToolOption{
+		tools.WithDescription(t.GetUnifiedDescription(dbList)),
+		tools.WithString("database",
+			tools.Description("Database ID to use"),
+			tools.Required(),
+		),
+	}, topQueriesOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// HandleRequest handles top queries tool requests
+func (t *TopQueriesTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	orderBy := getStringParam(request.Parameters, "order_by")
+	if orderBy == "" {
+		orderBy = "total_time"
+	}
+	column, ok := topQueriesOrder[orderBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid order_by %s (use total_time, mean_time, calls or rows)", orderBy)
+	}
+	limit := limitParam(request.Parameters, 10)
+
+	var errs []string
+	for _, times := range pgStatStatementsTimes {
+		result, err := useCase.ExecuteQuery(ctx, dbID, buildTopQueriesSQL(times[0], times[1], column), []interface{}{limit})
+		if err == nil {
+			return createTextResponse(result), nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return nil, fmt.Errorf("failed to read pg_stat_statements: %s", strings.Join(errs, "; "))
+}
+
+// buildTopQueriesSQL builds the query of the statements of the current
+// database, sorted by one of the output columns
+func buildTopQueriesSQL(totalTime, meanTime, orderBy string) string {
+	return fmt.Sprintf(`SELECT queryid,
+       calls,
+       round(%[1]s::numeric, 2) AS total_ms,
+       round(%[2]s::numeric, 2) AS mean_ms,
+       rows,
+       round((100 * %[1]s / NULLIF(sum(%[1]s) OVER (), 0))::numeric, 2) AS percent_total,
+       left(regexp_replace(query, '\s+', ' ', 'g'), 500) AS query
+FROM pg_stat_statements
+WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+ORDER BY %[3]s DESC
+LIMIT $1`, totalTime, meanTime, orderBy)
+}
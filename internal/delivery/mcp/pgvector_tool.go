@@ -0,0 +1,200 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+)
+
+// vectorDistances maps the metric parameter of the vector search tool to the
+// pgvector distance operator
+var vectorDistances = map[string]string{
+	"l2":            "<->",
+	"cosine":        "<=>",
+	"inner_product": "<#>", // negative inner product, so smaller is closer
+	"l1":            "<+>",
+}
+
+// vectorColumnsSQL lists the pgvector columns of the tables and views
+const vectorColumnsSQL = `SELECT n.nspname AS table_schema,
+       c.relname AS table_name,
+       a.attname AS column_name,
+       format_type(a.atttypid, a.atttypmod) AS data_type
+FROM pg_attribute a
+JOIN pg_class c ON c.oid = a.attrelid
+JOIN pg_namespace n ON n.oid = c.relnamespace
+JOIN pg_type t ON t.oid = a.atttypid
+WHERE t.typname = 'vector'
+  AND a.attnum > 0 AND NOT a.attisdropped
+  AND c.relkind IN ('r', 'p', 'v', 'm')
+  AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+ORDER BY 1, 2, 3`
+
+//------------------------------------------------------------------------------
+// VectorSearchTool implementation
+//------------------------------------------------------------------------------
+
+// VectorSearchTool runs nearest neighbour searches over pgvector columns
+type VectorSearchTool struct {
+	BaseToolType
+}
+
+// NewVectorSearchTool creates a vector search tool type
+func NewVectorSearchTool() *VectorSearchTool {
+	return &VectorSearchTool{
+		BaseToolType: BaseToolType{
+			name:        "vector_search",
+			description: "Similarity search over pgvector columns: find the rows nearest to a vector, or list the vector columns",
+		},
+	}
+}
+
+// vectorSearchOptions are the parameters shared by the per-database and unified tools
+func vectorSearchOptions() []tools.ToolOption {
+	return []tools.ToolOption{
+		tools.WithString("action",
+			tools.Description("search (default) finds the nearest rows, columns lists the vector columns"),
+		),
+		tools.WithString("table",
+			tools.Description("Table to search, optionally schema-qualified"),
+		),
+		tools.WithString("column",
+			tools.Description("Vector column to compare"),
+		),
+		tools.WithArray("vector",
+			tools.Description("Query vector, with as many dimensions as the column"),
+			tools.Items(map[string]interface{}{"type": "number"}),
+		),
+		tools.WithString("metric",
+			tools.Description("Distance: l2 (default), cosine, inner_product or l1"),
+		),
+		tools.WithArray("select",
+			tools.Description("Columns to return; all but the vector column when omitted"),
+			tools.Items(map[string]interface{}{"type": "string"}),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Maximum number of rows (default 10)"),
+		),
+	}
+}
+
+// CreateTool creates a vector search tool
+func (t *VectorSearchTool) CreateTool(name string, dbID string) interface{} {
+	options := append([]tools.ToolOption{tools.WithDescription(t.GetDescription(dbID))}, vectorSearchOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// CreateUnifiedTool creates a unified vector search tool with database parameter
+func (t *VectorSearchTool) CreateUnifiedTool(name string, dbList []string) interface{} {
+	options := append([]tools.ToolOption{
+		tools.WithDescription(t.GetUnifiedDescription(dbList)),
+		tools.WithString("database",
+			tools.Description("Database ID to use"),
+			tools.Required(),
+		),
+	}, vectorSearchOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// HandleRequest handles vector search tool requests
+func (t *VectorSearchTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	action := getStringParam(request.Parameters, "action")
+	switch action {
+	case "columns":
+		result, err := useCase.ExecuteQuery(ctx, dbID, vectorColumnsSQL, nil)
+		if err != nil {
+			return nil, err
+		}
+		return createTextResponse(result), nil
+
+	case "", "search":
+		query, params, err := buildVectorSearchSQL(request.Parameters)
+		if err != nil {
+			return nil, err
+		}
+		result, err := useCase.ExecuteQuery(ctx, dbID, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return createTextResponse(result), nil
+	}
+
+	return nil, fmt.Errorf("unknown vector_search action %s (use search or columns)", action)
+}
+
+// buildVectorSearchSQL builds the nearest neighbour query of a search request
+func buildVectorSearchSQL(params map[string]interface{}) (string, []interface{}, error) {
+	table, err := requiredTable(params)
+	if err != nil {
+		return "", nil, err
+	}
+	column, err := requiredIdentifier(params, "column")
+	if err != nil {
+		return "", nil, err
+	}
+	vector, err := vectorParam(params["vector"])
+	if err != nil {
+		return "", nil, err
+	}
+
+	metric := getStringParam(params, "metric")
+	if metric == "" {
+		metric = "l2"
+	}
+	operator, ok := vectorDistances[metric]
+	if !ok {
+		return "", nil, fmt.Errorf("invalid metric %s (use l2, cosine, inner_product or l1)", metric)
+	}
+
+	// Rows are returned as JSON without the vector, which is long and of
+	// little use in the result
+	selectList := "to_jsonb(t) - $3::text AS row"
+	args := []interface{}{vector, limitParam(params, 10), getStringParam(params, "column")}
+	if columns := getStringsParam(params, "select"); len(columns) > 0 {
+		quoted := make([]string, 0, len(columns))
+		for _, c := range columns {
+			q := sanitizeIdentifier(c)
+			if q == "" {
+				return "", nil, fmt.Errorf("invalid column name %q", c)
+			}
+			quoted = append(quoted, "t."+q)
+		}
+		selectList = strings.Join(quoted, ", ")
+		args = args[:2]
+	}
+
+	query := fmt.Sprintf(`SELECT %s, t.%s %s $1::vector AS distance
+FROM %s t
+ORDER BY t.%s %s $1::vector
+LIMIT $2`, selectList, column, operator, table, column, operator)
+	return query, args, nil
+}
+
+// vectorParam formats the vector parameter as a pgvector literal such as
+// [1,2,3], from a list of numbers or a string literal
+func vectorParam(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case []interface{}:
+		if len(v) == 0 {
+			break
+		}
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			n, ok := item.(float64)
+			if !ok {
+				return "", fmt.Errorf("vector must be a list of numbers")
+			}
+			parts = append(parts, strconv.FormatFloat(n, 'g', -1, 32))
+		}
+		return "[" + strings.Join(parts, ",") + "]", nil
+	case string:
+		if v = strings.TrimSpace(v); v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("vector parameter is required")
+}
@@ -0,0 +1,198 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// PoolStats reports the connection pool of a database, or of one of its
+// read replicas
+type PoolStats struct {
+	Database               string      `json:"database,omitempty"`
+	Type                   string      `json:"type,omitempty"`
+	Address                string      `json:"address,omitempty"` // replicas only
+	Connected              bool        `json:"connected"`
+	MaxOpenConns           int         `json:"max_open_conns,omitempty"`
+	MaxIdleConns           int         `json:"max_idle_conns,omitempty"`
+	ConnMaxLifetimeSeconds int         `json:"conn_max_lifetime_seconds,omitempty"`
+	OpenConnections        int         `json:"open_connections"`
+	InUse                  int         `json:"in_use"`
+	Idle                   int         `json:"idle"`
+	WaitCount              int64       `json:"wait_count"`
+	WaitDurationMs         int64       `json:"wait_duration_ms"`
+	MaxIdleClosed          int64       `json:"max_idle_closed"`
+	MaxIdleTimeClosed      int64       `json:"max_idle_time_closed"`
+	MaxLifetimeClosed      int64       `json:"max_lifetime_closed"`
+	Replicas               []PoolStats `json:"replicas,omitempty"`
+}
+
+// PoolSettings changes the connection pool of a database. Zero fields keep
+// their current setting.
+type PoolSettings struct {
+	MaxOpenConns           int
+	MaxIdleConns           int
+	ConnMaxLifetimeSeconds int
+}
+
+// PoolManager reports and tunes the connection pools of the databases
+type PoolManager interface {
+	// PoolStats returns the pool of a database, or of all databases when
+	// dbID is empty
+	PoolStats(dbID string) ([]PoolStats, error)
+	TunePool(dbID string, settings PoolSettings) (PoolStats, error)
+}
+
+// poolToolKinds are the tool types that report and tune connection pools
+var poolToolKinds = []string{"pool_stats", "tune_pool"}
+
+//------------------------------------------------------------------------------
+// PoolTool implementation
+//------------------------------------------------------------------------------
+
+// PoolTool lets administrators inspect and resize database connection pools
+type PoolTool struct {
+	BaseToolType
+	pools PoolManager
+}
+
+// NewPoolTool creates a pool tool type for one of poolToolKinds
+func NewPoolTool(name string, pools PoolManager) *PoolTool {
+	descriptions := map[string]string{
+		"pool_stats": "Show connection pool statistics: open, in use and idle connections, waits and closed connections",
+		"tune_pool":  "Change the connection pool limits of a database at runtime",
+	}
+	return &PoolTool{
+		BaseToolType: BaseToolType{
+			name:        name,
+			description: descriptions[name],
+		},
+		pools: pools,
+	}
+}
+
+// CreateTool creates a pool tool
+func (t *PoolTool) CreateTool(name string, _ string) interface{} {
+	options := []tools.ToolOption{tools.WithDescription(t.description)}
+
+	switch t.name {
+	case "pool_stats":
+		options = append(options,
+			tools.WithString("database",
+				tools.Description("Database ID; all databases when omitted"),
+			),
+		)
+	case "tune_pool":
+		options = append(options,
+			tools.WithString("database",
+				tools.Description("Database ID"),
+				tools.Required(),
+			),
+			tools.WithNumber("max_open_conns",
+				tools.Description("Maximum number of open connections"),
+			),
+			tools.WithNumber("max_idle_conns",
+				tools.Description("Maximum number of idle connections"),
+			),
+			tools.WithNumber("conn_max_lifetime_seconds",
+				tools.Description("Maximum time a connection may be reused, in seconds"),
+			),
+		)
+	}
+
+	return tools.NewTool(name, options...)
+}
+
+// CreateUnifiedTool creates a unified pool tool (the database is a parameter)
+func (t *PoolTool) CreateUnifiedTool(name string, _ []string) interface{} {
+	return t.CreateTool(name, "")
+}
+
+// HandleRequest handles pool tool requests
+func (t *PoolTool) HandleRequest(_ context.Context, request server.ToolCallRequest, _ string, _ UseCaseProvider) (interface{}, error) {
+	database := getStringParam(request.Parameters, "database")
+
+	switch t.name {
+	case "pool_stats":
+		stats, err := t.pools.PoolStats(database)
+		if err != nil {
+			return nil, err
+		}
+		return poolResponse(fmt.Sprintf("Connection pools of %d database(s):", len(stats)), stats)
+
+	case "tune_pool":
+		if database == "" {
+			return nil, fmt.Errorf("database parameter is required")
+		}
+		settings := PoolSettings{
+			MaxOpenConns:           getIntParam(request.Parameters, "max_open_conns"),
+			MaxIdleConns:           getIntParam(request.Parameters, "max_idle_conns"),
+			ConnMaxLifetimeSeconds: getIntParam(request.Parameters, "conn_max_lifetime_seconds"),
+		}
+		if settings == (PoolSettings{}) {
+			return nil, fmt.Errorf("at least one of max_open_conns, max_idle_conns and conn_max_lifetime_seconds is required")
+		}
+		stats, err := t.pools.TunePool(database, settings)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Connection pool of %s tuned at runtime: %+v", database, settings)
+		return poolResponse(fmt.Sprintf("Tuned the connection pool of %s:", database), []PoolStats{stats})
+	}
+
+	return nil, fmt.Errorf("unknown pool tool %s", t.name)
+}
+
+// poolResponse formats pool statistics as JSON below a heading
+func poolResponse(heading string, stats []PoolStats) (interface{}, error) {
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format pool statistics: %w", err)
+	}
+	resp := createTextResponse(fmt.Sprintf("%s\n\n%s", heading, data))
+	return addMetadata(resp, "count", len(stats)), nil
+}
+
+// getIntParam safely extracts a numeric parameter from a parameter map
+func getIntParam(params map[string]interface{}, key string) int {
+	if value, ok := params[key].(float64); ok {
+		return int(value)
+	}
+	return 0
+}
+
+// EnablePools registers the pool_stats and tune_pool tools
+func (tr *ToolRegistry) EnablePools(pools PoolManager) {
+	for _, kind := range poolToolKinds {
+		tr.factory.Register(NewPoolTool(kind, pools))
+	}
+}
+
+// registerPoolTools registers the pool tools when enabled
+func (tr *ToolRegistry) registerPoolTools(ctx context.Context) {
+	for _, kind := range poolToolKinds {
+		if _, ok := tr.factory.toolTypes[kind]; !ok {
+			continue
+		}
+		if err := tr.registerTool(ctx, kind, kind, ""); err != nil {
+			logger.Error("Error registering %s tool: %v", kind, err)
+		} else {
+			logger.Info("Successfully registered tool %s", kind)
+		}
+	}
+}
+
+// isPoolTool reports whether a tool type reports or tunes connection pools
+func isPoolTool(kind string) bool {
+	for _, poolKind := range poolToolKinds {
+		if kind == poolKind {
+			return true
+		}
+	}
+	return false
+}
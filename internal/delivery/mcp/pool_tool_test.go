@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePools reports fixed pools and records tuning for tests
+type fakePools struct {
+	tuned map[string]PoolSettings
+}
+
+func (p *fakePools) PoolStats(dbID string) ([]PoolStats, error) {
+	all := []PoolStats{
+		{Database: "pg", Type: "postgres", Connected: true, MaxOpenConns: 25, OpenConnections: 3, InUse: 1, Idle: 2,
+			Replicas: []PoolStats{{Address: "replica:5432", Connected: true, OpenConnections: 1}}},
+		{Database: "lite", Type: "sqlite"},
+	}
+	if dbID == "" {
+		return all, nil
+	}
+	for _, stats := range all {
+		if stats.Database == dbID {
+			return []PoolStats{stats}, nil
+		}
+	}
+	return nil, errors.New("database connection " + dbID + " not found")
+}
+
+func (p *fakePools) TunePool(dbID string, settings PoolSettings) (PoolStats, error) {
+	p.tuned[dbID] = settings
+	return PoolStats{Database: dbID, Connected: true, MaxOpenConns: settings.MaxOpenConns}, nil
+}
+
+func TestPoolTools(t *testing.T) {
+	pools := &fakePools{tuned: map[string]PoolSettings{}}
+	ctx := context.Background()
+	handle := func(kind string, params map[string]interface{}) (map[string]interface{}, error) {
+		response, err := NewPoolTool(kind, pools).HandleRequest(ctx, server.ToolCallRequest{Name: kind, Parameters: params}, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		return response.(map[string]interface{}), nil
+	}
+	text := func(response map[string]interface{}) string {
+		return response["content"].([]map[string]interface{})[0]["text"].(string)
+	}
+
+	response, err := handle("pool_stats", map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, response["metadata"].(map[string]interface{})["count"])
+	assert.Contains(t, text(response), `"open_connections": 3`)
+	assert.Contains(t, text(response), `"address": "replica:5432"`)
+
+	response, err = handle("pool_stats", map[string]interface{}{"database": "lite"})
+	require.NoError(t, err)
+	assert.Contains(t, text(response), `"connected": false`)
+	assert.NotContains(t, text(response), `"pg"`)
+
+	_, err = handle("pool_stats", map[string]interface{}{"database": "missing"})
+	assert.Error(t, err)
+
+	response, err = handle("tune_pool", map[string]interface{}{"database": "pg", "max_open_conns": float64(40), "conn_max_lifetime_seconds": float64(600)})
+	require.NoError(t, err)
+	assert.Contains(t, text(response), `"max_open_conns": 40`)
+	assert.Equal(t, PoolSettings{MaxOpenConns: 40, ConnMaxLifetimeSeconds: 600}, pools.tuned["pg"])
+
+	// A database and at least one setting are required
+	_, err = handle("tune_pool", map[string]interface{}{"max_open_conns": float64(40)})
+	assert.Error(t, err)
+	_, err = handle("tune_pool", map[string]interface{}{"database": "pg"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+)
+
+// spatialColumnsSQL lists the geometry and geography columns registered by
+// PostGIS
+const spatialColumnsSQL = `SELECT f_table_schema AS table_schema, f_table_name AS table_name,
+       f_geometry_column AS column_name, 'geometry' AS kind, type, srid
+FROM geometry_columns
+UNION ALL
+SELECT f_table_schema, f_table_name, f_geography_column, 'geography', type, srid
+FROM geography_columns
+ORDER BY 1, 2, 3`
+
+//------------------------------------------------------------------------------
+// SpatialQueryTool implementation
+//------------------------------------------------------------------------------
+
+// SpatialQueryTool runs bounding box and distance queries over PostGIS
+// geometry and geography columns
+type SpatialQueryTool struct {
+	BaseToolType
+}
+
+// NewSpatialQueryTool creates a spatial query tool type
+func NewSpatialQueryTool() *SpatialQueryTool {
+	return &SpatialQueryTool{
+		BaseToolType: BaseToolType{
+			name:        "spatial_query",
+			description: "PostGIS spatial queries: rows within a bounding box, rows within a distance of a point (nearest first), or the spatial columns",
+		},
+	}
+}
+
+// spatialQueryOptions are the parameters shared by the per-database and unified tools
+func spatialQueryOptions() []tools.ToolOption {
+	return []tools.ToolOption{
+		tools.WithString("action",
+			tools.Description("bbox finds rows intersecting a bounding box, nearby finds rows within radius meters of a point, columns lists the geometry and geography columns"),
+			tools.Required(),
+		),
+		tools.WithString("table",
+			tools.Description("Table to query, optionally schema-qualified"),
+		),
+		tools.WithString("column",
+			tools.Description("Geometry or geography column"),
+		),
+		tools.WithNumber("min_x", tools.Description("bbox: west edge (longitude)")),
+		tools.WithNumber("min_y", tools.Description("bbox: south edge (latitude)")),
+		tools.WithNumber("max_x", tools.Description("bbox: east edge (longitude)")),
+		tools.WithNumber("max_y", tools.Description("bbox: north edge (latitude)")),
+		tools.WithNumber("srid",
+			tools.Description("bbox: SRID of the box coordinates (default 4326); must match the column"),
+		),
+		tools.WithNumber("x", tools.Description("nearby: longitude of the point")),
+		tools.WithNumber("y", tools.Description("nearby: latitude of the point")),
+		tools.WithNumber("radius",
+			tools.Description("nearby: distance from the point in meters"),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Maximum number of rows (default 20)"),
+		),
+	}
+}
+
+// CreateTool creates a spatial query tool
+func (t *SpatialQueryTool) CreateTool(name string, dbID string) interface{} {
+	options := append([]tools.ToolOption{tools.WithDescription(t.GetDescription(dbID))}, spatialQueryOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// CreateUnifiedTool creates a unified spatial query tool with database parameter
+func (t *SpatialQueryTool) CreateUnifiedTool(name string, dbList []string) interface{} {
+	options := append([]tools.ToolOption{
+		tools.WithDescription(t.GetUnifiedDescription(dbList)),
+		tools.WithString("database",
+			tools.Description("Database ID to use"),
+			tools.Required(),
+		),
+	}, spatialQueryOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// HandleRequest handles spatial query tool requests
+func (t *SpatialQueryTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	var query string
+	var params []interface{}
+	var err error
+
+	action := getStringParam(request.Parameters, "action")
+	switch action {
+	case "columns":
+		query = spatialColumnsSQL
+	case "bbox":
+		query, params, err = buildBoundingBoxSQL(request.Parameters)
+	case "nearby":
+		query, params, err = buildNearbySQL(request.Parameters)
+	default:
+		return nil, fmt.Errorf("unknown spatial_query action %q (use bbox, nearby or columns)", action)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := useCase.ExecuteQuery(ctx, dbID, query, params)
+	if err != nil {
+		return nil, err
+	}
+	return createTextResponse(result), nil
+}
+
+// buildBoundingBoxSQL builds the query of the rows whose shape intersects a
+// bounding box, using the spatial index of the column
+func buildBoundingBoxSQL(params map[string]interface{}) (string, []interface{}, error) {
+	table, column, err := spatialTarget(params)
+	if err != nil {
+		return "", nil, err
+	}
+	bounds, err := numberParams(params, "min_x", "min_y", "max_x", "max_y")
+	if err != nil {
+		return "", nil, err
+	}
+	srid := getIntParam(params, "srid")
+	if srid == 0 {
+		srid = 4326
+	}
+
+	args := append(bounds, srid, limitParam(params, 20), getStringParam(params, "column"))
+	query := fmt.Sprintf(`SELECT to_jsonb(t) - $7::text AS row, left(ST_AsText(t.%[1]s), 200) AS shape
+FROM %[2]s t
+WHERE t.%[1]s && ST_MakeEnvelope($1, $2, $3, $4, $5)
+LIMIT $6`, column, table)
+	return query, args, nil
+}
+
+// buildNearbySQL builds the query of the rows within a distance in meters of
+// a point, nearest first. Shapes are compared as geography in WGS 84, so
+// columns in any SRID can be queried with longitude and latitude.
+func buildNearbySQL(params map[string]interface{}) (string, []interface{}, error) {
+	table, column, err := spatialTarget(params)
+	if err != nil {
+		return "", nil, err
+	}
+	point, err := numberParams(params, "x", "y", "radius")
+	if err != nil {
+		return "", nil, err
+	}
+
+	args := append(point, limitParam(params, 20), getStringParam(params, "column"))
+	query := fmt.Sprintf(`SELECT to_jsonb(t) - $5::text AS row, left(ST_AsText(t.%[1]s), 200) AS shape,
+       round(ST_Distance(ST_Transform(t.%[1]s::geometry, 4326)::geography, p.point)::numeric, 2) AS distance_m
+FROM %[2]s t, (SELECT ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography AS point) p
+WHERE ST_DWithin(ST_Transform(t.%[1]s::geometry, 4326)::geography, p.point, $3)
+ORDER BY distance_m
+LIMIT $4`, column, table)
+	return query, args, nil
+}
+
+// spatialTarget returns the quoted table and column parameters
+func spatialTarget(params map[string]interface{}) (string, string, error) {
+	table, err := requiredTable(params)
+	if err != nil {
+		return "", "", err
+	}
+	column, err := requiredIdentifier(params, "column")
+	if err != nil {
+		return "", "", err
+	}
+	return table, column, nil
+}
+
+// numberParams returns required number parameters in order
+func numberParams(params map[string]interface{}, keys ...string) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		value, ok := params[key].(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s parameter is required", key)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -0,0 +1,30 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/FreePeak/cortex/pkg/server"
+
+	"github.com/FreePeak/db-mcp-server/internal/ratelimit"
+)
+
+// RateLimitMiddleware rejects tool calls that exceed the session or database
+// limits, telling the caller when to retry
+func RateLimitMiddleware(limiter *ratelimit.Limiter) ToolMiddleware {
+	return func(call ToolCall, next server.ToolHandler) server.ToolHandler {
+		return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+			release, err := limiter.Acquire(sessionID(request), call.Database(request))
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return next(ctx, request)
+		}
+	}
+}
+
+// EnableRateLimit applies rate and concurrency limits to every tool
+// registered afterwards
+func (tr *ToolRegistry) EnableRateLimit(limiter *ratelimit.Limiter) {
+	tr.Use(RateLimitMiddleware(limiter))
+}
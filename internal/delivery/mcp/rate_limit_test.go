@@ -0,0 +1,50 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/ratelimit"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	limiter := ratelimit.New(ratelimit.Config{Enabled: true}, map[string]ratelimit.Limit{"db1": {MaxConcurrent: 1}})
+
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	slow := RateLimitMiddleware(limiter)(ToolCall{Name: "query_db1", Kind: "query", DatabaseID: "db1"},
+		func(context.Context, server.ToolCallRequest) (interface{}, error) {
+			close(started)
+			<-finish
+			return createTextResponse("ok"), nil
+		})
+	fast := RateLimitMiddleware(limiter)(ToolCall{Name: "query", Kind: "query"},
+		func(context.Context, server.ToolCallRequest) (interface{}, error) {
+			return createTextResponse("ok"), nil
+		})
+
+	request := server.ToolCallRequest{Session: &types.ClientSession{ID: "s1"}}
+	done := make(chan error)
+	go func() {
+		_, err := slow(context.Background(), request)
+		done <- err
+	}()
+	<-started
+
+	// The unified tool targets db1 through its parameter and hits the same limit
+	request.Parameters = map[string]interface{}{"database": "db1"}
+	_, err := fast(context.Background(), request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "retry after")
+
+	close(finish)
+	require.NoError(t, <-done)
+
+	_, err = fast(context.Background(), request)
+	assert.NoError(t, err)
+}
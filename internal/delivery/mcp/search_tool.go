@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// SearchTable is a full-text (FTS5) table of a database
+type SearchTable struct {
+	Name    string   `json:"name"`
+	Columns []string `json:"columns"`
+}
+
+// SearchRequest is a full-text query of one table
+type SearchRequest struct {
+	Table   string
+	Query   string
+	Columns []string // restricts the query to these columns; all when empty
+	Limit   int
+}
+
+// SearchMatch is a row matching a full-text query
+type SearchMatch struct {
+	Rowid     int64             `json:"rowid"`
+	Score     float64           `json:"bm25"` // lower is a better match
+	Snippet   string            `json:"snippet"`
+	Highlight map[string]string `json:"highlight"`
+}
+
+// FullTextSearch finds, queries and creates the FTS5 tables of SQLite
+// databases
+type FullTextSearch interface {
+	SearchTables(ctx context.Context, dbID string) ([]SearchTable, error)
+	Search(ctx context.Context, dbID string, req SearchRequest) ([]SearchMatch, error)
+	// CreateSearchIndex creates an FTS5 table over text columns of a table,
+	// all of them when columns is empty, and keeps it in sync with triggers
+	CreateSearchIndex(ctx context.Context, dbID, table string, columns []string) (SearchTable, error)
+}
+
+// searchEngines are the connection types the search tool supports
+var searchEngines = map[string]bool{"sqlite": true}
+
+//------------------------------------------------------------------------------
+// SearchTool implementation
+//------------------------------------------------------------------------------
+
+// SearchTool runs ranked full-text queries against the FTS5 tables of SQLite
+// databases
+type SearchTool struct {
+	BaseToolType
+	search FullTextSearch
+}
+
+// NewSearchTool creates a search tool type
+func NewSearchTool(search FullTextSearch) *SearchTool {
+	return &SearchTool{
+		BaseToolType: BaseToolType{
+			name:        "search",
+			description: "Full-text search of SQLite FTS5 tables with ranked, highlighted matches",
+		},
+		search: search,
+	}
+}
+
+// searchOptions are the parameters shared by the per-database and unified tools
+func searchOptions() []tools.ToolOption {
+	return []tools.ToolOption{
+		tools.WithString("action",
+			tools.Description("search (default) queries a table, tables lists the FTS5 tables and their columns, create_index creates an FTS5 table named <table>_fts over text columns of a table"),
+		),
+		tools.WithString("table",
+			tools.Description("FTS5 table to search, or the table to index for create_index"),
+		),
+		tools.WithString("query",
+			tools.Description("FTS5 search expression, such as: disk AND (full OR error), \"exact phrase\", prefix*, NEAR(a b, 5)"),
+		),
+		tools.WithArray("columns",
+			tools.Description("Columns to search, or to index for create_index; all (text) columns when omitted"),
+			tools.Items(map[string]interface{}{"type": "string"}),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Maximum number of matches (default 20)"),
+		),
+	}
+}
+
+// CreateTool creates a search tool
+func (t *SearchTool) CreateTool(name string, dbID string) interface{} {
+	options := append([]tools.ToolOption{tools.WithDescription(t.GetDescription(dbID))}, searchOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// CreateUnifiedTool creates a unified search tool with database parameter
+func (t *SearchTool) CreateUnifiedTool(name string, dbList []string) interface{} {
+	options := append([]tools.ToolOption{
+		tools.WithDescription(t.GetUnifiedDescription(dbList)),
+		tools.WithString("database",
+			tools.Description("Database ID to use"),
+			tools.Required(),
+		),
+	}, searchOptions()...)
+	return tools.NewTool(name, options...)
+}
+
+// HandleRequest handles search tool requests
+func (t *SearchTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	if dbType, err := useCase.GetDatabaseType(dbID); err != nil {
+		return nil, err
+	} else if !searchEngines[dbType] {
+		return nil, fmt.Errorf("full-text search is only supported for SQLite databases, %s is %s", dbID, dbType)
+	}
+
+	table := getStringParam(request.Parameters, "table")
+	columns := getStringsParam(request.Parameters, "columns")
+
+	action := getStringParam(request.Parameters, "action")
+	switch action {
+	case "", "search":
+		query := getStringParam(request.Parameters, "query")
+		if table == "" || query == "" {
+			return nil, fmt.Errorf("table and query parameters are required")
+		}
+		matches, err := t.search.Search(ctx, dbID, SearchRequest{
+			Table:   table,
+			Query:   query,
+			Columns: columns,
+			Limit:   getIntParam(request.Parameters, "limit"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if matches == nil {
+			matches = []SearchMatch{}
+		}
+		return searchResponse(fmt.Sprintf("%d match(es) for %q in %s, best first:", len(matches), query, table), matches, len(matches))
+
+	case "tables":
+		tables, err := t.search.SearchTables(ctx, dbID)
+		if err != nil {
+			return nil, err
+		}
+		if tables == nil {
+			tables = []SearchTable{}
+		}
+		return searchResponse(fmt.Sprintf("%d FTS5 table(s) in %s:", len(tables), dbID), tables, len(tables))
+
+	case "create_index":
+		if table == "" {
+			return nil, fmt.Errorf("table parameter is required for create_index")
+		}
+		index, err := t.search.CreateSearchIndex(ctx, dbID, table, columns)
+		if err != nil {
+			return nil, err
+		}
+		logger.Info("Created FTS5 table %s over %s of %s", index.Name, table, dbID)
+		return searchResponse(fmt.Sprintf("Created FTS5 table %s over %s, kept in sync by triggers:", index.Name, table), index, 1)
+	}
+
+	return nil, fmt.Errorf("unknown search action %s (use search, tables or create_index)", action)
+}
+
+// searchResponse formats search results as JSON below a heading
+func searchResponse(heading string, result interface{}, count int) (interface{}, error) {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format search results: %w", err)
+	}
+	resp := createTextResponse(fmt.Sprintf("%s\n\n%s", heading, data))
+	return addMetadata(resp, "count", count), nil
+}
+
+// getStringsParam extracts a list of strings from a parameter map, ignoring
+// values that are not strings
+func getStringsParam(params map[string]interface{}, key string) []string {
+	values, _ := params[key].([]interface{})
+	var result []string
+	for _, value := range values {
+		if s, ok := value.(string); ok && s != "" {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// EnableSearch registers the search tool of SQLite databases
+func (tr *ToolRegistry) EnableSearch(search FullTextSearch) {
+	tr.factory.Register(NewSearchTool(search))
+}
+
+// registerSearchTool registers the search_<dbID> tool when search is enabled
+// and the engine of the database supports it
+func (tr *ToolRegistry) registerSearchTool(ctx context.Context, dbID, dbType string) error {
+	if _, ok := tr.factory.toolTypes["search"]; !ok || !searchEngines[dbType] {
+		return nil
+	}
+	return tr.registerTool(ctx, "search", fmt.Sprintf("search_%s", dbID), dbID)
+}
+
+// registerUnifiedSearchTool registers the unified search tool when search is
+// enabled and one of the databases supports it
+func (tr *ToolRegistry) registerUnifiedSearchTool(ctx context.Context, dbList []string) error {
+	if _, ok := tr.factory.toolTypes["search"]; !ok {
+		return nil
+	}
+	var supported []string
+	for _, dbID := range dbList {
+		if dbType, err := tr.databaseUseCase.GetDatabaseType(dbID); err == nil && searchEngines[dbType] {
+			supported = append(supported, dbID)
+		}
+	}
+	if len(supported) == 0 {
+		return nil
+	}
+	return tr.registerUnifiedTool(ctx, "search", "search", supported)
+}
@@ -0,0 +1,91 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSearch records search requests and returns fixed results
+type fakeSearch struct {
+	request SearchRequest
+	indexed []string
+}
+
+func (s *fakeSearch) SearchTables(context.Context, string) ([]SearchTable, error) {
+	return []SearchTable{{Name: "logs", Columns: []string{"service", "message"}}}, nil
+}
+
+func (s *fakeSearch) Search(_ context.Context, _ string, req SearchRequest) ([]SearchMatch, error) {
+	s.request = req
+	return []SearchMatch{{Rowid: 1, Score: -1.5, Snippet: "<mark>disk</mark> full", Highlight: map[string]string{"message": "<mark>disk</mark> full"}}}, nil
+}
+
+func (s *fakeSearch) CreateSearchIndex(_ context.Context, _, table string, columns []string) (SearchTable, error) {
+	s.indexed = columns
+	return SearchTable{Name: table + "_fts", Columns: columns}, nil
+}
+
+func TestSearchTool(t *testing.T) {
+	useCase := new(MockDatabaseUseCase)
+	useCase.On("GetDatabaseType", "docs").Return("sqlite", nil)
+	useCase.On("GetDatabaseType", "pg").Return("postgres", nil)
+
+	search := &fakeSearch{}
+	tool := NewSearchTool(search)
+	ctx := context.Background()
+	handle := func(dbID string, params map[string]interface{}) (string, error) {
+		response, err := tool.HandleRequest(ctx, server.ToolCallRequest{Name: "search_" + dbID, Parameters: params}, dbID, useCase)
+		if err != nil {
+			return "", err
+		}
+		return response.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string), nil
+	}
+
+	text, err := handle("docs", map[string]interface{}{
+		"table": "logs", "query": "disk", "columns": []interface{}{"message"}, "limit": float64(5),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, SearchRequest{Table: "logs", Query: "disk", Columns: []string{"message"}, Limit: 5}, search.request)
+	assert.Contains(t, text, `1 match(es) for "disk" in logs`)
+	assert.Contains(t, text, `"bm25": -1.5`)
+
+	text, err = handle("docs", map[string]interface{}{"action": "tables"})
+	require.NoError(t, err)
+	assert.Contains(t, text, "1 FTS5 table(s) in docs")
+
+	text, err = handle("docs", map[string]interface{}{"action": "create_index", "table": "articles"})
+	require.NoError(t, err)
+	assert.Contains(t, text, "Created FTS5 table articles_fts")
+	assert.Empty(t, search.indexed)
+
+	// Missing parameters, other engines and unknown actions are rejected
+	_, err = handle("docs", map[string]interface{}{"table": "logs"})
+	assert.Error(t, err)
+	_, err = handle("docs", map[string]interface{}{"action": "create_index"})
+	assert.Error(t, err)
+	_, err = handle("pg", map[string]interface{}{"table": "logs", "query": "disk"})
+	assert.Error(t, err)
+	_, err = handle("docs", map[string]interface{}{"action": "drop"})
+	assert.Error(t, err)
+}
+
+func TestSearchToolRegistration(t *testing.T) {
+	useCase := new(MockDatabaseUseCase)
+	useCase.On("ListDatabases").Return([]string{"docs", "app"})
+	useCase.On("GetDatabaseType", "docs").Return("sqlite", nil)
+	useCase.On("GetDatabaseType", "app").Return("mysql", nil)
+	useCase.On("IsLazyLoading").Return(true)
+	useCase.On("ListExtensions", mock.Anything, mock.Anything).Return([]string{}, nil)
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	tr.EnableSearch(&fakeSearch{})
+	require.NoError(t, tr.RegisterAllTools(context.Background(), useCase))
+
+	assert.True(t, tr.ToolVisible(nil, "search_docs"))
+	assert.False(t, tr.ToolVisible(nil, "search_app"))
+}
@@ -0,0 +1,89 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FreePeak/cortex/pkg/server"
+)
+
+// ToolCall describes the tool a handler has been registered for
+type ToolCall struct {
+	Name       string // registered tool name, e.g. query_mydb
+	Kind       string // tool type name, e.g. query
+	DatabaseID string // empty for unified and common tools
+}
+
+// Database returns the database targeted by a request: the registered database
+// for per-database tools, or the "database" parameter for unified tools
+func (c ToolCall) Database(request server.ToolCallRequest) string {
+	if c.DatabaseID != "" {
+		return c.DatabaseID
+	}
+	database, _ := request.Parameters["database"].(string)
+	return database
+}
+
+// ToolMiddleware wraps a tool handler with cross-cutting behaviour such as auditing
+type ToolMiddleware func(call ToolCall, next server.ToolHandler) server.ToolHandler
+
+// sessionID returns the MCP session ID of a request, if any
+func sessionID(request server.ToolCallRequest) string {
+	if request.Session == nil {
+		return ""
+	}
+	return request.Session.ID
+}
+
+// chainMiddleware applies middlewares so that the first one is the outermost
+func chainMiddleware(call ToolCall, handler server.ToolHandler, middlewares []ToolMiddleware) server.ToolHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](call, handler)
+	}
+	return handler
+}
+
+// Use adds middlewares to every tool registered afterwards
+func (tr *ToolRegistry) Use(middlewares ...ToolMiddleware) {
+	tr.middlewares = append(tr.middlewares, middlewares...)
+}
+
+// addTool registers a tool with the server, wrapping its handler with the
+// registry's middlewares. Registering a name again replaces its handler.
+func (tr *ToolRegistry) addTool(ctx context.Context, call ToolCall, tool interface{}, handler server.ToolHandler) error {
+	// The server keeps the first handler registered for a name, so it gets a
+	// dispatcher that looks up the current one
+	if err := tr.server.AddTool(ctx, tool, tr.dispatch(call.Name)); err != nil {
+		return err
+	}
+
+	tr.mu.Lock()
+	tr.calls[call.Name] = call
+	tr.handlers[call.Name] = chainMiddleware(call, handler, tr.middlewares)
+	tr.mu.Unlock()
+	return nil
+}
+
+// dispatch returns a handler that calls the current handler of a tool
+func (tr *ToolRegistry) dispatch(name string) server.ToolHandler {
+	return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+		tr.mu.RLock()
+		handler, ok := tr.handlers[name]
+		tr.mu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("tool %s is no longer available", name)
+		}
+		return handler(ctx, request)
+	}
+}
+
+// removeTool deregisters a tool. The server cannot forget a tool, so it
+// stays known there, but calls fail and tools/list results served through
+// the gateway hide it. Over stdio there is no gateway, so removed tools stay
+// listed until the server restarts.
+func (tr *ToolRegistry) removeTool(name string) {
+	tr.mu.Lock()
+	delete(tr.calls, name)
+	delete(tr.handlers, name)
+	tr.mu.Unlock()
+}
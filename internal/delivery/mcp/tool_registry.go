@@ -10,6 +10,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 
 	"github.com/FreePeak/cortex/pkg/server"
 
@@ -23,6 +24,12 @@ type ToolRegistry struct {
 	databaseUseCase UseCaseProvider
 	factory         *ToolTypeFactory
 	unifiedMode     bool
+	middlewares     []ToolMiddleware
+	labels          LabelProvider // optional, describes databases in unified tools
+
+	mu       sync.RWMutex
+	calls    map[string]ToolCall           // registered tools by name
+	handlers map[string]server.ToolHandler // current handlers, wrapped with middlewares
 }
 
 // NewToolRegistry creates a new tool registry
@@ -33,6 +40,8 @@ func NewToolRegistry(mcpServer *server.MCPServer, unifiedMode bool) *ToolRegistr
 		mcpServer:   mcpServer,
 		factory:     factory,
 		unifiedMode: unifiedMode,
+		calls:       make(map[string]ToolCall),
+		handlers:    make(map[string]server.ToolHandler),
 	}
 }
 
@@ -73,6 +82,59 @@ func (tr *ToolRegistry) RegisterAllTools(ctx context.Context, useCase UseCasePro
 	return nil
 }
 
+// SyncDatabases updates the registered tools after database connections were
+// added, removed or changed at runtime
+func (tr *ToolRegistry) SyncDatabases(ctx context.Context, added, removed, changed []string) error {
+	if tr.databaseUseCase == nil {
+		return fmt.Errorf("tools have not been registered yet")
+	}
+
+	// Mock tools only stand in while no database is configured
+	if len(tr.databaseUseCase.ListDatabases()) > 0 {
+		tr.removeToolsFor("mock")
+	}
+
+	// Unified tools list the available databases, so they are registered again
+	if tr.unifiedMode {
+		return tr.registerUnifiedTools(ctx)
+	}
+
+	for _, dbID := range append(append([]string{}, removed...), changed...) {
+		tr.removeToolsFor(dbID)
+		logger.Info("Removed tools for database %s", dbID)
+	}
+
+	registrationErrors := 0
+	for _, dbID := range append(append([]string{}, added...), changed...) {
+		if err := tr.registerDatabaseTools(ctx, dbID); err != nil {
+			logger.Error("Error registering tools for database %s: %v", dbID, err)
+			registrationErrors++
+		}
+	}
+	tr.registerCommonTools(ctx)
+
+	if registrationErrors > 0 {
+		return fmt.Errorf("errors occurred while registering tools for %d databases", registrationErrors)
+	}
+	return nil
+}
+
+// removeToolsFor deregisters every tool bound to a database
+func (tr *ToolRegistry) removeToolsFor(dbID string) {
+	tr.mu.RLock()
+	var names []string
+	for name, call := range tr.calls {
+		if call.DatabaseID == dbID {
+			names = append(names, name)
+		}
+	}
+	tr.mu.RUnlock()
+
+	for _, name := range names {
+		tr.removeTool(name)
+	}
+}
+
 // registerDatabaseTools registers all tools for a specific database
 func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string) error {
 	// Get all tool types from the factory
@@ -101,6 +163,9 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 		// Register each tool type for this database
 		registrationErrors := 0
 		for _, typeName := range toolTypeNames {
+			if typeName == "transaction" && !tr.databaseUseCase.SupportsTransactions(dbID) {
+				continue
+			}
 			// Use simpler tool names: <tooltype>_<dbID>
 			toolName := fmt.Sprintf("%s_%s", typeName, dbID)
 			if err := tr.registerTool(ctx, typeName, toolName, dbID); err != nil {
@@ -111,47 +176,44 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 			}
 		}
 
-		// Check if TimescaleDB is available for this PostgreSQL database
-		// Skip this check if lazy loading is enabled to avoid establishing connections during startup
-		if !tr.databaseUseCase.IsLazyLoading() {
-			checkQuery := "SELECT 1 FROM pg_extension WHERE extname = 'timescaledb'"
-			result, err := tr.databaseUseCase.ExecuteQuery(ctx, dbID, checkQuery, nil)
-			if err == nil && result != "[]" && result != "" {
-				logger.Info("TimescaleDB extension detected for database %s, registering TimescaleDB tools", dbID)
-
-				// Register TimescaleDB-specific tools
-				timescaleTool := NewTimescaleDBTool()
-
-				// Register time series query tool
-				tsQueryToolName := fmt.Sprintf("timescaledb_timeseries_query_%s", dbID)
-				tsQueryTool := timescaleTool.CreateTimeSeriesQueryTool(tsQueryToolName, dbID)
-				if err := tr.server.AddTool(ctx, tsQueryTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
-					response, err := timescaleTool.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
-					return FormatResponse(response, err)
-				}); err != nil {
-					logger.Error("Error registering TimescaleDB time series query tool: %v", err)
-					registrationErrors++
-				} else {
-					logger.Info("Successfully registered TimescaleDB time series query tool: %s", tsQueryToolName)
-				}
+		// Register the tools of the installed extensions, such as TimescaleDB.
+		// With lazy loading they are detected on a temporary connection.
+		extensions := tr.postgresExtensions(ctx, dbID)
+		if extensions["timescaledb"] {
+			logger.Info("TimescaleDB extension detected for database %s, registering TimescaleDB tools", dbID)
+
+			// Register TimescaleDB-specific tools
+			timescaleTool := NewTimescaleDBTool()
+
+			// Register time series query tool
+			tsQueryToolName := fmt.Sprintf("timescaledb_timeseries_query_%s", dbID)
+			tsQueryTool := timescaleTool.CreateTimeSeriesQueryTool(tsQueryToolName, dbID)
+			if err := tr.addTool(ctx, ToolCall{Name: tsQueryToolName, Kind: "timescaledb", DatabaseID: dbID}, tsQueryTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+				response, err := timescaleTool.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
+				return FormatResponse(response, err)
+			}); err != nil {
+				logger.Error("Error registering TimescaleDB time series query tool: %v", err)
+				registrationErrors++
+			} else {
+				logger.Info("Successfully registered TimescaleDB time series query tool: %s", tsQueryToolName)
+			}
 
-				// Register time series analyze tool
-				tsAnalyzeToolName := fmt.Sprintf("timescaledb_analyze_timeseries_%s", dbID)
-				tsAnalyzeTool := timescaleTool.CreateTimeSeriesAnalyzeTool(tsAnalyzeToolName, dbID)
-				if err := tr.server.AddTool(ctx, tsAnalyzeTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
-					response, err := timescaleTool.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
-					return FormatResponse(response, err)
-				}); err != nil {
-					logger.Error("Error registering TimescaleDB time series analyze tool: %v", err)
-					registrationErrors++
-				} else {
-					logger.Info("Successfully registered TimescaleDB time series analyze tool: %s", tsAnalyzeToolName)
-				}
+			// Register time series analyze tool
+			tsAnalyzeToolName := fmt.Sprintf("timescaledb_analyze_timeseries_%s", dbID)
+			tsAnalyzeTool := timescaleTool.CreateTimeSeriesAnalyzeTool(tsAnalyzeToolName, dbID)
+			if err := tr.addTool(ctx, ToolCall{Name: tsAnalyzeToolName, Kind: "timescaledb", DatabaseID: dbID}, tsAnalyzeTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+				response, err := timescaleTool.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
+				return FormatResponse(response, err)
+			}); err != nil {
+				logger.Error("Error registering TimescaleDB time series analyze tool: %v", err)
+				registrationErrors++
+			} else {
+				logger.Info("Successfully registered TimescaleDB time series analyze tool: %s", tsAnalyzeToolName)
 			}
-		} else {
-			logger.Info("Skipping TimescaleDB detection for database %s (lazy loading enabled)", dbID)
 		}
 
+		registrationErrors += tr.registerExtensionTools(ctx, dbID, extensions)
+
 		if registrationErrors > 0 {
 			return fmt.Errorf("errors occurred while registering %d tools", registrationErrors)
 		}
@@ -167,6 +229,10 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 	// Register each tool type for this database
 	registrationErrors := 0
 	for _, typeName := range toolTypeNames {
+		// Engines without transactions, such as ClickHouse, get no transaction tool
+		if typeName == "transaction" && !tr.databaseUseCase.SupportsTransactions(dbID) {
+			continue
+		}
 		// Use simpler tool names: <tooltype>_<dbID>
 		toolName := fmt.Sprintf("%s_%s", typeName, dbID)
 		if err := tr.registerTool(ctx, typeName, toolName, dbID); err != nil {
@@ -177,6 +243,18 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 		}
 	}
 
+	// SQLite databases get a backup tool when backups are enabled
+	if err := tr.registerBackupTool(ctx, dbID, dbType); err != nil {
+		logger.Error("Error registering backup tool for %s: %v", dbID, err)
+		registrationErrors++
+	}
+
+	// SQLite databases get a full-text search tool when search is enabled
+	if err := tr.registerSearchTool(ctx, dbID, dbType); err != nil {
+		logger.Error("Error registering search tool for %s: %v", dbID, err)
+		registrationErrors++
+	}
+
 	if registrationErrors > 0 {
 		return fmt.Errorf("errors occurred while registering %d tools", registrationErrors)
 	}
@@ -196,7 +274,7 @@ func (tr *ToolRegistry) registerTool(ctx context.Context, toolTypeName string, n
 
 	tool := toolTypeImpl.CreateTool(name, dbID)
 
-	return tr.server.AddTool(ctx, tool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+	return tr.addTool(ctx, ToolCall{Name: name, Kind: toolTypeImpl.GetName(), DatabaseID: dbID}, tool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
 		response, err := toolTypeImpl.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
 		return FormatResponse(response, err)
 	})
@@ -218,51 +296,66 @@ func (tr *ToolRegistry) registerUnifiedTools(ctx context.Context) error {
 		}
 	}
 
-	// Check for TimescaleDB on any PostgreSQL database
-	if !tr.databaseUseCase.IsLazyLoading() {
-		for _, dbID := range dbList {
-			dbType, err := tr.databaseUseCase.GetDatabaseType(dbID)
-			if err != nil || dbType != "postgres" {
-				continue
-			}
+	if err := tr.registerUnifiedBackupTool(ctx, dbList); err != nil {
+		logger.Error("Error registering unified backup tool: %v", err)
+		registrationErrors++
+	}
 
-			checkQuery := "SELECT 1 FROM pg_extension WHERE extname = 'timescaledb'"
-			result, err := tr.databaseUseCase.ExecuteQuery(ctx, dbID, checkQuery, nil)
-			if err == nil && result != "[]" && result != "" {
-				logger.Info("TimescaleDB extension detected, registering unified TimescaleDB tools")
-				timescaleTool := NewTimescaleDBTool()
-
-				tsQueryTool := timescaleTool.CreateUnifiedTimeSeriesQueryTool("timescaledb_timeseries_query", dbList)
-				if err := tr.server.AddTool(ctx, tsQueryTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
-					database, err := extractAndValidateDatabase(request, dbList)
-					if err != nil {
-						return FormatResponse(nil, err)
-					}
-					response, err := timescaleTool.HandleRequest(ctx, request, database, tr.databaseUseCase)
-					return FormatResponse(response, err)
-				}); err != nil {
-					logger.Error("Error registering unified TimescaleDB time series query tool: %v", err)
-					registrationErrors++
-				}
+	if err := tr.registerUnifiedSearchTool(ctx, dbList); err != nil {
+		logger.Error("Error registering unified search tool: %v", err)
+		registrationErrors++
+	}
+
+	// Detect the extensions of each PostgreSQL database, registering the
+	// TimescaleDB tools once any database has it
+	extensionDBs := make(map[string][]string)
+	timescale := false
+	for _, dbID := range dbList {
+		dbType, err := tr.databaseUseCase.GetDatabaseType(dbID)
+		if err != nil || dbType != "postgres" {
+			continue
+		}
 
-				tsAnalyzeTool := timescaleTool.CreateUnifiedTimeSeriesAnalyzeTool("timescaledb_analyze_timeseries", dbList)
-				if err := tr.server.AddTool(ctx, tsAnalyzeTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
-					database, err := extractAndValidateDatabase(request, dbList)
-					if err != nil {
-						return FormatResponse(nil, err)
-					}
-					response, err := timescaleTool.HandleRequest(ctx, request, database, tr.databaseUseCase)
-					return FormatResponse(response, err)
-				}); err != nil {
-					logger.Error("Error registering unified TimescaleDB time series analyze tool: %v", err)
-					registrationErrors++
+		extensions := tr.postgresExtensions(ctx, dbID)
+		for extension := range extensions {
+			extensionDBs[extension] = append(extensionDBs[extension], dbID)
+		}
+
+		if extensions["timescaledb"] && !timescale {
+			timescale = true
+			logger.Info("TimescaleDB extension detected, registering unified TimescaleDB tools")
+			timescaleTool := NewTimescaleDBTool()
+
+			tsQueryTool := timescaleTool.CreateUnifiedTimeSeriesQueryTool("timescaledb_timeseries_query", tr.describeDatabases(dbList))
+			if err := tr.addTool(ctx, ToolCall{Name: "timescaledb_timeseries_query", Kind: "timescaledb"}, tsQueryTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+				database, err := extractAndValidateDatabase(request, dbList)
+				if err != nil {
+					return FormatResponse(nil, err)
 				}
+				response, err := timescaleTool.HandleRequest(ctx, request, database, tr.databaseUseCase)
+				return FormatResponse(response, err)
+			}); err != nil {
+				logger.Error("Error registering unified TimescaleDB time series query tool: %v", err)
+				registrationErrors++
+			}
 
-				break
+			tsAnalyzeTool := timescaleTool.CreateUnifiedTimeSeriesAnalyzeTool("timescaledb_analyze_timeseries", tr.describeDatabases(dbList))
+			if err := tr.addTool(ctx, ToolCall{Name: "timescaledb_analyze_timeseries", Kind: "timescaledb"}, tsAnalyzeTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+				database, err := extractAndValidateDatabase(request, dbList)
+				if err != nil {
+					return FormatResponse(nil, err)
+				}
+				response, err := timescaleTool.HandleRequest(ctx, request, database, tr.databaseUseCase)
+				return FormatResponse(response, err)
+			}); err != nil {
+				logger.Error("Error registering unified TimescaleDB time series analyze tool: %v", err)
+				registrationErrors++
 			}
 		}
 	}
 
+	registrationErrors += tr.registerUnifiedExtensionTools(ctx, extensionDBs)
+
 	// Register common tools
 	tr.registerCommonTools(ctx)
 
@@ -279,9 +372,9 @@ func (tr *ToolRegistry) registerUnifiedTool(ctx context.Context, toolTypeName st
 		return fmt.Errorf("failed to get tool type for '%s'", toolTypeName)
 	}
 
-	tool := toolTypeImpl.CreateUnifiedTool(name, dbList)
+	tool := toolTypeImpl.CreateUnifiedTool(name, tr.describeDatabases(dbList))
 
-	return tr.server.AddTool(ctx, tool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+	return tr.addTool(ctx, ToolCall{Name: name, Kind: toolTypeImpl.GetName()}, tool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
 		database, err := extractAndValidateDatabase(request, dbList)
 		if err != nil {
 			return FormatResponse(nil, err)
@@ -332,6 +425,20 @@ func (tr *ToolRegistry) registerCommonTools(ctx context.Context) {
 			logger.Info("Successfully registered tool %s", listDirName)
 		}
 	}
+
+	// Register the audit search tool when auditing is enabled
+	_, ok = tr.factory.GetToolType("audit_search")
+	if ok {
+		auditSearchName := "audit_search"
+		if err := tr.registerTool(ctx, "audit_search", auditSearchName, ""); err != nil {
+			logger.Error("Error registering %s tool: %v", auditSearchName, err)
+		} else {
+			logger.Info("Successfully registered tool %s", auditSearchName)
+		}
+	}
+
+	tr.registerConnectionTools(ctx)
+	tr.registerPoolTools(ctx)
 }
 
 // RegisterMockTools registers mock tools with the server when no db connections available
@@ -340,6 +447,10 @@ func (tr *ToolRegistry) RegisterMockTools(ctx context.Context) error {
 
 	// For each tool type, register a simplified mock tool
 	for toolTypeName := range tr.factory.toolTypes {
+		if isConnectionTool(toolTypeName) || isPoolTool(toolTypeName) || toolTypeName == "backup" || toolTypeName == "search" || isExtensionTool(toolTypeName) {
+			continue
+		}
+
 		// Format: mock_<tooltype>
 		mockToolName := fmt.Sprintf("mock_%s", toolTypeName)
 
@@ -351,7 +462,7 @@ func (tr *ToolRegistry) RegisterMockTools(ctx context.Context) error {
 
 		tool := toolTypeImpl.CreateTool(mockToolName, "mock")
 
-		err := tr.server.AddTool(ctx, tool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+		err := tr.addTool(ctx, ToolCall{Name: mockToolName, Kind: toolTypeName, DatabaseID: "mock"}, tool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
 			response, err := toolTypeImpl.HandleRequest(ctx, request, "mock", tr.databaseUseCase)
 			return FormatResponse(response, err)
 		})
@@ -362,6 +473,9 @@ func (tr *ToolRegistry) RegisterMockTools(ctx context.Context) error {
 		}
 	}
 
+	// Connection tools stay available so that a database can be added
+	tr.registerConnectionTools(ctx)
+
 	return nil
 }
 
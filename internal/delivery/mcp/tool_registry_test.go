@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncDatabases(t *testing.T) {
+	useCase := new(MockDatabaseUseCase)
+	useCase.On("ListDatabases").Return([]string{"db1", "db2"}).Once()
+	useCase.On("ListDatabases").Return([]string{"db1", "db3"})
+	useCase.On("GetDatabaseType", "db1").Return("sqlite", nil)
+	useCase.On("GetDatabaseType", "db2").Return("sqlite", nil)
+	useCase.On("GetDatabaseType", "db3").Return("sqlite", nil)
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	ctx := context.Background()
+	require.NoError(t, tr.RegisterAllTools(ctx, useCase))
+	assert.True(t, tr.ToolVisible(nil, "query_db2"))
+	assert.False(t, tr.ToolVisible(nil, "query_db3"))
+
+	require.NoError(t, tr.SyncDatabases(ctx, []string{"db3"}, []string{"db2"}, nil))
+	assert.True(t, tr.ToolVisible(nil, "query_db1"))
+	assert.True(t, tr.ToolVisible(nil, "query_db3"))
+	assert.True(t, tr.ToolVisible(nil, "list_databases"))
+	assert.False(t, tr.ToolVisible(nil, "query_db2"))
+
+	// The server still routes calls to the removed tool, which now fail
+	_, err := tr.dispatch("query_db2")(ctx, server.ToolCallRequest{Name: "query_db2"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no longer available")
+}
+
+func TestAddToolReplacesHandler(t *testing.T) {
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	ctx := context.Background()
+	call := ToolCall{Name: "query_db1", Kind: "query", DatabaseID: "db1"}
+	tool := NewQueryTool().CreateTool(call.Name, call.DatabaseID)
+
+	for _, text := range []string{"first", "second"} {
+		text := text
+		require.NoError(t, tr.addTool(ctx, call, tool, func(context.Context, server.ToolCallRequest) (interface{}, error) {
+			return createTextResponse(text), nil
+		}))
+	}
+
+	response, err := tr.dispatch("query_db1")(ctx, server.ToolCallRequest{Name: "query_db1"})
+	require.NoError(t, err)
+	assert.Equal(t, createTextResponse("second"), response)
+}
+
+// noTransactionsUseCase reports that one database cannot run transactions
+type noTransactionsUseCase struct {
+	*MockDatabaseUseCase
+	dbID string
+}
+
+func (u noTransactionsUseCase) SupportsTransactions(dbID string) bool {
+	return dbID != u.dbID
+}
+
+func TestTransactionToolSkipped(t *testing.T) {
+	useCase := new(MockDatabaseUseCase)
+	useCase.On("ListDatabases").Return([]string{"events", "app"})
+	useCase.On("GetDatabaseType", "events").Return("clickhouse", nil)
+	useCase.On("GetDatabaseType", "app").Return("postgres", nil)
+	useCase.On("IsLazyLoading").Return(true)
+	useCase.On("ListExtensions", mock.Anything, mock.Anything).Return([]string{}, nil)
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil), false)
+	require.NoError(t, tr.RegisterAllTools(context.Background(), noTransactionsUseCase{useCase, "events"}))
+
+	assert.True(t, tr.ToolVisible(nil, "query_events"))
+	assert.False(t, tr.ToolVisible(nil, "transaction_events"))
+	assert.True(t, tr.ToolVisible(nil, "transaction_app"))
+}
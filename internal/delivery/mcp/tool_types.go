@@ -41,7 +41,6 @@ func addMetadata(resp map[string]interface{}, key string, value interface{}) map
 // TODO: Refactor tool type implementations to reduce duplication and improve maintainability
 // TODO: Consider using a code generation approach for repetitive tool patterns
 // TODO: Add comprehensive request validation for all tool parameters
-// TODO: Implement proper rate limiting and resource protection
 // TODO: Add detailed documentation for each tool type and its parameters
 
 // ToolType interface defines the structure for different types of database tools
@@ -71,6 +70,9 @@ type UseCaseProvider interface {
 	GetDatabaseInfo(dbID string) (map[string]interface{}, error)
 	ListDatabases() []string
 	GetDatabaseType(dbID string) (string, error)
+	SupportsTransactions(dbID string) bool
+	// ListExtensions returns the extensions installed in a PostgreSQL database
+	ListExtensions(ctx context.Context, dbID string) ([]string, error)
 	IsLazyLoading() bool
 }
 
@@ -128,6 +130,9 @@ func (t *QueryTool) CreateTool(name string, dbID string) interface{} {
 			tools.Description("Query parameters"),
 			tools.Items(map[string]interface{}{"type": "string"}),
 		),
+		tools.WithBoolean("confirm",
+			tools.Description("Run the query even if its estimated cost exceeds the connection's cost guard"),
+		),
 	)
 }
 
@@ -148,6 +153,9 @@ func (t *QueryTool) CreateUnifiedTool(name string, dbList []string) interface{}
 			tools.Description("Query parameters"),
 			tools.Items(map[string]interface{}{"type": "string"}),
 		),
+		tools.WithBoolean("confirm",
+			tools.Description("Run the query even if its estimated cost exceeds the connection's cost guard"),
+		),
 	)
 }
 
@@ -599,6 +607,8 @@ func (t *SchemaTool) HandleRequest(_ context.Context, request server.ToolCallReq
 // ListDatabasesTool handles listing available databases
 type ListDatabasesTool struct {
 	BaseToolType
+	health HealthReporter // optional, adds the health of each database
+	labels LabelProvider  // optional, adds the description, environment and tags of each database
 }
 
 // NewListDatabasesTool creates a new list databases tool type
@@ -634,15 +644,54 @@ func (t *ListDatabasesTool) HandleRequest(_ context.Context, _ server.ToolCallRe
 
 	// Format as text for display
 	output := "Available databases:\n\n"
+	healthByDB := make(map[string]interface{})
+	labelsByDB := make(map[string]interface{})
 	for i, db := range databases {
-		output += fmt.Sprintf("%d. %s\n", i+1, db)
+		var details []string
+		if t.labels != nil {
+			if labels, ok := t.labels.DatabaseLabels(db); ok {
+				if text := describeLabels(labels); text != "" {
+					details = append(details, text)
+				}
+				labelsByDB[db] = map[string]interface{}{
+					"description": labels.Description,
+					"environment": labels.Environment,
+					"tags":        labels.Tags,
+					"read_only":   labels.ReadOnly,
+				}
+			}
+		}
+		if t.health != nil {
+			if health, ok := t.health.DatabaseHealth(db); ok {
+				details = append(details, describeHealth(health))
+				healthByDB[db] = map[string]interface{}{
+					"state":                health.State,
+					"latency_ms":           health.Latency.Milliseconds(),
+					"last_check":           health.LastCheck,
+					"last_error":           health.LastError,
+					"consecutive_failures": health.ConsecutiveFailures,
+				}
+			}
+		}
+		if len(details) == 0 {
+			output += fmt.Sprintf("%d. %s\n", i+1, db)
+			continue
+		}
+		output += fmt.Sprintf("%d. %s (%s)\n", i+1, db, strings.Join(details, "; "))
 	}
 
 	if len(databases) == 0 {
 		output += "No databases configured.\n"
 	}
 
-	return createTextResponse(output), nil
+	resp := createTextResponse(output)
+	if len(healthByDB) > 0 {
+		resp = addMetadata(resp, "health", healthByDB)
+	}
+	if len(labelsByDB) > 0 {
+		resp = addMetadata(resp, "labels", labelsByDB)
+	}
+	return resp, nil
 }
 
 //------------------------------------------------------------------------------
@@ -669,6 +718,11 @@ func NewToolTypeFactory() *ToolTypeFactory {
 	factory.Register(NewListDatabasesTool())
 	factory.Register(NewListDirectoryTool())
 
+	// Tools of PostgreSQL extensions, registered for databases that have them
+	factory.Register(NewTopQueriesTool())
+	factory.Register(NewVectorSearchTool())
+	factory.Register(NewSpatialQueryTool())
+
 	return factory
 }
 
@@ -679,18 +733,15 @@ func (f *ToolTypeFactory) Register(toolType ToolType) {
 
 // GetToolType returns a tool type by name
 func (f *ToolTypeFactory) GetToolType(name string) (ToolType, bool) {
-	// Handle new simpler format: <tooltype>_<dbID> or just the tool type name
-	parts := strings.Split(name, "_")
-	if len(parts) > 0 {
-		// First part is the tool type name
-		toolType, ok := f.toolTypes[parts[0]]
-		if ok {
-			return toolType, true
-		}
+	// Direct tool type lookup first so names such as list_databases are not
+	// mistaken for the "list" tool type
+	if toolType, ok := f.toolTypes[name]; ok {
+		return toolType, true
 	}
 
-	// Direct tool type lookup
-	toolType, ok := f.toolTypes[name]
+	// Handle new simpler format: <tooltype>_<dbID>
+	parts := strings.Split(name, "_")
+	toolType, ok := f.toolTypes[parts[0]]
 	return toolType, ok
 }
 
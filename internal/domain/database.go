@@ -8,6 +8,9 @@ import (
 // Database represents a database connection and operations
 type Database interface {
 	Query(ctx context.Context, query string, args ...interface{}) (Rows, error)
+	// QueryPrepared runs a query as a prepared statement, which the server
+	// parses as a single statement
+	QueryPrepared(ctx context.Context, query string, args ...interface{}) (Rows, error)
 	Exec(ctx context.Context, statement string, args ...interface{}) (Result, error)
 	Begin(ctx context.Context, opts *TxOptions) (Tx, error)
 }
@@ -110,7 +113,15 @@ type ConstraintInfo struct {
 // DatabaseRepository defines methods for managing database connections
 type DatabaseRepository interface {
 	GetDatabase(id string) (Database, error)
+	// GetReadDatabase returns a database for reads, which may be a replica
+	GetReadDatabase(id string) (Database, error)
+	// InspectDatabase runs fn on a database, without keeping its connection
+	// open when lazy loading is enabled
+	InspectDatabase(id string, fn func(Database) error) error
 	ListDatabases() []string
 	GetDatabaseType(id string) (string, error)
+	// SupportsTransactions reports whether the engine of a database can run
+	// transactions
+	SupportsTransactions(id string) bool
 	IsLazyLoading() bool
 }
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"sync"
+)
+
+// ExecutedStatement records a single SQL statement run on behalf of a tool call
+type ExecutedStatement struct {
+	SQL    string
+	Params []interface{}
+}
+
+// ExecutionStats collects what the database layer did while serving a tool call
+type ExecutionStats struct {
+	mu           sync.Mutex
+	statements   []ExecutedStatement
+	rowsReturned int64
+	rowsAffected int64
+}
+
+type executionStatsKey struct{}
+
+// WithExecutionStats returns a context that carries a fresh ExecutionStats collector
+func WithExecutionStats(ctx context.Context) (context.Context, *ExecutionStats) {
+	stats := &ExecutionStats{}
+	return context.WithValue(ctx, executionStatsKey{}, stats), stats
+}
+
+// ExecutionStatsFromContext returns the collector attached to ctx, or nil if there is none
+func ExecutionStatsFromContext(ctx context.Context) *ExecutionStats {
+	stats, _ := ctx.Value(executionStatsKey{}).(*ExecutionStats)
+	return stats
+}
+
+// AddStatement records a statement executed against the database
+func (s *ExecutionStats) AddStatement(sql string, params []interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.statements = append(s.statements, ExecutedStatement{SQL: sql, Params: params})
+}
+
+// AddRowsReturned adds to the number of rows returned by queries
+func (s *ExecutionStats) AddRowsReturned(n int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rowsReturned += n
+}
+
+// AddRowsAffected adds to the number of rows affected by statements
+func (s *ExecutionStats) AddRowsAffected(n int64) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rowsAffected += n
+}
+
+// Statements returns the statements recorded so far
+func (s *ExecutionStats) Statements() []ExecutedStatement {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]ExecutedStatement(nil), s.statements...)
+}
+
+// RowsReturned returns the number of rows returned by queries
+func (s *ExecutionStats) RowsReturned() int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rowsReturned
+}
+
+// RowsAffected returns the number of rows affected by statements
+func (s *ExecutionStats) RowsAffected() int64 {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.rowsAffected
+}
@@ -39,8 +39,49 @@ var (
 	stdioLogFile *os.File
 	// Mutex to protect log file access
 	logMutex sync.Mutex
+	// Secrets masked in every log message
+	secretsMutex   sync.RWMutex
+	secrets        = make(map[string]bool)
+	secretReplacer *strings.Replacer
 )
 
+// minSecretLength is the shortest secret masked in log messages, shorter
+// values would mask ordinary text
+const minSecretLength = 4
+
+// AddSecret masks a value, such as a resolved database password, in every
+// log message written afterwards
+func AddSecret(secret string) {
+	if len(secret) < minSecretLength {
+		return
+	}
+
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	if secrets[secret] {
+		return
+	}
+	secrets[secret] = true
+
+	pairs := make([]string, 0, 2*len(secrets))
+	for value := range secrets {
+		pairs = append(pairs, value, "********")
+	}
+	secretReplacer = strings.NewReplacer(pairs...)
+}
+
+// Redact masks the secrets registered with AddSecret in a message
+func Redact(message string) string {
+	secretsMutex.RLock()
+	replacer := secretReplacer
+	secretsMutex.RUnlock()
+
+	if replacer == nil {
+		return message
+	}
+	return replacer.Replace(message)
+}
+
 // Config represents logger configuration
 type Config struct {
 	Level  string // Log level (debug, info, warn, error)
@@ -216,7 +257,7 @@ func Debug(format string, v ...interface{}) {
 	if logLevel > LevelDebug {
 		return
 	}
-	msg := fmt.Sprintf(format, v...)
+	msg := Redact(fmt.Sprintf(format, v...))
 	zapLogger.Debug(msg)
 }
 
@@ -228,7 +269,7 @@ func Info(format string, v ...interface{}) {
 	if logLevel > LevelInfo {
 		return
 	}
-	msg := fmt.Sprintf(format, v...)
+	msg := Redact(fmt.Sprintf(format, v...))
 	zapLogger.Info(msg)
 }
 
@@ -240,7 +281,7 @@ func Warn(format string, v ...interface{}) {
 	if logLevel > LevelWarn {
 		return
 	}
-	msg := fmt.Sprintf(format, v...)
+	msg := Redact(fmt.Sprintf(format, v...))
 	zapLogger.Warn(msg)
 }
 
@@ -252,7 +293,7 @@ func Error(format string, v ...interface{}) {
 	if logLevel > LevelError {
 		return
 	}
-	msg := fmt.Sprintf(format, v...)
+	msg := Redact(fmt.Sprintf(format, v...))
 	zapLogger.Error(msg)
 }
 
@@ -262,7 +303,7 @@ func ErrorWithStack(err error) {
 		return
 	}
 	zapLogger.Error(
-		err.Error(),
+		Redact(err.Error()),
 		zap.String("stack", string(debug.Stack())),
 	)
 }
@@ -276,7 +317,7 @@ func RequestLog(method, url, sessionID, body string) {
 		zap.String("method", method),
 		zap.String("url", url),
 		zap.String("sessionID", sessionID),
-		zap.String("body", body),
+		zap.String("body", Redact(body)),
 	)
 }
 
@@ -288,7 +329,7 @@ func ResponseLog(statusCode int, sessionID, body string) {
 	zapLogger.Debug("HTTP Response",
 		zap.Int("statusCode", statusCode),
 		zap.String("sessionID", sessionID),
-		zap.String("body", body),
+		zap.String("body", Redact(body)),
 	)
 }
 
@@ -300,7 +341,7 @@ func SSEEventLog(eventType, sessionID, data string) {
 	zapLogger.Debug("SSE Event",
 		zap.String("eventType", eventType),
 		zap.String("sessionID", sessionID),
-		zap.String("data", data),
+		zap.String("data", Redact(data)),
 	)
 }
 
@@ -336,7 +377,7 @@ func RequestResponseLog(method, sessionID string, requestData, responseData stri
 	zapLogger.Debug("Request/Response",
 		zap.String("method", method),
 		zap.String("sessionID", sessionID),
-		zap.String("request", formattedRequest),
-		zap.String("response", formattedResponse),
+		zap.String("request", Redact(formattedRequest)),
+		zap.String("response", Redact(formattedResponse)),
 	)
 }
@@ -112,6 +112,21 @@ func TestInfo(t *testing.T) {
 	assert.Empty(t, output)
 }
 
+func TestAddSecret(t *testing.T) {
+	zapLogger = zaptest.NewLogger(t)
+	logLevel = LevelInfo
+
+	AddSecret("hunter2-password")
+	AddSecret("abc") // too short to be masked
+
+	output := captureOutput(func() {
+		Info("connecting with %s and %s", "hunter2-password", "abc")
+	})
+	assert.NotContains(t, output, "hunter2-password")
+	assert.Contains(t, output, "connecting with ******** and abc")
+	assert.Equal(t, "dsn=user:********@host", Redact("dsn=user:hunter2-password@host"))
+}
+
 func TestWarn(t *testing.T) {
 	// Setup test logger
 	zapLogger = zaptest.NewLogger(t)
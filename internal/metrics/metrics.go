@@ -0,0 +1,233 @@
+// Package metrics keeps simple counters and gauges and exposes them in the
+// Prometheus text exposition format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Default is the registry used by the server and served on /metrics
+var Default = NewRegistry()
+
+// Registry holds a set of metric families
+type Registry struct {
+	mu       sync.RWMutex
+	families map[string]*family
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]*family)}
+}
+
+// family is a metric with a fixed set of label names and one value per label combination
+type family struct {
+	name       string
+	help       string
+	kind       string // counter or gauge
+	labelNames []string
+
+	mu      sync.Mutex
+	values  map[string]float64
+	labels  map[string][]string
+	collect func() map[string]float64 // optional, for metrics read on demand
+}
+
+// Counter is a monotonically increasing metric
+type Counter struct{ f *family }
+
+// Gauge is a metric that can go up and down
+type Gauge struct{ f *family }
+
+// Counter returns the counter with the given name, creating it if needed
+func (r *Registry) Counter(name, help string, labelNames ...string) *Counter {
+	return &Counter{f: r.family(name, help, "counter", labelNames)}
+}
+
+// Gauge returns the gauge with the given name, creating it if needed
+func (r *Registry) Gauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{f: r.family(name, help, "gauge", labelNames)}
+}
+
+// GaugeFunc registers a gauge whose values are read from collect at scrape
+// time. collect returns values keyed by the value of the single label.
+func (r *Registry) GaugeFunc(name, help, labelName string, collect func() map[string]float64) {
+	r.collectFunc(name, help, "gauge", labelName, collect)
+}
+
+// CounterFunc registers a counter whose values are read from collect at
+// scrape time, for totals kept elsewhere. collect returns values keyed by the
+// value of the single label.
+func (r *Registry) CounterFunc(name, help, labelName string, collect func() map[string]float64) {
+	r.collectFunc(name, help, "counter", labelName, collect)
+}
+
+// collectFunc registers a metric family read from collect at scrape time
+func (r *Registry) collectFunc(name, help, kind, labelName string, collect func() map[string]float64) {
+	f := r.family(name, help, kind, []string{labelName})
+	f.mu.Lock()
+	f.collect = collect
+	f.mu.Unlock()
+}
+
+// family returns an existing metric family or registers a new one
+func (r *Registry) family(name, help, kind string, labelNames []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if f, ok := r.families[name]; ok {
+		return f
+	}
+	f := &family{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		values:     make(map[string]float64),
+		labels:     make(map[string][]string),
+	}
+	r.families[name] = f
+	return f
+}
+
+// Inc adds one to the counter for the given label values
+func (c *Counter) Inc(labelValues ...string) {
+	c.f.add(1, labelValues)
+}
+
+// Add adds v to the counter for the given label values
+func (c *Counter) Add(v float64, labelValues ...string) {
+	c.f.add(v, labelValues)
+}
+
+// Set sets the gauge for the given label values
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.mu.Lock()
+	defer g.f.mu.Unlock()
+	key := g.f.key(labelValues)
+	g.f.values[key] = v
+}
+
+// Add adds v (which may be negative) to the gauge for the given label values
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.add(v, labelValues)
+}
+
+// Value returns the current gauge value for the given label values
+func (g *Gauge) Value(labelValues ...string) float64 {
+	return g.f.value(labelValues)
+}
+
+// Value returns the current counter value for the given label values
+func (c *Counter) Value(labelValues ...string) float64 {
+	return c.f.value(labelValues)
+}
+
+func (f *family) add(v float64, labelValues []string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	key := f.key(labelValues)
+	f.values[key] += v
+}
+
+func (f *family) value(labelValues []string) float64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.values[strings.Join(labelValues, "\xff")]
+}
+
+// key records the label values and returns the map key for them; callers hold f.mu
+func (f *family) key(labelValues []string) string {
+	key := strings.Join(labelValues, "\xff")
+	if _, ok := f.labels[key]; !ok {
+		f.labels[key] = append([]string(nil), labelValues...)
+	}
+	return key
+}
+
+// WritePrometheus writes all metrics in the Prometheus text format
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.RLock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	r.mu.RUnlock()
+	sort.Strings(names)
+
+	for _, name := range names {
+		r.mu.RLock()
+		f := r.families[name]
+		r.mu.RUnlock()
+		if err := f.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (f *family) write(w io.Writer) error {
+	f.mu.Lock()
+	values := make(map[string]float64, len(f.values))
+	labels := make(map[string][]string, len(f.labels))
+	for k, v := range f.values {
+		values[k] = v
+		labels[k] = f.labels[k]
+	}
+	collect := f.collect
+	f.mu.Unlock()
+
+	if collect != nil {
+		for labelValue, v := range collect() {
+			values[labelValue] = v
+			labels[labelValue] = []string{labelValue}
+		}
+	}
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if _, err := fmt.Fprintf(w, "%s%s %g\n", f.name, formatLabels(f.labelNames, labels[k]), values[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatLabels renders {name="value",...} for a sample
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		value = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`).Replace(value)
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, value))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Handler serves the registry in the Prometheus text format
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = r.WritePrometheus(w)
+	})
+}
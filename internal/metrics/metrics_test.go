@@ -0,0 +1,55 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	r := NewRegistry()
+	calls := r.Counter("test_calls_total", "Calls", "tool")
+	calls.Inc("query")
+	calls.Add(2, "query")
+	calls.Inc(`we"ird`)
+	assert.Equal(t, float64(3), calls.Value("query"))
+
+	inflight := r.Gauge("test_inflight", "In flight")
+	inflight.Add(2)
+	inflight.Add(-1)
+	assert.Equal(t, float64(1), inflight.Value())
+
+	r.GaugeFunc("test_open", "Open connections", "database", func() map[string]float64 {
+		return map[string]float64{"db1": 4}
+	})
+	r.CounterFunc("test_waits_total", "Waits", "database", func() map[string]float64 {
+		return map[string]float64{"db1": 7}
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, r.WritePrometheus(&buf))
+	out := buf.String()
+
+	assert.Contains(t, out, "# TYPE test_calls_total counter\n")
+	assert.Contains(t, out, `test_calls_total{tool="query"} 3`)
+	assert.Contains(t, out, `test_calls_total{tool="we\"ird"} 1`)
+	assert.Contains(t, out, "# TYPE test_inflight gauge\ntest_inflight 1\n")
+	assert.Contains(t, out, `test_open{database="db1"} 4`)
+	assert.Contains(t, out, "# TYPE test_waits_total counter\ntest_waits_total{database=\"db1\"} 7\n")
+
+	// Registering the same name again returns the existing metric
+	assert.Equal(t, float64(3), r.Counter("test_calls_total", "Calls", "tool").Value("query"))
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("test_total", "Test").Inc()
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Contains(t, rec.Body.String(), "test_total 1")
+}
@@ -0,0 +1,252 @@
+// Package ratelimit protects databases from bursts of tool calls with token
+// bucket rate limits and caps on concurrent queries, per session and per
+// database connection.
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/metrics"
+)
+
+// Limit describes a rate and concurrency limit. Zero values disable the
+// corresponding check.
+type Limit struct {
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty"` // sustained rate of tool calls
+	Burst             int     `json:"burst,omitempty"`               // bucket size (default: one second worth of requests)
+	MaxConcurrent     int     `json:"max_concurrent,omitempty"`      // tool calls allowed to run at the same time
+}
+
+// isZero reports whether the limit disables both checks
+func (l Limit) isZero() bool {
+	return l.RequestsPerSecond <= 0 && l.MaxConcurrent <= 0
+}
+
+// Config holds the rate limit configuration (the "rate_limit" section of config.json)
+type Config struct {
+	Enabled    bool  `json:"enabled"`
+	Session    Limit `json:"session"`    // applied to every MCP session
+	Connection Limit `json:"connection"` // default for database connections without their own rate_limit
+}
+
+// concurrencyRetryAfter is the retry hint given when a concurrency limit is hit
+const concurrencyRetryAfter = time.Second
+
+// idleTimeout is how long an unused session or connection state is kept
+const idleTimeout = 10 * time.Minute
+
+// Error is returned when a limit is exceeded
+type Error struct {
+	Scope      string // "session" or "database"
+	Key        string // session ID or database ID
+	Reason     string // "rate" or "concurrency"
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *Error) Error() string {
+	kind := "rate limit"
+	if e.Reason == "concurrency" {
+		kind = "concurrent query limit"
+	}
+	target := e.Scope
+	if e.Scope == "database" {
+		target = "database " + e.Key
+	}
+	return fmt.Sprintf("%s exceeded for %s, retry after %s", kind, target, e.RetryAfter.Round(time.Millisecond))
+}
+
+var (
+	rejectedTotal = metrics.Default.Counter("mcp_rate_limited_total",
+		"Tool calls rejected by rate or concurrency limits", "scope", "reason")
+	inflightGauge = metrics.Default.Gauge("mcp_inflight_tool_calls",
+		"Tool calls currently running per database", "database")
+)
+
+// state tracks a token bucket and the number of in-flight calls for one key
+type state struct {
+	limit    Limit
+	tokens   float64
+	refilled time.Time // when tokens were last refilled
+	lastUsed time.Time // when a call last started or finished
+	inflight int
+}
+
+// Limiter enforces session and connection limits
+type Limiter struct {
+	mu          sync.Mutex
+	cfg         Config
+	connLimits  map[string]Limit
+	sessions    map[string]*state
+	connections map[string]*state
+	lastPrune   time.Time
+	now         func() time.Time
+}
+
+// New creates a limiter. connLimits holds per-connection overrides of cfg.Connection.
+func New(cfg Config, connLimits map[string]Limit) *Limiter {
+	return &Limiter{
+		cfg:         cfg,
+		connLimits:  connLimits,
+		sessions:    make(map[string]*state),
+		connections: make(map[string]*state),
+		now:         time.Now,
+	}
+}
+
+// SetConnectionLimits replaces the per-connection overrides. Existing
+// connection states are kept, so calls in flight still count against the
+// new concurrency limits.
+func (l *Limiter) SetConnectionLimits(connLimits map[string]Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.connLimits = connLimits
+
+	now := l.now()
+	for dbID, s := range l.connections {
+		limit := l.connectionLimit(dbID)
+		switch {
+		case limit.RequestsPerSecond <= 0:
+		case s.limit.RequestsPerSecond <= 0:
+			// A new rate limit starts with a full bucket
+			s.tokens = float64(burst(limit))
+		default:
+			// Refill at the old rate, then cap at the new bucket size
+			elapsed := now.Sub(s.refilled).Seconds()
+			s.tokens = math.Min(float64(burst(limit)), s.tokens+elapsed*s.limit.RequestsPerSecond)
+		}
+		s.refilled = now
+		s.limit = limit
+	}
+}
+
+// connectionLimit returns the effective limit for a database; callers hold l.mu
+func (l *Limiter) connectionLimit(dbID string) Limit {
+	if limit, ok := l.connLimits[dbID]; ok {
+		return limit
+	}
+	return l.cfg.Connection
+}
+
+// Acquire admits a tool call for a session and database. Either may be
+// empty. On success the returned release function must be called when the
+// call finishes.
+func (l *Limiter) Acquire(sessionID, dbID string) (func(), error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.prune(now)
+
+	var sessionState, connState *state
+	if sessionID != "" && !l.cfg.Session.isZero() {
+		sessionState = l.stateFor(l.sessions, sessionID, l.cfg.Session, now)
+	}
+	if dbID != "" {
+		if limit := l.connectionLimit(dbID); !limit.isZero() {
+			connState = l.stateFor(l.connections, dbID, limit, now)
+		}
+	}
+
+	// Check every limit before consuming anything so a rejected call costs nothing
+	if err := check(sessionState, "session", sessionID); err != nil {
+		return nil, err
+	}
+	if err := check(connState, "database", dbID); err != nil {
+		return nil, err
+	}
+
+	for _, s := range []*state{sessionState, connState} {
+		if s == nil {
+			continue
+		}
+		if s.limit.RequestsPerSecond > 0 {
+			s.tokens--
+		}
+		s.inflight++
+	}
+	if dbID != "" {
+		inflightGauge.Add(1, dbID)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			for _, s := range []*state{sessionState, connState} {
+				if s != nil {
+					s.inflight--
+					s.lastUsed = l.now()
+				}
+			}
+			if dbID != "" {
+				inflightGauge.Add(-1, dbID)
+			}
+		})
+	}, nil
+}
+
+// stateFor returns the state for a key, refilling its bucket; callers hold l.mu
+func (l *Limiter) stateFor(states map[string]*state, key string, limit Limit, now time.Time) *state {
+	s, ok := states[key]
+	if !ok {
+		s = &state{limit: limit, tokens: float64(burst(limit)), refilled: now, lastUsed: now}
+		states[key] = s
+		return s
+	}
+
+	if limit.RequestsPerSecond > 0 {
+		elapsed := now.Sub(s.refilled).Seconds()
+		s.tokens = math.Min(float64(burst(limit)), s.tokens+elapsed*limit.RequestsPerSecond)
+	}
+	s.refilled = now
+	s.lastUsed = now
+	return s
+}
+
+// check reports whether a call fits within a state's limits
+func check(s *state, scope, key string) error {
+	if s == nil {
+		return nil
+	}
+
+	if s.limit.MaxConcurrent > 0 && s.inflight >= s.limit.MaxConcurrent {
+		rejectedTotal.Inc(scope, "concurrency")
+		return &Error{Scope: scope, Key: key, Reason: "concurrency", RetryAfter: concurrencyRetryAfter}
+	}
+
+	if s.limit.RequestsPerSecond > 0 && s.tokens < 1 {
+		rejectedTotal.Inc(scope, "rate")
+		wait := time.Duration((1 - s.tokens) / s.limit.RequestsPerSecond * float64(time.Second))
+		return &Error{Scope: scope, Key: key, Reason: "rate", RetryAfter: wait}
+	}
+	return nil
+}
+
+// burst returns the bucket size for a limit
+func burst(limit Limit) int {
+	if limit.Burst > 0 {
+		return limit.Burst
+	}
+	return int(math.Max(1, math.Ceil(limit.RequestsPerSecond)))
+}
+
+// prune forgets idle sessions and connections; callers hold l.mu
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.lastPrune) < time.Minute {
+		return
+	}
+	l.lastPrune = now
+
+	for _, states := range []map[string]*state{l.sessions, l.connections} {
+		for key, s := range states {
+			if s.inflight == 0 && now.Sub(s.lastUsed) > idleTimeout {
+				delete(states, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a manually advanced clock
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func newTestLimiter(cfg Config, connLimits map[string]Limit) (*Limiter, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}
+	l := New(cfg, connLimits)
+	l.now = clock.now
+	return l, clock
+}
+
+func TestTokenBucket(t *testing.T) {
+	l, clock := newTestLimiter(Config{Enabled: true, Session: Limit{RequestsPerSecond: 2, Burst: 2}}, nil)
+
+	for i := 0; i < 2; i++ {
+		release, err := l.Acquire("s1", "")
+		require.NoError(t, err)
+		release()
+	}
+
+	_, err := l.Acquire("s1", "")
+	var limitErr *Error
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "session", limitErr.Scope)
+	assert.Equal(t, "rate", limitErr.Reason)
+	assert.Equal(t, 500*time.Millisecond, limitErr.RetryAfter)
+	assert.Contains(t, err.Error(), "retry after 500ms")
+
+	// Other sessions have their own bucket
+	release, err := l.Acquire("s2", "")
+	require.NoError(t, err)
+	release()
+
+	// Tokens refill over time
+	clock.t = clock.t.Add(500 * time.Millisecond)
+	release, err = l.Acquire("s1", "")
+	require.NoError(t, err)
+	release()
+}
+
+func TestConcurrencyLimitPerConnection(t *testing.T) {
+	l, _ := newTestLimiter(Config{Enabled: true, Connection: Limit{MaxConcurrent: 1}},
+		map[string]Limit{"big": {MaxConcurrent: 2}})
+
+	release, err := l.Acquire("s1", "small")
+	require.NoError(t, err)
+
+	_, err = l.Acquire("s2", "small")
+	var limitErr *Error
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "database", limitErr.Scope)
+	assert.Equal(t, "concurrency", limitErr.Reason)
+	assert.Contains(t, err.Error(), "database small")
+
+	// The per-connection override allows two at a time
+	r1, err := l.Acquire("s1", "big")
+	require.NoError(t, err)
+	r2, err := l.Acquire("s2", "big")
+	require.NoError(t, err)
+	_, err = l.Acquire("s3", "big")
+	assert.Error(t, err)
+	r1()
+	r2()
+
+	// Releasing frees the slot, and releasing twice has no effect
+	release()
+	release()
+	r3, err := l.Acquire("s2", "small")
+	require.NoError(t, err)
+	_, err = l.Acquire("s3", "small")
+	assert.Error(t, err)
+	r3()
+}
+
+func TestSetConnectionLimitsKeepsInflightCalls(t *testing.T) {
+	l, _ := newTestLimiter(Config{Enabled: true}, map[string]Limit{"db1": {MaxConcurrent: 2}})
+
+	r1, err := l.Acquire("s1", "db1")
+	require.NoError(t, err)
+	r2, err := l.Acquire("s2", "db1")
+	require.NoError(t, err)
+
+	// Lowering the limit while calls run counts them against the new limit
+	l.SetConnectionLimits(map[string]Limit{"db1": {MaxConcurrent: 1, RequestsPerSecond: 1}})
+	_, err = l.Acquire("s3", "db1")
+	var limitErr *Error
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "concurrency", limitErr.Reason)
+
+	r1()
+	_, err = l.Acquire("s3", "db1")
+	require.Error(t, err)
+	r2()
+
+	// The new rate limit starts with a full bucket
+	release, err := l.Acquire("s3", "db1")
+	require.NoError(t, err)
+	release()
+	_, err = l.Acquire("s3", "db1")
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, "rate", limitErr.Reason)
+}
+
+func TestRejectedCallsConsumeNothing(t *testing.T) {
+	l, _ := newTestLimiter(Config{
+		Enabled:    true,
+		Session:    Limit{RequestsPerSecond: 1, Burst: 1},
+		Connection: Limit{MaxConcurrent: 1},
+	}, nil)
+
+	hold, err := l.Acquire("s1", "db1")
+	require.NoError(t, err)
+
+	// s2 is rejected by the connection limit and keeps its session token
+	_, err = l.Acquire("s2", "db1")
+	require.Error(t, err)
+	hold()
+
+	release, err := l.Acquire("s2", "db1")
+	require.NoError(t, err)
+	release()
+}
+
+func TestPruneIdleState(t *testing.T) {
+	l, clock := newTestLimiter(Config{Enabled: true, Session: Limit{RequestsPerSecond: 1}}, nil)
+
+	release, err := l.Acquire("s1", "")
+	require.NoError(t, err)
+	release()
+	assert.Len(t, l.sessions, 1)
+
+	clock.t = clock.t.Add(idleTimeout + time.Minute)
+	release, err = l.Acquire("s2", "")
+	require.NoError(t, err)
+	release()
+	assert.Len(t, l.sessions, 1)
+	assert.Contains(t, l.sessions, "s2")
+}
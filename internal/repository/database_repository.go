@@ -6,6 +6,7 @@ import (
 	"database/sql"
 
 	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
 	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
 )
 
@@ -32,6 +33,24 @@ func (r *DatabaseRepository) GetDatabase(id string) (domain.Database, error) {
 	return &DatabaseAdapter{db: db}, nil
 }
 
+// GetReadDatabase retrieves a database for reads by ID, a read replica when
+// the connection has a healthy one
+func (r *DatabaseRepository) GetReadDatabase(id string) (domain.Database, error) {
+	db, err := dbtools.GetReadDatabase(id)
+	if err != nil {
+		return nil, err
+	}
+	return &DatabaseAdapter{db: db}, nil
+}
+
+// InspectDatabase runs fn on a database, with a temporary connection when
+// lazy loading has not opened it yet
+func (r *DatabaseRepository) InspectDatabase(id string, fn func(domain.Database) error) error {
+	return dbtools.InspectDatabase(id, func(db db.Database) error {
+		return fn(&DatabaseAdapter{db: db})
+	})
+}
+
 // ListDatabases returns a list of available database IDs
 func (r *DatabaseRepository) ListDatabases() []string {
 	return dbtools.ListDatabases()
@@ -45,6 +64,12 @@ func (r *DatabaseRepository) GetDatabaseType(id string) (string, error) {
 	return dbtools.GetDatabaseType(id)
 }
 
+// SupportsTransactions reports whether the engine of a database can run
+// transactions, without establishing a connection
+func (r *DatabaseRepository) SupportsTransactions(id string) bool {
+	return dbtools.SupportsTransactions(id)
+}
+
 // IsLazyLoading returns whether lazy loading mode is enabled
 func (r *DatabaseRepository) IsLazyLoading() bool {
 	return dbtools.IsLazyLoading()
@@ -56,6 +81,7 @@ type DatabaseAdapter struct {
 		Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 		Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+		DB() *sql.DB
 	}
 }
 
@@ -68,6 +94,20 @@ func (a *DatabaseAdapter) Query(ctx context.Context, query string, args ...inter
 	return &RowsAdapter{rows: rows}, nil
 }
 
+// QueryPrepared executes a query on the database as a prepared statement
+func (a *DatabaseAdapter) QueryPrepared(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
+	stmt, err := a.db.DB().PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := stmt.QueryContext(ctx, args...)
+	if err != nil {
+		_ = stmt.Close()
+		return nil, err
+	}
+	return &RowsAdapter{rows: rows, stmt: stmt}, nil
+}
+
 // Exec executes a statement on the database
 func (a *DatabaseAdapter) Exec(ctx context.Context, statement string, args ...interface{}) (domain.Result, error) {
 	result, err := a.db.Exec(ctx, statement, args...)
@@ -94,11 +134,18 @@ func (a *DatabaseAdapter) Begin(ctx context.Context, opts *domain.TxOptions) (do
 // RowsAdapter adapts sql.Rows to domain.Rows
 type RowsAdapter struct {
 	rows *sql.Rows
+	stmt *sql.Stmt // closed with the rows, if they come from a prepared statement
 }
 
 // Close closes the rows
 func (a *RowsAdapter) Close() error {
-	return a.rows.Close()
+	err := a.rows.Close()
+	if a.stmt != nil {
+		if stmtErr := a.stmt.Close(); err == nil {
+			err = stmtErr
+		}
+	}
+	return err
 }
 
 // Columns returns the column names
@@ -9,6 +9,7 @@ import (
 
 	"github.com/FreePeak/db-mcp-server/internal/domain"
 	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
 )
 
 // TODO: Improve error handling with custom error types and better error messages
@@ -22,60 +23,19 @@ type QueryFactory interface {
 	GetTablesQueries() []string
 }
 
-// PostgresQueryFactory creates queries for PostgreSQL
-type PostgresQueryFactory struct{}
-
-// GetTablesQueries returns table queries for PostgreSQL
-func (f *PostgresQueryFactory) GetTablesQueries() []string {
-	return []string{
-		// Primary PostgreSQL query using pg_catalog (most reliable)
-		"SELECT tablename AS table_name FROM pg_catalog.pg_tables WHERE schemaname = 'public'",
-		// Fallback 1: Using information_schema
-		"SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'",
-		// Fallback 2: Using pg_class for relations
-		"SELECT relname AS table_name FROM pg_catalog.pg_class WHERE relkind = 'r' AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public')",
-	}
+// dialectQueryFactory creates queries from the catalog queries of a dialect
+type dialectQueryFactory struct {
+	dialect dialect.Dialect
 }
 
-// MySQLQueryFactory creates queries for MySQL
-type MySQLQueryFactory struct{}
-
-// GetTablesQueries returns table queries for MySQL
-func (f *MySQLQueryFactory) GetTablesQueries() []string {
-	return []string{
-		// Primary MySQL query
-		"SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()",
-		// Fallback MySQL query
-		"SHOW TABLES",
-	}
-}
-
-// OracleQueryFactory creates queries for Oracle
-type OracleQueryFactory struct{}
-
-// GetTablesQueries returns table queries for Oracle
-func (f *OracleQueryFactory) GetTablesQueries() []string {
-	return []string{
-		// User's own tables (most common)
-		"SELECT table_name FROM user_tables ORDER BY table_name",
-		// All tables accessible to user
-		"SELECT table_name FROM all_tables WHERE owner NOT IN ('SYS', 'SYSTEM', 'OUTLN', 'XDB', 'CTXSYS', 'MDSYS', 'WMSYS', 'ORDSYS', 'ORDDATA', 'APEX_030200', 'APEX_040000', 'APEX_040100', 'APEX_040200') ORDER BY table_name",
-		// All tables with owner prefix
-		"SELECT owner || '.' || table_name AS table_name FROM all_tables WHERE owner NOT IN ('SYS', 'SYSTEM') ORDER BY owner, table_name",
-	}
-}
-
-// SQLiteQueryFactory creates queries for SQLite (including CozoDB with SQLite storage)
-type SQLiteQueryFactory struct{}
-
-// GetTablesQueries returns table queries for SQLite
-func (f *SQLiteQueryFactory) GetTablesQueries() []string {
-	return []string{
-		// Primary: sqlite_master approach for user tables
-		"SELECT name AS table_name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'",
-		// Fallback: pragma_table_list approach
-		"SELECT name AS table_name FROM pragma_table_list() WHERE type='table' AND schema='main' AND name NOT LIKE 'sqlite_%'",
+// GetTablesQueries returns the table queries of the dialect
+func (f *dialectQueryFactory) GetTablesQueries() []string {
+	queries := f.dialect.TablesQueries()
+	tableQueries := make([]string, len(queries))
+	for i, q := range queries {
+		tableQueries[i] = q.SQL
 	}
+	return tableQueries
 }
 
 // GenericQueryFactory creates generic queries for unknown database types
@@ -89,21 +49,15 @@ func (f *GenericQueryFactory) GetTablesQueries() []string {
 	}
 }
 
-// NewQueryFactory creates the appropriate query factory for the database type
+// NewQueryFactory creates the query factory for the database type from its
+// registered dialect
 func NewQueryFactory(dbType string) QueryFactory {
-	switch dbType {
-	case "postgres":
-		return &PostgresQueryFactory{}
-	case "mysql":
-		return &MySQLQueryFactory{}
-	case "oracle":
-		return &OracleQueryFactory{}
-	case "sqlite", "sqlite3":
-		return &SQLiteQueryFactory{}
-	default:
+	d, ok := dialect.Lookup(dbType)
+	if !ok {
 		logger.Warn("Unknown database type: %s, will use generic query factory", dbType)
 		return &GenericQueryFactory{}
 	}
+	return &dialectQueryFactory{dialect: d}
 }
 
 // executeQueriesWithFallback tries multiple queries until one succeeds
@@ -144,8 +98,8 @@ func (uc *DatabaseUseCase) ListDatabases() []string {
 
 // GetDatabaseInfo returns information about a database
 func (uc *DatabaseUseCase) GetDatabaseInfo(dbID string) (map[string]interface{}, error) {
-	// Get database connection
-	db, err := uc.repo.GetDatabase(dbID)
+	// Get database connection, schema reads may use a replica
+	db, err := uc.repo.GetReadDatabase(dbID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database: %w", err)
 	}
@@ -223,13 +177,17 @@ func (uc *DatabaseUseCase) GetDatabaseInfo(dbID string) (map[string]interface{},
 	return result, nil
 }
 
-// ExecuteQuery executes a SQL query and returns the formatted results
+// ExecuteQuery executes a SQL query and returns the formatted results. Queries
+// may run on a read replica, statements and transactions use the primary.
 func (uc *DatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error) {
-	db, err := uc.repo.GetDatabase(dbID)
+	db, err := uc.repo.GetReadDatabase(dbID)
 	if err != nil {
 		return "", fmt.Errorf("failed to get database: %w", err)
 	}
 
+	stats := domain.ExecutionStatsFromContext(ctx)
+	stats.AddStatement(query, params)
+
 	// Execute query
 	rows, err := db.Query(ctx, query, params...)
 	if err != nil {
@@ -291,6 +249,7 @@ func (uc *DatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string,
 		return "", fmt.Errorf("error reading rows: %w", err)
 	}
 
+	stats.AddRowsReturned(int64(rowCount))
 	resultText.WriteString(fmt.Sprintf("\nTotal rows: %d", rowCount))
 	return resultText.String(), nil
 }
@@ -302,6 +261,9 @@ func (uc *DatabaseUseCase) ExecuteStatement(ctx context.Context, dbID, statement
 		return "", fmt.Errorf("failed to get database: %w", err)
 	}
 
+	stats := domain.ExecutionStatsFromContext(ctx)
+	stats.AddStatement(statement, params)
+
 	// Execute statement
 	result, err := db.Exec(ctx, statement, params...)
 	if err != nil {
@@ -313,6 +275,7 @@ func (uc *DatabaseUseCase) ExecuteStatement(ctx context.Context, dbID, statement
 	if err != nil {
 		rowsAffected = 0
 	}
+	stats.AddRowsAffected(rowsAffected)
 
 	// Get last insert ID (if applicable)
 	lastInsertID, err := result.LastInsertId()
@@ -329,6 +292,10 @@ func (uc *DatabaseUseCase) ExecuteTransaction(ctx context.Context, dbID, action
 
 	switch action {
 	case "begin":
+		if !uc.SupportsTransactions(dbID) {
+			dbType, _ := uc.repo.GetDatabaseType(dbID)
+			return "", nil, fmt.Errorf("%s databases do not support transactions", dbType)
+		}
 		db, err := uc.repo.GetDatabase(dbID)
 		if err != nil {
 			return "", nil, fmt.Errorf("failed to get database: %w", err)
@@ -374,11 +341,47 @@ func timeNowUnix() int64 {
 	return time.Now().Unix()
 }
 
+// ListExtensions returns the extensions installed in a PostgreSQL database,
+// in order. With lazy loading the connection is not kept open.
+func (uc *DatabaseUseCase) ListExtensions(ctx context.Context, dbID string) ([]string, error) {
+	var extensions []string
+	err := uc.repo.InspectDatabase(dbID, func(db domain.Database) error {
+		rows, err := db.Query(ctx, "SELECT extname FROM pg_extension ORDER BY extname")
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if closeErr := rows.Close(); closeErr != nil {
+				logger.Error("error closing rows: %v", closeErr)
+			}
+		}()
+
+		for rows.Next() {
+			var name string
+			if err := rows.Scan(&name); err != nil {
+				return err
+			}
+			extensions = append(extensions, name)
+		}
+		return rows.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list extensions: %w", err)
+	}
+	return extensions, nil
+}
+
 // GetDatabaseType returns the type of a database by ID
 func (uc *DatabaseUseCase) GetDatabaseType(dbID string) (string, error) {
 	return uc.repo.GetDatabaseType(dbID)
 }
 
+// SupportsTransactions reports whether the engine of a database can run
+// transactions
+func (uc *DatabaseUseCase) SupportsTransactions(dbID string) bool {
+	return uc.repo.SupportsTransactions(dbID)
+}
+
 // IsLazyLoading returns whether lazy loading mode is enabled
 func (uc *DatabaseUseCase) IsLazyLoading() bool {
 	return uc.repo.IsLazyLoading()
@@ -0,0 +1,241 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// backupTimeFormat is the timestamp in the names of backup files, sortable
+// and without characters that are special in paths
+const backupTimeFormat = "20060102T150405.000Z"
+
+// BackupConfig configures SQLite backups from the "backup" section of the
+// config file
+type BackupConfig struct {
+	Dir string `json:"dir"` // directory of the backup files, relative to the config file, defaults to "backups"
+}
+
+// Backup is a backup file of a SQLite database
+type Backup struct {
+	ID        string    // connection the backup was taken from
+	File      string    // path of the backup file
+	Size      int64     // in bytes
+	Integrity string    // result of PRAGMA integrity_check, "ok" when intact; empty when not checked
+	CreatedAt time.Time // when the backup was taken
+}
+
+// Backup writes a consistent copy of a SQLite database into dir with VACUUM
+// INTO, named after the connection and the time, and checks the integrity of
+// the copy. Attached databases are not included.
+func (m *Manager) Backup(ctx context.Context, id, dir string) (Backup, error) {
+	// The ID is part of the file name
+	if err := validateConnectionID(id); err != nil {
+		return Backup{}, err
+	}
+	cfg, err := m.GetRawDatabaseConfig(id)
+	if err != nil {
+		return Backup{}, err
+	}
+	if cfg.Type != "sqlite" {
+		return Backup{}, fmt.Errorf("backups are only supported for SQLite databases, %s is %s", id, cfg.Type)
+	}
+
+	database, release, err := m.AcquireDatabase(id)
+	if err != nil {
+		return Backup{}, err
+	}
+	defer release()
+
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return Backup{}, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	created := time.Now().UTC()
+	file := filepath.Join(dir, fmt.Sprintf("%s-%s.db", id, created.Format(backupTimeFormat)))
+	if _, err := database.Exec(ctx, "VACUUM INTO ?", file); err != nil {
+		return Backup{}, fmt.Errorf("failed to back up %s: %w", id, err)
+	}
+
+	backup := Backup{ID: id, File: file, CreatedAt: created}
+	if info, err := os.Stat(file); err == nil {
+		backup.Size = info.Size()
+	}
+
+	backup.Integrity, err = checkIntegrity(ctx, cfg, file)
+	if err != nil {
+		return backup, fmt.Errorf("backup %s was written but not checked: %w", file, err)
+	}
+	if backup.Integrity != "ok" {
+		logger.Warn("Backup %s of %s failed the integrity check: %s", file, id, backup.Integrity)
+	}
+	return backup, nil
+}
+
+// checkIntegrity runs PRAGMA integrity_check on a backup file, opened
+// read-only with the settings of its connection, such as the encryption key
+func checkIntegrity(ctx context.Context, cfg DatabaseConnectionConfig, file string) (string, error) {
+	check := cfg
+	check.ID = cfg.ID + "-backup"
+	check.DatabasePath = file
+	check.ReadOnly = true
+	check.Attach = nil
+	check.MaxOpenConns = 1
+	check.MaxIdleConns = 1
+
+	database, err := createAndConnectDatabase(check.ID, check)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := database.Close(); err != nil {
+			logger.Error("Failed to close backup %s: %v", file, err)
+		}
+	}()
+
+	rows, err := database.Query(ctx, "PRAGMA integrity_check")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	var problems []string
+	for rows.Next() {
+		var problem string
+		if err := rows.Scan(&problem); err != nil {
+			return "", err
+		}
+		problems = append(problems, problem)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	return strings.Join(problems, "; "), nil
+}
+
+// ListBackups returns the backup files of a connection in dir, newest first
+func ListBackups(id, dir string) ([]Backup, error) {
+	if err := validateConnectionID(id); err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	var backups []Backup
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, id+"-") || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		created, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, id+"-"), ".db"))
+		if err != nil {
+			continue // another connection whose ID starts with id-
+		}
+		backup := Backup{ID: id, File: filepath.Join(dir, name), CreatedAt: created}
+		if info, err := entry.Info(); err == nil {
+			backup.Size = info.Size()
+		}
+		backups = append(backups, backup)
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// RestoreBackup copies a backup of a connection to dir/restored/<newID>.db and
+// returns the settings of a new connection opening the copy, based on those
+// of the original connection. The backup is a file name from ListBackups, or
+// empty for the newest one. The caller adds the connection.
+func (m *Manager) RestoreBackup(id, backup, newID, dir string) (DatabaseConnectionConfig, error) {
+	cfg, err := m.GetRawDatabaseConfig(id)
+	if err != nil {
+		return cfg, err
+	}
+	if newID == "" {
+		return cfg, fmt.Errorf("an ID for the restored connection is required")
+	}
+	if err := validateConnectionID(newID); err != nil {
+		return cfg, err
+	}
+	if _, err := m.GetRawDatabaseConfig(newID); err == nil {
+		return cfg, fmt.Errorf("database connection %s already exists", newID)
+	}
+
+	backups, err := ListBackups(id, dir)
+	if err != nil {
+		return cfg, err
+	}
+	var source string
+	for _, b := range backups {
+		if backup == "" || filepath.Base(b.File) == backup {
+			source = b.File
+			break
+		}
+	}
+	if source == "" {
+		if backup == "" {
+			return cfg, fmt.Errorf("no backups of %s in %s", id, dir)
+		}
+		return cfg, fmt.Errorf("backup %s of %s not found in %s", backup, id, dir)
+	}
+
+	target := filepath.Join(dir, "restored", newID+".db")
+	if err := copyNewFile(source, target); err != nil {
+		return cfg, fmt.Errorf("failed to restore %s: %w", filepath.Base(source), err)
+	}
+
+	restored := cfg
+	restored.ID = newID
+	restored.DatabasePath = target
+	restored.Attach = nil
+	return restored, nil
+}
+
+// copyNewFile copies a file to a path that must not exist yet
+func copyNewFile(source, target string) (err error) {
+	if err := os.MkdirAll(filepath.Dir(target), 0o750); err != nil {
+		return err
+	}
+
+	in, err := os.Open(source)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = in.Close()
+	}()
+
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			_ = os.Remove(target)
+		}
+	}()
+
+	_, err = io.Copy(out, in)
+	return err
+}
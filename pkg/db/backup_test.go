@@ -0,0 +1,102 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	initLoggerForTests()
+
+	tempDir := t.TempDir()
+	backupDir := filepath.Join(tempDir, "backups")
+	ctx := context.Background()
+
+	manager := NewDBManager()
+	app := DatabaseConnectionConfig{ID: "app", Type: "sqlite", DatabasePath: filepath.Join(tempDir, "app.db"), UseModerncDriver: true}
+	if err := manager.AddConnection(app); err != nil {
+		t.Fatalf("failed to add connection: %v", err)
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	database, err := manager.GetDatabase("app")
+	if err != nil {
+		t.Fatalf("expected connection to be open: %v", err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE notes (id INTEGER PRIMARY KEY, body TEXT)",
+		"INSERT INTO notes (body) VALUES ('first'), ('second')",
+	} {
+		if _, err := database.Exec(ctx, stmt); err != nil {
+			t.Fatalf("failed to prepare database: %v", err)
+		}
+	}
+
+	backup, err := manager.Backup(ctx, "app", backupDir)
+	if err != nil {
+		t.Fatalf("failed to back up: %v", err)
+	}
+	if backup.Integrity != "ok" {
+		t.Errorf("expected intact backup, got %q", backup.Integrity)
+	}
+	if backup.Size == 0 || filepath.Dir(backup.File) != backupDir {
+		t.Errorf("unexpected backup file %s of %d bytes", backup.File, backup.Size)
+	}
+
+	// Changes after the backup are not in it
+	if _, err := database.Exec(ctx, "INSERT INTO notes (body) VALUES ('third')"); err != nil {
+		t.Fatalf("failed to insert: %v", err)
+	}
+
+	backups, err := ListBackups("app", backupDir)
+	if err != nil || len(backups) != 1 || backups[0].File != backup.File {
+		t.Fatalf("expected the backup to be listed, got %v (err: %v)", backups, err)
+	}
+
+	restored, err := manager.RestoreBackup("app", "", "app_restored", backupDir)
+	if err != nil {
+		t.Fatalf("failed to restore: %v", err)
+	}
+	if restored.DatabasePath != filepath.Join(backupDir, "restored", "app_restored.db") {
+		t.Errorf("unexpected restored path %s", restored.DatabasePath)
+	}
+	if err := manager.AddConnection(restored); err != nil {
+		t.Fatalf("failed to add restored connection: %v", err)
+	}
+	copyDB, err := manager.GetDatabase("app_restored")
+	if err != nil {
+		t.Fatalf("expected restored connection to be open: %v", err)
+	}
+	var count int
+	if err := copyDB.QueryRow(ctx, "SELECT COUNT(*) FROM notes").Scan(&count); err != nil {
+		t.Fatalf("failed to query restored database: %v", err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 rows in the restored database, got %d", count)
+	}
+
+	// Existing connections, unknown backups and other engines are rejected
+	if _, err := manager.RestoreBackup("app", "", "app_restored", backupDir); err == nil {
+		t.Error("expected restore into an existing connection to fail")
+	}
+	if _, err := manager.RestoreBackup("app", "app-missing.db", "other", backupDir); err == nil {
+		t.Error("expected restore of an unknown backup to fail")
+	}
+	if _, err := manager.RestoreBackup("app", "", "../escape", backupDir); err == nil {
+		t.Error("expected restore into a path outside the backup directory to fail")
+	}
+	if _, err := manager.Backup(ctx, "../app", backupDir); err == nil {
+		t.Error("expected backup to a path outside the backup directory to fail")
+	}
+	if _, err := ListBackups("../app", backupDir); err == nil {
+		t.Error("expected listing backups outside the backup directory to fail")
+	}
+	manager.lazyLoading = true
+	if err := manager.AddConnection(DatabaseConnectionConfig{ID: "pg", Type: "postgres", Host: "localhost", Name: "app"}); err != nil {
+		t.Fatalf("failed to add connection: %v", err)
+	}
+	if _, err := manager.Backup(ctx, "pg", backupDir); err == nil {
+		t.Error("expected backup of a PostgreSQL database to fail")
+	}
+}
@@ -0,0 +1,127 @@
+package dialect
+
+import (
+	"database/sql"
+	"strings"
+)
+
+// Base implements the optional parts of a Dialect with ANSI SQL behaviour
+// and information_schema catalog queries. Engines embed it and override what
+// differs.
+type Base struct{}
+
+// Configure leaves the configuration unchanged
+func (Base) Configure(*Config) {}
+
+// SetDefaults sets no engine defaults
+func (Base) SetDefaults(*Config) {}
+
+// Validate accepts every configuration
+func (Base) Validate(Config) error { return nil }
+
+// OnConnect does nothing
+func (Base) OnConnect(*sql.DB, Config) {}
+
+// Placeholder returns "?"
+func (Base) Placeholder(int) string { return "?" }
+
+// QuoteIdentifier quotes a name with double quotes
+func (Base) QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// Syntax returns the ANSI SQL syntax
+func (Base) Syntax() Syntax { return Syntax{} }
+
+// Explain reports that queries cannot be planned
+func (Base) Explain() ExplainStyle { return ExplainNone }
+
+// Transactions reports that transactions are supported
+func (Base) Transactions() bool { return true }
+
+// TablesQueries lists tables through information_schema
+func (Base) TablesQueries() []Query {
+	return []Query{
+		{SQL: "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'"},
+		{SQL: "SELECT table_name FROM information_schema.tables"},
+		{SQL: "SHOW TABLES"}, // Last resort
+	}
+}
+
+// ColumnsQueries lists the columns of a table through information_schema
+func (Base) ColumnsQueries(table string) []Query {
+	return []Query{
+		// Try PostgreSQL-style query first
+		{
+			SQL: `
+				SELECT column_name, data_type, is_nullable, column_default
+				FROM information_schema.columns
+				WHERE table_name = $1
+				ORDER BY ordinal_position
+			`,
+			Args: []interface{}{table},
+		},
+		// Try MySQL-style query
+		{
+			SQL: `
+				SELECT column_name, data_type, is_nullable, column_default
+				FROM information_schema.columns
+				WHERE table_name = ?
+				ORDER BY ordinal_position
+			`,
+			Args: []interface{}{table},
+		},
+	}
+}
+
+// RelationshipsQueries lists foreign keys through information_schema, of one
+// table or of all tables if table is empty
+func (Base) RelationshipsQueries(table string) []Query {
+	pgQuery := Query{
+		SQL: `
+			SELECT
+				tc.table_schema,
+				tc.constraint_name,
+				tc.table_name,
+				kcu.column_name,
+				ccu.table_schema AS foreign_table_schema,
+				ccu.table_name AS foreign_table_name,
+				ccu.column_name AS foreign_column_name
+			FROM information_schema.table_constraints AS tc
+			JOIN information_schema.key_column_usage AS kcu
+				ON tc.constraint_name = kcu.constraint_name
+				AND tc.table_schema = kcu.table_schema
+			JOIN information_schema.constraint_column_usage AS ccu
+				ON ccu.constraint_name = tc.constraint_name
+				AND ccu.table_schema = tc.table_schema
+			WHERE tc.constraint_type = 'FOREIGN KEY'
+		`,
+		Args: []interface{}{},
+	}
+
+	mysqlQuery := Query{
+		SQL: `
+			SELECT
+				kcu.constraint_schema AS table_schema,
+				kcu.constraint_name,
+				kcu.table_name,
+				kcu.column_name,
+				kcu.referenced_table_schema AS foreign_table_schema,
+				kcu.referenced_table_name AS foreign_table_name,
+				kcu.referenced_column_name AS foreign_column_name
+			FROM information_schema.key_column_usage kcu
+			WHERE kcu.referenced_table_name IS NOT NULL
+		`,
+		Args: []interface{}{},
+	}
+
+	if table != "" {
+		pgQuery.SQL += " AND (tc.table_name = $1 OR ccu.table_name = $1)"
+		pgQuery.Args = append(pgQuery.Args, table)
+
+		mysqlQuery.SQL += " AND (kcu.table_name = ? OR kcu.referenced_table_name = ?)"
+		mysqlQuery.Args = append(mysqlQuery.Args, table, table)
+	}
+
+	return []Query{pgQuery, mysqlQuery}
+}
@@ -0,0 +1,226 @@
+// Package clickhouse registers the ClickHouse dialect, using the
+// clickhouse-go driver over the native or HTTP protocol.
+package clickhouse
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+	// Import the database driver
+	_ "github.com/ClickHouse/clickhouse-go/v2"
+)
+
+func init() {
+	dialect.Register(Dialect{})
+}
+
+// Protocols of the ClickHouse driver
+const (
+	ProtocolNative = "native"
+	ProtocolHTTP   = "http"
+)
+
+// Dialect is the ClickHouse dialect
+type Dialect struct {
+	dialect.Base
+}
+
+// Name returns "clickhouse"
+func (Dialect) Name() string { return "clickhouse" }
+
+// DriverName returns the clickhouse-go driver
+func (Dialect) DriverName(dialect.Config) string { return "clickhouse" }
+
+// Configure extracts the protocol from the options map
+func (Dialect) Configure(cfg *dialect.Config) {
+	if protocol, ok := cfg.Options["protocol"]; ok {
+		cfg.Protocol = protocol
+	}
+}
+
+// secure reports whether the connection uses TLS
+func secure(cfg dialect.Config) bool {
+	return cfg.Options["secure"] == "true"
+}
+
+// SetDefaults sets the protocol and its default port
+func (Dialect) SetDefaults(cfg *dialect.Config) {
+	if cfg.Protocol == "" {
+		cfg.Protocol = ProtocolNative
+	}
+	if cfg.Port != 0 {
+		return
+	}
+	switch {
+	case cfg.Protocol == ProtocolHTTP && secure(*cfg):
+		cfg.Port = 8443
+	case cfg.Protocol == ProtocolHTTP:
+		cfg.Port = 8123
+	case secure(*cfg):
+		cfg.Port = 9440
+	default:
+		cfg.Port = 9000
+	}
+}
+
+// Validate requires a host and a known protocol
+func (Dialect) Validate(cfg dialect.Config) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("ClickHouse databases require a host")
+	}
+	switch cfg.Protocol {
+	case "", ProtocolNative, ProtocolHTTP:
+	default:
+		return fmt.Errorf("unknown ClickHouse protocol %q (use %s or %s)", cfg.Protocol, ProtocolNative, ProtocolHTTP)
+	}
+	return nil
+}
+
+// scheme returns the DSN scheme of the protocol
+func scheme(cfg dialect.Config) string {
+	if cfg.Protocol != ProtocolHTTP {
+		return "clickhouse"
+	}
+	if secure(cfg) {
+		return "https"
+	}
+	return "http"
+}
+
+// DSN builds a ClickHouse connection URL. Options other than the protocol,
+// such as secure, compress or ClickHouse settings, become URL parameters.
+// Read-only connections set readonly=2, which allows reads and setting
+// changes only.
+func (Dialect) DSN(cfg dialect.Config) string {
+	u := &url.URL{
+		Scheme: scheme(cfg),
+		User:   url.UserPassword(cfg.User, cfg.Password),
+		Host:   net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:   "/" + cfg.Name,
+	}
+
+	params := url.Values{}
+	if cfg.ConnectTimeout > 0 {
+		params.Set("dial_timeout", fmt.Sprintf("%ds", cfg.ConnectTimeout))
+	}
+	if cfg.ReadOnly {
+		params.Set("readonly", "2")
+	}
+	for key, value := range cfg.Options {
+		if key != "protocol" {
+			params.Set(key, value)
+		}
+	}
+
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+// Describe returns the connection URL with the password masked
+func (Dialect) Describe(cfg dialect.Config) string {
+	return fmt.Sprintf("%s://%s:***@%s/%s", scheme(cfg), cfg.User, net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)), cfg.Name)
+}
+
+// Explain reports that plans come from EXPLAIN PLAN, see Plan
+func (Dialect) Explain() dialect.ExplainStyle { return dialect.ExplainClickHousePlan }
+
+// Transactions reports that ClickHouse has no transactions
+func (Dialect) Transactions() bool { return false }
+
+// Plan returns the query plan from EXPLAIN PLAN, one step per row
+func (Dialect) Plan(ctx context.Context, db *sql.DB, query string) (string, error) {
+	rows, err := db.QueryContext(ctx, "EXPLAIN PLAN "+query)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	var plan []string
+	for rows.Next() {
+		var step string
+		if err := rows.Scan(&step); err != nil {
+			return "", fmt.Errorf("failed to read plan: %w", err)
+		}
+		plan = append(plan, step)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(plan) == 0 {
+		return "", fmt.Errorf("no plan returned")
+	}
+	return strings.Join(plan, "\n"), nil
+}
+
+// TablesQueries returns queries for retrieving tables in ClickHouse, with
+// their engine and keys
+func (Dialect) TablesQueries() []dialect.Query {
+	return []dialect.Query{
+		// Primary: system.tables with the table engine and keys
+		{SQL: `
+			SELECT name AS table_name, engine, partition_key, sorting_key, primary_key, total_rows
+			FROM system.tables
+			WHERE database = currentDatabase() AND NOT is_temporary
+			ORDER BY name
+		`},
+		// Secondary: names only
+		{SQL: "SELECT name AS table_name FROM system.tables WHERE database = currentDatabase() ORDER BY name"},
+	}
+}
+
+// ColumnsQueries returns queries for retrieving columns in ClickHouse, with
+// their part in the partition and sort keys
+func (Dialect) ColumnsQueries(table string) []dialect.Query {
+	return []dialect.Query{
+		// Primary: system.columns approach
+		{
+			SQL: `
+				SELECT name AS column_name, type AS data_type,
+				if(startsWith(type, 'Nullable('), 'YES', 'NO') AS is_nullable,
+				default_expression AS column_default,
+				is_in_partition_key, is_in_sorting_key, is_in_primary_key, comment
+				FROM system.columns
+				WHERE database = currentDatabase() AND table = ?
+				ORDER BY position
+			`,
+			Args: []interface{}{table},
+		},
+		// Secondary: information_schema approach (ClickHouse 23.x and later)
+		{
+			SQL: `
+				SELECT column_name, data_type, is_nullable, column_default
+				FROM information_schema.columns
+				WHERE table_schema = currentDatabase() AND table_name = ?
+				ORDER BY ordinal_position
+			`,
+			Args: []interface{}{table},
+		},
+	}
+}
+
+// RelationshipsQueries returns no rows, since ClickHouse has no foreign keys
+func (Dialect) RelationshipsQueries(string) []dialect.Query {
+	return []dialect.Query{
+		{
+			SQL: `
+				SELECT
+					'' AS table_schema, '' AS constraint_name, '' AS table_name, '' AS column_name,
+					'' AS foreign_table_schema, '' AS foreign_table_name, '' AS foreign_column_name
+				FROM system.one
+				WHERE 0
+			`,
+			Args: []interface{}{},
+		},
+	}
+}
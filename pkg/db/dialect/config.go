@@ -0,0 +1,109 @@
+package dialect
+
+import "time"
+
+// PostgresSSLMode defines the SSL mode for PostgreSQL connections
+type PostgresSSLMode string
+
+// SSLMode constants for PostgreSQL
+const (
+	SSLDisable    PostgresSSLMode = "disable"
+	SSLRequire    PostgresSSLMode = "require"
+	SSLVerifyCA   PostgresSSLMode = "verify-ca"
+	SSLVerifyFull PostgresSSLMode = "verify-full"
+	SSLPrefer     PostgresSSLMode = "prefer"
+)
+
+// SQLiteJournalMode defines the journal mode for SQLite connections
+type SQLiteJournalMode string
+
+// SQLiteJournalMode constants
+const (
+	JournalDelete   SQLiteJournalMode = "DELETE"
+	JournalTruncate SQLiteJournalMode = "TRUNCATE"
+	JournalPersist  SQLiteJournalMode = "PERSIST"
+	JournalWAL      SQLiteJournalMode = "WAL"
+	JournalOff      SQLiteJournalMode = "OFF"
+)
+
+// Config represents database connection configuration
+type Config struct {
+	Type     string
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string
+
+	// Additional PostgreSQL specific options, the SSL settings also apply to MySQL
+	SSLMode            PostgresSSLMode
+	SSLCert            string
+	SSLKey             string
+	SSLRootCert        string
+	ApplicationName    string
+	ConnectTimeout     int               // in seconds
+	QueryTimeout       int               // in seconds, default is 30 seconds
+	TargetSessionAttrs string            // for PostgreSQL 10+
+	Options            map[string]string // Extra connection options
+
+	// SQLite specific options
+	DatabasePath     string            // Path to SQLite database file
+	EncryptionKey    string            // Key for SQLCipher encryption
+	ReadOnly         bool              // Open database in read-only mode
+	CacheSize        int               // SQLite cache size (in pages)
+	JournalMode      SQLiteJournalMode // Journal mode for SQLite
+	UseModerncDriver bool              // Use modernc.org/sqlite driver instead of mattn/go-sqlite3
+	Attach           map[string]string // Database files attached to the connection, by schema name
+
+	// Oracle specific options
+	ServiceName     string   // Oracle service name (preferred over SID)
+	SID             string   // Oracle SID (legacy)
+	WalletLocation  string   // Path to Oracle Cloud wallet
+	TNSAdmin        string   // Path to tnsnames.ora directory
+	TNSEntry        string   // TNS entry name from tnsnames.ora
+	Edition         string   // Oracle Edition-Based Redefinition
+	Pooling         bool     // Enable connection pooling
+	StandbySessions bool     // Allow connections to standby database
+	NLSLang         string   // NLS_LANG setting (e.g., "AMERICAN_AMERICA.AL32UTF8")
+	SchemaOwners    []string // Schemas listed by the schema tools, default the connecting user's
+
+	// SQL Server specific options
+	Instance               string // Named instance, located through the SQL Server Browser
+	Encrypt                string // TLS: "true", "false", "disable" or "strict"
+	TrustServerCertificate bool   // Accept the server certificate without validation
+
+	// ClickHouse specific options
+	Protocol string // "native" (default) or "http"
+
+	// Connection pool settings
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// SetDefaults sets default values for the configuration if they are not set,
+// then the defaults of its engine
+func (c *Config) SetDefaults() {
+	if c.MaxOpenConns == 0 {
+		c.MaxOpenConns = 25
+	}
+	if c.MaxIdleConns == 0 {
+		c.MaxIdleConns = 5
+	}
+	if c.ConnMaxLifetime == 0 {
+		c.ConnMaxLifetime = 5 * time.Minute
+	}
+	if c.ConnMaxIdleTime == 0 {
+		c.ConnMaxIdleTime = 5 * time.Minute
+	}
+	if d, ok := Lookup(c.Type); ok {
+		d.SetDefaults(c)
+	}
+	if c.ConnectTimeout == 0 {
+		c.ConnectTimeout = 10 // Default 10 seconds
+	}
+	if c.QueryTimeout == 0 {
+		c.QueryTimeout = 30 // Default 30 seconds
+	}
+}
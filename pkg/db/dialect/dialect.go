@@ -0,0 +1,183 @@
+// Package dialect defines how the server talks to a database engine and keeps
+// a registry of the engines it supports.
+//
+// Each engine lives in its own package that registers a Dialect from init.
+// The built-in engines are imported by package db; other engines are added by
+// importing their package, or calling Register, from the server's main before
+// the configuration is loaded:
+//
+//	func init() {
+//		dialect.Register(cockroach.Dialect{}, "cockroachdb")
+//	}
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Dialect describes a database engine
+type Dialect interface {
+	// Name is the connection type in the config file, such as "postgres"
+	Name() string
+
+	// DriverName returns the database/sql driver used for a connection
+	DriverName(cfg Config) string
+	// Configure copies engine-specific settings out of cfg.Options
+	Configure(cfg *Config)
+	// SetDefaults fills engine defaults, such as the port
+	SetDefaults(cfg *Config)
+	// Validate checks the engine-specific settings of a connection
+	Validate(cfg Config) error
+	// DSN builds the driver connection string. Connections with
+	// cfg.ReadOnly set must be made read-only here, or by a Connector, for
+	// every session the pool opens.
+	DSN(cfg Config) string
+	// Describe describes a connection for logs without its secrets
+	Describe(cfg Config) string
+	// OnConnect runs once after the connection is opened, such as for
+	// SQLite pragmas
+	OnConnect(db *sql.DB, cfg Config)
+
+	// Placeholder returns the n-th (1-based) bind placeholder
+	Placeholder(n int) string
+	// QuoteIdentifier quotes a table or column name
+	QuoteIdentifier(name string) string
+	// Syntax returns how the engine writes comments, strings and quoted names
+	Syntax() Syntax
+	// Explain returns how the engine plans a query without running it
+	Explain() ExplainStyle
+	// Transactions reports whether the engine can run transactions
+	Transactions() bool
+
+	// Catalog queries, tried in order until one succeeds. Each returns rows
+	// starting with the columns of the generic information_schema queries in
+	// Base; engines may add details after them.
+	TablesQueries() []Query
+	ColumnsQueries(table string) []Query
+	RelationshipsQueries(table string) []Query
+}
+
+// Planner is implemented by dialects that need more than an EXPLAIN prefix
+// to show the plan of a query
+type Planner interface {
+	// Plan returns the plan of a query without running it
+	Plan(ctx context.Context, db *sql.DB, query string) (string, error)
+}
+
+// Scoper is implemented by dialects whose catalog queries depend on the
+// settings of a connection, such as the schemas they list
+type Scoper interface {
+	// Scope returns the dialect for one connection
+	Scope(cfg Config) Dialect
+}
+
+// Connector is implemented by dialects that prepare every connection of the
+// pool, such as attaching databases
+type Connector interface {
+	// Connector returns the connector of the data source name, or nil to
+	// open it with sql.Open
+	Connector(cfg Config, dsn string) (driver.Connector, error)
+}
+
+// Query is a catalog query with its arguments
+type Query struct {
+	SQL  string
+	Args []interface{}
+}
+
+// ExplainStyle names how an engine reports query plans
+type ExplainStyle string
+
+// Explain styles of the built-in engines
+const (
+	ExplainNone            ExplainStyle = ""                  // the engine cannot plan queries
+	ExplainPostgresJSON    ExplainStyle = "postgres_json"     // EXPLAIN (FORMAT JSON)
+	ExplainMySQLJSON       ExplainStyle = "mysql_json"        // EXPLAIN FORMAT=JSON
+	ExplainOraclePlanTable ExplainStyle = "oracle_plan_table" // EXPLAIN PLAN into PLAN_TABLE
+	ExplainShowplanXML     ExplainStyle = "showplan_xml"      // SET SHOWPLAN_XML ON, see Planner
+	ExplainDuckDB          ExplainStyle = "duckdb"            // EXPLAIN as key and value rows, see Planner
+	ExplainClickHousePlan  ExplainStyle = "clickhouse_plan"   // EXPLAIN PLAN, see Planner
+)
+
+var (
+	mu       sync.RWMutex
+	dialects = make(map[string]Dialect)
+	aliases  = make(map[string]string)
+)
+
+// Register adds an engine, also known by the given aliases, such as the
+// names of its drivers. It panics if the name is already taken, like
+// sql.Register.
+func Register(d Dialect, alias ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	for _, name := range append([]string{d.Name()}, alias...) {
+		if _, taken := dialects[name]; taken {
+			panic("dialect: Register called twice for " + name)
+		}
+		if _, taken := aliases[name]; taken {
+			panic("dialect: Register called twice for " + name)
+		}
+	}
+	dialects[d.Name()] = d
+	for _, name := range alias {
+		aliases[name] = d.Name()
+	}
+}
+
+// Lookup returns the engine with a name or alias
+func Lookup(name string) (Dialect, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if target, ok := aliases[name]; ok {
+		name = target
+	}
+	d, ok := dialects[name]
+	return d, ok
+}
+
+// Names returns the names of the registered engines, sorted
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	names := make([]string, 0, len(dialects))
+	for name := range dialects {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForConnection returns the engine of a connection, scoped to its settings
+func ForConnection(cfg Config) (Dialect, error) {
+	d, err := ForType(cfg.Type)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := d.(Scoper); ok {
+		return s.Scope(cfg), nil
+	}
+	return d, nil
+}
+
+// ForType returns the engine for a connection type. Aliases are not
+// connection types.
+func ForType(connType string) (Dialect, error) {
+	mu.RLock()
+	d, ok := dialects[connType]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported database type: %s (must be one of %s)", connType, strings.Join(Names(), ", "))
+	}
+	return d, nil
+}
@@ -0,0 +1,55 @@
+package dialect
+
+import (
+	"strings"
+	"testing"
+)
+
+// testDialect is a minimal engine for registry tests
+type testDialect struct {
+	Base
+	name string
+}
+
+func (d testDialect) Name() string               { return d.name }
+func (d testDialect) DriverName(Config) string   { return d.name }
+func (d testDialect) DSN(cfg Config) string      { return cfg.Name }
+func (d testDialect) Describe(cfg Config) string { return cfg.Name }
+
+func TestRegistry(t *testing.T) {
+	Register(testDialect{name: "registry_test"}, "registry_alias")
+
+	d, ok := Lookup("registry_test")
+	if !ok || d.Name() != "registry_test" {
+		t.Fatalf("expected registry_test to be registered, got %v", d)
+	}
+	if d, ok := Lookup("registry_alias"); !ok || d.Name() != "registry_test" {
+		t.Errorf("expected the alias to resolve to registry_test, got %v", d)
+	}
+	if _, err := ForType("registry_alias"); err == nil {
+		t.Error("expected aliases not to be connection types")
+	}
+	if _, err := ForType("missing"); err == nil || !strings.Contains(err.Error(), "registry_test") {
+		t.Errorf("expected an error listing the registered types, got %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected registering a taken alias to panic")
+		}
+	}()
+	Register(testDialect{name: "other"}, "registry_alias")
+}
+
+func TestBase(t *testing.T) {
+	var b Base
+	if got := b.QuoteIdentifier(`my "table"`); got != `"my ""table"""` {
+		t.Errorf("unexpected quoted identifier %s", got)
+	}
+	if b.Placeholder(3) != "?" || b.Explain() != ExplainNone {
+		t.Error("expected ? placeholders and no explain support")
+	}
+	if queries := b.RelationshipsQueries("orders"); len(queries) != 2 || len(queries[1].Args) != 2 {
+		t.Errorf("expected table-filtered relationship queries, got %+v", queries)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build cgo
+
+package duckdb
+
+import (
+	// Import the database driver
+	_ "github.com/marcboeker/go-duckdb"
+)
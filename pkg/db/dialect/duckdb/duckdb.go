@@ -0,0 +1,191 @@
+// Package duckdb registers the DuckDB dialect, for local analytics databases
+// and, through DuckDB, Parquet and CSV files on disk.
+//
+// The go-duckdb driver links the DuckDB library with cgo, so it is only
+// built in when cgo is enabled. Without it connections fail to open with an
+// unknown driver error.
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+func init() {
+	dialect.Register(Dialect{})
+}
+
+// Dialect is the DuckDB dialect
+type Dialect struct {
+	dialect.Base
+}
+
+// Name returns "duckdb"
+func (Dialect) Name() string { return "duckdb" }
+
+// DriverName returns the go-duckdb driver
+func (Dialect) DriverName(dialect.Config) string { return "duckdb" }
+
+// SetDefaults uses the name as the path if no path is given
+func (Dialect) SetDefaults(c *dialect.Config) {
+	if c.DatabasePath == "" && c.Name != "" {
+		c.DatabasePath = c.Name
+	}
+}
+
+// Validate requires a database path
+func (Dialect) Validate(config dialect.Config) error {
+	if config.DatabasePath == "" && config.Name == "" {
+		return fmt.Errorf("DuckDB databases require either database_path or name to be specified")
+	}
+	return nil
+}
+
+// path returns the database file, or ":memory:"
+func path(config dialect.Config) string {
+	if config.DatabasePath != "" {
+		return config.DatabasePath
+	}
+	return config.Name
+}
+
+// DSN builds a DuckDB connection string. Options, such as threads or
+// memory_limit, are passed as configuration settings.
+func (Dialect) DSN(config dialect.Config) string {
+	dbPath := path(config)
+
+	params := make(url.Values)
+	for key, value := range config.Options {
+		params.Set(key, value)
+	}
+
+	if dbPath == ":memory:" {
+		// In-memory databases cannot be opened read-only, so they rely on
+		// the tool checks
+		dbPath = ""
+	} else {
+		dbPath = filepath.Clean(dbPath)
+		if config.ReadOnly {
+			params.Set("access_mode", "read_only")
+		}
+	}
+
+	if len(params) > 0 {
+		return dbPath + "?" + params.Encode()
+	}
+	return dbPath
+}
+
+// Describe returns the database path
+func (Dialect) Describe(config dialect.Config) string {
+	dbPath := path(config)
+	if dbPath == ":memory:" {
+		return "DuckDB in-memory database"
+	}
+	return fmt.Sprintf("DuckDB database: %s", dbPath)
+}
+
+// Explain reports that plans come from EXPLAIN, see Plan
+func (Dialect) Explain() dialect.ExplainStyle { return dialect.ExplainDuckDB }
+
+// Plan returns the physical plan of a query. EXPLAIN returns the plan as
+// key and value rows, whose values are joined.
+func (Dialect) Plan(ctx context.Context, db *sql.DB, query string) (string, error) {
+	rows, err := db.QueryContext(ctx, "EXPLAIN "+query)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	var plan []string
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return "", fmt.Errorf("failed to read plan: %w", err)
+		}
+		plan = append(plan, value)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if len(plan) == 0 {
+		return "", fmt.Errorf("no plan returned")
+	}
+	return strings.Join(plan, "\n"), nil
+}
+
+// TablesQueries returns queries for retrieving tables in DuckDB
+func (Dialect) TablesQueries() []dialect.Query {
+	return []dialect.Query{
+		// Primary: tables and views of the main schema
+		{SQL: "SELECT table_name FROM information_schema.tables WHERE table_schema = 'main' ORDER BY table_name"},
+		// Secondary: metadata function
+		{SQL: "SELECT table_name FROM duckdb_tables() WHERE NOT internal ORDER BY table_name"},
+	}
+}
+
+// ColumnsQueries returns queries for retrieving columns in DuckDB
+func (Dialect) ColumnsQueries(table string) []dialect.Query {
+	return []dialect.Query{
+		// Primary: information_schema approach
+		{
+			SQL: `
+				SELECT column_name, data_type, is_nullable, column_default
+				FROM information_schema.columns
+				WHERE table_name = ?
+				ORDER BY ordinal_position
+			`,
+			Args: []interface{}{table},
+		},
+		// Secondary: metadata function
+		{
+			SQL: `
+				SELECT column_name, data_type,
+				CASE WHEN is_nullable THEN 'YES' ELSE 'NO' END as is_nullable,
+				column_default
+				FROM duckdb_columns()
+				WHERE table_name = ?
+				ORDER BY column_index
+			`,
+			Args: []interface{}{table},
+		},
+	}
+}
+
+// RelationshipsQueries returns queries for retrieving relationships in DuckDB
+func (Dialect) RelationshipsQueries(table string) []dialect.Query {
+	query := dialect.Query{
+		SQL: `
+			SELECT
+				c.schema_name as table_schema,
+				c.constraint_name,
+				c.table_name,
+				unnest(c.constraint_column_names) as column_name,
+				c.schema_name as foreign_table_schema,
+				c.referenced_table as foreign_table_name,
+				unnest(c.referenced_column_names) as foreign_column_name
+			FROM duckdb_constraints() c
+			WHERE c.constraint_type = 'FOREIGN KEY'
+		`,
+		Args: []interface{}{},
+	}
+
+	if table != "" {
+		// Add table filter
+		query.SQL += " AND (c.table_name = ? OR c.referenced_table = ?)"
+		query.Args = []interface{}{table, table}
+	}
+
+	return []dialect.Query{query}
+}
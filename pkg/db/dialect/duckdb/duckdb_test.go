@@ -0,0 +1,120 @@
+package duckdb
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDuckDBDSN tests the DuckDB connection string builder
+func TestDuckDBDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   dialect.Config
+		expected string
+	}{
+		{
+			name:     "File",
+			config:   dialect.Config{Type: "duckdb", DatabasePath: "/data/analytics.duckdb"},
+			expected: "/data/analytics.duckdb",
+		},
+		{
+			name:     "Name as path",
+			config:   dialect.Config{Type: "duckdb", Name: "/data/./analytics.duckdb"},
+			expected: "/data/analytics.duckdb",
+		},
+		{
+			name:     "Read-only with options",
+			config:   dialect.Config{Type: "duckdb", DatabasePath: "/data/analytics.duckdb", ReadOnly: true, Options: map[string]string{"threads": "4"}},
+			expected: "/data/analytics.duckdb?access_mode=read_only&threads=4",
+		},
+		{
+			name:     "In-memory",
+			config:   dialect.Config{Type: "duckdb", DatabasePath: ":memory:", ReadOnly: true},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Dialect{}
+			cfg := tt.config
+			d.SetDefaults(&cfg)
+			require.NoError(t, d.Validate(cfg))
+			assert.Equal(t, tt.expected, d.DSN(cfg))
+		})
+	}
+
+	assert.Error(t, Dialect{}.Validate(dialect.Config{Type: "duckdb"}))
+	assert.Equal(t, "DuckDB in-memory database", Dialect{}.Describe(dialect.Config{Name: ":memory:"}))
+}
+
+// TestDuckDBCatalog runs the catalog queries and the planner on a database
+// file, when the driver is built in
+func TestDuckDBCatalog(t *testing.T) {
+	if !slices.Contains(sql.Drivers(), "duckdb") {
+		t.Skip("DuckDB driver requires cgo")
+	}
+
+	d := Dialect{}
+	cfg := dialect.Config{Type: "duckdb", DatabasePath: filepath.Join(t.TempDir(), "test.duckdb")}
+	db, err := sql.Open(d.DriverName(cfg), d.DSN(cfg))
+	require.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name VARCHAR NOT NULL)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES customers(id), total DOUBLE)",
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+
+	ctx := context.Background()
+	for _, q := range d.TablesQueries() {
+		var tables []string
+		rows, err := db.QueryContext(ctx, q.SQL, q.Args...)
+		require.NoError(t, err, q.SQL)
+		for rows.Next() {
+			var name string
+			require.NoError(t, rows.Scan(&name))
+			tables = append(tables, name)
+		}
+		require.NoError(t, rows.Close())
+		assert.Equal(t, []string{"customers", "orders"}, tables, q.SQL)
+	}
+
+	for _, q := range d.ColumnsQueries("orders") {
+		var columns []string
+		rows, err := db.QueryContext(ctx, q.SQL, q.Args...)
+		require.NoError(t, err, q.SQL)
+		for rows.Next() {
+			var name, dataType, nullable string
+			var def sql.NullString
+			require.NoError(t, rows.Scan(&name, &dataType, &nullable, &def))
+			columns = append(columns, name)
+		}
+		require.NoError(t, rows.Close())
+		assert.Equal(t, []string{"id", "customer_id", "total"}, columns, q.SQL)
+	}
+
+	for _, q := range d.RelationshipsQueries("customers") {
+		rows, err := db.QueryContext(ctx, q.SQL, q.Args...)
+		require.NoError(t, err, q.SQL)
+		require.True(t, rows.Next())
+		var schema, constraint, table, column, foreignSchema, foreignTable, foreignColumn string
+		require.NoError(t, rows.Scan(&schema, &constraint, &table, &column, &foreignSchema, &foreignTable, &foreignColumn))
+		require.NoError(t, rows.Close())
+		assert.Equal(t, []string{"orders", "customer_id", "customers", "id"}, []string{table, column, foreignTable, foreignColumn})
+	}
+
+	plan, err := d.Plan(ctx, db, "SELECT * FROM orders WHERE total > 10")
+	require.NoError(t, err)
+	assert.Contains(t, plan, "orders")
+}
@@ -0,0 +1,190 @@
+// Package mysql registers the MySQL dialect.
+package mysql
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+	// Import the database driver
+	_ "github.com/go-sql-driver/mysql"
+)
+
+func init() {
+	dialect.Register(Dialect{})
+}
+
+// Dialect is the MySQL dialect
+type Dialect struct {
+	dialect.Base
+}
+
+// Name returns "mysql"
+func (Dialect) Name() string { return "mysql" }
+
+// DriverName returns the go-sql-driver/mysql driver
+func (Dialect) DriverName(dialect.Config) string { return "mysql" }
+
+// Validate checks the TLS mode and loads its certificate files
+func (Dialect) Validate(config dialect.Config) error {
+	_, err := tlsConfig(config)
+	return err
+}
+
+// address returns the network address of the server, a unix socket when the
+// socket option is set
+func address(config dialect.Config) string {
+	if socket := config.Options["socket"]; socket != "" {
+		return fmt.Sprintf("unix(%s)", socket)
+	}
+	return fmt.Sprintf("tcp(%s)", net.JoinHostPort(config.Host, strconv.Itoa(config.Port)))
+}
+
+// DSN builds a MySQL data source name. The connect timeout becomes the dial
+// timeout and the query timeout the I/O timeouts, TLS settings select or
+// register a driver TLS config, and options other than socket, such as
+// charset, collation, loc or server variables, become DSN parameters and
+// override the defaults, except read-only.
+func (Dialect) DSN(config dialect.Config) string {
+	params := url.Values{}
+	params.Set("parseTime", "true")
+
+	if config.ConnectTimeout > 0 {
+		params.Set("timeout", fmt.Sprintf("%ds", config.ConnectTimeout))
+	}
+	if config.QueryTimeout > 0 {
+		params.Set("readTimeout", fmt.Sprintf("%ds", config.QueryTimeout))
+		params.Set("writeTimeout", fmt.Sprintf("%ds", config.QueryTimeout))
+	}
+
+	if value, err := tlsParam(config); err != nil {
+		logger.Error("MySQL TLS configuration for %s: %v", config.Host, err)
+	} else if value != "" {
+		params.Set("tls", value)
+	}
+
+	for key, value := range config.Options {
+		if key != "socket" {
+			params.Set(key, value)
+		}
+	}
+
+	if config.ReadOnly {
+		// Set as a session variable on every connection (MySQL 5.7.20+),
+		// after the options so that they cannot turn it off
+		params.Set("transaction_read_only", "1")
+	}
+
+	return fmt.Sprintf("%s:%s@%s/%s?%s",
+		config.User, config.Password, address(config), config.Name, params.Encode())
+}
+
+// Describe returns the data source name with the password masked
+func (Dialect) Describe(config dialect.Config) string {
+	return fmt.Sprintf("%s:***@%s/%s", config.User, address(config), config.Name)
+}
+
+// QuoteIdentifier quotes a name with backticks
+func (Dialect) QuoteIdentifier(name string) string {
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Explain plans queries with EXPLAIN FORMAT=JSON
+func (Dialect) Explain() dialect.ExplainStyle { return dialect.ExplainMySQLJSON }
+
+// TablesQueries returns queries for retrieving tables in MySQL
+func (Dialect) TablesQueries() []dialect.Query {
+	return []dialect.Query{
+		// Primary: information_schema approach
+		{SQL: "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()"},
+		// Secondary: SHOW TABLES approach
+		{SQL: "SHOW TABLES"},
+	}
+}
+
+// ColumnsQueries returns queries for retrieving columns in MySQL
+func (d Dialect) ColumnsQueries(table string) []dialect.Query {
+	return []dialect.Query{
+		// MySQL query for columns
+		{
+			SQL: `
+				SELECT column_name, data_type, is_nullable, column_default
+				FROM information_schema.columns
+				WHERE table_name = ? AND table_schema = DATABASE()
+				ORDER BY ordinal_position
+			`,
+			Args: []interface{}{table},
+		},
+		// Fallback for older MySQL versions
+		{
+			SQL:  `SHOW COLUMNS FROM ` + d.QuoteIdentifier(table),
+			Args: []interface{}{},
+		},
+	}
+}
+
+// RelationshipsQueries returns queries for retrieving relationships in MySQL
+func (Dialect) RelationshipsQueries(table string) []dialect.Query {
+	baseQueries := []dialect.Query{
+		// Primary approach for MySQL
+		{
+			SQL: `
+				SELECT
+					tc.table_schema,
+					tc.constraint_name,
+					tc.table_name,
+					kcu.column_name,
+					kcu.referenced_table_schema AS foreign_table_schema,
+					kcu.referenced_table_name AS foreign_table_name,
+					kcu.referenced_column_name AS foreign_column_name
+				FROM information_schema.table_constraints AS tc
+				JOIN information_schema.key_column_usage AS kcu
+					ON tc.constraint_name = kcu.constraint_name
+					AND tc.table_schema = kcu.table_schema
+				WHERE tc.constraint_type = 'FOREIGN KEY'
+					AND tc.table_schema = DATABASE()
+			`,
+			Args: []interface{}{},
+		},
+		// Fallback using simpler query for older MySQL versions
+		{
+			SQL: `
+				SELECT
+					kcu.constraint_schema AS table_schema,
+					kcu.constraint_name,
+					kcu.table_name,
+					kcu.column_name,
+					kcu.referenced_table_schema AS foreign_table_schema,
+					kcu.referenced_table_name AS foreign_table_name,
+					kcu.referenced_column_name AS foreign_column_name
+				FROM information_schema.key_column_usage kcu
+				WHERE kcu.referenced_table_name IS NOT NULL
+					AND kcu.constraint_schema = DATABASE()
+			`,
+			Args: []interface{}{},
+		},
+	}
+
+	if table == "" {
+		return baseQueries
+	}
+
+	queries := make([]dialect.Query, len(baseQueries))
+
+	// Add table filter
+	queries[0] = dialect.Query{
+		SQL:  baseQueries[0].SQL + " AND (tc.table_name = ? OR kcu.referenced_table_name = ?)",
+		Args: []interface{}{table, table},
+	}
+
+	queries[1] = dialect.Query{
+		SQL:  baseQueries[1].SQL + " AND (kcu.table_name = ? OR kcu.referenced_table_name = ?)",
+		Args: []interface{}{table, table},
+	}
+
+	return queries
+}
@@ -0,0 +1,162 @@
+package mysql
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	driver "github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMySQLDSN tests the MySQL data source name builder
+func TestMySQLDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   dialect.Config
+		expected string
+	}{
+		{
+			name:     "Defaults",
+			config:   dialect.Config{Type: "mysql", Host: "localhost", Port: 3306, User: "app", Password: "secret", Name: "shop"},
+			expected: "app:secret@tcp(localhost:3306)/shop?parseTime=true",
+		},
+		{
+			name: "Timeouts and read-only",
+			config: dialect.Config{Type: "mysql", Host: "localhost", Port: 3306, User: "app", Password: "secret", Name: "shop",
+				ConnectTimeout: 10, QueryTimeout: 30, ReadOnly: true},
+			expected: "app:secret@tcp(localhost:3306)/shop?parseTime=true&readTimeout=30s&timeout=10s&transaction_read_only=1&writeTimeout=30s",
+		},
+		{
+			name: "Unix socket with options",
+			config: dialect.Config{Type: "mysql", User: "app", Password: "secret", Name: "shop",
+				Options: map[string]string{"socket": "/var/run/mysqld/mysqld.sock", "charset": "utf8mb4", "collation": "utf8mb4_unicode_ci", "loc": "Europe/Paris"}},
+			expected: "app:secret@unix(/var/run/mysqld/mysqld.sock)/shop?charset=utf8mb4&collation=utf8mb4_unicode_ci&loc=Europe%2FParis&parseTime=true",
+		},
+		{
+			name: "Options override the defaults",
+			config: dialect.Config{Type: "mysql", Host: "localhost", Port: 3306, User: "app", Password: "secret", Name: "shop",
+				QueryTimeout: 30, Options: map[string]string{"readTimeout": "5m"}},
+			expected: "app:secret@tcp(localhost:3306)/shop?parseTime=true&readTimeout=5m&writeTimeout=30s",
+		},
+		{
+			name: "Options cannot turn off read-only",
+			config: dialect.Config{Type: "mysql", Host: "localhost", Port: 3306, User: "app", Password: "secret", Name: "shop",
+				ReadOnly: true, Options: map[string]string{"transaction_read_only": "0"}},
+			expected: "app:secret@tcp(localhost:3306)/shop?parseTime=true&transaction_read_only=1",
+		},
+		{
+			name: "Builtin TLS mode",
+			config: dialect.Config{Type: "mysql", Host: "db.example.com", Port: 3306, User: "app", Password: "secret", Name: "shop",
+				SSLMode: dialect.SSLRequire},
+			expected: "app:secret@tcp(db.example.com:3306)/shop?parseTime=true&tls=skip-verify",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := Dialect{}
+			require.NoError(t, d.Validate(tt.config))
+			dsn := d.DSN(tt.config)
+			assert.Equal(t, tt.expected, dsn)
+			assert.NotContains(t, d.Describe(tt.config), "secret")
+
+			// The driver understands every parameter
+			_, err := driver.ParseDSN(dsn)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestMySQLTLS tests that TLS settings with certificate files register a
+// driver TLS config
+func TestMySQLTLS(t *testing.T) {
+	ca := writeCA(t)
+
+	config := dialect.Config{Type: "mysql", Host: "db.example.com", Port: 3306, User: "app", Name: "shop",
+		SSLMode: dialect.SSLVerifyCA, SSLRootCert: ca}
+	require.NoError(t, Dialect{}.Validate(config))
+
+	parsed, err := driver.ParseDSN(Dialect{}.DSN(config))
+	require.NoError(t, err)
+	require.NotNil(t, parsed.TLS)
+	assert.NotNil(t, parsed.TLS.RootCAs)
+	assert.NotNil(t, parsed.TLS.VerifyPeerCertificate)
+
+	// Unknown modes and missing files are rejected
+	assert.Error(t, Dialect{}.Validate(dialect.Config{Type: "mysql", SSLMode: "always"}))
+	assert.Error(t, Dialect{}.Validate(dialect.Config{Type: "mysql", SSLMode: dialect.SSLVerifyFull, SSLRootCert: filepath.Join(t.TempDir(), "missing.pem")}))
+	assert.Error(t, Dialect{}.Validate(dialect.Config{Type: "mysql", SSLMode: dialect.SSLRequire, SSLCert: ca}))
+	assert.False(t, strings.Contains(Dialect{}.DSN(dialect.Config{Type: "mysql", SSLMode: "always"}), "tls="))
+}
+
+// writeCA writes a self-signed CA certificate and returns its path
+func writeCA(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	return path
+}
+
+// TestMySQLReadOnly tests the statement checks of read-only connections
+func TestMySQLReadOnly(t *testing.T) {
+	allowed := []string{
+		"SELECT * FROM orders",
+		"  # recent orders\n select * from `orders` where note = 'it\\'s; delete'",
+		"WITH recent AS (SELECT id FROM orders) SELECT * FROM recent;",
+		"EXPLAIN FORMAT=JSON SELECT 1",
+		"SHOW TABLES",
+		"SELECT 1--1",
+	}
+	for _, query := range allowed {
+		assert.NoError(t, checkReadOnly(query), query)
+	}
+
+	rejected := []string{
+		"",
+		"SET SESSION transaction_read_only = 0",
+		"START TRANSACTION READ WRITE",
+		"SELECT 1; DELETE FROM orders",
+		"SELECT 'a\\\\'; DELETE FROM orders",
+		"SELECT 1--1; DELETE FROM orders",
+		"SELECT 1 /*!; DELETE FROM orders */",
+		"SELECT * FROM orders INTO OUTFILE '/tmp/orders'",
+	}
+	for _, query := range rejected {
+		assert.Error(t, checkReadOnly(query), query)
+	}
+
+	d := Dialect{}
+	dsn := "app:secret@tcp(localhost:3306)/shop"
+	connector, err := d.Connector(dialect.Config{Type: "mysql"}, dsn)
+	require.NoError(t, err)
+	assert.Nil(t, connector)
+	connector, err = d.Connector(dialect.Config{Type: "mysql", ReadOnly: true}, dsn)
+	require.NoError(t, err)
+	assert.NotNil(t, connector)
+}
@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	sqldriver "database/sql/driver"
+	"fmt"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// syntax is the MySQL quoting and comment syntax
+var syntax = dialect.Syntax{
+	BacktickNames:      true,
+	HashComments:       true,
+	DashCommentSpace:   true,
+	ExecutableComments: true,
+	BackslashEscapes:   true,
+}
+
+// Syntax returns the MySQL syntax
+func (Dialect) Syntax() dialect.Syntax { return syntax }
+
+// readOnlyStatements are the keywords a statement of a read-only connection
+// may start with. Writes in them are rejected by the read-only transaction.
+var readOnlyStatements = map[string]bool{
+	"SELECT":   true,
+	"WITH":     true,
+	"VALUES":   true,
+	"TABLE":    true,
+	"EXPLAIN":  true,
+	"DESCRIBE": true,
+	"DESC":     true,
+	"SHOW":     true,
+}
+
+// fileKeywords write the results of a query to a file on the server, which
+// a read-only transaction allows
+var fileKeywords = map[string]bool{
+	"OUTFILE":  true,
+	"DUMPFILE": true,
+}
+
+// resetReadOnly makes a session read-only again before it is reused
+const resetReadOnly = "SET SESSION transaction_read_only = 1"
+
+// checkReadOnly rejects a statement unless it is a single query. The
+// session variable makes every transaction read-only, so the statements
+// that could change it are kept out: SET, START TRANSACTION READ WRITE and
+// batches, when the multiStatements option allows them.
+func checkReadOnly(query string) error {
+	statements := syntax.Statements(query)
+	if len(statements) > 1 {
+		return fmt.Errorf("read-only connection: only one statement is allowed")
+	}
+	if len(statements) == 0 || !readOnlyStatements[statements[0][0]] {
+		return fmt.Errorf("read-only connection: only SELECT queries are allowed")
+	}
+	for _, word := range statements[0] {
+		if fileKeywords[word] {
+			return fmt.Errorf("read-only connection: %s is not allowed", word)
+		}
+	}
+	return nil
+}
+
+// Connector returns a connector for read-only connections whose sessions
+// only run queries, checking every statement before it is sent. It returns
+// nil for other connections.
+func (Dialect) Connector(cfg dialect.Config, dsn string) (sqldriver.Connector, error) {
+	if !cfg.ReadOnly {
+		return nil, nil
+	}
+	config, err := driver.ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := driver.NewConnector(config)
+	if err != nil {
+		return nil, err
+	}
+	return dialect.ReadOnlyConnector(connector, checkReadOnly, resetReadOnly), nil
+}
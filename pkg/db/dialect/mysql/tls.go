@@ -0,0 +1,126 @@
+package mysql
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	driver "github.com/go-sql-driver/mysql"
+)
+
+// tlsConfig builds the TLS configuration of an SSL mode with certificate
+// files. It returns nil when the mode needs no custom configuration: no TLS,
+// or one of the driver's builtin modes.
+//
+// The modes follow PostgreSQL: require encrypts without checking the server
+// certificate, verify-ca checks it against the CA and verify-full also checks
+// the host name.
+func tlsConfig(config dialect.Config) (*tls.Config, error) {
+	switch config.SSLMode {
+	case "", dialect.SSLDisable, dialect.SSLPrefer:
+		return nil, nil
+	case dialect.SSLRequire, dialect.SSLVerifyCA, dialect.SSLVerifyFull:
+	default:
+		return nil, fmt.Errorf("unknown MySQL SSL mode %q (use disable, prefer, require, verify-ca or verify-full)", config.SSLMode)
+	}
+
+	if (config.SSLCert == "") != (config.SSLKey == "") {
+		return nil, fmt.Errorf("MySQL client certificates require both ssl_cert and ssl_key")
+	}
+	if config.SSLCert == "" && config.SSLRootCert == "" && config.SSLMode != dialect.SSLVerifyCA {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{ServerName: config.Host, MinVersion: tls.VersionTLS12}
+
+	if config.SSLRootCert != "" {
+		pem, err := os.ReadFile(config.SSLRootCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssl_root_cert: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ssl_root_cert %s", config.SSLRootCert)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if config.SSLCert != "" {
+		cert, err := tls.LoadX509KeyPair(config.SSLCert, config.SSLKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	switch config.SSLMode {
+	case dialect.SSLRequire:
+		cfg.InsecureSkipVerify = true
+	case dialect.SSLVerifyCA:
+		// Check the chain without the host name, which Go only skips
+		// together with the whole verification
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyPeerCertificate = verifyChain(cfg.RootCAs)
+	}
+
+	return cfg, nil
+}
+
+// verifyChain returns a callback checking the server certificate chain
+// against roots, or the system pool when roots is nil
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return fmt.Errorf("server sent no certificate")
+		}
+		certs := make([]*x509.Certificate, len(rawCerts))
+		for i, raw := range rawCerts {
+			cert, err := x509.ParseCertificate(raw)
+			if err != nil {
+				return fmt.Errorf("invalid server certificate: %w", err)
+			}
+			certs[i] = cert
+		}
+		opts := x509.VerifyOptions{Roots: roots, Intermediates: x509.NewCertPool()}
+		for _, cert := range certs[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := certs[0].Verify(opts)
+		return err
+	}
+}
+
+// tlsParam returns the tls parameter of the DSN. Custom configurations are
+// registered with the driver under a name derived from the settings, so
+// connections with the same settings share it.
+func tlsParam(config dialect.Config) (string, error) {
+	cfg, err := tlsConfig(config)
+	if err != nil {
+		return "", err
+	}
+	if cfg == nil {
+		switch config.SSLMode {
+		case dialect.SSLDisable:
+			return "false", nil
+		case dialect.SSLPrefer:
+			return "preferred", nil
+		case dialect.SSLRequire:
+			return "skip-verify", nil
+		case dialect.SSLVerifyFull:
+			return "true", nil
+		}
+		return "", nil
+	}
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%s|%s",
+		config.SSLMode, config.Host, config.SSLRootCert, config.SSLCert, config.SSLKey)))
+	name := "db-mcp-" + hex.EncodeToString(sum[:8])
+	if err := driver.RegisterTLSConfig(name, cfg); err != nil {
+		return "", err
+	}
+	return name, nil
+}
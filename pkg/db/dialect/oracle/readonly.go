@@ -0,0 +1,105 @@
+package oracle
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	go_ora "github.com/sijms/go-ora/v2"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+)
+
+// syntax is the Oracle quoting and comment syntax
+var syntax = dialect.Syntax{QStrings: true}
+
+// Syntax returns the Oracle syntax
+func (Dialect) Syntax() dialect.Syntax { return syntax }
+
+// readOnlyStatements are the keywords a statement of a read-only connection
+// may start with
+var readOnlyStatements = map[string]bool{
+	"SELECT": true,
+	"WITH":   true,
+}
+
+// writeKeywords are the reserved words that write within a query, such as
+// SELECT ... INTO or FOR UPDATE, or start a statement that writes. Being
+// reserved, they cannot appear unquoted as names in a query. Other commands,
+// such as MERGE, CALL, COMMIT or PL/SQL blocks, are caught by the first word
+// of the statement instead, since their keywords may be names.
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "INTO": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "RENAME": true, "COMMENT": true,
+	"GRANT": true, "REVOKE": true, "AUDIT": true, "NOAUDIT": true, "LOCK": true, "SET": true,
+}
+
+// checkReadOnly rejects statements other than queries. A read-only
+// transaction would end at the first commit, including one in a PL/SQL
+// block, so statements are checked instead. Planning a query with EXPLAIN
+// PLAN and clearing the session's plan table, as the cost guard does, are
+// allowed.
+func checkReadOnly(query string) error {
+	statements := syntax.Statements(query)
+	if len(statements) == 0 {
+		return fmt.Errorf("read-only connection: only SELECT queries are allowed")
+	}
+	for _, words := range statements {
+		if err := checkStatement(words); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkStatement checks the words of a single statement
+func checkStatement(words []string) error {
+	if hasWords(words, "DELETE", "FROM", "PLAN_TABLE") {
+		return checkWords(words[3:])
+	}
+	if hasWords(words, "EXPLAIN", "PLAN", "SET", "STATEMENT_ID", "FOR") {
+		words = words[5:]
+	} else if hasWords(words, "EXPLAIN", "PLAN", "FOR") {
+		words = words[3:]
+	}
+	if len(words) == 0 || !readOnlyStatements[words[0]] {
+		return fmt.Errorf("read-only connection: only SELECT queries are allowed")
+	}
+	// WITH FUNCTION and WITH PROCEDURE declare PL/SQL, which may write
+	if hasWords(words, "WITH", "FUNCTION") || hasWords(words, "WITH", "PROCEDURE") {
+		return fmt.Errorf("read-only connection: PL/SQL declarations are not allowed")
+	}
+	return checkWords(words)
+}
+
+// hasWords reports whether a statement starts with the given words
+func hasWords(words []string, prefix ...string) bool {
+	if len(words) < len(prefix) {
+		return false
+	}
+	for i, word := range prefix {
+		if words[i] != word {
+			return false
+		}
+	}
+	return true
+}
+
+// checkWords rejects the words of a statement that may write
+func checkWords(words []string) error {
+	for _, word := range words {
+		if writeKeywords[word] {
+			return fmt.Errorf("read-only connection: %s is not allowed", word)
+		}
+	}
+	return nil
+}
+
+// Connector returns a connector for read-only connections whose sessions
+// only run queries, checking every statement before it is sent. It returns
+// nil for other connections.
+func (Dialect) Connector(cfg dialect.Config, dsn string) (driver.Connector, error) {
+	if !cfg.ReadOnly {
+		return nil, nil
+	}
+	return dialect.ReadOnlyConnector(go_ora.NewConnector(dsn), checkReadOnly, ""), nil
+}
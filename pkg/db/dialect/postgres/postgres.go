@@ -0,0 +1,247 @@
+// Package postgres registers the PostgreSQL dialect, also used for
+// TimescaleDB.
+package postgres
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+	// Import the database driver
+	_ "github.com/lib/pq"
+)
+
+func init() {
+	dialect.Register(Dialect{}, "timescaledb")
+}
+
+// Dialect is the PostgreSQL dialect
+type Dialect struct {
+	dialect.Base
+}
+
+// Name returns "postgres"
+func (Dialect) Name() string { return "postgres" }
+
+// DriverName returns the lib/pq driver
+func (Dialect) DriverName(dialect.Config) string { return "postgres" }
+
+// SetDefaults disables SSL unless configured
+func (Dialect) SetDefaults(cfg *dialect.Config) {
+	if cfg.SSLMode == "" {
+		cfg.SSLMode = dialect.SSLDisable
+	}
+}
+
+// DSN builds a PostgreSQL connection string with all options
+func (Dialect) DSN(config dialect.Config) string {
+	params := make([]string, 0)
+
+	// Required parameters
+	params = append(params, fmt.Sprintf("host=%s", config.Host))
+	params = append(params, fmt.Sprintf("port=%d", config.Port))
+	params = append(params, fmt.Sprintf("user=%s", config.User))
+
+	if config.Password != "" {
+		params = append(params, fmt.Sprintf("password=%s", config.Password))
+	}
+
+	if config.Name != "" {
+		params = append(params, fmt.Sprintf("dbname=%s", config.Name))
+	}
+
+	// SSL configuration
+	params = append(params, fmt.Sprintf("sslmode=%s", config.SSLMode))
+
+	if config.SSLCert != "" {
+		params = append(params, fmt.Sprintf("sslcert=%s", config.SSLCert))
+	}
+
+	if config.SSLKey != "" {
+		params = append(params, fmt.Sprintf("sslkey=%s", config.SSLKey))
+	}
+
+	if config.SSLRootCert != "" {
+		params = append(params, fmt.Sprintf("sslrootcert=%s", config.SSLRootCert))
+	}
+
+	// Connection timeout
+	if config.ConnectTimeout > 0 {
+		params = append(params, fmt.Sprintf("connect_timeout=%d", config.ConnectTimeout))
+	}
+
+	// Application name for better identification in pg_stat_activity
+	if config.ApplicationName != "" {
+		params = append(params, fmt.Sprintf("application_name=%s", url.QueryEscape(config.ApplicationName)))
+	}
+
+	// Target session attributes for load balancing and failover (PostgreSQL 10+)
+	if config.TargetSessionAttrs != "" {
+		params = append(params, fmt.Sprintf("target_session_attrs=%s", config.TargetSessionAttrs))
+	}
+
+	// Add any additional options from the map
+	if config.Options != nil {
+		for key, value := range config.Options {
+			// Such as options=-c default_transaction_read_only=off
+			if config.ReadOnly && strings.Contains(strings.ToLower(key+"="+value), "read_only") {
+				logger.Warn("Ignoring option %s of read-only PostgreSQL connection to %s", key, config.Host)
+				continue
+			}
+			params = append(params, fmt.Sprintf("%s=%s", key, url.QueryEscape(value)))
+		}
+	}
+
+	// Sent as a run-time parameter, so every transaction of the session is
+	// read-only. It comes last, as the last value of a parameter wins.
+	if config.ReadOnly {
+		params = append(params, "default_transaction_read_only=on")
+	}
+
+	return strings.Join(params, " ")
+}
+
+// Describe returns the connection string with the password masked
+func (Dialect) Describe(config dialect.Config) string {
+	params := make([]string, 0)
+
+	params = append(params, fmt.Sprintf("host=%s", config.Host))
+	params = append(params, fmt.Sprintf("port=%d", config.Port))
+	params = append(params, fmt.Sprintf("user=%s", config.User))
+	params = append(params, "password=***")
+	params = append(params, fmt.Sprintf("dbname=%s", config.Name))
+
+	if string(config.SSLMode) != "" {
+		params = append(params, fmt.Sprintf("sslmode=%s", config.SSLMode))
+	}
+
+	if config.ApplicationName != "" {
+		params = append(params, fmt.Sprintf("application_name=%s", config.ApplicationName))
+	}
+
+	return strings.Join(params, " ")
+}
+
+// Placeholder returns $n
+func (Dialect) Placeholder(n int) string { return fmt.Sprintf("$%d", n) }
+
+// Explain plans queries with EXPLAIN (FORMAT JSON)
+func (Dialect) Explain() dialect.ExplainStyle { return dialect.ExplainPostgresJSON }
+
+// TablesQueries returns queries for retrieving tables in PostgreSQL
+func (Dialect) TablesQueries() []dialect.Query {
+	return []dialect.Query{
+		// Primary: pg_catalog approach
+		{SQL: "SELECT tablename as table_name FROM pg_catalog.pg_tables WHERE schemaname = 'public'"},
+		// Secondary: information_schema approach
+		{SQL: "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'"},
+		// Tertiary: pg_class approach
+		{SQL: "SELECT relname as table_name FROM pg_catalog.pg_class WHERE relkind = 'r' AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public')"},
+	}
+}
+
+// ColumnsQueries returns queries for retrieving columns in PostgreSQL
+func (Dialect) ColumnsQueries(table string) []dialect.Query {
+	return []dialect.Query{
+		// Primary: information_schema approach for PostgreSQL
+		{
+			SQL: `
+				SELECT column_name, data_type,
+				CASE WHEN is_nullable = 'YES' THEN 'YES' ELSE 'NO' END as is_nullable,
+				column_default
+				FROM information_schema.columns
+				WHERE table_name = $1 AND table_schema = 'public'
+				ORDER BY ordinal_position
+			`,
+			Args: []interface{}{table},
+		},
+		// Secondary: pg_catalog approach for PostgreSQL
+		{
+			SQL: `
+				SELECT a.attname as column_name,
+				pg_catalog.format_type(a.atttypid, a.atttypmod) as data_type,
+				CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END as is_nullable,
+				pg_catalog.pg_get_expr(d.adbin, d.adrelid) as column_default
+				FROM pg_catalog.pg_attribute a
+				LEFT JOIN pg_catalog.pg_attrdef d ON (a.attrelid = d.adrelid AND a.attnum = d.adnum)
+				WHERE a.attrelid = (SELECT oid FROM pg_catalog.pg_class WHERE relname = $1 AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public'))
+				AND a.attnum > 0 AND NOT a.attisdropped
+				ORDER BY a.attnum
+			`,
+			Args: []interface{}{table},
+		},
+	}
+}
+
+// RelationshipsQueries returns queries for retrieving relationships in PostgreSQL
+func (Dialect) RelationshipsQueries(table string) []dialect.Query {
+	baseQueries := []dialect.Query{
+		// Primary: Standard information_schema approach for PostgreSQL
+		{
+			SQL: `
+				SELECT
+					tc.table_schema,
+					tc.constraint_name,
+					tc.table_name,
+					kcu.column_name,
+					ccu.table_schema AS foreign_table_schema,
+					ccu.table_name AS foreign_table_name,
+					ccu.column_name AS foreign_column_name
+				FROM information_schema.table_constraints AS tc
+				JOIN information_schema.key_column_usage AS kcu
+					ON tc.constraint_name = kcu.constraint_name
+					AND tc.table_schema = kcu.table_schema
+				JOIN information_schema.constraint_column_usage AS ccu
+					ON ccu.constraint_name = tc.constraint_name
+					AND ccu.table_schema = tc.table_schema
+				WHERE tc.constraint_type = 'FOREIGN KEY'
+					AND tc.table_schema = 'public'
+			`,
+			Args: []interface{}{},
+		},
+		// Alternate: Using pg_catalog for older PostgreSQL versions
+		{
+			SQL: `
+				SELECT
+					ns.nspname AS table_schema,
+					c.conname AS constraint_name,
+					cl.relname AS table_name,
+					att.attname AS column_name,
+					ns2.nspname AS foreign_table_schema,
+					cl2.relname AS foreign_table_name,
+					att2.attname AS foreign_column_name
+				FROM pg_constraint c
+				JOIN pg_class cl ON c.conrelid = cl.oid
+				JOIN pg_attribute att ON att.attrelid = cl.oid AND att.attnum = ANY(c.conkey)
+				JOIN pg_namespace ns ON ns.oid = cl.relnamespace
+				JOIN pg_class cl2 ON c.confrelid = cl2.oid
+				JOIN pg_attribute att2 ON att2.attrelid = cl2.oid AND att2.attnum = ANY(c.confkey)
+				JOIN pg_namespace ns2 ON ns2.oid = cl2.relnamespace
+				WHERE c.contype = 'f'
+				AND ns.nspname = 'public'
+			`,
+			Args: []interface{}{},
+		},
+	}
+
+	if table == "" {
+		return baseQueries
+	}
+
+	queries := make([]dialect.Query, len(baseQueries))
+
+	// Add table filter
+	queries[0] = dialect.Query{
+		SQL:  baseQueries[0].SQL + " AND (tc.table_name = $1 OR ccu.table_name = $1)",
+		Args: []interface{}{table},
+	}
+
+	queries[1] = dialect.Query{
+		SQL:  baseQueries[1].SQL + " AND (cl.relname = $1 OR cl2.relname = $1)",
+		Args: []interface{}{table},
+	}
+
+	return queries
+}
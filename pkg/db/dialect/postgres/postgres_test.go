@@ -0,0 +1,75 @@
+package postgres
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+)
+
+func TestReadOnlyConnectionString(t *testing.T) {
+	config := dialect.Config{Type: "postgres", Host: "localhost", Port: 5432, User: "user", Password: "pass", Name: "db", ReadOnly: true}
+	if dsn := (Dialect{}).DSN(config); !strings.Contains(dsn, "default_transaction_read_only=on") {
+		t.Errorf("expected read-only PostgreSQL sessions, got %s", dsn)
+	}
+	config.ReadOnly = false
+	if dsn := (Dialect{}).DSN(config); strings.Contains(dsn, "default_transaction_read_only") {
+		t.Errorf("expected writable PostgreSQL sessions, got %s", dsn)
+	}
+}
+
+func TestReadOnlyConnectionStringIgnoresOptions(t *testing.T) {
+	config := dialect.Config{Type: "postgres", Host: "localhost", Port: 5432, User: "user", Name: "db", ReadOnly: true,
+		Options: map[string]string{"default_transaction_read_only": "off", "options": "-c default_transaction_read_only=off", "search_path": "app"}}
+	dsn := (Dialect{}).DSN(config)
+	if strings.Contains(dsn, "=off") || !strings.HasSuffix(dsn, "default_transaction_read_only=on") {
+		t.Errorf("expected options not to change read-only settings, got %s", dsn)
+	}
+	if !strings.Contains(dsn, "search_path=app") {
+		t.Errorf("expected other options to be kept, got %s", dsn)
+	}
+}
+
+func TestReadOnlyStatements(t *testing.T) {
+	allowed := []string{
+		"SELECT * FROM orders",
+		"  -- recent orders\n select * from orders where note = 'delete; me'",
+		"WITH recent AS (SELECT id FROM orders) SELECT * FROM recent;",
+		"EXPLAIN (FORMAT JSON) SELECT 1",
+		"SHOW search_path",
+		"SELECT $$ ; SET x $$, E'\\' ; BEGIN' FROM t",
+	}
+	for _, query := range allowed {
+		if err := checkReadOnly(query); err != nil {
+			t.Errorf("expected %q to be allowed, got %v", query, err)
+		}
+	}
+
+	rejected := []string{
+		"",
+		"SET default_transaction_read_only = off",
+		"SET SESSION CHARACTERISTICS AS TRANSACTION READ WRITE",
+		"RESET ALL",
+		"BEGIN READ WRITE",
+		"START TRANSACTION READ WRITE",
+		"SELECT 1; DELETE FROM orders",
+		"SELECT 'x'; DELETE FROM orders",
+		"SELECT pg_catalog.set_config('default_transaction_read_only', 'off', false)",
+		"DO $$ BEGIN PERFORM 1; END $$",
+		"CALL refresh_continuous_aggregate('daily', NULL, NULL)",
+	}
+	for _, query := range rejected {
+		if err := checkReadOnly(query); err == nil {
+			t.Errorf("expected %q to be rejected", query)
+		}
+	}
+
+	d := Dialect{}
+	dsn := "host=localhost port=5432 user=user sslmode=disable"
+	if connector, err := d.Connector(dialect.Config{Type: "postgres"}, dsn); err != nil || connector != nil {
+		t.Errorf("expected no connector for writable connections, got %v, %v", connector, err)
+	}
+	if connector, err := d.Connector(dialect.Config{Type: "postgres", ReadOnly: true}, dsn); err != nil || connector == nil {
+		t.Errorf("expected a connector for read-only connections, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+)
+
+// syntax is the PostgreSQL quoting and comment syntax
+var syntax = dialect.Syntax{NestedComments: true, EStrings: true, DollarStrings: true}
+
+// Syntax returns the PostgreSQL syntax
+func (Dialect) Syntax() dialect.Syntax { return syntax }
+
+// readOnlyStatements are the keywords a statement of a read-only connection
+// may start with. Writes in them are rejected by the read-only transaction.
+var readOnlyStatements = map[string]bool{
+	"SELECT":  true,
+	"WITH":    true,
+	"VALUES":  true,
+	"TABLE":   true,
+	"EXPLAIN": true,
+	"SHOW":    true,
+}
+
+// resetReadOnly makes a session read-only again before it is reused
+const resetReadOnly = "SET default_transaction_read_only = on"
+
+// checkReadOnly rejects a statement unless it is a single query. The
+// session setting makes every transaction read-only, so the statements that
+// could end or change it are kept out: SET, RESET, BEGIN READ WRITE,
+// set_config() and batches, which lib/pq sends as one message when a query
+// has no parameters. A change that gets past the check, such as from a
+// function calling set_config, lasts until the session is reused.
+func checkReadOnly(query string) error {
+	statements := syntax.Statements(query)
+	if len(statements) > 1 {
+		return fmt.Errorf("read-only connection: only one statement is allowed")
+	}
+	if len(statements) == 0 || !readOnlyStatements[statements[0][0]] {
+		return fmt.Errorf("read-only connection: only SELECT queries are allowed")
+	}
+	for _, word := range statements[0] {
+		if word == "SET_CONFIG" {
+			return fmt.Errorf("read-only connection: set_config is not allowed")
+		}
+	}
+	return nil
+}
+
+// Connector returns a connector for read-only connections whose sessions
+// only run queries, checking every statement before it is sent. It returns
+// nil for other connections.
+func (Dialect) Connector(cfg dialect.Config, dsn string) (driver.Connector, error) {
+	if !cfg.ReadOnly {
+		return nil, nil
+	}
+	connector, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return dialect.ReadOnlyConnector(connector, checkReadOnly, resetReadOnly), nil
+}
@@ -0,0 +1,328 @@
+package dialect
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Syntax describes how an engine writes comments, strings and quoted names,
+// for reading the keywords of a statement
+type Syntax struct {
+	BracketNames       bool // [name] is a quoted name, as in SQL Server
+	BacktickNames      bool // `name` is a quoted name, as in MySQL
+	NestedComments     bool // /* */ comments nest, as in SQL Server and PostgreSQL
+	HashComments       bool // # starts a comment, as in MySQL
+	DashCommentSpace   bool // -- only starts a comment before a space, as in MySQL
+	ExecutableComments bool // /*! */ comments are run, as in MySQL
+	BackslashEscapes   bool // \ escapes a character in strings, as in MySQL
+	QStrings           bool // q'[text]' is a string, as in Oracle
+	EStrings           bool // E'text' is a string with \ escapes, as in PostgreSQL
+	DollarStrings      bool // $tag$text$tag$ is a string, as in PostgreSQL
+}
+
+// Words returns the unquoted words of a statement in upper case, skipping
+// comments, string literals and quoted names
+func (s Syntax) Words(query string) []string {
+	var words []string
+	for _, statement := range s.Statements(query) {
+		words = append(words, statement...)
+	}
+	return words
+}
+
+// Statements splits a batch at semicolons into the words of each statement,
+// as Words returns them. Empty statements are left out.
+func (s Syntax) Statements(query string) [][]string {
+	var statements [][]string
+	var words []string
+	runes := []rune(query)
+	at := func(i int, r rune) bool { return i < len(runes) && runes[i] == r }
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case r == '-' && at(i+1, '-') && (!s.DashCommentSpace || i+2 == len(runes) || unicode.IsSpace(runes[i+2])),
+			r == '#' && s.HashComments:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '/' && at(i+1, '*') && s.ExecutableComments && at(i+2, '!'):
+			// The comment is run, without its version number
+			for i += 3; i < len(runes) && unicode.IsDigit(runes[i]); i++ {
+			}
+		case r == '/' && at(i+1, '*'):
+			depth := 0
+			for i < len(runes) {
+				if runes[i] == '/' && at(i+1, '*') {
+					depth++
+					i += 2
+				} else if runes[i] == '*' && at(i+1, '/') {
+					depth--
+					i += 2
+					if depth == 0 || !s.NestedComments {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+		case r == '\'' || r == '"':
+			i = skipQuoted(runes, i, r, s.BackslashEscapes)
+		case r == '[' && s.BracketNames:
+			i = skipQuoted(runes, i, ']', false)
+		case r == '`' && s.BacktickNames:
+			i = skipQuoted(runes, i, '`', false)
+		case r == '$' && s.DollarStrings && dollarTag(runes, i) != "":
+			tag := []rune(dollarTag(runes, i))
+			for i += len(tag); i < len(runes) && !hasPrefix(runes[i:], tag); i++ {
+			}
+			i += len(tag)
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			word := strings.ToUpper(string(runes[start:i]))
+			if s.QStrings && (word == "Q" || word == "NQ") && at(i, '\'') && i+1 < len(runes) {
+				i = skipQString(runes, i+1)
+				continue
+			}
+			if s.EStrings && word == "E" && at(i, '\'') {
+				i = skipQuoted(runes, i, '\'', true)
+				continue
+			}
+			words = append(words, word)
+		case r == ';':
+			if len(words) > 0 {
+				statements = append(statements, words)
+				words = nil
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	if len(words) > 0 {
+		statements = append(statements, words)
+	}
+	return statements
+}
+
+// skipQuoted returns the position after the string or quoted name starting
+// at i. A doubled closing character is escaped, as is any character after a
+// backslash when backslash is set.
+func skipQuoted(runes []rune, i int, closing rune, backslash bool) int {
+	for i++; i < len(runes); i++ {
+		switch {
+		case backslash && runes[i] == '\\':
+			i++
+		case runes[i] == closing:
+			if i+1 < len(runes) && runes[i+1] == closing {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return i
+}
+
+// dollarTag returns the $tag$ opening a dollar-quoted string at i, or ""
+// if there is none there. Tags are names, so $1 is a parameter.
+func dollarTag(runes []rune, i int) string {
+	for j := i + 1; j < len(runes); j++ {
+		r := runes[j]
+		switch {
+		case r == '$':
+			return string(runes[i : j+1])
+		case r == '_' || unicode.IsLetter(r) || (j > i+1 && unicode.IsDigit(r)):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+// hasPrefix reports whether runes starts with prefix
+func hasPrefix(runes, prefix []rune) bool {
+	return len(runes) >= len(prefix) && string(runes[:len(prefix)]) == string(prefix)
+}
+
+// skipQString returns the position after the q'<open>text<close>' string
+// whose opening delimiter is at i
+func skipQString(runes []rune, i int) int {
+	closing := runes[i]
+	switch closing {
+	case '[':
+		closing = ']'
+	case '(':
+		closing = ')'
+	case '{':
+		closing = '}'
+	case '<':
+		closing = '>'
+	}
+	for i++; i < len(runes); i++ {
+		if runes[i] == closing && i+1 < len(runes) && runes[i+1] == '\'' {
+			return i + 2
+		}
+	}
+	return i
+}
+
+// isWordRune reports whether a rune is part of a keyword, name or variable
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '@' || r == '#' || r == '$'
+}
+
+// ReadOnlyConnector wraps a connector so that its sessions run a statement
+// only when check allows it, whether it is queried, executed or prepared.
+// For engines with a read-only session setting, reset is run whenever a
+// session is reused, so that a change to the setting that got past check
+// does not outlive the statement that made it.
+func ReadOnlyConnector(connector driver.Connector, check func(query string) error, reset string) driver.Connector {
+	return &readOnlyConnector{connector: connector, check: check, reset: reset}
+}
+
+// readOnlyConnector opens sessions that check their statements
+type readOnlyConnector struct {
+	connector driver.Connector
+	check     func(query string) error
+	reset     string
+}
+
+// Connect opens a session
+func (c *readOnlyConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &readOnlyConn{Conn: conn, check: c.check, reset: c.reset}, nil
+}
+
+// Driver returns the underlying driver
+func (c *readOnlyConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// readOnlyConn is a session that checks statements before they are sent.
+// The optional interfaces of the driver's session are passed through.
+type readOnlyConn struct {
+	driver.Conn
+	check func(query string) error
+	reset string
+}
+
+// Prepare checks and prepares a statement
+func (c *readOnlyConn) Prepare(query string) (driver.Stmt, error) {
+	if err := c.check(query); err != nil {
+		return nil, err
+	}
+	return c.Conn.Prepare(query)
+}
+
+// PrepareContext checks and prepares a statement
+func (c *readOnlyConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if err := c.check(query); err != nil {
+		return nil, err
+	}
+	if pc, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return pc.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+// QueryContext checks and runs a query. Without driver support the query
+// is prepared, and checked there.
+func (c *readOnlyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	qc, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.check(query); err != nil {
+		return nil, err
+	}
+	return qc.QueryContext(ctx, query, args)
+}
+
+// ExecContext checks and runs a statement. Without driver support the
+// statement is prepared, and checked there.
+func (c *readOnlyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	ec, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	if err := c.check(query); err != nil {
+		return nil, err
+	}
+	return ec.ExecContext(ctx, query, args)
+}
+
+// BeginTx begins a transaction. Transactions are not begun read-only, which
+// not every driver supports, since their statements are checked anyway.
+func (c *readOnlyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	opts.ReadOnly = false
+	if bc, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return bc.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin() //nolint:staticcheck // fallback for drivers without BeginTx
+}
+
+// Ping checks the session
+func (c *readOnlyConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ResetSession resets the session before it is reused, making it read-only
+// again. A session that cannot be made read-only is discarded.
+func (c *readOnlyConn) ResetSession(ctx context.Context) error {
+	if r, ok := c.Conn.(driver.SessionResetter); ok {
+		if err := r.ResetSession(ctx); err != nil {
+			return err
+		}
+	}
+	if c.reset == "" {
+		return nil
+	}
+	if err := c.exec(ctx, c.reset); err != nil {
+		return fmt.Errorf("%w: %v", driver.ErrBadConn, err)
+	}
+	return nil
+}
+
+// exec runs a statement of the connector itself, without checking it
+func (c *readOnlyConn) exec(ctx context.Context, query string) error {
+	if ec, ok := c.Conn.(driver.ExecerContext); ok {
+		_, err := ec.ExecContext(ctx, query, nil)
+		return err
+	}
+	stmt, err := c.Conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = stmt.Close() }()
+	_, err = stmt.Exec(nil) //nolint:staticcheck // fallback for drivers without ExecerContext
+	return err
+}
+
+// IsValid reports whether the session can be reused
+func (c *readOnlyConn) IsValid() bool {
+	if v, ok := c.Conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+// CheckNamedValue lets the driver convert its own parameter types
+func (c *readOnlyConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if nc, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return nc.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
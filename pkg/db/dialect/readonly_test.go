@@ -0,0 +1,122 @@
+package dialect
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyntaxWords(t *testing.T) {
+	tests := []struct {
+		syntax   Syntax
+		query    string
+		expected []string
+	}{
+		{Syntax{}, "select a -- drop\nfrom t where b = 'it''s delete'", []string{"SELECT", "A", "FROM", "T", "WHERE", "B"}},
+		{Syntax{}, `SELECT "update" FROM t`, []string{"SELECT", "FROM", "T"}},
+		{Syntax{}, "/* /* */ DELETE", []string{"DELETE"}},
+		{Syntax{NestedComments: true}, "/* /* */ DELETE */ SELECT", []string{"SELECT"}},
+		{Syntax{BracketNames: true}, "SELECT [drop]]table] FROM #tmp", []string{"SELECT", "FROM", "#TMP"}},
+		{Syntax{QStrings: true}, "SELECT q'[it's]', nq'{x}' FROM dual", []string{"SELECT", "FROM", "DUAL"}},
+		{Syntax{DollarStrings: true}, "SELECT $1, $$drop$$, $fn$ it's $$ $fn$ FROM t", []string{"SELECT", "$1", "FROM", "T"}},
+		{Syntax{EStrings: true}, `SELECT E'it\'s; set' FROM t`, []string{"SELECT", "FROM", "T"}},
+		{Syntax{}, `SELECT 'a\' FROM t`, []string{"SELECT", "FROM", "T"}},
+		{Syntax{BackslashEscapes: true}, `SELECT 'it\'s', "\"" FROM t`, []string{"SELECT", "FROM", "T"}},
+		{Syntax{BacktickNames: true, HashComments: true}, "SELECT `delete` # drop\nFROM t", []string{"SELECT", "FROM", "T"}},
+		{Syntax{DashCommentSpace: true}, "SELECT 1--1 FROM t -- drop", []string{"SELECT", "1", "1", "FROM", "T"}},
+		{Syntax{ExecutableComments: true}, "SELECT /*!50000 SQL_NO_CACHE */ 1 /* drop */", []string{"SELECT", "SQL_NO_CACHE", "1"}},
+	}
+	for _, tt := range tests {
+		if got := tt.syntax.Words(tt.query); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("Words(%q) = %v, expected %v", tt.query, got, tt.expected)
+		}
+	}
+}
+
+func TestSyntaxStatements(t *testing.T) {
+	syntax := Syntax{DollarStrings: true}
+	statements := syntax.Statements("SELECT ';'; ; DELETE FROM t; $$;$$ -- ;\n;")
+	expected := [][]string{{"SELECT"}, {"DELETE", "FROM", "T"}}
+	if !reflect.DeepEqual(statements, expected) {
+		t.Errorf("expected %v, got %v", expected, statements)
+	}
+}
+
+// fakeConnector opens fakeConns
+type fakeConnector struct{ ran *[]string }
+
+func (c fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn(c), nil }
+func (c fakeConnector) Driver() driver.Driver                        { return nil }
+
+// fakeConn records the statements it runs
+type fakeConn struct{ ran *[]string }
+
+func (c fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c fakeConn) Close() error                        { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	*c.ran = append(*c.ran, query)
+	return driver.RowsAffected(0), nil
+}
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	*c.ran = append(*c.ran, query)
+	return emptyRows{}, nil
+}
+
+// emptyRows is a result without rows
+type emptyRows struct{}
+
+func (emptyRows) Columns() []string         { return []string{"x"} }
+func (emptyRows) Close() error              { return nil }
+func (emptyRows) Next([]driver.Value) error { return io.EOF }
+
+func TestReadOnlyConnector(t *testing.T) {
+	var ran []string
+	check := func(query string) error {
+		if !strings.HasPrefix(query, "SELECT") {
+			return errors.New("read-only connection")
+		}
+		return nil
+	}
+	db := sql.OpenDB(ReadOnlyConnector(fakeConnector{ran: &ran}, check, ""))
+	defer func() { _ = db.Close() }()
+
+	rows, err := db.Query("SELECT 1")
+	if err != nil {
+		t.Fatalf("expected query to run, got %v", err)
+	}
+	_ = rows.Close()
+	if _, err := db.Exec("DELETE FROM t"); err == nil {
+		t.Error("expected DELETE to be rejected")
+	}
+	if _, err := db.Query("DELETE FROM t RETURNING *"); err == nil {
+		t.Error("expected DELETE to be rejected as a query")
+	}
+	if !reflect.DeepEqual(ran, []string{"SELECT 1"}) {
+		t.Errorf("expected only the query to run, ran %v", ran)
+	}
+}
+
+func TestReadOnlyConnectorResetsSessions(t *testing.T) {
+	var ran []string
+	db := sql.OpenDB(ReadOnlyConnector(fakeConnector{ran: &ran}, func(string) error { return nil }, "SET read_only"))
+	defer func() { _ = db.Close() }()
+	db.SetMaxOpenConns(1)
+
+	for i := 0; i < 2; i++ {
+		if _, err := db.Exec("SELECT 1"); err != nil {
+			t.Fatalf("expected query to run, got %v", err)
+		}
+	}
+	expected := []string{"SELECT 1", "SET read_only", "SELECT 1"}
+	if !reflect.DeepEqual(ran, expected) {
+		t.Errorf("expected the session to be reset before it is reused, ran %v", ran)
+	}
+}
@@ -0,0 +1,121 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+)
+
+// schemaName matches the names databases can be attached as
+var schemaName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// uriEscaper escapes the characters of a path that are special in SQLite URI
+// filenames
+var uriEscaper = strings.NewReplacer("%", "%25", "?", "%3f", "#", "%23")
+
+// validateAttach checks the schema names and paths of attached databases
+func validateAttach(attach map[string]string) error {
+	for _, name := range schemaNames(attach) {
+		if !schemaName.MatchString(name) {
+			return fmt.Errorf("invalid attached database name %q (use letters, digits and underscores)", name)
+		}
+		if strings.EqualFold(name, "main") || strings.EqualFold(name, "temp") {
+			return fmt.Errorf("attached database name %q is reserved", name)
+		}
+		if attach[name] == "" {
+			return fmt.Errorf("attached database %s requires a path", name)
+		}
+	}
+	return nil
+}
+
+// schemaNames returns the names of the attached databases in order
+func schemaNames(attach map[string]string) []string {
+	names := make([]string, 0, len(attach))
+	for name := range attach {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Connector returns a connector attaching the databases of the connection to
+// every connection it opens, since an ATTACH only applies to the connection
+// it runs on. Attached databases of read-only connections are opened
+// read-only. It returns nil when there is nothing to attach.
+func (d Dialect) Connector(cfg dialect.Config, dsn string) (driver.Connector, error) {
+	if len(cfg.Attach) == 0 {
+		return nil, nil
+	}
+
+	// Find the registered driver without opening a connection
+	db, err := sql.Open(d.DriverName(cfg), dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+
+	c := &attachConnector{driver: drv, dsn: dsn}
+	for _, name := range schemaNames(cfg.Attach) {
+		path := cfg.Attach[name]
+		if path != ":memory:" {
+			path = filepath.Clean(path)
+			if cfg.ReadOnly {
+				path = "file:" + uriEscaper.Replace(path) + "?mode=ro"
+			}
+		}
+		c.attach = append(c.attach, attachment{name: name, path: path})
+	}
+	return c, nil
+}
+
+// attachment is a database attached as a schema
+type attachment struct {
+	name string
+	path string
+}
+
+// attachConnector opens connections with the driver and attaches databases
+// to them
+type attachConnector struct {
+	driver driver.Driver
+	dsn    string
+	attach []attachment
+}
+
+// Connect opens a connection and attaches the databases
+func (c *attachConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.driver.Open(c.dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	execer, ok := conn.(driver.ExecerContext)
+	if !ok {
+		_ = conn.Close()
+		return nil, fmt.Errorf("SQLite driver cannot attach databases")
+	}
+	for _, a := range c.attach {
+		args := []driver.NamedValue{{Ordinal: 1, Value: a.path}}
+		if _, err := execer.ExecContext(ctx, "ATTACH DATABASE ? AS "+Dialect{}.QuoteIdentifier(a.name), args); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("failed to attach %s as %s: %w", a.path, a.name, err)
+		}
+	}
+	return conn, nil
+}
+
+// Driver returns the underlying driver
+func (c *attachConnector) Driver() driver.Driver {
+	return c.driver
+}
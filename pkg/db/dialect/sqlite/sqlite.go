@@ -0,0 +1,331 @@
+// Package sqlite registers the SQLite dialect. Connections use the CGO-free
+// modernc.org/sqlite driver, or mattn/go-sqlite3 for SQLCipher encryption.
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+	// Import the database drivers
+	_ "github.com/mattn/go-sqlite3"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	dialect.Register(Dialect{}, "sqlite3")
+}
+
+// Dialect is the SQLite dialect. Schemas are the names of the databases
+// attached to the connection, listed by the catalog queries after main.
+type Dialect struct {
+	dialect.Base
+	Schemas []string
+}
+
+// Scope returns the dialect listing the databases attached to a connection
+func (Dialect) Scope(cfg dialect.Config) dialect.Dialect {
+	return Dialect{Schemas: schemaNames(cfg.Attach)}
+}
+
+// Name returns "sqlite"
+func (Dialect) Name() string { return "sqlite" }
+
+// DriverName returns the modernc.org/sqlite driver, or mattn/go-sqlite3 for
+// encrypted databases
+func (Dialect) DriverName(config dialect.Config) string {
+	if config.UseModerncDriver || config.EncryptionKey == "" {
+		return "sqlite"
+	}
+	// Use mattn/go-sqlite3 driver for SQLCipher
+	return "sqlite3"
+}
+
+// SetDefaults sets the journal mode and cache size, and uses the name as the
+// path if no path is given
+func (Dialect) SetDefaults(c *dialect.Config) {
+	if c.JournalMode == "" {
+		c.JournalMode = dialect.JournalWAL // Default to WAL mode for better concurrency
+	}
+	if c.CacheSize == 0 {
+		c.CacheSize = 2000 // Default cache size
+	}
+	if c.DatabasePath == "" && c.Name != "" {
+		c.DatabasePath = c.Name
+	}
+}
+
+// Validate requires a database path, and valid schema names and paths for
+// the attached databases
+func (Dialect) Validate(config dialect.Config) error {
+	if config.DatabasePath == "" && config.Name == "" {
+		return fmt.Errorf("SQLite databases require either database_path or name to be specified")
+	}
+	return validateAttach(config.Attach)
+}
+
+// path returns the database file, or ":memory:"
+func path(config dialect.Config) string {
+	if config.DatabasePath != "" {
+		return config.DatabasePath
+	}
+	return config.Name
+}
+
+// DSN builds a SQLite connection string with all options
+func (Dialect) DSN(config dialect.Config) string {
+	dbPath := path(config)
+
+	// Handle special SQLite paths
+	if dbPath == ":memory:" {
+		return ":memory:"
+	}
+
+	// Clean the path
+	dbPath = filepath.Clean(dbPath)
+
+	// Build query parameters
+	params := make(url.Values)
+
+	// Read-only mode
+	if config.ReadOnly {
+		params.Set("mode", "ro")
+	} else {
+		params.Set("mode", "rwc")
+	}
+
+	// Cache size
+	if config.CacheSize > 0 {
+		params.Set("cache", "shared")
+		// Cache size will be set via PRAGMA after connection
+	}
+
+	// Journal mode
+	if config.JournalMode != "" {
+		params.Set("_journal_mode", string(config.JournalMode))
+	}
+
+	// Foreign key constraints
+	params.Set("_foreign_keys", "enabled")
+
+	// SQLCipher encryption key
+	if config.EncryptionKey != "" {
+		params.Set("_pragma_key", config.EncryptionKey)
+		// Set cipher page size for SQLCipher compatibility
+		params.Set("_cipher_page_size", "4096")
+	}
+
+	// Additional options
+	if config.Options != nil {
+		for key, value := range config.Options {
+			params.Set(key, value)
+		}
+	}
+
+	// Build the final connection string
+	connStr := fmt.Sprintf("file:%s", dbPath)
+	if len(params) > 0 {
+		connStr += "?" + params.Encode()
+	}
+
+	return connStr
+}
+
+// Describe returns the database path, without the encryption key
+func (Dialect) Describe(config dialect.Config) string {
+	dbPath := path(config)
+	if dbPath == ":memory:" {
+		return "SQLite in-memory database"
+	}
+
+	// Mask encryption key if present
+	if config.EncryptionKey != "" {
+		return fmt.Sprintf("SQLite database: %s (encrypted)", dbPath)
+	}
+	return fmt.Sprintf("SQLite database: %s", dbPath)
+}
+
+// OnConnect sets the cache size and tuning pragmas
+func (Dialect) OnConnect(db *sql.DB, config dialect.Config) {
+	// Set cache size if specified
+	if config.CacheSize > 0 {
+		_, err := db.Exec(fmt.Sprintf("PRAGMA cache_size = %d", config.CacheSize))
+		if err != nil {
+			logger.Warn("Failed to set SQLite cache size: %v", err)
+		}
+	}
+
+	// Additional SQLite optimizations
+	pragmas := []string{
+		"PRAGMA synchronous = NORMAL",
+		"PRAGMA temp_store = MEMORY",
+		"PRAGMA mmap_size = 268435456", // 256MB
+	}
+
+	for _, pragma := range pragmas {
+		_, err := db.Exec(pragma)
+		if err != nil {
+			logger.Warn("Failed to execute SQLite pragma '%s': %v", pragma, err)
+		}
+	}
+}
+
+// TablesQueries returns queries for retrieving tables in SQLite. With
+// attached databases, tables are listed per schema, the attached ones
+// qualified with their schema name.
+func (d Dialect) TablesQueries() []dialect.Query {
+	queries := []dialect.Query{
+		// Primary: sqlite_master approach
+		{SQL: "SELECT name as table_name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"},
+		// Secondary: sqlite_master with different filter
+		{SQL: "SELECT name as table_name FROM sqlite_master WHERE type='table' AND name NOT IN ('sqlite_sequence', 'sqlite_stat1')"},
+		// Tertiary: PRAGMA approach
+		{SQL: "SELECT name as table_name FROM pragma_table_list() WHERE type='table' AND schema='main' AND name NOT LIKE 'sqlite_%'"},
+	}
+	if len(d.Schemas) == 0 {
+		return queries
+	}
+
+	// Attached databases: PRAGMA approach across schemas
+	attached := dialect.Query{SQL: `
+		SELECT CASE WHEN schema = 'main' THEN name ELSE schema || '.' || name END as table_name,
+		schema as table_schema
+		FROM pragma_table_list()
+		WHERE type='table' AND schema <> 'temp' AND name NOT LIKE 'sqlite_%'
+		ORDER BY schema <> 'main', schema, name
+	`}
+	return append([]dialect.Query{attached}, queries...)
+}
+
+// split splits a table name qualified with the name of an attached database
+func (d Dialect) split(table string) (schema, name string) {
+	if i := strings.Index(table, "."); i > 0 && slices.Contains(d.Schemas, table[:i]) {
+		return table[:i], table[i+1:]
+	}
+	return "main", table
+}
+
+// ColumnsQueries returns queries for retrieving columns in SQLite
+func (d Dialect) ColumnsQueries(table string) []dialect.Query {
+	schema, name := d.split(table)
+	prefix := ""
+	if schema != "main" {
+		prefix = d.QuoteIdentifier(schema) + "."
+	}
+
+	return []dialect.Query{
+		// Primary: PRAGMA table_info approach
+		{
+			SQL:  "PRAGMA " + prefix + "table_info(" + d.QuoteIdentifier(name) + ")",
+			Args: []interface{}{},
+		},
+		// Secondary: sqlite_master approach for column info
+		{
+			SQL: `
+				SELECT p.name as column_name, p.type as data_type,
+				CASE WHEN p."notnull" = 0 THEN 'YES' ELSE 'NO' END as is_nullable,
+				p.dflt_value as column_default
+				FROM pragma_table_info(?, ?) p
+				ORDER BY p.cid
+			`,
+			Args: []interface{}{name, schema},
+		},
+		// Tertiary: Using sqlite_master with parsing
+		{
+			SQL: `
+				SELECT
+					CASE
+						WHEN sql LIKE 'CREATE TABLE % (' THEN
+							SUBSTR(SUBSTR(sql, INSTR(sql, '(') + 1),
+								INSTR(SUBSTR(sql, INSTR(sql, '(') + 1), ',') + 1)
+					END as column_info
+				FROM ` + prefix + `sqlite_master
+				WHERE type='table' AND name=?
+			`,
+			Args: []interface{}{name},
+		},
+	}
+}
+
+// foreignKeys returns the query for the foreign keys of all tables in a
+// schema. Schema names are checked by Validate, so they are safe to quote as
+// literals.
+func (d Dialect) foreignKeys(schema string) string {
+	prefix := ""
+	if schema != "main" {
+		prefix = d.QuoteIdentifier(schema) + "."
+	}
+	return `
+				SELECT
+					'` + schema + `' as table_schema,
+					'fk_' || m.name || '_' || f.id as constraint_name,
+					m.name as table_name,
+					f."from" as column_name,
+					'` + schema + `' as foreign_table_schema,
+					f."table" as foreign_table_name,
+					f."to" as foreign_column_name
+				FROM ` + prefix + `sqlite_master m
+				JOIN pragma_foreign_key_list(m.name, '` + schema + `') f
+				WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
+			`
+}
+
+// RelationshipsQueries returns queries for retrieving relationships in SQLite
+func (d Dialect) RelationshipsQueries(table string) []dialect.Query {
+	schema, name := d.split(table)
+
+	// Primary: PRAGMA foreign_key_list approach, across attached databases
+	all := []string{d.foreignKeys("main")}
+	for _, attached := range d.Schemas {
+		all = append(all, d.foreignKeys(attached))
+	}
+
+	baseQueries := []dialect.Query{
+		{
+			SQL:  strings.Join(all, "UNION ALL"),
+			Args: []interface{}{},
+		},
+		// Secondary: Using sqlite_master with foreign_key_list
+		{
+			SQL: `
+				SELECT
+					'` + schema + `' as table_schema,
+					'fk_' || ? || '_' || id as constraint_name,
+					? as table_name,
+					"from" as column_name,
+					'` + schema + `' as foreign_table_schema,
+					"table" as foreign_table_name,
+					"to" as foreign_column_name
+				FROM pragma_foreign_key_list(?, ?)
+			`,
+			Args: []interface{}{name, name, name, schema},
+		},
+		// Tertiary: Using table_info to check for foreign keys
+		{
+			SQL: `
+				SELECT
+					'` + schema + `' as table_schema,
+					'fk_check_' || name as constraint_name,
+					? as table_name,
+					name as column_name,
+					'` + schema + `' as foreign_table_schema,
+					'' as foreign_table_name,
+					'' as foreign_column_name
+				FROM pragma_table_info(?, ?)
+				WHERE pk > 0
+			`,
+			Args: []interface{}{name, name, schema},
+		},
+	}
+
+	if table == "" {
+		return baseQueries[:1] // Only use the first query for all tables
+	}
+
+	return baseQueries
+}
@@ -0,0 +1,68 @@
+package sqlserver
+
+import (
+	"database/sql/driver"
+	"fmt"
+
+	mssql "github.com/microsoft/go-mssqldb"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+)
+
+// syntax is the T-SQL quoting and comment syntax
+var syntax = dialect.Syntax{BracketNames: true, NestedComments: true}
+
+// Syntax returns the T-SQL syntax
+func (Dialect) Syntax() dialect.Syntax { return syntax }
+
+// readOnlyStatements are the keywords a statement of a read-only connection
+// may start with
+var readOnlyStatements = map[string]bool{
+	"SELECT": true,
+	"WITH":   true,
+}
+
+// writeKeywords are keywords that change data, schema, permissions or the
+// session, or run code that may. They are reserved words, so they cannot
+// appear unquoted as names in a query.
+var writeKeywords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "INTO": true, "TRUNCATE": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRIGGER": true,
+	"GRANT": true, "REVOKE": true, "DENY": true,
+	"EXEC": true, "EXECUTE": true, "OPENQUERY": true, "OPENROWSET": true, "OPENDATASOURCE": true,
+	"BACKUP": true, "RESTORE": true, "BULK": true, "DBCC": true, "WRITETEXT": true, "UPDATETEXT": true,
+	"SET": true, "USE": true, "KILL": true, "SHUTDOWN": true, "RECONFIGURE": true, "CHECKPOINT": true,
+}
+
+// checkReadOnly rejects a batch unless it is a query, since SQL Server has
+// no read-only session setting. The SHOWPLAN_XML switch used by Plan is
+// allowed.
+func checkReadOnly(query string) error {
+	words := syntax.Words(query)
+	if len(words) == 3 && words[0] == "SET" && words[1] == "SHOWPLAN_XML" && (words[2] == "ON" || words[2] == "OFF") {
+		return nil
+	}
+	if len(words) == 0 || !readOnlyStatements[words[0]] {
+		return fmt.Errorf("read-only connection: only SELECT queries are allowed")
+	}
+	for _, word := range words {
+		if writeKeywords[word] {
+			return fmt.Errorf("read-only connection: %s is not allowed", word)
+		}
+	}
+	return nil
+}
+
+// Connector returns a connector for read-only connections whose sessions
+// only run queries, checking every statement before it is sent. It returns
+// nil for other connections.
+func (Dialect) Connector(cfg dialect.Config, dsn string) (driver.Connector, error) {
+	if !cfg.ReadOnly {
+		return nil, nil
+	}
+	connector, err := mssql.NewConnector(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return dialect.ReadOnlyConnector(connector, checkReadOnly, ""), nil
+}
@@ -0,0 +1,299 @@
+// Package sqlserver registers the Microsoft SQL Server dialect, using the
+// pure Go go-mssqldb driver.
+package sqlserver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+	// Import the database driver
+	_ "github.com/microsoft/go-mssqldb"
+)
+
+func init() {
+	dialect.Register(Dialect{}, "mssql")
+}
+
+// Dialect is the SQL Server dialect
+type Dialect struct {
+	dialect.Base
+}
+
+// Name returns "sqlserver"
+func (Dialect) Name() string { return "sqlserver" }
+
+// DriverName returns the go-mssqldb driver, which binds @p1 style parameters
+func (Dialect) DriverName(dialect.Config) string { return "sqlserver" }
+
+// Configure extracts the SQL Server settings from the options map
+func (Dialect) Configure(cfg *dialect.Config) {
+	if instance, ok := cfg.Options["instance"]; ok {
+		cfg.Instance = instance
+	}
+	if encrypt, ok := cfg.Options["encrypt"]; ok {
+		cfg.Encrypt = encrypt
+	}
+	if trust, ok := cfg.Options["trust_server_certificate"]; ok {
+		cfg.TrustServerCertificate = trust == "true"
+	}
+}
+
+// SetDefaults sets the default port, unless a named instance is used
+func (Dialect) SetDefaults(cfg *dialect.Config) {
+	if cfg.Port == 0 && cfg.Instance == "" {
+		cfg.Port = 1433
+	}
+}
+
+// Validate requires a host and a known encryption setting
+func (Dialect) Validate(cfg dialect.Config) error {
+	if cfg.Host == "" {
+		return fmt.Errorf("SQL Server databases require a host")
+	}
+	switch cfg.Encrypt {
+	case "", "true", "false", "disable", "strict":
+	default:
+		return fmt.Errorf("unknown encrypt option %q (use true, false, disable or strict)", cfg.Encrypt)
+	}
+	return nil
+}
+
+// handledOptions are the options turned into Config fields by Configure
+var handledOptions = map[string]bool{
+	"instance":                 true,
+	"encrypt":                  true,
+	"trust_server_certificate": true,
+}
+
+// address returns the host and port, or the host and instance name
+func address(cfg dialect.Config) *url.URL {
+	u := &url.URL{Scheme: "sqlserver", Host: cfg.Host}
+	if cfg.Instance != "" {
+		// The port of a named instance is looked up unless given
+		u.Path = cfg.Instance
+		if cfg.Port > 0 {
+			u.Host = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+		}
+	} else if cfg.Port > 0 {
+		u.Host = net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	}
+	return u
+}
+
+// DSN builds a sqlserver:// connection URL. Read-only connections declare a
+// read-only application intent, which routes them to readable secondaries of
+// an availability group; their statements are checked by Connector, as the
+// intent alone does not prevent writes.
+func (Dialect) DSN(cfg dialect.Config) string {
+	u := address(cfg)
+	u.User = url.UserPassword(cfg.User, cfg.Password)
+
+	params := url.Values{}
+	if cfg.Name != "" {
+		params.Set("database", cfg.Name)
+	}
+	if cfg.Encrypt != "" {
+		params.Set("encrypt", cfg.Encrypt)
+	}
+	if cfg.TrustServerCertificate {
+		params.Set("TrustServerCertificate", "true")
+	}
+	if cfg.ConnectTimeout > 0 {
+		params.Set("connection timeout", strconv.Itoa(cfg.ConnectTimeout))
+	}
+	if cfg.ApplicationName != "" {
+		params.Set("app name", cfg.ApplicationName)
+	}
+	if cfg.ReadOnly {
+		params.Set("ApplicationIntent", "ReadOnly")
+	}
+	for key, value := range cfg.Options {
+		if !handledOptions[key] {
+			params.Set(key, value)
+		}
+	}
+
+	u.RawQuery = params.Encode()
+	return u.String()
+}
+
+// Describe returns the connection URL with the password masked
+func (Dialect) Describe(cfg dialect.Config) string {
+	u := address(cfg)
+	described := fmt.Sprintf("sqlserver://%s:***@%s", cfg.User, u.Host)
+	if cfg.Instance != "" {
+		described += "/" + cfg.Instance
+	}
+	if cfg.Name != "" {
+		described += "?database=" + cfg.Name
+	}
+	return described
+}
+
+// Placeholder returns @pn
+func (Dialect) Placeholder(n int) string { return fmt.Sprintf("@p%d", n) }
+
+// QuoteIdentifier quotes a name with brackets
+func (Dialect) QuoteIdentifier(name string) string {
+	return "[" + strings.ReplaceAll(name, "]", "]]") + "]"
+}
+
+// Explain reports that plans are shown with SET SHOWPLAN_XML
+func (Dialect) Explain() dialect.ExplainStyle { return dialect.ExplainShowplanXML }
+
+// Plan returns the estimated XML plan of a query. SHOWPLAN_XML applies to
+// the session, so the query is planned on a connection of its own.
+func (Dialect) Plan(ctx context.Context, db *sql.DB, query string) (string, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := conn.Close(); err != nil {
+			logger.Warn("Failed to release SQL Server connection: %v", err)
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, "SET SHOWPLAN_XML ON"); err != nil {
+		return "", fmt.Errorf("failed to enable SHOWPLAN_XML: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SET SHOWPLAN_XML OFF"); err != nil {
+			// Statements on this session would only be planned, so discard it
+			logger.Warn("Failed to disable SHOWPLAN_XML, closing the connection: %v", err)
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	rows, err := conn.QueryContext(ctx, query)
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	var plan strings.Builder
+	for rows.Next() {
+		var part string
+		if err := rows.Scan(&part); err != nil {
+			return "", fmt.Errorf("failed to read plan: %w", err)
+		}
+		plan.WriteString(part)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+	if plan.Len() == 0 {
+		return "", fmt.Errorf("no plan returned")
+	}
+	return plan.String(), nil
+}
+
+// TablesQueries returns queries for retrieving tables in SQL Server
+func (Dialect) TablesQueries() []dialect.Query {
+	return []dialect.Query{
+		// Primary: INFORMATION_SCHEMA approach
+		{SQL: "SELECT TABLE_NAME AS table_name FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_NAME"},
+		// Secondary: catalog views
+		{SQL: "SELECT name AS table_name FROM sys.tables WHERE is_ms_shipped = 0 ORDER BY name"},
+	}
+}
+
+// ColumnsQueries returns queries for retrieving columns in SQL Server
+func (Dialect) ColumnsQueries(table string) []dialect.Query {
+	return []dialect.Query{
+		// Primary: INFORMATION_SCHEMA approach
+		{
+			SQL: `
+				SELECT COLUMN_NAME AS column_name, DATA_TYPE AS data_type,
+				IS_NULLABLE AS is_nullable, COLUMN_DEFAULT AS column_default
+				FROM INFORMATION_SCHEMA.COLUMNS
+				WHERE TABLE_NAME = @p1
+				ORDER BY ORDINAL_POSITION
+			`,
+			Args: []interface{}{table},
+		},
+		// Secondary: catalog views
+		{
+			SQL: `
+				SELECT c.name AS column_name, t.name AS data_type,
+				CASE WHEN c.is_nullable = 1 THEN 'YES' ELSE 'NO' END AS is_nullable,
+				OBJECT_DEFINITION(c.default_object_id) AS column_default
+				FROM sys.columns c
+				JOIN sys.types t ON t.user_type_id = c.user_type_id
+				WHERE c.object_id = OBJECT_ID(@p1)
+				ORDER BY c.column_id
+			`,
+			Args: []interface{}{table},
+		},
+	}
+}
+
+// RelationshipsQueries returns queries for retrieving relationships in SQL Server
+func (Dialect) RelationshipsQueries(table string) []dialect.Query {
+	queries := []dialect.Query{
+		// Primary: catalog views
+		{
+			SQL: `
+				SELECT
+					SCHEMA_NAME(fk.schema_id) AS table_schema,
+					fk.name AS constraint_name,
+					OBJECT_NAME(fk.parent_object_id) AS table_name,
+					COL_NAME(fkc.parent_object_id, fkc.parent_column_id) AS column_name,
+					SCHEMA_NAME(rt.schema_id) AS foreign_table_schema,
+					rt.name AS foreign_table_name,
+					COL_NAME(fkc.referenced_object_id, fkc.referenced_column_id) AS foreign_column_name
+				FROM sys.foreign_keys fk
+				JOIN sys.foreign_key_columns fkc ON fkc.constraint_object_id = fk.object_id
+				JOIN sys.tables rt ON rt.object_id = fkc.referenced_object_id
+				WHERE fk.is_ms_shipped = 0
+			`,
+			Args: []interface{}{},
+		},
+		// Secondary: INFORMATION_SCHEMA approach
+		{
+			SQL: `
+				SELECT
+					kcu.TABLE_SCHEMA AS table_schema,
+					rc.CONSTRAINT_NAME AS constraint_name,
+					kcu.TABLE_NAME AS table_name,
+					kcu.COLUMN_NAME AS column_name,
+					ccu.TABLE_SCHEMA AS foreign_table_schema,
+					ccu.TABLE_NAME AS foreign_table_name,
+					ccu.COLUMN_NAME AS foreign_column_name
+				FROM INFORMATION_SCHEMA.REFERENTIAL_CONSTRAINTS rc
+				JOIN INFORMATION_SCHEMA.KEY_COLUMN_USAGE kcu
+					ON kcu.CONSTRAINT_NAME = rc.CONSTRAINT_NAME
+					AND kcu.CONSTRAINT_SCHEMA = rc.CONSTRAINT_SCHEMA
+				JOIN INFORMATION_SCHEMA.CONSTRAINT_COLUMN_USAGE ccu
+					ON ccu.CONSTRAINT_NAME = rc.UNIQUE_CONSTRAINT_NAME
+					AND ccu.CONSTRAINT_SCHEMA = rc.UNIQUE_CONSTRAINT_SCHEMA
+				WHERE 1 = 1
+			`,
+			Args: []interface{}{},
+		},
+	}
+
+	if table == "" {
+		return queries
+	}
+
+	// Add table filter
+	queries[0].SQL += " AND (OBJECT_NAME(fk.parent_object_id) = @p1 OR rt.name = @p1)"
+	queries[0].Args = []interface{}{table}
+	queries[1].SQL += " AND (kcu.TABLE_NAME = @p1 OR ccu.TABLE_NAME = @p1)"
+	queries[1].Args = []interface{}{table}
+
+	return queries
+}
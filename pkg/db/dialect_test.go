@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect/sqlite"
+)
+
+// embeddedDialect is an engine registered from outside package db, backed by
+// the SQLite driver
+type embeddedDialect struct {
+	sqlite.Dialect
+}
+
+func (embeddedDialect) Name() string { return "embedded" }
+
+func init() {
+	dialect.Register(embeddedDialect{})
+}
+
+func TestRegisteredDialect(t *testing.T) {
+	manager := NewDBManager()
+	configJSON := `{
+		"connections": [
+			{"id": "plugin", "type": "embedded", "database_path": ":memory:", "use_modernc_driver": true}
+		]
+	}`
+	if err := manager.LoadConfig([]byte(configJSON)); err != nil {
+		t.Fatalf("failed to load config with a registered dialect: %v", err)
+	}
+	if err := manager.Connect(); err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	database, err := manager.GetDatabase("plugin")
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	if err := database.Ping(context.Background()); err != nil {
+		t.Errorf("failed to ping: %v", err)
+	}
+	if got := database.ConnectionString(); got != "SQLite in-memory database" {
+		t.Errorf("expected the dialect to describe the connection, got %s", got)
+	}
+
+	// The dialect validates its own settings
+	if err := manager.AddConnection(DatabaseConnectionConfig{ID: "nopath", Type: "embedded"}); err == nil {
+		t.Error("expected a connection without a path to be rejected")
+	}
+}
@@ -0,0 +1,12 @@
+package db
+
+// The built-in database engines register their dialects when imported
+import (
+	_ "github.com/FreePeak/db-mcp-server/pkg/db/dialect/clickhouse"
+	_ "github.com/FreePeak/db-mcp-server/pkg/db/dialect/duckdb"
+	_ "github.com/FreePeak/db-mcp-server/pkg/db/dialect/mysql"
+	_ "github.com/FreePeak/db-mcp-server/pkg/db/dialect/oracle"
+	_ "github.com/FreePeak/db-mcp-server/pkg/db/dialect/postgres"
+	_ "github.com/FreePeak/db-mcp-server/pkg/db/dialect/sqlite"
+	_ "github.com/FreePeak/db-mcp-server/pkg/db/dialect/sqlserver"
+)
@@ -0,0 +1,284 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// maxEvictionInterval is the longest time between checks for idle connections
+const maxEvictionInterval = 30 * time.Second
+
+// EvictionConfig bounds the connections kept open with lazy loading, from
+// the "lazy_loading" section of the config file. Evicted connections are
+// reopened on their next use.
+type EvictionConfig struct {
+	IdleTimeoutSeconds int `json:"idle_timeout_seconds"` // close connections unused this long, 0 keeps them open
+	MaxOpenDatabases   int `json:"max_open_databases"`   // close the least recently used connections above this many, 0 for no limit
+}
+
+// Validate checks the eviction settings
+func (c EvictionConfig) Validate() error {
+	if c.IdleTimeoutSeconds < 0 || c.MaxOpenDatabases < 0 {
+		return fmt.Errorf("lazy loading settings must not be negative")
+	}
+	return nil
+}
+
+// Enabled reports whether any connections are evicted
+func (c EvictionConfig) Enabled() bool {
+	return c.IdleTimeoutSeconds > 0 || c.MaxOpenDatabases > 0
+}
+
+// leaseGrace is how long a connection returned by GetDatabase is not evicted
+const leaseGrace = 10 * time.Second
+
+// lease keeps a connection from being evicted while callers use it
+type lease struct {
+	count int       // leases taken by AcquireDatabase and not yet released
+	until time.Time // end of the grace period of the last GetDatabase
+}
+
+// evictor tracks when connections were last used and closes idle ones
+type evictor struct {
+	idleTimeout time.Duration
+	maxOpen     int
+	now         func() time.Time
+
+	mu       sync.Mutex
+	lastUsed map[string]time.Time
+	leases   map[string]*lease
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// lease records a use of a connection and leases it, until the returned
+// function is called or for leaseGrace when grace is set
+func (e *evictor) lease(id string, grace bool) func() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now()
+	e.lastUsed[id] = now
+	l, ok := e.leases[id]
+	if !ok {
+		l = &lease{}
+		e.leases[id] = l
+	}
+	if grace {
+		l.until = now.Add(leaseGrace)
+		return func() {}
+	}
+
+	l.count++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			e.mu.Lock()
+			defer e.mu.Unlock()
+			l.count--
+		})
+	}
+}
+
+// leased reports whether a connection is leased; callers hold e.mu
+func (e *evictor) leased(id string, now time.Time) bool {
+	l, ok := e.leases[id]
+	return ok && (l.count > 0 || now.Before(l.until))
+}
+
+// StartEviction closes connections that were idle for too long and caps the
+// number of open connections, replacing eviction settings already in place.
+// Evicted connections are reopened on their next use, so eviction requires
+// lazy loading. It is stopped by CloseAll.
+func (m *Manager) StartEviction(cfg EvictionConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	m.StopEviction()
+	if !cfg.Enabled() {
+		return nil
+	}
+	if !m.IsLazyLoading() {
+		return fmt.Errorf("idle eviction and max_open_databases require lazy loading")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e := &evictor{
+		idleTimeout: time.Duration(cfg.IdleTimeoutSeconds) * time.Second,
+		maxOpen:     cfg.MaxOpenDatabases,
+		now:         time.Now,
+		lastUsed:    make(map[string]time.Time),
+		leases:      make(map[string]*lease),
+		cancel:      cancel,
+	}
+
+	m.mu.Lock()
+	m.eviction = e
+	m.mu.Unlock()
+
+	if e.idleTimeout > 0 {
+		interval := e.idleTimeout
+		if interval > maxEvictionInterval {
+			interval = maxEvictionInterval
+		}
+		e.wg.Add(1)
+		go m.evictLoop(ctx, e, interval)
+	}
+	m.enforceOpenLimit("")
+	logger.Info("Connection eviction enabled: idle timeout %s, at most %d open databases (0 for no limit)", e.idleTimeout, e.maxOpen)
+	return nil
+}
+
+// StopEviction stops evicting connections; open connections stay open
+func (m *Manager) StopEviction() {
+	m.mu.Lock()
+	e := m.eviction
+	m.eviction = nil
+	m.mu.Unlock()
+
+	if e != nil {
+		e.cancel()
+		e.wg.Wait()
+	}
+}
+
+// evictLoop closes idle connections until eviction is stopped
+func (m *Manager) evictLoop(ctx context.Context, e *evictor, interval time.Duration) {
+	defer e.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.evictIdle(e)
+		}
+	}
+}
+
+// evictIdle closes the connections that were not used for the idle timeout
+// and are not leased
+func (m *Manager) evictIdle(e *evictor) {
+	now := e.now()
+
+	m.mu.Lock()
+	e.mu.Lock()
+	var victims []string
+	for id, database := range m.connections {
+		lastUsed, seen := e.lastUsed[id]
+		if !seen {
+			// Opened before eviction started, its idle time starts now
+			e.lastUsed[id] = now
+			continue
+		}
+		if now.Sub(lastUsed) >= e.idleTimeout && !e.leased(id, now) && !connectionInUse(database) {
+			victims = append(victims, id)
+		}
+	}
+	e.mu.Unlock()
+	closing := m.evict(e, victims)
+	m.mu.Unlock()
+
+	closeEvicted(closing, "idle for "+e.idleTimeout.String())
+}
+
+// enforceOpenLimit closes the least recently used connections while more
+// than the maximum are open. Leased connections and keep, just opened, are
+// not closed.
+func (m *Manager) enforceOpenLimit(keep string) {
+	m.mu.Lock()
+	e := m.eviction
+	if e == nil || e.maxOpen <= 0 {
+		m.mu.Unlock()
+		return
+	}
+
+	e.mu.Lock()
+	now := e.now()
+	var victims []string
+	excess := len(m.connections) - e.maxOpen
+	for excess > 0 {
+		victim := ""
+		var oldest time.Time
+		for id, database := range m.connections {
+			if id == keep || containsString(victims, id) || e.leased(id, now) || connectionInUse(database) {
+				continue
+			}
+			lastUsed := e.lastUsed[id] // connections never leased go first
+			if victim == "" || lastUsed.Before(oldest) {
+				victim, oldest = id, lastUsed
+			}
+		}
+		if victim == "" {
+			logger.Warn("%d databases are open, above the limit of %d, but all are in use or leased", len(m.connections), e.maxOpen)
+			break
+		}
+		victims = append(victims, victim)
+		excess--
+	}
+	e.mu.Unlock()
+	closing := m.evict(e, victims)
+	m.mu.Unlock()
+
+	closeEvicted(closing, fmt.Sprintf("least recently used of more than %d open databases", e.maxOpen))
+}
+
+// evict removes connections from the manager and returns them for closing.
+// The caller holds m.mu.
+func (m *Manager) evict(e *evictor, ids []string) map[string]Database {
+	closing := make(map[string]Database, len(ids))
+	e.mu.Lock()
+	for _, id := range ids {
+		closing[id] = m.connections[id]
+		delete(m.connections, id)
+		delete(e.lastUsed, id)
+		delete(e.leases, id)
+	}
+	e.mu.Unlock()
+	return closing
+}
+
+// closeEvicted closes evicted connections outside the manager's lock, since
+// Close waits for running queries
+func closeEvicted(closing map[string]Database, reason string) {
+	for id, database := range closing {
+		if err := database.Close(); err != nil {
+			logger.Warn("Failed to close evicted database %s: %v", id, err)
+			continue
+		}
+		logger.Info("Closed database %s (%s), it reconnects on next use", id, reason)
+	}
+}
+
+// connectionInUse reports whether a connection or one of its replicas has
+// connections in use, such as running queries or open transactions
+func connectionInUse(database Database) bool {
+	if pool := database.DB(); pool != nil && pool.Stats().InUse > 0 {
+		return true
+	}
+	if replicated, ok := database.(*replicatedDatabase); ok {
+		for _, stats := range replicated.poolStats() {
+			if stats.InUse > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,167 @@
+package db
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+)
+
+// connectedIDs returns the IDs of the open connections in order
+func connectedIDs(manager *Manager) []string {
+	ids := manager.GetConnectedDatabases()
+	sort.Strings(ids)
+	return ids
+}
+
+func TestEviction(t *testing.T) {
+	manager := NewDBManager()
+	manager.SetLazyLoading(true)
+	for _, id := range []string{"a", "b", "c"} {
+		if err := manager.AddConnection(DatabaseConnectionConfig{ID: id, Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true}); err != nil {
+			t.Fatalf("failed to add connection: %v", err)
+		}
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	if err := manager.StartEviction(EvictionConfig{IdleTimeoutSeconds: 3600, MaxOpenDatabases: 2}); err != nil {
+		t.Fatalf("failed to start eviction: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := manager.eviction
+	e.now = func() time.Time { return now }
+
+	use := func(id string) Database {
+		t.Helper()
+		database, err := manager.GetDatabase(id)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", id, err)
+		}
+		if err := database.Ping(context.Background()); err != nil {
+			t.Fatalf("failed to ping %s: %v", id, err)
+		}
+		// Past the grace period, so that only pool use keeps it open
+		now = now.Add(leaseGrace)
+		return database
+	}
+
+	// Opening a third database closes the least recently used one
+	first := use("a")
+	use("b")
+	use("a")
+	use("c")
+	if got := connectedIDs(manager); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Fatalf("expected b to be evicted, got %v", got)
+	}
+
+	// Connections idle for the timeout are closed, unless they are in use
+	c, err := manager.GetDatabase("c")
+	if err != nil {
+		t.Fatalf("failed to get c: %v", err)
+	}
+	tx, err := c.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("failed to begin transaction: %v", err)
+	}
+	now = now.Add(2 * time.Hour)
+	manager.evictIdle(e)
+	if got := connectedIDs(manager); len(got) != 1 || got[0] != "c" {
+		t.Errorf("expected idle a to be evicted and c in a transaction to be kept, got %v", got)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatalf("failed to roll back: %v", err)
+	}
+
+	// Evicted connections are reopened on next use
+	if err := first.Ping(context.Background()); err == nil {
+		t.Error("expected the evicted connection to be closed")
+	}
+	if reopened := use("a"); reopened == first {
+		t.Error("expected a new connection after eviction")
+	}
+	if got := connectedIDs(manager); len(got) != 2 {
+		t.Errorf("expected a and c to be open, got %v", got)
+	}
+
+	manager.StopEviction()
+	use("b")
+	if got := connectedIDs(manager); len(got) != 3 {
+		t.Errorf("expected no limit after eviction is stopped, got %v", got)
+	}
+}
+
+func TestEvictionLeases(t *testing.T) {
+	manager := NewDBManager()
+	manager.SetLazyLoading(true)
+	for _, id := range []string{"a", "b"} {
+		if err := manager.AddConnection(DatabaseConnectionConfig{ID: id, Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true}); err != nil {
+			t.Fatalf("failed to add connection: %v", err)
+		}
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	if err := manager.StartEviction(EvictionConfig{IdleTimeoutSeconds: 60, MaxOpenDatabases: 1}); err != nil {
+		t.Fatalf("failed to start eviction: %v", err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	e := manager.eviction
+	e.now = func() time.Time { return now }
+
+	// A leased connection is not closed for the open limit or when idle,
+	// even before its caller runs a statement
+	a, release, err := manager.AcquireDatabase("a")
+	if err != nil {
+		t.Fatalf("failed to acquire a: %v", err)
+	}
+	if _, err := manager.GetDatabase("b"); err != nil {
+		t.Fatalf("failed to get b: %v", err)
+	}
+	now = now.Add(time.Hour)
+	manager.evictIdle(e)
+	if got := connectedIDs(manager); len(got) != 1 || got[0] != "a" {
+		t.Fatalf("expected leased a to be kept and idle b to be evicted, got %v", got)
+	}
+	if err := a.Ping(context.Background()); err != nil {
+		t.Fatalf("expected leased a to stay open, got %v", err)
+	}
+
+	// Released twice, the lease is gone once
+	release()
+	release()
+	now = now.Add(time.Hour)
+	manager.evictIdle(e)
+	if got := connectedIDs(manager); len(got) != 0 {
+		t.Errorf("expected released a to be evicted, got %v", got)
+	}
+
+	// GetDatabase leases for the grace period
+	if _, err := manager.GetDatabase("a"); err != nil {
+		t.Fatalf("failed to get a: %v", err)
+	}
+	now = now.Add(leaseGrace / 2)
+	if _, err := manager.GetDatabase("b"); err != nil {
+		t.Fatalf("failed to get b: %v", err)
+	}
+	if got := connectedIDs(manager); len(got) != 2 {
+		t.Errorf("expected a within its grace period to be kept, got %v", got)
+	}
+	now = now.Add(leaseGrace)
+	manager.enforceOpenLimit("")
+	if got := connectedIDs(manager); len(got) != 1 || got[0] != "b" {
+		t.Errorf("expected a to be evicted after its grace period, got %v", got)
+	}
+}
+
+func TestEvictionConfig(t *testing.T) {
+	manager := NewDBManager()
+	if err := manager.StartEviction(EvictionConfig{}); err != nil {
+		t.Errorf("expected disabled eviction to be accepted without lazy loading, got %v", err)
+	}
+	if err := manager.StartEviction(EvictionConfig{IdleTimeoutSeconds: 60}); err == nil {
+		t.Error("expected eviction to require lazy loading")
+	}
+	manager.SetLazyLoading(true)
+	if err := manager.StartEviction(EvictionConfig{MaxOpenDatabases: -1}); err == nil {
+		t.Error("expected negative settings to be rejected")
+	}
+}
@@ -0,0 +1,317 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Markers around the matched terms in search excerpts
+const (
+	highlightStart = "<mark>"
+	highlightEnd   = "</mark>"
+)
+
+// defaultSearchLimit is the number of matches returned when no limit is given
+const defaultSearchLimit = 20
+
+// FTSTable is an FTS5 virtual table of a SQLite database
+type FTSTable struct {
+	Name    string
+	Columns []string
+}
+
+// SearchRequest is a full-text search of an FTS5 table
+type SearchRequest struct {
+	Table   string
+	Query   string   // FTS5 query expression, such as "error AND disk*"
+	Columns []string // restricts the query to these columns; all columns when empty
+	Limit   int
+}
+
+// SearchMatch is a row matching a full-text search, best matches first
+type SearchMatch struct {
+	Rowid     int64
+	Score     float64           // bm25 score of the match, lower is better
+	Snippet   string            // excerpt of the best matching column with the terms marked
+	Highlight map[string]string // column values with the matched terms marked
+}
+
+// FTSTables returns the FTS5 virtual tables of a SQLite database
+func (m *Manager) FTSTables(ctx context.Context, id string) ([]FTSTable, error) {
+	database, release, err := m.sqliteDatabase(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return ftsTables(ctx, database)
+}
+
+// ftsTables lists the FTS5 tables and their columns
+func ftsTables(ctx context.Context, database Database) ([]FTSTable, error) {
+	rows, err := database.Query(ctx, `SELECT name FROM sqlite_master
+		WHERE type = 'table' AND sql LIKE 'CREATE VIRTUAL TABLE%USING fts5%' COLLATE NOCASE
+		ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list FTS5 tables: %w", err)
+	}
+	names, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list FTS5 tables: %w", err)
+	}
+
+	tables := make([]FTSTable, 0, len(names))
+	for _, name := range names {
+		rows, err := database.Query(ctx, "SELECT name FROM pragma_table_info(?) ORDER BY cid", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+		}
+		columns, err := scanStrings(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+		}
+		tables = append(tables, FTSTable{Name: name, Columns: columns})
+	}
+	return tables, nil
+}
+
+// Search runs a full-text query against an FTS5 table and returns the best
+// matches ranked by bm25, with a snippet and the highlighted column values
+func (m *Manager) Search(ctx context.Context, id string, req SearchRequest) ([]SearchMatch, error) {
+	if strings.TrimSpace(req.Query) == "" {
+		return nil, fmt.Errorf("a search query is required")
+	}
+	database, release, err := m.sqliteDatabase(id)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Only tables found in the catalog are queried, so their names are safe
+	// to quote into the statement
+	table, err := findFTSTable(ctx, database, req.Table)
+	if err != nil {
+		return nil, err
+	}
+	d := database.Dialect()
+
+	match := req.Query
+	if len(req.Columns) > 0 {
+		quoted := make([]string, 0, len(req.Columns))
+		for _, column := range req.Columns {
+			if !slices.Contains(table.Columns, column) {
+				return nil, fmt.Errorf("table %s has no column %s (columns: %s)", table.Name, column, strings.Join(table.Columns, ", "))
+			}
+			quoted = append(quoted, d.QuoteIdentifier(column))
+		}
+		// FTS5 column filter: only the listed columns are searched
+		match = fmt.Sprintf("{%s} : (%s)", strings.Join(quoted, " "), req.Query)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+
+	name := d.QuoteIdentifier(table.Name)
+	selects := []string{"rowid", fmt.Sprintf("bm25(%s)", name), fmt.Sprintf("snippet(%s, -1, ?, ?, '…', 16)", name)}
+	args := []interface{}{highlightStart, highlightEnd}
+	for i := range table.Columns {
+		selects = append(selects, fmt.Sprintf("highlight(%s, %d, ?, ?)", name, i))
+		args = append(args, highlightStart, highlightEnd)
+	}
+	args = append(args, match, limit)
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s MATCH ? ORDER BY rank LIMIT ?", strings.Join(selects, ", "), name, name)
+
+	rows, err := database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("search of %s failed: %w", table.Name, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	var matches []SearchMatch
+	for rows.Next() {
+		var match SearchMatch
+		var snippet sql.NullString
+		values := make([]sql.NullString, len(table.Columns))
+		dest := []interface{}{&match.Rowid, &match.Score, &snippet}
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to read search results: %w", err)
+		}
+		match.Snippet = snippet.String
+		match.Highlight = make(map[string]string, len(values))
+		for i, value := range values {
+			if value.Valid {
+				match.Highlight[table.Columns[i]] = value.String
+			}
+		}
+		matches = append(matches, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("search of %s failed: %w", table.Name, err)
+	}
+	return matches, nil
+}
+
+// CreateFTSIndex creates an external content FTS5 table named <table>_fts
+// over text columns of a table, fills it, and adds triggers keeping it in
+// sync with the table. Without columns, all text columns are indexed.
+func (m *Manager) CreateFTSIndex(ctx context.Context, id, table string, columns []string) (FTSTable, error) {
+	database, release, err := m.sqliteDatabase(id)
+	if err != nil {
+		return FTSTable{}, err
+	}
+	defer release()
+
+	textColumns, err := textColumns(ctx, database, table)
+	if err != nil {
+		return FTSTable{}, err
+	}
+	if len(columns) == 0 {
+		columns = textColumns
+	}
+	if len(columns) == 0 {
+		return FTSTable{}, fmt.Errorf("table %s has no text columns to index", table)
+	}
+	for _, column := range columns {
+		if !slices.Contains(textColumns, column) {
+			return FTSTable{}, fmt.Errorf("table %s has no text column %s (text columns: %s)", table, column, strings.Join(textColumns, ", "))
+		}
+	}
+
+	index := FTSTable{Name: table + "_fts", Columns: columns}
+	if existing, err := findFTSTable(ctx, database, index.Name); err == nil {
+		return existing, fmt.Errorf("FTS5 table %s already exists", index.Name)
+	}
+
+	d := database.Dialect()
+	name := d.QuoteIdentifier(index.Name)
+	quoted := make([]string, len(columns))
+	newValues := make([]string, len(columns))
+	oldValues := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = d.QuoteIdentifier(column)
+		newValues[i] = "new." + quoted[i]
+		oldValues[i] = "old." + quoted[i]
+	}
+	cols := strings.Join(quoted, ", ")
+	insertNew := fmt.Sprintf("INSERT INTO %s(rowid, %s) VALUES (new.rowid, %s);", name, cols, strings.Join(newValues, ", "))
+	deleteOld := fmt.Sprintf("INSERT INTO %s(%s, rowid, %s) VALUES ('delete', old.rowid, %s);", name, name, cols, strings.Join(oldValues, ", "))
+	trigger := func(event string) string {
+		return d.QuoteIdentifier(fmt.Sprintf("%s_%s", index.Name, event))
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE VIRTUAL TABLE %s USING fts5(%s, content=%s, content_rowid='rowid')",
+			name, cols, sqlString(table)),
+		fmt.Sprintf("INSERT INTO %s(%s) VALUES ('rebuild')", name, name),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER INSERT ON %s BEGIN %s END", trigger("ai"), d.QuoteIdentifier(table), insertNew),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER DELETE ON %s BEGIN %s END", trigger("ad"), d.QuoteIdentifier(table), deleteOld),
+		fmt.Sprintf("CREATE TRIGGER %s AFTER UPDATE ON %s BEGIN %s %s END", trigger("au"), d.QuoteIdentifier(table), deleteOld, insertNew),
+	}
+
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return FTSTable{}, fmt.Errorf("failed to create FTS5 table %s: %w", index.Name, err)
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				logger.Error("Failed to roll back creation of %s: %v", index.Name, rbErr)
+			}
+			return FTSTable{}, fmt.Errorf("failed to create FTS5 table %s: %w", index.Name, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return FTSTable{}, fmt.Errorf("failed to create FTS5 table %s: %w", index.Name, err)
+	}
+
+	logger.Info("Created FTS5 table %s over %s(%s) of %s", index.Name, table, strings.Join(columns, ", "), id)
+	return index, nil
+}
+
+// sqliteDatabase acquires the database of a SQLite connection
+func (m *Manager) sqliteDatabase(id string) (Database, func(), error) {
+	cfg, err := m.GetRawDatabaseConfig(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cfg.Type != "sqlite" {
+		return nil, nil, fmt.Errorf("full-text search is only supported for SQLite databases, %s is %s", id, cfg.Type)
+	}
+	return m.AcquireDatabase(id)
+}
+
+// findFTSTable returns the FTS5 table with the given name
+func findFTSTable(ctx context.Context, database Database, name string) (FTSTable, error) {
+	tables, err := ftsTables(ctx, database)
+	if err != nil {
+		return FTSTable{}, err
+	}
+	names := make([]string, 0, len(tables))
+	for _, table := range tables {
+		if table.Name == name {
+			return table, nil
+		}
+		names = append(names, table.Name)
+	}
+	if len(names) == 0 {
+		return FTSTable{}, fmt.Errorf("FTS5 table %s not found, the database has no FTS5 tables", name)
+	}
+	return FTSTable{}, fmt.Errorf("FTS5 table %s not found (FTS5 tables: %s)", name, strings.Join(names, ", "))
+}
+
+// textColumns returns the columns of a table with text affinity, in order
+func textColumns(ctx context.Context, database Database, table string) ([]string, error) {
+	rows, err := database.Query(ctx, `SELECT name FROM pragma_table_info(?)
+		WHERE upper(type) LIKE '%CHAR%' OR upper(type) LIKE '%CLOB%' OR upper(type) LIKE '%TEXT%'
+		ORDER BY cid`, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	columns, err := scanStrings(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", table, err)
+	}
+	if len(columns) == 0 {
+		var exists int
+		if err := database.QueryRow(ctx, "SELECT COUNT(*) FROM pragma_table_info(?)", table).Scan(&exists); err != nil || exists == 0 {
+			return nil, fmt.Errorf("table %s not found", table)
+		}
+	}
+	return columns, nil
+}
+
+// scanStrings reads a single string column and closes the rows
+func scanStrings(rows *sql.Rows) ([]string, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
+
+// sqlString quotes a value as a SQL string literal
+func sqlString(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
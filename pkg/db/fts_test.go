@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFullTextSearch(t *testing.T) {
+	initLoggerForTests()
+
+	ctx := context.Background()
+	manager := NewDBManager()
+	app := DatabaseConnectionConfig{ID: "docs", Type: "sqlite", DatabasePath: filepath.Join(t.TempDir(), "docs.db"), UseModerncDriver: true}
+	if err := manager.AddConnection(app); err != nil {
+		t.Fatalf("failed to add connection: %v", err)
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	database, err := manager.GetDatabase("docs")
+	if err != nil {
+		t.Fatalf("expected connection to be open: %v", err)
+	}
+	for _, stmt := range []string{
+		"CREATE VIRTUAL TABLE logs USING fts5(service, message)",
+		"INSERT INTO logs (service, message) VALUES ('api', 'disk full on volume data'), ('worker', 'job finished'), ('disk', 'api latency high')",
+		"CREATE TABLE articles (id INTEGER PRIMARY KEY, title TEXT, body TEXT, views INTEGER)",
+		"INSERT INTO articles (title, body, views) VALUES ('Backups', 'How to back up SQLite with VACUUM INTO', 10), ('Search', 'Full text search with FTS5', 5)",
+	} {
+		if _, err := database.Exec(ctx, stmt); err != nil {
+			t.Fatalf("failed to prepare database: %v", err)
+		}
+	}
+
+	tables, err := manager.FTSTables(ctx, "docs")
+	if err != nil || len(tables) != 1 || tables[0].Name != "logs" || strings.Join(tables[0].Columns, ",") != "service,message" {
+		t.Fatalf("expected the logs table, got %v (err: %v)", tables, err)
+	}
+
+	matches, err := manager.Search(ctx, "docs", SearchRequest{Table: "logs", Query: "disk"})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Fatalf("expected 2 matches, got %v", matches)
+	}
+	if !strings.Contains(matches[0].Snippet, "<mark>disk</mark>") {
+		t.Errorf("expected the term to be marked in the snippet, got %q", matches[0].Snippet)
+	}
+
+	// Column filters restrict the match
+	matches, err = manager.Search(ctx, "docs", SearchRequest{Table: "logs", Query: "disk", Columns: []string{"message"}})
+	if err != nil {
+		t.Fatalf("failed to search: %v", err)
+	}
+	if len(matches) != 1 || matches[0].Highlight["message"] != "<mark>disk</mark> full on volume data" || matches[0].Score >= 0 {
+		t.Errorf("unexpected matches %+v", matches)
+	}
+
+	if _, err := manager.Search(ctx, "docs", SearchRequest{Table: "articles", Query: "disk"}); err == nil {
+		t.Error("expected search of a table without FTS5 to fail")
+	}
+	if _, err := manager.Search(ctx, "docs", SearchRequest{Table: "logs", Query: "disk", Columns: []string{"level"}}); err == nil {
+		t.Error("expected search of an unknown column to fail")
+	}
+
+	// Indexes over existing tables follow changes to the table
+	index, err := manager.CreateFTSIndex(ctx, "docs", "articles", nil)
+	if err != nil {
+		t.Fatalf("failed to create index: %v", err)
+	}
+	if index.Name != "articles_fts" || strings.Join(index.Columns, ",") != "title,body" {
+		t.Errorf("unexpected index %+v", index)
+	}
+	if _, err := database.Exec(ctx, "UPDATE articles SET body = 'Ranking with bm25' WHERE title = 'Search'"); err != nil {
+		t.Fatalf("failed to update: %v", err)
+	}
+	matches, err = manager.Search(ctx, "docs", SearchRequest{Table: "articles_fts", Query: "bm25 OR vacuum"})
+	if err != nil {
+		t.Fatalf("failed to search index: %v", err)
+	}
+	if len(matches) != 2 || matches[0].Highlight["title"] == "" {
+		t.Errorf("expected both articles to match, got %+v", matches)
+	}
+	if matches, _ := manager.Search(ctx, "docs", SearchRequest{Table: "articles_fts", Query: "FTS5"}); len(matches) != 0 {
+		t.Errorf("expected updated text to be removed from the index, got %+v", matches)
+	}
+
+	if _, err := manager.CreateFTSIndex(ctx, "docs", "articles", nil); err == nil {
+		t.Error("expected a second index of the table to fail")
+	}
+	if _, err := manager.CreateFTSIndex(ctx, "docs", "articles", []string{"views"}); err == nil {
+		t.Error("expected an index of a numeric column to fail")
+	}
+	if _, err := manager.CreateFTSIndex(ctx, "docs", "missing", nil); err == nil {
+		t.Error("expected an index of an unknown table to fail")
+	}
+}
@@ -0,0 +1,409 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Health states of a connection
+const (
+	HealthHealthy      = "healthy"       // the last ping succeeded in time
+	HealthDegraded     = "degraded"      // the last ping was slow or failed fewer than FailureThreshold times
+	HealthDown         = "down"          // pings failed FailureThreshold times in a row, reconnecting
+	HealthNotConnected = "not_connected" // not opened yet, with lazy loading
+)
+
+// Default health monitor settings
+const (
+	defaultHealthInterval         = 30 * time.Second
+	defaultHealthTimeout          = 5 * time.Second
+	defaultHealthDegradedLatency  = time.Second
+	defaultHealthFailureThreshold = 3
+	defaultHealthMinBackoff       = time.Second
+	defaultHealthMaxBackoff       = 5 * time.Minute
+)
+
+// HealthConfig configures the health monitor from the "health" section of
+// the config file. Zero values select the defaults.
+type HealthConfig struct {
+	Disabled          bool `json:"disabled"`
+	IntervalSeconds   int  `json:"interval_seconds"`    // between pings of a connection, defaults to 30
+	TimeoutSeconds    int  `json:"timeout_seconds"`     // for a ping or reconnect, defaults to 5
+	DegradedLatencyMs int  `json:"degraded_latency_ms"` // pings slower than this are degraded, defaults to 1000
+	FailureThreshold  int  `json:"failure_threshold"`   // failed pings before a connection is down, defaults to 3
+	MaxBackoffSeconds int  `json:"max_backoff_seconds"` // longest wait between reconnects, defaults to 300
+}
+
+// Validate checks the health monitor settings
+func (c HealthConfig) Validate() error {
+	if c.IntervalSeconds < 0 || c.TimeoutSeconds < 0 || c.DegradedLatencyMs < 0 || c.FailureThreshold < 0 || c.MaxBackoffSeconds < 0 {
+		return fmt.Errorf("health settings must not be negative")
+	}
+	return nil
+}
+
+// healthSettings are the effective health monitor settings
+type healthSettings struct {
+	interval         time.Duration
+	timeout          time.Duration
+	degradedLatency  time.Duration
+	failureThreshold int
+	minBackoff       time.Duration
+	maxBackoff       time.Duration
+}
+
+// settings applies the defaults to a health configuration
+func (c HealthConfig) settings() healthSettings {
+	s := healthSettings{
+		interval:         time.Duration(c.IntervalSeconds) * time.Second,
+		timeout:          time.Duration(c.TimeoutSeconds) * time.Second,
+		degradedLatency:  time.Duration(c.DegradedLatencyMs) * time.Millisecond,
+		failureThreshold: c.FailureThreshold,
+		minBackoff:       defaultHealthMinBackoff,
+		maxBackoff:       time.Duration(c.MaxBackoffSeconds) * time.Second,
+	}
+	if s.interval == 0 {
+		s.interval = defaultHealthInterval
+	}
+	if s.timeout == 0 {
+		s.timeout = defaultHealthTimeout
+	}
+	if s.degradedLatency == 0 {
+		s.degradedLatency = defaultHealthDegradedLatency
+	}
+	if s.failureThreshold == 0 {
+		s.failureThreshold = defaultHealthFailureThreshold
+	}
+	if s.maxBackoff == 0 {
+		s.maxBackoff = defaultHealthMaxBackoff
+	}
+	return s
+}
+
+// DatabaseHealth is the health of a connection as last seen by the monitor
+type DatabaseHealth struct {
+	ID                  string        `json:"id"`
+	Type                string        `json:"type"`
+	State               string        `json:"state"`
+	Latency             time.Duration `json:"latency"`
+	LastCheck           time.Time     `json:"last_check"`
+	LastError           string        `json:"last_error,omitempty"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	NextReconnect       time.Time     `json:"next_reconnect"` // set while down
+}
+
+// healthRecord is the monitor's state for one connection
+type healthRecord struct {
+	health    DatabaseHealth
+	database  Database // the connection the record describes
+	nextCheck time.Time
+	backoff   time.Duration
+	checking  bool
+}
+
+// healthMonitor pings the manager's connections periodically and reconnects
+// those that are down with exponential backoff
+type healthMonitor struct {
+	manager  *Manager
+	settings healthSettings
+
+	mu      sync.Mutex
+	records map[string]*healthRecord
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// StartHealthMonitor starts monitoring the connections, replacing a monitor
+// that is already running. It is stopped by CloseAll.
+func (m *Manager) StartHealthMonitor(cfg HealthConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	m.StopHealthMonitor()
+	if cfg.Disabled {
+		return nil
+	}
+	m.startHealthMonitor(cfg.settings())
+	return nil
+}
+
+// startHealthMonitor starts a monitor with the given settings
+func (m *Manager) startHealthMonitor(settings healthSettings) {
+	ctx, cancel := context.WithCancel(context.Background())
+	monitor := &healthMonitor{
+		manager:  m,
+		settings: settings,
+		records:  make(map[string]*healthRecord),
+		cancel:   cancel,
+	}
+
+	m.mu.Lock()
+	m.health = monitor
+	m.mu.Unlock()
+
+	monitor.wg.Add(1)
+	go monitor.run(ctx)
+	logger.Info("Health monitor started, checking connections every %s", settings.interval)
+}
+
+// StopHealthMonitor stops the health monitor if it is running
+func (m *Manager) StopHealthMonitor() {
+	m.mu.Lock()
+	monitor := m.health
+	m.health = nil
+	m.mu.Unlock()
+
+	if monitor != nil {
+		monitor.cancel()
+		monitor.wg.Wait()
+	}
+}
+
+// Health returns the health of every configured connection, sorted by ID.
+// Without a running monitor connections are reported as healthy once
+// connected, since nothing checks them.
+func (m *Manager) Health() []DatabaseHealth {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.configs))
+	for id := range m.configs {
+		ids = append(ids, id)
+	}
+	m.mu.RUnlock()
+	sort.Strings(ids)
+
+	result := make([]DatabaseHealth, 0, len(ids))
+	for _, id := range ids {
+		if health, err := m.DatabaseHealth(id); err == nil {
+			result = append(result, health)
+		}
+	}
+	return result
+}
+
+// DatabaseHealth returns the health of a connection
+func (m *Manager) DatabaseHealth(id string) (DatabaseHealth, error) {
+	m.mu.RLock()
+	cfg, exists := m.configs[id]
+	database, connected := m.connections[id]
+	monitor := m.health
+	m.mu.RUnlock()
+
+	if !exists {
+		return DatabaseHealth{}, fmt.Errorf("database configuration %s not found", id)
+	}
+	health := DatabaseHealth{ID: id, Type: cfg.Type, State: HealthNotConnected}
+	if !connected {
+		return health, nil
+	}
+
+	health.State = HealthHealthy
+	if monitor != nil {
+		monitor.mu.Lock()
+		if record, ok := monitor.records[id]; ok && record.database == database && !record.health.LastCheck.IsZero() {
+			health = record.health
+		}
+		monitor.mu.Unlock()
+	}
+	health.Type = cfg.Type
+	return health, nil
+}
+
+// run checks the connections that are due until the monitor is stopped
+func (h *healthMonitor) run(ctx context.Context) {
+	defer h.wg.Done()
+
+	tick := time.Second
+	if h.settings.interval < tick {
+		tick = h.settings.interval
+	}
+	ticker := time.NewTicker(tick)
+	defer ticker.Stop()
+
+	for {
+		h.checkDue(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDue starts a check of every connection whose next check is due and
+// forgets connections that were closed or removed
+func (h *healthMonitor) checkDue(ctx context.Context) {
+	h.manager.mu.RLock()
+	connections := make(map[string]Database, len(h.manager.connections))
+	for id, database := range h.manager.connections {
+		connections[id] = database
+	}
+	h.manager.mu.RUnlock()
+
+	now := time.Now()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, record := range h.records {
+		if database, ok := connections[id]; (!ok || database != record.database) && !record.checking {
+			delete(h.records, id)
+		}
+	}
+	for id, database := range connections {
+		record, ok := h.records[id]
+		if !ok {
+			record = &healthRecord{database: database}
+			h.records[id] = record
+		}
+		if record.checking || now.Before(record.nextCheck) {
+			continue
+		}
+		record.checking = true
+		h.wg.Add(1)
+		go h.check(ctx, id, record)
+	}
+}
+
+// check pings a connection, or reconnects it when it is down
+func (h *healthMonitor) check(ctx context.Context, id string, record *healthRecord) {
+	defer h.wg.Done()
+
+	h.mu.Lock()
+	database := record.database
+	down := record.health.State == HealthDown
+	h.mu.Unlock()
+
+	var latency time.Duration
+	var err error
+	if down {
+		database, latency, err = h.reconnect(ctx, id, database)
+	} else {
+		latency, err = h.ping(ctx, database)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	record.checking = false
+	if ctx.Err() != nil {
+		return
+	}
+	record.database = database
+	h.update(id, record, latency, err)
+}
+
+// ping pings a connection and measures its latency
+func (h *healthMonitor) ping(ctx context.Context, database Database) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.settings.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := database.Ping(ctx)
+	return time.Since(start), err
+}
+
+// reconnect opens a new connection and replaces the broken one with it. The
+// broken connection is kept when the new one cannot be opened, or when the
+// connection was replaced or removed meanwhile.
+func (h *healthMonitor) reconnect(ctx context.Context, id string, old Database) (Database, time.Duration, error) {
+	m := h.manager
+	m.mu.RLock()
+	cfg, exists := m.configs[id]
+	m.mu.RUnlock()
+	if !exists {
+		return old, 0, fmt.Errorf("database configuration %s not found", id)
+	}
+
+	logger.Info("Health monitor: reconnecting to database %s", id)
+	database, err := createAndConnectDatabase(id, cfg)
+	if err != nil {
+		return old, 0, err
+	}
+	latency, err := h.ping(ctx, database)
+	if err != nil {
+		_ = database.Close()
+		return old, 0, err
+	}
+
+	m.mu.Lock()
+	current, connected := m.connections[id]
+	replaced := connected && current == old
+	if replaced {
+		m.connections[id] = database
+	}
+	m.mu.Unlock()
+
+	if !replaced {
+		_ = database.Close()
+		return old, 0, fmt.Errorf("database connection %s changed during reconnect", id)
+	}
+	// Close outside the lock, Close waits for running queries
+	if err := old.Close(); err != nil {
+		logger.Warn("Health monitor: failed to close broken connection to %s: %v", id, err)
+	}
+	logger.Info("Health monitor: reconnected to database %s", id)
+	return database, latency, nil
+}
+
+// update records the result of a check and schedules the next one. The
+// caller holds h.mu.
+func (h *healthMonitor) update(id string, record *healthRecord, latency time.Duration, err error) {
+	now := time.Now()
+	health := &record.health
+	previous := health.State
+	health.ID = id
+	health.LastCheck = now
+	health.NextReconnect = time.Time{}
+
+	if err == nil {
+		health.Latency = latency
+		health.LastError = ""
+		health.ConsecutiveFailures = 0
+		health.State = HealthHealthy
+		if latency > h.settings.degradedLatency {
+			health.State = HealthDegraded
+		}
+		record.backoff = 0
+		record.nextCheck = now.Add(h.settings.interval)
+	} else {
+		health.LastError = err.Error()
+		health.ConsecutiveFailures++
+		health.State = HealthDegraded
+		record.nextCheck = now.Add(h.settings.interval)
+		if health.ConsecutiveFailures >= h.settings.failureThreshold {
+			health.State = HealthDown
+			record.backoff = nextBackoff(record.backoff, h.settings.minBackoff, h.settings.maxBackoff)
+			record.nextCheck = now.Add(record.backoff)
+			health.NextReconnect = record.nextCheck
+		}
+	}
+
+	if health.State != previous {
+		switch health.State {
+		case HealthHealthy:
+			if previous != "" {
+				logger.Info("Health monitor: database %s is healthy again", id)
+			}
+		case HealthDegraded:
+			logger.Warn("Health monitor: database %s is degraded: latency %s, error: %s", id, latency, health.LastError)
+		case HealthDown:
+			logger.Error("Health monitor: database %s is down after %d failed checks: %s", id, health.ConsecutiveFailures, health.LastError)
+		}
+	}
+}
+
+// nextBackoff doubles a reconnect backoff within its bounds
+func nextBackoff(current, minimum, maximum time.Duration) time.Duration {
+	next := current * 2
+	if next < minimum {
+		next = minimum
+	}
+	if next > maximum {
+		next = maximum
+	}
+	return next
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyDatabase is a connection whose pings fail until it is fixed
+type flakyDatabase struct {
+	Database
+
+	mu     sync.Mutex
+	broken bool
+	closed bool
+}
+
+func (d *flakyDatabase) setBroken(broken bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.broken = broken
+}
+
+func (d *flakyDatabase) isClosed() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.closed
+}
+
+func (d *flakyDatabase) Ping(ctx context.Context) error {
+	d.mu.Lock()
+	broken := d.broken
+	d.mu.Unlock()
+	if broken {
+		return errors.New("connection refused")
+	}
+	return d.Database.Ping(ctx)
+}
+
+func (d *flakyDatabase) Close() error {
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	return d.Database.Close()
+}
+
+// waitForHealth waits until a connection reaches a health state
+func waitForHealth(t *testing.T, manager *Manager, id, state string) DatabaseHealth {
+	t.Helper()
+	return waitForCheck(t, manager, id, state, time.Time{})
+}
+
+// waitForCheck waits until a connection reaches a health state in a check
+// after the given time
+func waitForCheck(t *testing.T, manager *Manager, id, state string, after time.Time) DatabaseHealth {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		health, err := manager.DatabaseHealth(id)
+		if err != nil {
+			t.Fatalf("failed to get health of %s: %v", id, err)
+		}
+		if health.State == state && (after.IsZero() || health.LastCheck.After(after)) {
+			return health
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("expected %s to be %s, got %+v", id, state, health)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHealthMonitor(t *testing.T) {
+	manager := NewDBManager()
+	manager.SetLazyLoading(true)
+	memory := DatabaseConnectionConfig{ID: "scratch", Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true}
+	lazy := DatabaseConnectionConfig{ID: "later", Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true}
+	for _, conn := range []DatabaseConnectionConfig{memory, lazy} {
+		if err := manager.AddConnection(conn); err != nil {
+			t.Fatalf("failed to add connection: %v", err)
+		}
+	}
+
+	// Wrap a real connection so that its pings can be made to fail
+	inner, err := createAndConnectDatabase(memory.ID, memory)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	flaky := &flakyDatabase{Database: inner}
+	manager.mu.Lock()
+	manager.connections[memory.ID] = flaky
+	manager.mu.Unlock()
+
+	if health := waitForHealth(t, manager, memory.ID, HealthHealthy); !health.LastCheck.IsZero() {
+		t.Errorf("expected no check before the monitor runs, got %+v", health)
+	}
+
+	started := time.Now()
+	manager.startHealthMonitor(healthSettings{
+		interval:         50 * time.Millisecond,
+		timeout:          time.Second,
+		degradedLatency:  time.Second,
+		failureThreshold: 2,
+		minBackoff:       10 * time.Millisecond,
+		maxBackoff:       20 * time.Millisecond,
+	})
+	defer func() {
+		if err := manager.CloseAll(); err != nil {
+			t.Errorf("failed to close: %v", err)
+		}
+	}()
+
+	health := waitForCheck(t, manager, memory.ID, HealthHealthy, started)
+	if health.Type != "sqlite" {
+		t.Errorf("expected a checked sqlite connection, got %+v", health)
+	}
+	if health := waitForHealth(t, manager, lazy.ID, HealthNotConnected); !health.LastCheck.IsZero() {
+		t.Errorf("expected lazy connection not to be checked, got %+v", health)
+	}
+
+	// Failing pings degrade the connection and then take it down; the monitor
+	// reconnects it with a new connection and closes the broken one
+	flaky.setBroken(true)
+	health = waitForHealth(t, manager, memory.ID, HealthDegraded)
+	if health.LastError != "connection refused" || health.ConsecutiveFailures != 1 {
+		t.Errorf("expected one failed check, got %+v", health)
+	}
+	health = waitForCheck(t, manager, memory.ID, HealthHealthy, health.LastCheck)
+	if health.ConsecutiveFailures != 0 || health.LastError != "" {
+		t.Errorf("expected failures to be reset after reconnecting, got %+v", health)
+	}
+	if !flaky.isClosed() {
+		t.Error("expected the broken connection to be closed")
+	}
+	database, err := manager.GetDatabase(memory.ID)
+	if err != nil {
+		t.Fatalf("expected the connection to be open: %v", err)
+	}
+	if database == Database(flaky) {
+		t.Error("expected the broken connection to be replaced")
+	}
+
+	// Health of all connections is reported in ID order
+	all := manager.Health()
+	if len(all) != 2 || all[0].ID != lazy.ID || all[1].ID != memory.ID {
+		t.Errorf("expected health of both connections, got %+v", all)
+	}
+	if _, err := manager.DatabaseHealth("missing"); err == nil {
+		t.Error("expected unknown connection to be rejected")
+	}
+}
+
+func TestHealthConfig(t *testing.T) {
+	settings := HealthConfig{}.settings()
+	if settings.interval != defaultHealthInterval || settings.failureThreshold != defaultHealthFailureThreshold {
+		t.Errorf("expected defaults, got %+v", settings)
+	}
+	if err := (HealthConfig{IntervalSeconds: -1}).Validate(); err == nil {
+		t.Error("expected negative interval to be rejected")
+	}
+
+	backoff := time.Duration(0)
+	var got []time.Duration
+	for i := 0; i < 4; i++ {
+		backoff = nextBackoff(backoff, time.Second, 5*time.Second)
+		got = append(got, backoff)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected backoff %v, got %v", want, got)
+			break
+		}
+	}
+}
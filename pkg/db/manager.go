@@ -1,25 +1,35 @@
 package db
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
 	"sync"
 	"time"
 
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
 	"github.com/FreePeak/db-mcp-server/pkg/logger"
 )
 
 // DatabaseConnectionConfig represents a single database connection configuration
 type DatabaseConnectionConfig struct {
 	ID       string `json:"id"`   // Unique identifier for this connection
-	Type     string `json:"type"` // mysql, postgres, oracle, or sqlite
+	Type     string `json:"type"` // a registered dialect: mysql, postgres, oracle, sqlite, sqlserver, duckdb, clickhouse, ...
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	User     string `json:"user"`
 	Password string `json:"password"`
 	Name     string `json:"name"`
 
-	// PostgreSQL specific options
+	// Labels for listing, filtering and policies
+	Description string   `json:"description,omitempty"`
+	Environment string   `json:"environment,omitempty"` // such as dev, staging or prod
+	Tags        []string `json:"tags,omitempty"`
+
+	// PostgreSQL specific options (the SSL settings also apply to MySQL)
 	SSLMode            string            `json:"ssl_mode,omitempty"`
 	SSLCert            string            `json:"ssl_cert,omitempty"`
 	SSLKey             string            `json:"ssl_key,omitempty"`
@@ -31,18 +41,50 @@ type DatabaseConnectionConfig struct {
 	Options            map[string]string `json:"options,omitempty"`
 
 	// SQLite specific options
-	DatabasePath     string `json:"database_path,omitempty"`      // Path to SQLite database file
-	EncryptionKey    string `json:"encryption_key,omitempty"`     // Key for SQLCipher encryption
-	ReadOnly         bool   `json:"read_only,omitempty"`          // Open database in read-only mode
-	CacheSize        int    `json:"cache_size,omitempty"`         // SQLite cache size (in pages)
-	JournalMode      string `json:"journal_mode,omitempty"`       // Journal mode for SQLite
-	UseModerncDriver bool   `json:"use_modernc_driver,omitempty"` // Use modernc.org/sqlite driver instead of mattn/go-sqlite3
+	DatabasePath     string            `json:"database_path,omitempty"`      // Path to SQLite database file
+	EncryptionKey    string            `json:"encryption_key,omitempty"`     // Key for SQLCipher encryption
+	ReadOnly         bool              `json:"read_only,omitempty"`          // Open database in read-only mode (also PostgreSQL, MySQL, DuckDB and ClickHouse sessions)
+	CacheSize        int               `json:"cache_size,omitempty"`         // SQLite cache size (in pages)
+	JournalMode      string            `json:"journal_mode,omitempty"`       // Journal mode for SQLite
+	UseModerncDriver bool              `json:"use_modernc_driver,omitempty"` // Use modernc.org/sqlite driver instead of mattn/go-sqlite3
+	Attach           map[string]string `json:"attach,omitempty"`             // Database files attached to the connection, by schema name
 
 	// Connection pool settings
 	MaxOpenConns    int `json:"max_open_conns,omitempty"`
 	MaxIdleConns    int `json:"max_idle_conns,omitempty"`
 	ConnMaxLifetime int `json:"conn_max_lifetime_seconds,omitempty"`  // in seconds
 	ConnMaxIdleTime int `json:"conn_max_idle_time_seconds,omitempty"` // in seconds
+
+	// Rate limits for tool calls against this connection
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty"`
+
+	// Planner cost thresholds for queries against this connection
+	CostGuard *CostGuardConfig `json:"cost_guard,omitempty"`
+
+	// Bastion host the connection is forwarded through
+	SSHTunnel *SSHTunnelConfig `json:"ssh_tunnel,omitempty"`
+
+	// Read replicas for the query and schema tools
+	Replicas      []ReplicaConfig `json:"replicas,omitempty"`
+	ReplicaPolicy string          `json:"replica_policy,omitempty"`  // round_robin (default) or least_latency
+	MaxReplicaLag int             `json:"max_replica_lag,omitempty"` // in seconds, replicas further behind are skipped
+}
+
+// RateLimitConfig limits the rate and concurrency of tool calls against a connection
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second,omitempty"`
+	Burst             int     `json:"burst,omitempty"`
+	MaxConcurrent     int     `json:"max_concurrent,omitempty"`
+}
+
+// CostGuardConfig rejects queries whose planner estimate exceeds the thresholds
+type CostGuardConfig struct {
+	MaxCost float64 `json:"max_cost,omitempty"`
+	MaxRows float64 `json:"max_rows,omitempty"`
+	Action  string  `json:"action,omitempty"` // reject (default) or confirm
+
+	// FailOpen runs queries that cannot be planned unchecked
+	FailOpen bool `json:"fail_open,omitempty"`
 }
 
 // MultiDBConfig represents the configuration for multiple database connections
@@ -55,7 +97,11 @@ type Manager struct {
 	mu          sync.RWMutex
 	connections map[string]Database
 	configs     map[string]DatabaseConnectionConfig
-	lazyLoading bool // When true, connections are established on first use instead of startup
+	lazyLoading bool           // When true, connections are established on first use instead of startup
+	health      *healthMonitor // Pings connections and reconnects them, nil when not started
+	eviction    *evictor       // Closes idle and least recently used connections, nil when not started
+	filter      ConnectionFilter
+	policies    []ConnectionPolicy
 }
 
 // NewDBManager creates a new database manager
@@ -97,43 +143,125 @@ func (m *Manager) LoadConfig(configJSON []byte) error {
 
 	// Validate and store configurations
 	for _, conn := range config.Connections {
-		if conn.ID == "" {
-			return fmt.Errorf("database connection ID cannot be empty")
+		if err := validateConnectionConfig(conn); err != nil {
+			return err
 		}
-		if conn.Type != "mysql" && conn.Type != "postgres" && conn.Type != "oracle" && conn.Type != "sqlite" {
-			return fmt.Errorf("unsupported database type for connection %s: %s (must be mysql, postgres, oracle, or sqlite)", conn.ID, conn.Type)
+		conn, ok := m.admit(conn)
+		if !ok {
+			logger.Info("Skipping database connection %s, excluded by the connection filter", conn.ID)
+			continue
 		}
+		m.configs[conn.ID] = conn
+	}
 
-		// SQLite-specific validation
-		if conn.Type == "sqlite" {
-			if conn.DatabasePath == "" && conn.Name == "" {
-				return fmt.Errorf("SQLite database %s requires either database_path or name to be specified", conn.ID)
-			}
-		}
+	return nil
+}
 
-		m.configs[conn.ID] = conn
+// connectionIDPattern matches valid connection IDs. IDs become part of tool
+// names and of file names, such as those of backups.
+var connectionIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// validateConnectionID checks that id can be used as a connection ID
+func validateConnectionID(id string) error {
+	if id == "" {
+		return fmt.Errorf("database connection ID cannot be empty")
+	}
+	if !connectionIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid database connection ID %q: use up to 64 letters, digits, '_' and '-'", id)
 	}
+	return nil
+}
 
+// validateConnectionConfig checks a connection configuration before it is used
+func validateConnectionConfig(conn DatabaseConnectionConfig) error {
+	if err := validateConnectionID(conn.ID); err != nil {
+		return err
+	}
+	d, err := dialect.ForType(conn.Type)
+	if err != nil {
+		return fmt.Errorf("connection %s: %w", conn.ID, err)
+	}
+	if err := d.Validate(buildDatabaseConfig(conn)); err != nil {
+		return fmt.Errorf("invalid connection %s: %w", conn.ID, err)
+	}
+
+	// Secret references must name a known provider, they are resolved on connect
+	if err := validateSecretRef("password of connection "+conn.ID, conn.Password); err != nil {
+		return err
+	}
+	if err := validateSecretRef("encryption key of connection "+conn.ID, conn.EncryptionKey); err != nil {
+		return err
+	}
+
+	if conn.SSHTunnel != nil {
+		if err := conn.SSHTunnel.validate(conn); err != nil {
+			return fmt.Errorf("invalid ssh_tunnel for connection %s: %w", conn.ID, err)
+		}
+	}
+	if err := validateReplicas(conn); err != nil {
+		return fmt.Errorf("invalid replicas for connection %s: %w", conn.ID, err)
+	}
 	return nil
 }
 
 // createAndConnectDatabase creates a database instance, connects to it, and returns it
-func createAndConnectDatabase(id string, cfg DatabaseConnectionConfig) (Database, error) {
+func createAndConnectDatabase(id string, conn DatabaseConnectionConfig) (Database, error) {
+	// Resolve secret references on every connect, so that rotated secrets are picked up
+	cfg, err := resolveSecrets(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Forward a local port to the database through the bastion host
+	var tunnel *sshTunnel
+	if cfg.SSHTunnel != nil {
+		tunnel, err = openSSHTunnel(*cfg.SSHTunnel, cfg.Host, cfg.Port)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open ssh tunnel for %s: %w", id, err)
+		}
+		cfg.Host = "127.0.0.1"
+		cfg.Port = tunnel.LocalPort()
+	}
+
 	// Build configuration
 	dbConfig := buildDatabaseConfig(cfg)
 
 	// Create database instance
 	db, err := NewDatabase(dbConfig)
+	if err == nil {
+		// Connect to database
+		if err = db.Connect(); err != nil {
+			err = fmt.Errorf("failed to connect to database %s: %w", id, err)
+		}
+	} else {
+		err = fmt.Errorf("failed to create database instance for %s: %w", id, err)
+	}
+
 	if err != nil {
-		return nil, fmt.Errorf("failed to create database instance for %s: %w", id, err)
+		if tunnel != nil {
+			_ = tunnel.Close()
+		}
+		return nil, err
 	}
 
-	// Connect to database
-	if err := db.Connect(); err != nil {
-		return nil, fmt.Errorf("failed to connect to database %s: %w", id, err)
+	var database Database = db
+	if tunnel != nil {
+		database = &tunneledDatabase{Database: db, tunnel: tunnel}
+	}
+	if len(cfg.Replicas) > 0 {
+		// Replicas start from the connection as configured rather than the
+		// tunneled address, so each one opens its own tunnel to its own host
+		replicated := newReplicatedDatabase(id, database, conn, replicaCheckInterval)
+		replicated.start()
+		database = replicated
 	}
+	return database, nil
+}
 
-	return db, nil
+// IsFileDatabase reports whether a connection type opens a local database
+// file rather than a server, like SQLite and DuckDB
+func IsFileDatabase(connType string) bool {
+	return connType == "sqlite" || connType == "duckdb"
 }
 
 // buildDatabaseConfig creates a Config from DatabaseConnectionConfig
@@ -145,71 +273,29 @@ func buildDatabaseConfig(cfg DatabaseConnectionConfig) Config {
 		User:     cfg.User,
 		Password: cfg.Password,
 		Name:     cfg.Name,
+
+		SSLMode:            PostgresSSLMode(cfg.SSLMode),
+		SSLCert:            cfg.SSLCert,
+		SSLKey:             cfg.SSLKey,
+		SSLRootCert:        cfg.SSLRootCert,
+		ApplicationName:    cfg.ApplicationName,
+		ConnectTimeout:     cfg.ConnectTimeout,
+		QueryTimeout:       cfg.QueryTimeout,
+		TargetSessionAttrs: cfg.TargetSessionAttrs,
+		Options:            cfg.Options,
+
+		DatabasePath:     cfg.DatabasePath,
+		Attach:           cfg.Attach,
+		EncryptionKey:    cfg.EncryptionKey,
+		CacheSize:        cfg.CacheSize,
+		JournalMode:      SQLiteJournalMode(cfg.JournalMode),
+		UseModerncDriver: cfg.UseModerncDriver,
+		ReadOnly:         cfg.ReadOnly,
 	}
 
-	// Set database-specific options based on type
-	switch cfg.Type {
-	case "postgres":
-		dbConfig.SSLMode = PostgresSSLMode(cfg.SSLMode)
-		dbConfig.SSLCert = cfg.SSLCert
-		dbConfig.SSLKey = cfg.SSLKey
-		dbConfig.SSLRootCert = cfg.SSLRootCert
-		dbConfig.ApplicationName = cfg.ApplicationName
-		dbConfig.ConnectTimeout = cfg.ConnectTimeout
-		dbConfig.QueryTimeout = cfg.QueryTimeout
-		dbConfig.TargetSessionAttrs = cfg.TargetSessionAttrs
-		dbConfig.Options = cfg.Options
-	case "oracle":
-		// Extract Oracle-specific options from Options map
-		if serviceName, ok := cfg.Options["service_name"]; ok {
-			dbConfig.ServiceName = serviceName
-		}
-		if sid, ok := cfg.Options["sid"]; ok {
-			dbConfig.SID = sid
-		}
-		if walletLoc, ok := cfg.Options["wallet_location"]; ok {
-			dbConfig.WalletLocation = walletLoc
-		}
-		if tnsAdmin, ok := cfg.Options["tns_admin"]; ok {
-			dbConfig.TNSAdmin = tnsAdmin
-		}
-		if tnsEntry, ok := cfg.Options["tns_entry"]; ok {
-			dbConfig.TNSEntry = tnsEntry
-		}
-		if edition, ok := cfg.Options["edition"]; ok {
-			dbConfig.Edition = edition
-		}
-		if pooling, ok := cfg.Options["pooling"]; ok {
-			dbConfig.Pooling = pooling == "true"
-		}
-		if standby, ok := cfg.Options["standby_sessions"]; ok {
-			dbConfig.StandbySessions = standby == "true"
-		}
-		if nlsLang, ok := cfg.Options["nls_lang"]; ok {
-			dbConfig.NLSLang = nlsLang
-		}
-		dbConfig.ConnectTimeout = cfg.ConnectTimeout
-		dbConfig.QueryTimeout = cfg.QueryTimeout
-		dbConfig.Options = cfg.Options
-	case "mysql":
-		// Set MySQL-specific options
-		dbConfig.ConnectTimeout = cfg.ConnectTimeout
-		dbConfig.QueryTimeout = cfg.QueryTimeout
-	case "sqlite":
-		// Set SQLite-specific options
-		dbConfig.DatabasePath = cfg.DatabasePath
-		dbConfig.EncryptionKey = cfg.EncryptionKey
-		dbConfig.ReadOnly = cfg.ReadOnly
-		dbConfig.CacheSize = cfg.CacheSize
-		if cfg.JournalMode != "" {
-			dbConfig.JournalMode = SQLiteJournalMode(cfg.JournalMode)
-		}
-		dbConfig.UseModerncDriver = cfg.UseModerncDriver
-	default:
-		// Default case - common configuration
-		dbConfig.ConnectTimeout = cfg.ConnectTimeout
-		dbConfig.QueryTimeout = cfg.QueryTimeout
-		dbConfig.Options = cfg.Options
+	// Engine-specific settings, such as the Oracle options
+	if d, ok := dialect.Lookup(cfg.Type); ok {
+		d.Configure(&dbConfig)
 	}
 
 	// Connection pool settings
@@ -292,7 +378,7 @@ func (m *Manager) Connect() error {
 		m.connections[id] = db
 
 		// Log connection info based on database type
-		if cfg.Type == "sqlite" {
+		if IsFileDatabase(cfg.Type) {
 			dbPath := cfg.DatabasePath
 			if dbPath == "" {
 				dbPath = cfg.Name
@@ -312,25 +398,94 @@ func (m *Manager) Connect() error {
 
 // GetDatabase retrieves a database connection by ID
 // If lazy loading is enabled and the connection doesn't exist, it will be established on-demand
+//
+// The caller cannot release the connection, so it is kept from eviction for
+// a grace period, long enough for the caller's statements to take pool
+// connections, which keep it open themselves. Callers that can release it
+// use AcquireDatabase.
 func (m *Manager) GetDatabase(id string) (Database, error) {
-	// First, try to get existing connection with read lock
+	db, _, err := m.acquireDatabase(id, true)
+	return db, err
+}
+
+// AcquireDatabase is GetDatabase for callers that release the connection
+// when done with it. It is not evicted until every lease is released.
+func (m *Manager) AcquireDatabase(id string) (Database, func(), error) {
+	return m.acquireDatabase(id, false)
+}
+
+// InspectDatabase runs fn on a connection, such as to detect the features of
+// a database when its tools are registered. With lazy loading a connection
+// that is not open yet stays closed: fn gets a temporary connection, which
+// is closed again afterwards.
+func (m *Manager) InspectDatabase(id string, fn func(Database) error) error {
 	m.mu.RLock()
-	db, exists := m.connections[id]
+	_, connected := m.connections[id]
+	cfg, exists := m.configs[id]
 	lazyEnabled := m.lazyLoading
 	m.mu.RUnlock()
 
-	// If connection exists, return it
-	if exists {
-		return db, nil
+	if !exists {
+		return fmt.Errorf("database configuration %s not found", id)
+	}
+	if connected || !lazyEnabled {
+		database, release, err := m.AcquireDatabase(id)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return fn(database)
 	}
 
-	// If lazy loading is disabled, connection should already exist
-	if !lazyEnabled {
-		return nil, fmt.Errorf("database connection %s not found", id)
+	database, err := createAndConnectDatabase(id, cfg)
+	if err != nil {
+		return err
 	}
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil {
+			logger.Error("Failed to close temporary connection %s: %v", id, closeErr)
+		}
+	}()
+	return fn(database)
+}
+
+// acquireDatabase returns a connection, opening it on demand with lazy
+// loading, and leases it against eviction until release is called, or for
+// the grace period when grace is set
+func (m *Manager) acquireDatabase(id string, grace bool) (Database, func(), error) {
+	opened := false
+	for {
+		// Lease under the read lock, so that eviction cannot pick the
+		// connection between the lookup and the lease
+		m.mu.RLock()
+		db, exists := m.connections[id]
+		lazyEnabled := m.lazyLoading
+		eviction := m.eviction
+		release := func() {}
+		if exists && eviction != nil {
+			release = eviction.lease(id, grace)
+		}
+		m.mu.RUnlock()
 
-	// Lazy loading is enabled - establish connection on-demand
-	return m.connectOnDemand(id)
+		if exists {
+			if opened && eviction != nil {
+				m.enforceOpenLimit(id)
+			}
+			return db, release, nil
+		}
+
+		// If lazy loading is disabled, connection should already exist
+		if !lazyEnabled {
+			return nil, nil, fmt.Errorf("database connection %s not found", id)
+		}
+
+		// Lazy loading is enabled - establish connection on-demand, and lease
+		// it on the next pass. A connection evicted before then is reopened.
+		if _, err := m.connectOnDemand(id); err != nil {
+			return nil, nil, err
+		}
+		opened = true
+	}
 }
 
 // connectOnDemand establishes a connection to a specific database on first use
@@ -361,7 +516,7 @@ func (m *Manager) connectOnDemand(id string) (Database, error) {
 	m.connections[id] = db
 
 	// Log connection info based on database type
-	if cfg.Type == "sqlite" {
+	if IsFileDatabase(cfg.Type) {
 		dbPath := cfg.DatabasePath
 		if dbPath == "" {
 			dbPath = cfg.Name
@@ -378,6 +533,20 @@ func (m *Manager) connectOnDemand(id string) (Database, error) {
 	return db, nil
 }
 
+// GetReadDatabase returns a connection for reads: a healthy replica that is
+// not lagging too far behind when the connection has replicas, otherwise the
+// primary. Writes and transactions must use GetDatabase.
+func (m *Manager) GetReadDatabase(id string) (Database, error) {
+	db, err := m.GetDatabase(id)
+	if err != nil {
+		return nil, err
+	}
+	if replicated, ok := db.(*replicatedDatabase); ok {
+		return replicated.Reader(), nil
+	}
+	return db, nil
+}
+
 // GetDatabaseType returns the type of a database by its ID
 func (m *Manager) GetDatabaseType(id string) (string, error) {
 	m.mu.RLock()
@@ -392,8 +561,24 @@ func (m *Manager) GetDatabaseType(id string) (string, error) {
 	return cfg.Type, nil
 }
 
-// CloseAll closes all database connections
+// SupportsTransactions reports whether the engine of a database can run
+// transactions, without establishing a connection. Unknown databases and
+// types are assumed to support them, so that their errors come from the
+// database.
+func (m *Manager) SupportsTransactions(id string) bool {
+	dbType, err := m.GetDatabaseType(id)
+	if err != nil {
+		return true
+	}
+	d, ok := dialect.Lookup(dbType)
+	return !ok || d.Transactions()
+}
+
+// CloseAll stops the health monitor and eviction and closes all database connections
 func (m *Manager) CloseAll() error {
+	m.StopHealthMonitor()
+	m.StopEviction()
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -461,8 +646,19 @@ func (m *Manager) GetConnectedDatabases() []string {
 	return ids
 }
 
-// GetDatabaseConfig returns the configuration for a specific database
+// GetDatabaseConfig returns the configuration for a specific database, with
+// plaintext passwords and encryption keys redacted
 func (m *Manager) GetDatabaseConfig(id string) (DatabaseConnectionConfig, error) {
+	cfg, err := m.GetRawDatabaseConfig(id)
+	if err != nil {
+		return cfg, err
+	}
+	return redactSecrets(cfg), nil
+}
+
+// GetRawDatabaseConfig returns the configuration for a specific database as
+// it was configured, including plaintext secrets
+func (m *Manager) GetRawDatabaseConfig(id string) (DatabaseConnectionConfig, error) {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -473,3 +669,240 @@ func (m *Manager) GetDatabaseConfig(id string) (DatabaseConnectionConfig, error)
 
 	return cfg, nil
 }
+
+// connectionChanged reports whether a connection must be reopened for new
+// settings. Settings used only by the server, such as rate limits, do not
+// require it.
+func connectionChanged(old, cfg DatabaseConnectionConfig) bool {
+	return !reflect.DeepEqual(buildDatabaseConfig(old), buildDatabaseConfig(cfg)) ||
+		!reflect.DeepEqual(old.SSHTunnel, cfg.SSHTunnel) ||
+		!reflect.DeepEqual(old.Replicas, cfg.Replicas) ||
+		old.ReplicaPolicy != cfg.ReplicaPolicy ||
+		old.MaxReplicaLag != cfg.MaxReplicaLag
+}
+
+// ConfigDiff lists the connections changed by a reload
+type ConfigDiff struct {
+	Added   []string
+	Removed []string
+	Changed []string
+}
+
+// Empty reports whether a reload changed nothing
+func (d ConfigDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Reload replaces the database configurations with those in configJSON.
+// Every connection is validated before anything changes. Connections that
+// were removed or whose settings changed are closed, letting running queries
+// finish, and new or changed connections are opened unless lazy loading is
+// enabled. Settings that do not affect the connection itself, such as rate
+// limits, are updated without reconnecting. A connection that fails to open
+// is logged and stays configured. A config without connections removes them
+// all.
+func (m *Manager) Reload(configJSON []byte) (ConfigDiff, error) {
+	var config MultiDBConfig
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return ConfigDiff{}, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+	configs := make(map[string]DatabaseConnectionConfig, len(config.Connections))
+	for _, conn := range config.Connections {
+		if err := validateConnectionConfig(conn); err != nil {
+			return ConfigDiff{}, err
+		}
+		if _, exists := configs[conn.ID]; exists {
+			return ConfigDiff{}, fmt.Errorf("duplicate database connection ID: %s", conn.ID)
+		}
+		conn, ok := m.admit(conn)
+		if !ok {
+			continue
+		}
+		configs[conn.ID] = conn
+	}
+
+	m.mu.Lock()
+	var diff ConfigDiff
+	var stale []Database
+	for id, old := range m.configs {
+		cfg, exists := configs[id]
+		switch {
+		case !exists:
+			diff.Removed = append(diff.Removed, id)
+		case connectionChanged(old, cfg):
+			diff.Changed = append(diff.Changed, id)
+		default:
+			continue
+		}
+		if conn, connected := m.connections[id]; connected {
+			stale = append(stale, conn)
+			delete(m.connections, id)
+		}
+	}
+	for id := range configs {
+		if _, exists := m.configs[id]; !exists {
+			diff.Added = append(diff.Added, id)
+		}
+	}
+	m.configs = configs
+	lazyEnabled := m.lazyLoading
+	m.mu.Unlock()
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Changed)
+
+	// Close outside the lock, Close waits for running queries
+	for _, conn := range stale {
+		if err := conn.Close(); err != nil {
+			logger.Error("Failed to close database: %v", err)
+		}
+	}
+
+	if !lazyEnabled {
+		for _, id := range append(append([]string{}, diff.Added...), diff.Changed...) {
+			if _, err := m.connectOnDemand(id); err != nil {
+				logger.Error("Failed to connect to reloaded database %s: %v", id, err)
+			}
+		}
+	}
+
+	return diff, nil
+}
+
+// AddConnection validates a connection with the same rules as LoadConfig and
+// adds it. Unless lazy loading is enabled the connection is opened first, and
+// a connection that fails to open is not added.
+func (m *Manager) AddConnection(conn DatabaseConnectionConfig) error {
+	if err := validateConnectionConfig(conn); err != nil {
+		return err
+	}
+	conn, ok := m.admit(conn)
+	if !ok {
+		return fmt.Errorf("database connection %s is excluded by the connection filter", conn.ID)
+	}
+	if _, err := m.GetDatabaseConfig(conn.ID); err == nil {
+		return fmt.Errorf("database connection %s already exists", conn.ID)
+	}
+
+	var database Database
+	if !m.IsLazyLoading() {
+		var err error
+		if database, err = createAndConnectDatabase(conn.ID, conn); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	if _, exists := m.configs[conn.ID]; exists {
+		m.mu.Unlock()
+		if database != nil {
+			_ = database.Close()
+		}
+		return fmt.Errorf("database connection %s already exists", conn.ID)
+	}
+	m.configs[conn.ID] = conn
+	if database != nil {
+		m.connections[conn.ID] = database
+	}
+	m.mu.Unlock()
+
+	logger.Info("Added database connection %s (%s)", conn.ID, conn.Type)
+	return nil
+}
+
+// UpdateConnection replaces the configuration of an existing connection and
+// reconnects it. Unless lazy loading is enabled the new connection is opened
+// before the old one is closed, and the old one is kept if that fails.
+func (m *Manager) UpdateConnection(conn DatabaseConnectionConfig) error {
+	if err := validateConnectionConfig(conn); err != nil {
+		return err
+	}
+	conn, ok := m.admit(conn)
+	if !ok {
+		return fmt.Errorf("database connection %s is excluded by the connection filter", conn.ID)
+	}
+	if _, err := m.GetDatabaseConfig(conn.ID); err != nil {
+		return err
+	}
+
+	var database Database
+	if !m.IsLazyLoading() {
+		var err error
+		if database, err = createAndConnectDatabase(conn.ID, conn); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	if _, exists := m.configs[conn.ID]; !exists {
+		m.mu.Unlock()
+		if database != nil {
+			_ = database.Close()
+		}
+		return fmt.Errorf("database configuration %s not found", conn.ID)
+	}
+	old, connected := m.connections[conn.ID]
+	m.configs[conn.ID] = conn
+	if database != nil {
+		m.connections[conn.ID] = database
+	} else {
+		delete(m.connections, conn.ID)
+	}
+	m.mu.Unlock()
+
+	// Close outside the lock, Close waits for running queries
+	if connected {
+		if err := old.Close(); err != nil {
+			logger.Error("Failed to close database %s: %v", conn.ID, err)
+		}
+	}
+
+	logger.Info("Updated database connection %s (%s)", conn.ID, conn.Type)
+	return nil
+}
+
+// RemoveConnection closes a connection and removes its configuration
+func (m *Manager) RemoveConnection(id string) error {
+	m.mu.Lock()
+	if _, exists := m.configs[id]; !exists {
+		m.mu.Unlock()
+		return fmt.Errorf("database configuration %s not found", id)
+	}
+	database, connected := m.connections[id]
+	delete(m.configs, id)
+	delete(m.connections, id)
+	m.mu.Unlock()
+
+	if connected {
+		if err := database.Close(); err != nil {
+			return fmt.Errorf("failed to close database %s: %w", id, err)
+		}
+	}
+
+	logger.Info("Removed database connection %s", id)
+	return nil
+}
+
+// TestConnection validates a connection configuration, opens it and pings
+// it without adding it to the manager
+func TestConnection(ctx context.Context, conn DatabaseConnectionConfig) error {
+	if err := validateConnectionConfig(conn); err != nil {
+		return err
+	}
+
+	database, err := createAndConnectDatabase(conn.ID, conn)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := database.Close(); closeErr != nil {
+			logger.Error("Failed to close test connection %s: %v", conn.ID, closeErr)
+		}
+	}()
+
+	if err := database.Ping(ctx); err != nil {
+		return fmt.Errorf("failed to ping database %s: %w", conn.ID, err)
+	}
+	return nil
+}
@@ -1,7 +1,9 @@
 package db
 
 import (
+	"context"
 	"encoding/json"
+	"path/filepath"
 	"sync"
 	"testing"
 	"time"
@@ -140,6 +142,24 @@ func TestLoadConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "database connection ID cannot be empty",
 		},
+		{
+			name: "database id with a path",
+			configJSON: `{
+				"connections": [
+					{
+						"id": "../prod",
+						"type": "postgres",
+						"host": "localhost",
+						"port": 5432,
+						"user": "testuser",
+						"password": "testpass",
+						"name": "testdb"
+					}
+				]
+			}`,
+			expectError: true,
+			errorMsg:    "invalid database connection ID",
+		},
 		{
 			name: "unsupported database type",
 			configJSON: `{
@@ -739,3 +759,189 @@ func TestBuildOracleConfig(t *testing.T) {
 		t.Errorf("expected ConnMaxIdleTime 300s, got %v", cfg.ConnMaxIdleTime)
 	}
 }
+
+func TestReload(t *testing.T) {
+	manager := NewDBManager()
+	// Lazy loading avoids connecting (direct field access to avoid logger dependency)
+	manager.lazyLoading = true
+
+	configJSON := `{
+		"connections": [
+			{"id": "db1", "type": "postgres", "host": "localhost", "port": 5432, "user": "user", "password": "pass", "name": "db1"},
+			{"id": "db2", "type": "postgres", "host": "localhost", "port": 5432, "user": "user", "password": "pass", "name": "db2"},
+			{"id": "db3", "type": "mysql", "host": "localhost", "port": 3306, "user": "user", "password": "pass", "name": "db3"}
+		]
+	}`
+	if err := manager.LoadConfig([]byte(configJSON)); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	// db1 is removed, db2 moves to another host, db3 only gets a rate limit, db4 is new
+	reloadJSON := `{
+		"connections": [
+			{"id": "db2", "type": "postgres", "host": "replica", "port": 5432, "user": "user", "password": "pass", "name": "db2"},
+			{"id": "db3", "type": "mysql", "host": "localhost", "port": 3306, "user": "user", "password": "pass", "name": "db3",
+			 "rate_limit": {"max_concurrent": 2}},
+			{"id": "db4", "type": "sqlite", "database_path": ":memory:"}
+		]
+	}`
+	diff, err := manager.Reload([]byte(reloadJSON))
+	if err != nil {
+		t.Fatalf("failed to reload config: %v", err)
+	}
+
+	if len(diff.Added) != 1 || diff.Added[0] != "db4" {
+		t.Errorf("expected db4 to be added, got %v", diff.Added)
+	}
+	if len(diff.Removed) != 1 || diff.Removed[0] != "db1" {
+		t.Errorf("expected db1 to be removed, got %v", diff.Removed)
+	}
+	if len(diff.Changed) != 1 || diff.Changed[0] != "db2" {
+		t.Errorf("expected only db2 to change, got %v", diff.Changed)
+	}
+
+	if _, err := manager.GetDatabaseType("db1"); err == nil {
+		t.Error("expected db1 to be gone after reload")
+	}
+	cfg, err := manager.GetDatabaseConfig("db3")
+	if err != nil || cfg.RateLimit == nil || cfg.RateLimit.MaxConcurrent != 2 {
+		t.Errorf("expected db3 rate limit to be updated, got %+v (err: %v)", cfg.RateLimit, err)
+	}
+
+	// An invalid configuration leaves the current one in place
+	if _, err := manager.Reload([]byte(`{"connections": [{"id": "bad", "type": "unknown"}]}`)); err == nil {
+		t.Error("expected invalid config to be rejected")
+	}
+	if len(manager.ListDatabases()) != 3 {
+		t.Errorf("expected 3 databases after rejected reloads, got %v", manager.ListDatabases())
+	}
+
+	// A config without connections removes them all
+	diff, err = manager.Reload([]byte(`{"connections": []}`))
+	if err != nil {
+		t.Fatalf("failed to reload empty config: %v", err)
+	}
+	if len(diff.Removed) != 3 || len(manager.ListDatabases()) != 0 {
+		t.Errorf("expected every connection to be removed, got %v (left: %v)", diff.Removed, manager.ListDatabases())
+	}
+}
+
+func TestRuntimeConnections(t *testing.T) {
+	manager := NewDBManager()
+
+	memory := DatabaseConnectionConfig{ID: "scratch", Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true}
+
+	// Invalid connections are rejected with the LoadConfig rules
+	if err := manager.AddConnection(DatabaseConnectionConfig{ID: "bad", Type: "unknown"}); err == nil {
+		t.Error("expected unsupported type to be rejected")
+	}
+	if err := TestConnection(context.Background(), DatabaseConnectionConfig{ID: "bad", Type: "sqlite"}); err == nil {
+		t.Error("expected SQLite connection without a path to be rejected")
+	}
+
+	if err := TestConnection(context.Background(), memory); err != nil {
+		t.Fatalf("expected test connection to succeed: %v", err)
+	}
+	if len(manager.ListDatabases()) != 0 {
+		t.Errorf("expected test connection not to be added, got %v", manager.ListDatabases())
+	}
+
+	if err := manager.AddConnection(memory); err != nil {
+		t.Fatalf("failed to add connection: %v", err)
+	}
+	if err := manager.AddConnection(memory); err == nil {
+		t.Error("expected duplicate connection to be rejected")
+	}
+	first, err := manager.GetDatabase("scratch")
+	if err != nil {
+		t.Fatalf("expected added connection to be open: %v", err)
+	}
+
+	updated := memory
+	updated.QueryTimeout = 5
+	if err := manager.UpdateConnection(updated); err != nil {
+		t.Fatalf("failed to update connection: %v", err)
+	}
+	second, err := manager.GetDatabase("scratch")
+	if err != nil || second == first {
+		t.Errorf("expected update to reconnect (err: %v)", err)
+	}
+	if cfg, _ := manager.GetDatabaseConfig("scratch"); cfg.QueryTimeout != 5 {
+		t.Errorf("expected updated query timeout, got %d", cfg.QueryTimeout)
+	}
+	if err := manager.UpdateConnection(DatabaseConnectionConfig{ID: "missing", Type: "sqlite", DatabasePath: ":memory:"}); err == nil {
+		t.Error("expected update of unknown connection to fail")
+	}
+
+	if err := manager.RemoveConnection("scratch"); err != nil {
+		t.Fatalf("failed to remove connection: %v", err)
+	}
+	if err := manager.RemoveConnection("scratch"); err == nil {
+		t.Error("expected second removal to fail")
+	}
+	if len(manager.ListDatabases()) != 0 || len(manager.GetConnectedDatabases()) != 0 {
+		t.Errorf("expected no connections after removal, got %v", manager.ListDatabases())
+	}
+}
+
+func TestInspectDatabaseLazyLoading(t *testing.T) {
+	initLoggerForTests()
+
+	manager := NewDBManager()
+	manager.lazyLoading = true
+	conn := DatabaseConnectionConfig{ID: "app", Type: "sqlite", DatabasePath: filepath.Join(t.TempDir(), "app.db"), UseModerncDriver: true}
+	if err := manager.AddConnection(conn); err != nil {
+		t.Fatalf("failed to add connection: %v", err)
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	// A temporary connection is used and the connection stays closed
+	var one int
+	err := manager.InspectDatabase("app", func(database Database) error {
+		return database.QueryRow(context.Background(), "SELECT 1").Scan(&one)
+	})
+	if err != nil || one != 1 {
+		t.Fatalf("expected inspection to run, got %d (err: %v)", one, err)
+	}
+	if connected := manager.GetConnectedDatabases(); len(connected) != 0 {
+		t.Errorf("expected no open connections after inspection, got %v", connected)
+	}
+
+	// An open connection is used as it is
+	if _, err := manager.GetDatabase("app"); err != nil {
+		t.Fatalf("failed to open connection: %v", err)
+	}
+	if err := manager.InspectDatabase("app", func(Database) error { return nil }); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if connected := manager.GetConnectedDatabases(); len(connected) != 1 {
+		t.Errorf("expected the connection to stay open, got %v", connected)
+	}
+
+	if err := manager.InspectDatabase("missing", func(Database) error { return nil }); err == nil {
+		t.Error("expected unknown connection to fail")
+	}
+}
+
+func TestSupportsTransactions(t *testing.T) {
+	manager := NewDBManager()
+	configJSON := `{
+		"connections": [
+			{"id": "pg", "type": "postgres", "host": "localhost", "port": 5432, "name": "app"},
+			{"id": "events", "type": "clickhouse", "host": "localhost", "port": 9000, "name": "events"}
+		]
+	}`
+	if err := manager.LoadConfig([]byte(configJSON)); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if !manager.SupportsTransactions("pg") {
+		t.Error("expected PostgreSQL to support transactions")
+	}
+	if manager.SupportsTransactions("events") {
+		t.Error("expected ClickHouse not to support transactions")
+	}
+	if !manager.SupportsTransactions("missing") {
+		t.Error("expected unknown databases to be assumed to support transactions")
+	}
+}
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConnectionPolicy sets defaults for the connections it matches, from the
+// "policies" section of the config file. A policy matches connections in its
+// environment and with any of its tags; a policy without either matches
+// every connection. Query timeouts and pool limits are only applied where a
+// connection leaves them unset, from the first matching policy that sets
+// them. ReadOnly is not a default: a connection is read-only when any
+// matching policy says so, and cannot opt out with read_only: false.
+type ConnectionPolicy struct {
+	Environment string   `json:"environment,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+
+	ReadOnly     bool `json:"read_only,omitempty"`
+	QueryTimeout int  `json:"query_timeout,omitempty"` // in seconds
+	MaxOpenConns int  `json:"max_open_conns,omitempty"`
+}
+
+// Validate checks a policy
+func (p ConnectionPolicy) Validate() error {
+	if p.QueryTimeout < 0 || p.MaxOpenConns < 0 {
+		return fmt.Errorf("policy settings must not be negative")
+	}
+	return nil
+}
+
+// Matches reports whether a policy applies to a connection
+func (p ConnectionPolicy) Matches(conn DatabaseConnectionConfig) bool {
+	if p.Environment != "" && !strings.EqualFold(p.Environment, conn.Environment) {
+		return false
+	}
+	return len(p.Tags) == 0 || hasAnyTag(conn, p.Tags)
+}
+
+// applyPolicies fills the settings a connection leaves unset from the
+// policies that match it, and makes it read-only if any of them is
+func applyPolicies(conn DatabaseConnectionConfig, policies []ConnectionPolicy) DatabaseConnectionConfig {
+	for _, p := range policies {
+		if !p.Matches(conn) {
+			continue
+		}
+		if p.ReadOnly {
+			conn.ReadOnly = true
+		}
+		if conn.QueryTimeout == 0 {
+			conn.QueryTimeout = p.QueryTimeout
+		}
+		if conn.MaxOpenConns == 0 {
+			conn.MaxOpenConns = p.MaxOpenConns
+		}
+	}
+	return conn
+}
+
+// ConnectionFilter selects the connections the server uses, set with the
+// -only-tags and -exclude-env flags. Other connections in the config file are
+// ignored.
+type ConnectionFilter struct {
+	OnlyTags            []string // connections need at least one of these tags
+	ExcludeEnvironments []string // connections in these environments are ignored
+}
+
+// Matches reports whether a connection passes the filter
+func (f ConnectionFilter) Matches(conn DatabaseConnectionConfig) bool {
+	for _, env := range f.ExcludeEnvironments {
+		if strings.EqualFold(env, conn.Environment) {
+			return false
+		}
+	}
+	return len(f.OnlyTags) == 0 || hasAnyTag(conn, f.OnlyTags)
+}
+
+// hasAnyTag reports whether a connection has one of the tags
+func hasAnyTag(conn DatabaseConnectionConfig, tags []string) bool {
+	for _, tag := range tags {
+		for _, own := range conn.Tags {
+			if strings.EqualFold(tag, own) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetConnectionRules sets the filter and policies applied to connections when
+// they are loaded, reloaded, added or updated. Call it before LoadConfig.
+func (m *Manager) SetConnectionRules(filter ConnectionFilter, policies []ConnectionPolicy) error {
+	for i, p := range policies {
+		if err := p.Validate(); err != nil {
+			return fmt.Errorf("policy %d: %w", i+1, err)
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.filter = filter
+	m.policies = policies
+	return nil
+}
+
+// admit applies the connection rules; ok is false for a connection the filter
+// excludes
+func (m *Manager) admit(conn DatabaseConnectionConfig) (DatabaseConnectionConfig, bool) {
+	m.mu.RLock()
+	filter, policies := m.filter, m.policies
+	m.mu.RUnlock()
+
+	if !filter.Matches(conn) {
+		return conn, false
+	}
+	return applyPolicies(conn, policies), true
+}
@@ -0,0 +1,117 @@
+package db
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConnectionPolicies(t *testing.T) {
+	prod := DatabaseConnectionConfig{ID: "billing", Environment: "Prod", Tags: []string{"billing", "core"}}
+	dev := DatabaseConnectionConfig{ID: "scratch", Environment: "dev", QueryTimeout: 5}
+
+	policies := []ConnectionPolicy{
+		{Environment: "prod", ReadOnly: true, QueryTimeout: 30},
+		{Tags: []string{"CORE"}, QueryTimeout: 60, MaxOpenConns: 5},
+		{MaxOpenConns: 10, QueryTimeout: 120},
+	}
+
+	got := applyPolicies(prod, policies)
+	if !got.ReadOnly || got.QueryTimeout != 30 || got.MaxOpenConns != 5 {
+		t.Errorf("expected the first matching policy to win, got read_only=%v query_timeout=%d max_open_conns=%d",
+			got.ReadOnly, got.QueryTimeout, got.MaxOpenConns)
+	}
+
+	// A connection cannot opt out of a read-only policy
+	prod.ReadOnly = false
+	if got = applyPolicies(prod, []ConnectionPolicy{{}, {Tags: []string{"billing"}, ReadOnly: true}}); !got.ReadOnly {
+		t.Error("expected any matching read-only policy to make the connection read-only")
+	}
+
+	got = applyPolicies(dev, policies)
+	if got.ReadOnly || got.QueryTimeout != 5 || got.MaxOpenConns != 10 {
+		t.Errorf("expected only unset settings to be filled, got read_only=%v query_timeout=%d max_open_conns=%d",
+			got.ReadOnly, got.QueryTimeout, got.MaxOpenConns)
+	}
+
+	if err := (ConnectionPolicy{QueryTimeout: -1}).Validate(); err == nil {
+		t.Error("expected negative policy settings to be rejected")
+	}
+	if err := NewDBManager().SetConnectionRules(ConnectionFilter{}, []ConnectionPolicy{{MaxOpenConns: -1}}); err == nil {
+		t.Error("expected invalid policies to be rejected")
+	}
+}
+
+func TestConnectionFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter ConnectionFilter
+		conn   DatabaseConnectionConfig
+		want   bool
+	}{
+		{"empty filter", ConnectionFilter{}, DatabaseConnectionConfig{}, true},
+		{"matching tag", ConnectionFilter{OnlyTags: []string{"a", "b"}}, DatabaseConnectionConfig{Tags: []string{"B"}}, true},
+		{"missing tag", ConnectionFilter{OnlyTags: []string{"a"}}, DatabaseConnectionConfig{Tags: []string{"b"}}, false},
+		{"untagged", ConnectionFilter{OnlyTags: []string{"a"}}, DatabaseConnectionConfig{}, false},
+		{"excluded environment", ConnectionFilter{ExcludeEnvironments: []string{"prod"}}, DatabaseConnectionConfig{Environment: "PROD"}, false},
+		{"other environment", ConnectionFilter{ExcludeEnvironments: []string{"prod"}}, DatabaseConnectionConfig{Environment: "dev"}, true},
+		{"tag in excluded environment", ConnectionFilter{OnlyTags: []string{"a"}, ExcludeEnvironments: []string{"prod"}},
+			DatabaseConnectionConfig{Environment: "prod", Tags: []string{"a"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tt.conn); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestManagerConnectionRules(t *testing.T) {
+	manager := NewDBManager()
+	manager.lazyLoading = true
+
+	err := manager.SetConnectionRules(
+		ConnectionFilter{ExcludeEnvironments: []string{"prod"}},
+		[]ConnectionPolicy{{Environment: "staging", ReadOnly: true}},
+	)
+	if err != nil {
+		t.Fatalf("failed to set connection rules: %v", err)
+	}
+
+	configJSON := `{
+		"connections": [
+			{"id": "live", "type": "sqlite", "database_path": ":memory:", "environment": "prod"},
+			{"id": "stage", "type": "sqlite", "database_path": ":memory:", "environment": "staging", "tags": ["reporting"]},
+			{"id": "local", "type": "sqlite", "database_path": ":memory:", "description": "Scratch database"}
+		]
+	}`
+	if err := manager.LoadConfig([]byte(configJSON)); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	if ids := manager.ListDatabases(); len(ids) != 2 {
+		t.Fatalf("expected the prod connection to be filtered out, got %v", ids)
+	}
+	stage, err := manager.GetDatabaseConfig("stage")
+	if err != nil || !stage.ReadOnly {
+		t.Errorf("expected the staging policy to make stage read-only, got %+v (err: %v)", stage, err)
+	}
+	raw, err := manager.GetRawDatabaseConfig("local")
+	if err != nil || raw.Description != "Scratch database" || raw.ReadOnly {
+		t.Errorf("expected local to keep its labels and stay writable, got %+v", raw)
+	}
+
+	// Filtered connections cannot be added at runtime or come back on reload
+	err = manager.AddConnection(DatabaseConnectionConfig{ID: "live", Type: "sqlite", DatabasePath: ":memory:", Environment: "prod"})
+	if err == nil || !strings.Contains(err.Error(), "excluded") {
+		t.Errorf("expected the connection filter to reject live, got %v", err)
+	}
+	diff, err := manager.Reload([]byte(configJSON))
+	if err != nil {
+		t.Fatalf("failed to reload config: %v", err)
+	}
+	if len(diff.Added) != 0 || len(diff.Removed) != 0 || len(diff.Changed) != 0 {
+		t.Errorf("expected an unchanged reload, got %+v", diff)
+	}
+}
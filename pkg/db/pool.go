@@ -0,0 +1,150 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// PoolStats reports the connection pool of a database connection
+type PoolStats struct {
+	ID        string
+	Type      string
+	Connected bool // false for lazily loaded connections that were not used yet
+
+	// Effective pool settings
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+
+	sql.DBStats                    // pool of the primary, zero when not connected
+	Replicas    []ReplicaPoolStats // pools of the connected read replicas
+}
+
+// ReplicaPoolStats reports the connection pool of a read replica
+type ReplicaPoolStats struct {
+	Address string
+	sql.DBStats
+}
+
+// PoolSettings changes the connection pool of a database at runtime. Zero
+// fields keep their current setting.
+type PoolSettings struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime int // in seconds
+}
+
+// poolConfig returns the effective pool settings of a connection
+func poolConfig(cfg DatabaseConnectionConfig) Config {
+	dbConfig := buildDatabaseConfig(cfg)
+	dbConfig.SetDefaults()
+	return dbConfig
+}
+
+// PoolStats returns the pool statistics of all connections, ordered by ID
+func (m *Manager) PoolStats() []PoolStats {
+	m.mu.RLock()
+	ids := make([]string, 0, len(m.configs))
+	for id := range m.configs {
+		ids = append(ids, id)
+	}
+	m.mu.RUnlock()
+	sort.Strings(ids)
+
+	stats := make([]PoolStats, 0, len(ids))
+	for _, id := range ids {
+		if s, err := m.DatabasePoolStats(id); err == nil {
+			stats = append(stats, s)
+		}
+	}
+	return stats
+}
+
+// DatabasePoolStats returns the pool statistics of a connection
+func (m *Manager) DatabasePoolStats(id string) (PoolStats, error) {
+	m.mu.RLock()
+	cfg, exists := m.configs[id]
+	database, connected := m.connections[id]
+	m.mu.RUnlock()
+	if !exists {
+		return PoolStats{}, fmt.Errorf("database connection %s not found", id)
+	}
+
+	settings := poolConfig(cfg)
+	stats := PoolStats{
+		ID:              id,
+		Type:            cfg.Type,
+		Connected:       connected,
+		MaxOpenConns:    settings.MaxOpenConns,
+		MaxIdleConns:    settings.MaxIdleConns,
+		ConnMaxLifetime: settings.ConnMaxLifetime,
+	}
+	if !connected {
+		return stats, nil
+	}
+	if pool := database.DB(); pool != nil {
+		stats.DBStats = pool.Stats()
+	}
+	if replicated, ok := database.(*replicatedDatabase); ok {
+		stats.Replicas = replicated.poolStats()
+	}
+	return stats, nil
+}
+
+// TunePool changes the pool settings of a connection. The open pools of the
+// primary and its replicas are adjusted in place, and the settings are kept
+// for reconnects until the connection is reconfigured.
+func (m *Manager) TunePool(id string, settings PoolSettings) (PoolStats, error) {
+	if settings.MaxOpenConns < 0 || settings.MaxIdleConns < 0 || settings.ConnMaxLifetime < 0 {
+		return PoolStats{}, fmt.Errorf("pool settings must not be negative")
+	}
+
+	m.mu.Lock()
+	cfg, exists := m.configs[id]
+	if !exists {
+		m.mu.Unlock()
+		return PoolStats{}, fmt.Errorf("database connection %s not found", id)
+	}
+	if settings.MaxOpenConns > 0 {
+		cfg.MaxOpenConns = settings.MaxOpenConns
+	}
+	if settings.MaxIdleConns > 0 {
+		cfg.MaxIdleConns = settings.MaxIdleConns
+	}
+	if settings.ConnMaxLifetime > 0 {
+		cfg.ConnMaxLifetime = settings.ConnMaxLifetime
+	}
+	effective := poolConfig(cfg)
+	if effective.MaxIdleConns > effective.MaxOpenConns {
+		m.mu.Unlock()
+		return PoolStats{}, fmt.Errorf("max idle connections (%d) must not exceed max open connections (%d)",
+			effective.MaxIdleConns, effective.MaxOpenConns)
+	}
+	m.configs[id] = cfg
+	database, connected := m.connections[id]
+	m.mu.Unlock()
+
+	if connected {
+		applyPoolSettings(database.DB(), effective)
+		if replicated, ok := database.(*replicatedDatabase); ok {
+			replicated.tunePool(cfg, effective)
+		}
+	}
+	logger.Info("Tuned connection pool of %s: max open %d, max idle %d, max lifetime %s",
+		id, effective.MaxOpenConns, effective.MaxIdleConns, effective.ConnMaxLifetime)
+	return m.DatabasePoolStats(id)
+}
+
+// applyPoolSettings adjusts an open pool
+func applyPoolSettings(pool *sql.DB, settings Config) {
+	if pool == nil {
+		return
+	}
+	pool.SetMaxOpenConns(settings.MaxOpenConns)
+	pool.SetMaxIdleConns(settings.MaxIdleConns)
+	pool.SetConnMaxLifetime(settings.ConnMaxLifetime)
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestPoolStatsAndTuning(t *testing.T) {
+	manager := NewDBManager()
+	manager.SetLazyLoading(true)
+	for _, conn := range []DatabaseConnectionConfig{
+		{ID: "scratch", Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true, MaxOpenConns: 4},
+		{ID: "later", Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true},
+	} {
+		if err := manager.AddConnection(conn); err != nil {
+			t.Fatalf("failed to add connection: %v", err)
+		}
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	database, err := manager.GetDatabase("scratch")
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if err := database.Ping(context.Background()); err != nil {
+		t.Fatalf("failed to ping: %v", err)
+	}
+
+	stats := manager.PoolStats()
+	if len(stats) != 2 || stats[0].ID != "later" || stats[1].ID != "scratch" {
+		t.Fatalf("expected stats of both connections in ID order, got %+v", stats)
+	}
+	if stats[0].Connected || stats[0].OpenConnections != 0 || stats[0].MaxOpenConns != 25 {
+		t.Errorf("expected defaults and no pool for the unused connection, got %+v", stats[0])
+	}
+	if !stats[1].Connected || stats[1].MaxOpenConns != 4 || stats[1].MaxOpenConnections != 4 || stats[1].OpenConnections == 0 {
+		t.Errorf("expected the configured pool of the connected database, got %+v", stats[1])
+	}
+
+	// Tuning adjusts the open pool and keeps settings that were not given
+	tuned, err := manager.TunePool("scratch", PoolSettings{MaxOpenConns: 8, ConnMaxLifetime: 60})
+	if err != nil {
+		t.Fatalf("failed to tune pool: %v", err)
+	}
+	if tuned.MaxOpenConns != 8 || tuned.MaxOpenConnections != 8 || tuned.MaxIdleConns != 5 || tuned.ConnMaxLifetime != time.Minute {
+		t.Errorf("expected the tuned pool, got %+v", tuned)
+	}
+	manager.mu.RLock()
+	cfg := manager.configs["scratch"]
+	manager.mu.RUnlock()
+	if cfg.MaxOpenConns != 8 || cfg.ConnMaxLifetime != 60 {
+		t.Errorf("expected tuned settings to be kept for reconnects, got %+v", cfg)
+	}
+
+	// Tuning a lazily loaded connection applies when it connects
+	if _, err := manager.TunePool("later", PoolSettings{MaxIdleConns: 2}); err != nil {
+		t.Fatalf("failed to tune unused pool: %v", err)
+	}
+	later, err := manager.GetDatabase("later")
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	if got := later.DB().Stats().MaxOpenConnections; got != 25 {
+		t.Errorf("expected default max open connections, got %d", got)
+	}
+
+	for name, settings := range map[string]PoolSettings{
+		"negative":          {MaxOpenConns: -1},
+		"idle above open":   {MaxOpenConns: 2, MaxIdleConns: 3},
+		"idle above limits": {MaxIdleConns: 9},
+	} {
+		if _, err := manager.TunePool("scratch", settings); err == nil {
+			t.Errorf("%s: expected pool settings to be rejected", name)
+		}
+	}
+	if _, err := manager.TunePool("missing", PoolSettings{MaxOpenConns: 1}); err == nil {
+		t.Error("expected unknown connection to be rejected")
+	}
+	if _, err := manager.DatabasePoolStats("missing"); err == nil {
+		t.Error("expected unknown connection to be rejected")
+	}
+}
@@ -0,0 +1,405 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Replica routing policies
+const (
+	ReplicaPolicyRoundRobin   = "round_robin"
+	ReplicaPolicyLeastLatency = "least_latency"
+)
+
+// replicaCheckInterval is how often replicas are pinged and their lag measured
+const replicaCheckInterval = 10 * time.Second
+
+// ReplicaConfig describes a read replica of a connection. Empty fields are
+// taken from the primary.
+type ReplicaConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"` // secret references allowed
+	Name     string `json:"name,omitempty"`
+}
+
+// address returns the replica's host:port for logs and errors
+func (r ReplicaConfig) address(primary DatabaseConnectionConfig) string {
+	port := r.Port
+	if port == 0 {
+		port = primary.Port
+	}
+	return net.JoinHostPort(r.Host, strconv.Itoa(port))
+}
+
+// connectionConfig returns the settings of a replica: those of the primary
+// with the replica's overrides
+func (r ReplicaConfig) connectionConfig(primary DatabaseConnectionConfig) DatabaseConnectionConfig {
+	cfg := primary
+	cfg.Replicas = nil
+	cfg.Host = r.Host
+	if r.Port != 0 {
+		cfg.Port = r.Port
+	}
+	if r.User != "" {
+		cfg.User = r.User
+	}
+	if r.Password != "" {
+		cfg.Password = r.Password
+	}
+	if r.Name != "" {
+		cfg.Name = r.Name
+	}
+	return cfg
+}
+
+// replicaLagCheckers measure how far a replica is behind its primary, by database type
+var replicaLagCheckers = map[string]func(ctx context.Context, db *sql.DB) (time.Duration, error){
+	"postgres": postgresReplicaLag,
+	"mysql":    mysqlReplicaLag,
+}
+
+// validateReplicas checks the replica settings of a connection
+func validateReplicas(conn DatabaseConnectionConfig) error {
+	if len(conn.Replicas) == 0 {
+		if conn.ReplicaPolicy != "" || conn.MaxReplicaLag != 0 {
+			return fmt.Errorf("replica_policy and max_replica_lag require replicas")
+		}
+		return nil
+	}
+	if IsFileDatabase(conn.Type) {
+		return fmt.Errorf("%s databases do not support replicas", conn.Type)
+	}
+	switch conn.ReplicaPolicy {
+	case "", ReplicaPolicyRoundRobin, ReplicaPolicyLeastLatency:
+	default:
+		return fmt.Errorf("unknown replica_policy %q (use %s or %s)", conn.ReplicaPolicy, ReplicaPolicyRoundRobin, ReplicaPolicyLeastLatency)
+	}
+	if conn.MaxReplicaLag < 0 {
+		return fmt.Errorf("max_replica_lag must not be negative")
+	}
+	if _, ok := replicaLagCheckers[conn.Type]; conn.MaxReplicaLag > 0 && !ok {
+		return fmt.Errorf("max_replica_lag is not supported for %s databases", conn.Type)
+	}
+	for i, replica := range conn.Replicas {
+		if replica.Host == "" {
+			return fmt.Errorf("replica %d: host is required", i+1)
+		}
+		if err := validateSecretRef(fmt.Sprintf("replica %d password", i+1), replica.Password); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// replica is a read replica and what its last check found
+type replica struct {
+	address string
+	cfg     DatabaseConnectionConfig
+
+	mu        sync.Mutex
+	database  Database // nil until connected
+	checked   bool
+	healthy   bool
+	latency   time.Duration
+	lag       time.Duration
+	lastError string
+}
+
+// replicatedDatabase is a primary database with read replicas. The embedded
+// primary serves writes and transactions; Reader picks a replica for reads.
+type replicatedDatabase struct {
+	Database
+	id       string
+	dbType   string
+	policy   string
+	maxLag   time.Duration
+	interval time.Duration
+	replicas []*replica
+	next     atomic.Uint64 // round-robin position
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// newReplicatedDatabase wraps a primary. Its replicas are connected and
+// checked in the background once started; reads go to the primary until a
+// replica has passed its first check.
+func newReplicatedDatabase(id string, primary Database, cfg DatabaseConnectionConfig, interval time.Duration) *replicatedDatabase {
+	d := &replicatedDatabase{
+		Database: primary,
+		id:       id,
+		dbType:   cfg.Type,
+		policy:   cfg.ReplicaPolicy,
+		maxLag:   time.Duration(cfg.MaxReplicaLag) * time.Second,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+	if d.policy == "" {
+		d.policy = ReplicaPolicyRoundRobin
+	}
+	for _, r := range cfg.Replicas {
+		d.replicas = append(d.replicas, &replica{address: r.address(cfg), cfg: r.connectionConfig(cfg)})
+	}
+	return d
+}
+
+// start begins checking the replicas
+func (d *replicatedDatabase) start() {
+	d.wg.Add(1)
+	go d.checkLoop()
+}
+
+// Reader returns a healthy replica that is not lagging too far behind, or
+// the primary when there is none
+func (d *replicatedDatabase) Reader() Database {
+	var candidates []*replica
+	for _, r := range d.replicas {
+		r.mu.Lock()
+		if r.database != nil && r.healthy && (d.maxLag == 0 || r.lag <= d.maxLag) {
+			candidates = append(candidates, r)
+		}
+		r.mu.Unlock()
+	}
+	if len(candidates) == 0 {
+		return d.Database
+	}
+
+	chosen := candidates[0]
+	switch d.policy {
+	case ReplicaPolicyLeastLatency:
+		best := time.Duration(-1)
+		for _, r := range candidates {
+			r.mu.Lock()
+			if best < 0 || r.latency < best {
+				best = r.latency
+				chosen = r
+			}
+			r.mu.Unlock()
+		}
+	default:
+		chosen = candidates[(d.next.Add(1)-1)%uint64(len(candidates))]
+	}
+
+	chosen.mu.Lock()
+	defer chosen.mu.Unlock()
+	return chosen.database
+}
+
+// Close stops checking the replicas and closes them and the primary
+func (d *replicatedDatabase) Close() error {
+	d.closeOnce.Do(func() {
+		close(d.done)
+		d.wg.Wait()
+		for _, r := range d.replicas {
+			r.mu.Lock()
+			if r.database != nil {
+				if err := r.database.Close(); err != nil {
+					logger.Warn("Failed to close replica %s of %s: %v", r.address, d.id, err)
+				}
+				r.database = nil
+			}
+			r.mu.Unlock()
+		}
+	})
+	return d.Database.Close()
+}
+
+// ConnectionString describes the primary and the number of replicas
+func (d *replicatedDatabase) ConnectionString() string {
+	return fmt.Sprintf("%s with %d replica(s)", d.Database.ConnectionString(), len(d.replicas))
+}
+
+// poolStats returns the pool statistics of the connected replicas
+func (d *replicatedDatabase) poolStats() []ReplicaPoolStats {
+	var stats []ReplicaPoolStats
+	for _, r := range d.replicas {
+		r.mu.Lock()
+		if r.database != nil && r.database.DB() != nil {
+			stats = append(stats, ReplicaPoolStats{Address: r.address, DBStats: r.database.DB().Stats()})
+		}
+		r.mu.Unlock()
+	}
+	return stats
+}
+
+// tunePool applies the pool settings of the primary to the replicas, both to
+// their open pools and to the settings used when they reconnect
+func (d *replicatedDatabase) tunePool(primary DatabaseConnectionConfig, settings Config) {
+	for _, r := range d.replicas {
+		r.mu.Lock()
+		r.cfg.MaxOpenConns = primary.MaxOpenConns
+		r.cfg.MaxIdleConns = primary.MaxIdleConns
+		r.cfg.ConnMaxLifetime = primary.ConnMaxLifetime
+		if r.database != nil {
+			applyPoolSettings(r.database.DB(), settings)
+		}
+		r.mu.Unlock()
+	}
+}
+
+// checkLoop checks the replicas until the database is closed
+func (d *replicatedDatabase) checkLoop() {
+	defer d.wg.Done()
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		d.checkReplicas()
+		select {
+		case <-d.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkReplicas checks every replica concurrently
+func (d *replicatedDatabase) checkReplicas() {
+	var wg sync.WaitGroup
+	for _, r := range d.replicas {
+		wg.Add(1)
+		go func(r *replica) {
+			defer wg.Done()
+			d.check(r)
+		}(r)
+	}
+	wg.Wait()
+}
+
+// check connects a replica if needed, pings it and measures its lag
+func (d *replicatedDatabase) check(r *replica) {
+	r.mu.Lock()
+	database := r.database
+	r.mu.Unlock()
+
+	if database == nil {
+		connected, err := createAndConnectDatabase(d.id, r.cfg)
+		if err != nil {
+			d.record(r, nil, 0, 0, err)
+			return
+		}
+		select {
+		case <-d.done:
+			_ = connected.Close()
+			return
+		default:
+		}
+		database = connected
+		logger.Info("Connected to replica %s of database %s", r.address, d.id)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), defaultHealthTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := database.Ping(ctx)
+	latency := time.Since(start)
+
+	var lag time.Duration
+	if err == nil && d.maxLag > 0 {
+		lag, err = replicaLagCheckers[d.dbType](ctx, database.DB())
+	}
+	d.record(r, database, latency, lag, err)
+}
+
+// record stores the result of a replica check
+func (d *replicatedDatabase) record(r *replica, database Database, latency, lag time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if database != nil {
+		r.database = database
+	}
+	wasHealthy, firstCheck := r.healthy, !r.checked
+	r.checked = true
+	r.healthy = err == nil
+	r.latency = latency
+	r.lag = lag
+	if err != nil {
+		r.lastError = err.Error()
+		if wasHealthy || firstCheck {
+			logger.Warn("Replica %s of database %s is unavailable, reading from the primary: %v", r.address, d.id, err)
+		}
+		return
+	}
+	r.lastError = ""
+	if !wasHealthy {
+		logger.Info("Replica %s of database %s is available for reads", r.address, d.id)
+	}
+	if d.maxLag > 0 && lag > d.maxLag {
+		logger.Warn("Replica %s of database %s is %s behind, reading from the primary", r.address, d.id, lag.Round(time.Second))
+	}
+}
+
+// postgresReplicaLag returns the replay lag of a PostgreSQL standby. A
+// standby that has replayed everything it received has no lag, even when the
+// primary has been idle since its last transaction.
+func postgresReplicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	var seconds sql.NullFloat64
+	err := db.QueryRowContext(ctx, `SELECT CASE
+		WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+	END`).Scan(&seconds)
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure replica lag: %w", err)
+	}
+	if !seconds.Valid {
+		return 0, fmt.Errorf("server is not replaying from a primary")
+	}
+	return time.Duration(seconds.Float64 * float64(time.Second)), nil
+}
+
+// mysqlReplicaLag returns Seconds_Behind_Source of a MySQL replica, falling
+// back to Seconds_Behind_Master on servers older than 8.0.22
+func mysqlReplicaLag(ctx context.Context, db *sql.DB) (time.Duration, error) {
+	rows, err := db.QueryContext(ctx, "SHOW REPLICA STATUS")
+	if err != nil {
+		rows, err = db.QueryContext(ctx, "SHOW SLAVE STATUS")
+		if err != nil {
+			return 0, fmt.Errorf("failed to measure replica lag: %w", err)
+		}
+	}
+	defer func() { _ = rows.Close() }()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, fmt.Errorf("failed to measure replica lag: %w", err)
+	}
+	if !rows.Next() {
+		return 0, fmt.Errorf("server is not a replica")
+	}
+	values := make([]sql.RawBytes, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return 0, fmt.Errorf("failed to measure replica lag: %w", err)
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Source" && column != "Seconds_Behind_Master" {
+			continue
+		}
+		if values[i] == nil {
+			return 0, fmt.Errorf("replication is not running")
+		}
+		seconds, err := strconv.Atoi(string(values[i]))
+		if err != nil {
+			return 0, fmt.Errorf("invalid replica lag %q: %w", values[i], err)
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return 0, fmt.Errorf("replica status has no Seconds_Behind_Source column")
+}
@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// roleDatabase creates a SQLite file that names its role and its replica lag
+func roleDatabase(t *testing.T, dir, role string, lag int) DatabaseConnectionConfig {
+	t.Helper()
+	cfg := DatabaseConnectionConfig{ID: role, Type: "sqlite", DatabasePath: filepath.Join(dir, role+".db"), UseModerncDriver: true}
+	database, err := createAndConnectDatabase(role, cfg)
+	if err != nil {
+		t.Fatalf("failed to create %s: %v", role, err)
+	}
+	defer func() { _ = database.Close() }()
+	ctx := context.Background()
+	if _, err := database.Exec(ctx, "CREATE TABLE role (name TEXT, lag INTEGER)"); err != nil {
+		t.Fatalf("failed to create role table: %v", err)
+	}
+	if _, err := database.Exec(ctx, "INSERT INTO role VALUES (?, ?)", role, lag); err != nil {
+		t.Fatalf("failed to insert role: %v", err)
+	}
+	return cfg
+}
+
+// roleOf returns the role of the database a read was routed to
+func roleOf(t *testing.T, database Database) string {
+	t.Helper()
+	var role string
+	if err := database.QueryRow(context.Background(), "SELECT name FROM role").Scan(&role); err != nil {
+		t.Fatalf("failed to read role: %v", err)
+	}
+	return role
+}
+
+// waitForReplicas waits until every replica has been checked
+func waitForReplicas(t *testing.T, d *replicatedDatabase) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		checked := true
+		for _, r := range d.replicas {
+			r.mu.Lock()
+			checked = checked && r.checked
+			r.mu.Unlock()
+		}
+		if checked {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected replicas to be checked")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReplicatedDatabase(t *testing.T) {
+	// Replica lag of the test databases is read from their role table
+	replicaLagCheckers["sqlite"] = func(ctx context.Context, db *sql.DB) (time.Duration, error) {
+		var seconds int
+		err := db.QueryRowContext(ctx, "SELECT lag FROM role").Scan(&seconds)
+		return time.Duration(seconds) * time.Second, err
+	}
+	defer delete(replicaLagCheckers, "sqlite")
+
+	dir := t.TempDir()
+	primaryCfg := roleDatabase(t, dir, "primary", 0)
+	first := roleDatabase(t, dir, "first", 0)
+	second := roleDatabase(t, dir, "second", 0)
+
+	primary, err := createAndConnectDatabase("primary", primaryCfg)
+	if err != nil {
+		t.Fatalf("failed to connect primary: %v", err)
+	}
+	cfg := primaryCfg
+	cfg.Replicas = []ReplicaConfig{{Host: "first"}, {Host: "second"}, {Host: "down"}}
+	d := newReplicatedDatabase("primary", primary, cfg, time.Hour)
+	d.replicas[0].cfg.DatabasePath = first.DatabasePath
+	d.replicas[1].cfg.DatabasePath = second.DatabasePath
+	d.replicas[2].cfg.DatabasePath = filepath.Join(dir, "missing", "down.db")
+
+	// Reads go to the primary until the replicas have been checked
+	if role := roleOf(t, d.Reader()); role != "primary" {
+		t.Errorf("expected reads from the primary before checks, got %s", role)
+	}
+
+	d.start()
+	defer func() {
+		if err := d.Close(); err != nil {
+			t.Errorf("failed to close: %v", err)
+		}
+	}()
+	waitForReplicas(t, d)
+
+	// Round robin alternates between the healthy replicas and skips the one
+	// that could not be connected
+	seen := map[string]int{}
+	for i := 0; i < 4; i++ {
+		seen[roleOf(t, d.Reader())]++
+	}
+	if seen["first"] != 2 || seen["second"] != 2 {
+		t.Errorf("expected reads to alternate between replicas, got %v", seen)
+	}
+	if d.replicas[2].lastError == "" {
+		t.Error("expected the unreachable replica to record its error")
+	}
+
+	// Least latency picks the fastest replica
+	d.policy = ReplicaPolicyLeastLatency
+	d.replicas[0].latency = 5 * time.Millisecond
+	d.replicas[1].latency = time.Millisecond
+	if role := roleOf(t, d.Reader()); role != "second" {
+		t.Errorf("expected reads from the fastest replica, got %s", role)
+	}
+
+	// Replicas lagging too far behind are skipped, and reads go to the
+	// primary when every replica is lagging
+	d.maxLag = 5 * time.Second
+	if _, err := d.replicas[1].database.Exec(context.Background(), "UPDATE role SET lag = 10"); err != nil {
+		t.Fatalf("failed to set lag: %v", err)
+	}
+	d.checkReplicas()
+	if role := roleOf(t, d.Reader()); role != "first" {
+		t.Errorf("expected reads from the replica within lag, got %s", role)
+	}
+	if _, err := d.replicas[0].database.Exec(context.Background(), "UPDATE role SET lag = 10"); err != nil {
+		t.Fatalf("failed to set lag: %v", err)
+	}
+	d.checkReplicas()
+	if role := roleOf(t, d.Reader()); role != "primary" {
+		t.Errorf("expected reads from the primary when replicas lag, got %s", role)
+	}
+
+	if !strings.HasSuffix(d.ConnectionString(), "with 3 replica(s)") {
+		t.Errorf("unexpected connection string %q", d.ConnectionString())
+	}
+}
+
+func TestGetReadDatabaseWithoutReplicas(t *testing.T) {
+	manager := NewDBManager()
+	if err := manager.AddConnection(DatabaseConnectionConfig{ID: "scratch", Type: "sqlite", DatabasePath: ":memory:", UseModerncDriver: true}); err != nil {
+		t.Fatalf("failed to add connection: %v", err)
+	}
+	defer func() { _ = manager.CloseAll() }()
+
+	primary, err := manager.GetDatabase("scratch")
+	if err != nil {
+		t.Fatalf("failed to get database: %v", err)
+	}
+	reader, err := manager.GetReadDatabase("scratch")
+	if err != nil {
+		t.Fatalf("failed to get read database: %v", err)
+	}
+	if reader != primary {
+		t.Error("expected reads from the primary of a connection without replicas")
+	}
+	if _, err := manager.GetReadDatabase("missing"); err == nil {
+		t.Error("expected unknown connection to be rejected")
+	}
+}
+
+func TestReplicaConfig(t *testing.T) {
+	primary := DatabaseConnectionConfig{ID: "pg", Type: "postgres", Host: "db1", Port: 5432, User: "app", Password: "secret", Name: "shop"}
+
+	replica := ReplicaConfig{Host: "db2", User: "reader"}.connectionConfig(primary)
+	if replica.Host != "db2" || replica.Port != 5432 || replica.User != "reader" || replica.Password != "secret" || replica.Name != "shop" {
+		t.Errorf("expected replica to inherit unset fields from the primary, got %+v", replica)
+	}
+	if got := (ReplicaConfig{Host: "db3", Port: 6432}).address(primary); got != "db3:6432" {
+		t.Errorf("unexpected replica address %q", got)
+	}
+
+	// Replicas behind a bastion are forwarded through their own tunnel
+	tunneled := primary
+	tunneled.SSHTunnel = &SSHTunnelConfig{Host: "bastion", User: "ops", UseAgent: true}
+	replica = ReplicaConfig{Host: "db2"}.connectionConfig(tunneled)
+	if replica.Host != "db2" || replica.Port != 5432 || replica.SSHTunnel == nil || replica.SSHTunnel.Host != "bastion" {
+		t.Errorf("expected replica to be tunneled to its own host, got %+v", replica)
+	}
+
+	valid := primary
+	valid.Replicas = []ReplicaConfig{{Host: "db2"}}
+	valid.ReplicaPolicy = ReplicaPolicyLeastLatency
+	valid.MaxReplicaLag = 30
+	if err := validateReplicas(valid); err != nil {
+		t.Errorf("expected valid replicas, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*DatabaseConnectionConfig)
+	}{
+		{"policy without replicas", func(c *DatabaseConnectionConfig) { c.Replicas = nil }},
+		{"sqlite", func(c *DatabaseConnectionConfig) { c.Type = "sqlite" }},
+		{"unknown policy", func(c *DatabaseConnectionConfig) { c.ReplicaPolicy = "random" }},
+		{"negative lag", func(c *DatabaseConnectionConfig) { c.MaxReplicaLag = -1 }},
+		{"lag on oracle", func(c *DatabaseConnectionConfig) { c.Type = "oracle" }},
+		{"missing host", func(c *DatabaseConnectionConfig) { c.Replicas = []ReplicaConfig{{Port: 5433}} }},
+	}
+	for _, tt := range tests {
+		cfg := valid
+		tt.modify(&cfg)
+		if err := validateReplicas(cfg); err == nil {
+			t.Errorf("%s: expected replicas to be rejected", tt.name)
+		}
+	}
+
+	redacted := redactSecrets(DatabaseConnectionConfig{Replicas: []ReplicaConfig{{Host: "db2", Password: "hunter2"}}})
+	if redacted.Replicas[0].Password == "hunter2" {
+		t.Error("expected replica password to be redacted")
+	}
+}
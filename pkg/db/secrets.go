@@ -0,0 +1,247 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// redactedSecret replaces plaintext secrets in configurations handed out by the manager
+const redactedSecret = "********"
+
+// defaultSecretCommandTimeout bounds commands run by a CommandSecretProvider
+const defaultSecretCommandTimeout = 10 * time.Second
+
+// secretRefPattern matches secret references such as ${env:PG_PASSWORD}
+var secretRefPattern = regexp.MustCompile(`^\$\{([A-Za-z][A-Za-z0-9_-]*):(.+)\}$`)
+
+// SecretProvider resolves secret references of the form ${scheme:reference}.
+// The provider registered for the scheme receives the reference part.
+type SecretProvider interface {
+	Resolve(reference string) (string, error)
+}
+
+var (
+	secretProvidersMu sync.RWMutex
+	secretProviders   = map[string]SecretProvider{
+		"env":  EnvSecretProvider{},
+		"file": FileSecretProvider{},
+	}
+)
+
+// RegisterSecretProvider makes a provider available for references with the
+// given scheme, replacing any provider registered for it before
+func RegisterSecretProvider(scheme string, provider SecretProvider) {
+	secretProvidersMu.Lock()
+	defer secretProvidersMu.Unlock()
+	secretProviders[scheme] = provider
+}
+
+// EnvSecretProvider reads secrets from environment variables: ${env:PG_PASSWORD}
+type EnvSecretProvider struct{}
+
+// Resolve returns the value of an environment variable
+func (EnvSecretProvider) Resolve(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s is not set", name)
+	}
+	return value, nil
+}
+
+// FileSecretProvider reads secrets from files such as Docker or Kubernetes
+// secrets: ${file:/run/secrets/pg}. A trailing newline is removed.
+type FileSecretProvider struct{}
+
+// Resolve returns the contents of a file
+func (FileSecretProvider) Resolve(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// CommandSecretProvider runs a command with the reference as its last
+// argument and uses the first line of its output, so that a provider with
+// Command ["pass", "show"] resolves ${pass:db/pg} by running "pass show db/pg".
+// The command is run directly, without a shell.
+type CommandSecretProvider struct {
+	Command []string
+	Timeout time.Duration // defaults to 10 seconds
+}
+
+// Resolve runs the command for a reference
+func (p *CommandSecretProvider) Resolve(reference string) (string, error) {
+	if len(p.Command) == 0 {
+		return "", fmt.Errorf("secret provider has no command")
+	}
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultSecretCommandTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	args := append(append([]string{}, p.Command[1:]...), reference)
+	cmd := exec.CommandContext(ctx, p.Command[0], args...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w: %s", p.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	secret, _, _ := strings.Cut(string(output), "\n")
+	return strings.TrimRight(secret, "\r"), nil
+}
+
+// IsSecretRef reports whether a value is a secret reference
+func IsSecretRef(value string) bool {
+	return secretRefPattern.MatchString(value)
+}
+
+// ResolveSecret resolves a secret reference with its provider. Other values
+// are returned unchanged.
+func ResolveSecret(value string) (string, error) {
+	match := secretRefPattern.FindStringSubmatch(value)
+	if match == nil {
+		return value, nil
+	}
+
+	provider, err := secretProvider(match[1])
+	if err != nil {
+		return "", err
+	}
+	secret, err := provider.Resolve(match[2])
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s: %w", value, err)
+	}
+	logger.AddSecret(secret)
+	return secret, nil
+}
+
+// secretProvider returns the provider registered for a scheme
+func secretProvider(scheme string) (SecretProvider, error) {
+	secretProvidersMu.RLock()
+	defer secretProvidersMu.RUnlock()
+
+	provider, ok := secretProviders[scheme]
+	if !ok {
+		schemes := make([]string, 0, len(secretProviders))
+		for name := range secretProviders {
+			schemes = append(schemes, name)
+		}
+		sort.Strings(schemes)
+		return nil, fmt.Errorf("unknown secret provider %q (available: %s)", scheme, strings.Join(schemes, ", "))
+	}
+	return provider, nil
+}
+
+// validateSecretRef checks that a secret reference names a registered provider
+func validateSecretRef(field, value string) error {
+	match := secretRefPattern.FindStringSubmatch(value)
+	if match == nil {
+		return nil
+	}
+	if _, err := secretProvider(match[1]); err != nil {
+		return fmt.Errorf("%s: %w", field, err)
+	}
+	return nil
+}
+
+// CheckSecretRefs rejects the secret references of a connection supplied at
+// runtime, such as through the connection tools. Otherwise anyone allowed to
+// add a connection could point it at their own server and have environment
+// variables or files of this server sent as its password. References kept
+// unchanged from previous, the settings being updated, are allowed.
+func CheckSecretRefs(conn DatabaseConnectionConfig, previous *DatabaseConnectionConfig) error {
+	var kept map[string]string
+	if previous != nil {
+		kept = secretRefs(*previous)
+	}
+	for field, ref := range secretRefs(conn) {
+		if kept[field] != ref {
+			return fmt.Errorf("%s of connection %s: secret references can only be used in the config file", field, conn.ID)
+		}
+	}
+	return nil
+}
+
+// secretRefs returns the secret references of a connection by field
+func secretRefs(cfg DatabaseConnectionConfig) map[string]string {
+	refs := make(map[string]string)
+	add := func(field, value string) {
+		if IsSecretRef(value) {
+			refs[field] = value
+		}
+	}
+	add("password", cfg.Password)
+	add("encryption key", cfg.EncryptionKey)
+	if cfg.SSHTunnel != nil {
+		add("ssh passphrase", cfg.SSHTunnel.Passphrase)
+	}
+	for i, replica := range cfg.Replicas {
+		add(fmt.Sprintf("replica %d password", i+1), replica.Password)
+	}
+	return refs
+}
+
+// resolveSecrets returns a copy of a connection with its secret references
+// resolved. Plaintext secrets are registered for redaction from the logs.
+func resolveSecrets(cfg DatabaseConnectionConfig) (DatabaseConnectionConfig, error) {
+	var err error
+	if cfg.Password, err = ResolveSecret(cfg.Password); err != nil {
+		return cfg, fmt.Errorf("password of connection %s: %w", cfg.ID, err)
+	}
+	if cfg.EncryptionKey, err = ResolveSecret(cfg.EncryptionKey); err != nil {
+		return cfg, fmt.Errorf("encryption key of connection %s: %w", cfg.ID, err)
+	}
+	if cfg.SSHTunnel != nil && cfg.SSHTunnel.Passphrase != "" {
+		tunnel := *cfg.SSHTunnel
+		if tunnel.Passphrase, err = ResolveSecret(tunnel.Passphrase); err != nil {
+			return cfg, fmt.Errorf("ssh passphrase of connection %s: %w", cfg.ID, err)
+		}
+		logger.AddSecret(tunnel.Passphrase)
+		cfg.SSHTunnel = &tunnel
+	}
+	logger.AddSecret(cfg.Password)
+	logger.AddSecret(cfg.EncryptionKey)
+	return cfg, nil
+}
+
+// redactSecrets returns a copy of a connection with plaintext secrets
+// replaced. Secret references are kept, they do not reveal the secret.
+func redactSecrets(cfg DatabaseConnectionConfig) DatabaseConnectionConfig {
+	if cfg.Password != "" && !IsSecretRef(cfg.Password) {
+		cfg.Password = redactedSecret
+	}
+	if cfg.EncryptionKey != "" && !IsSecretRef(cfg.EncryptionKey) {
+		cfg.EncryptionKey = redactedSecret
+	}
+	if cfg.SSHTunnel != nil && cfg.SSHTunnel.Passphrase != "" && !IsSecretRef(cfg.SSHTunnel.Passphrase) {
+		tunnel := *cfg.SSHTunnel
+		tunnel.Passphrase = redactedSecret
+		cfg.SSHTunnel = &tunnel
+	}
+	if len(cfg.Replicas) > 0 {
+		replicas := make([]ReplicaConfig, len(cfg.Replicas))
+		for i, replica := range cfg.Replicas {
+			if replica.Password != "" && !IsSecretRef(replica.Password) {
+				replica.Password = redactedSecret
+			}
+			replicas[i] = replica
+		}
+		cfg.Replicas = replicas
+	}
+	return cfg
+}
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("TEST_DB_PASSWORD", "from-env")
+
+	secretFile := filepath.Join(t.TempDir(), "pg")
+	if err := os.WriteFile(secretFile, []byte("from-file\n"), 0600); err != nil {
+		t.Fatalf("failed to write secret file: %v", err)
+	}
+
+	RegisterSecretProvider("echo", &CommandSecretProvider{Command: []string{"echo", "cmd"}})
+
+	tests := []struct {
+		value    string
+		expected string
+	}{
+		{"plain", "plain"},
+		{"", ""},
+		{"${env:TEST_DB_PASSWORD}", "from-env"},
+		{"${file:" + secretFile + "}", "from-file"},
+		{"${echo:secret}", "cmd secret"},
+		{"prefix ${env:TEST_DB_PASSWORD}", "prefix ${env:TEST_DB_PASSWORD}"}, // only whole values are references
+	}
+	for _, tt := range tests {
+		secret, err := ResolveSecret(tt.value)
+		if err != nil {
+			t.Errorf("ResolveSecret(%q) failed: %v", tt.value, err)
+			continue
+		}
+		if secret != tt.expected {
+			t.Errorf("ResolveSecret(%q) = %q, expected %q", tt.value, secret, tt.expected)
+		}
+	}
+
+	for _, value := range []string{"${env:TEST_DB_MISSING}", "${file:/nonexistent/secret}", "${vault:pg}"} {
+		if _, err := ResolveSecret(value); err == nil {
+			t.Errorf("expected ResolveSecret(%q) to fail", value)
+		}
+	}
+}
+
+func TestSecretReferencesInConfig(t *testing.T) {
+	manager := NewDBManager()
+
+	// Unknown providers are rejected when the config is loaded
+	err := manager.LoadConfig([]byte(`{"connections": [{"id": "db1", "type": "postgres", "password": "${vault:pg}"}]}`))
+	if err == nil || !strings.Contains(err.Error(), "unknown secret provider") {
+		t.Errorf("expected unknown secret provider error, got %v", err)
+	}
+
+	configJSON := `{
+		"connections": [
+			{"id": "plain", "type": "postgres", "password": "hunter2"},
+			{"id": "ref", "type": "postgres", "password": "${env:PG_PASSWORD}"}
+		]
+	}`
+	if err := manager.LoadConfig([]byte(configJSON)); err != nil {
+		t.Fatalf("failed to load config: %v", err)
+	}
+
+	// Plaintext secrets are redacted, references are shown as configured
+	cfg, _ := manager.GetDatabaseConfig("plain")
+	if cfg.Password != redactedSecret {
+		t.Errorf("expected plaintext password to be redacted, got %q", cfg.Password)
+	}
+	cfg, _ = manager.GetDatabaseConfig("ref")
+	if cfg.Password != "${env:PG_PASSWORD}" {
+		t.Errorf("expected secret reference to be kept, got %q", cfg.Password)
+	}
+	raw, _ := manager.GetRawDatabaseConfig("plain")
+	if raw.Password != "hunter2" {
+		t.Errorf("expected raw config to keep the password, got %q", raw.Password)
+	}
+
+	// Secrets are resolved when the connection is built
+	t.Setenv("PG_PASSWORD", "s3cret")
+	resolved, err := resolveSecrets(DatabaseConnectionConfig{ID: "ref", Password: "${env:PG_PASSWORD}"})
+	if err != nil || buildDatabaseConfig(resolved).Password != "s3cret" {
+		t.Errorf("expected resolved password, got %q (err: %v)", resolved.Password, err)
+	}
+}
+
+func TestCheckSecretRefs(t *testing.T) {
+	previous := DatabaseConnectionConfig{ID: "db1", Type: "postgres", Host: "db", Password: "${env:PG_PASSWORD}"}
+
+	if err := CheckSecretRefs(DatabaseConnectionConfig{ID: "db2", Type: "postgres", Password: "hunter2"}, nil); err != nil {
+		t.Errorf("expected plaintext password to be accepted, got %v", err)
+	}
+
+	// New references could read any variable or file of the server
+	for _, conn := range []DatabaseConnectionConfig{
+		{ID: "db2", Type: "postgres", Password: "${env:PG_PASSWORD}"},
+		{ID: "db2", Type: "sqlite", EncryptionKey: "${file:/etc/shadow}"},
+		{ID: "db2", Type: "postgres", SSHTunnel: &SSHTunnelConfig{Passphrase: "${env:HOME}"}},
+		{ID: "db2", Type: "postgres", Replicas: []ReplicaConfig{{Password: "${env:PG_PASSWORD}"}}},
+	} {
+		err := CheckSecretRefs(conn, nil)
+		if err == nil || !strings.Contains(err.Error(), "secret references can only be used in the config file") {
+			t.Errorf("expected secret reference to be rejected, got %v", err)
+		}
+	}
+
+	// Updates may keep the references of the connection, but not change them
+	updated := previous
+	updated.Host = "db2"
+	if err := CheckSecretRefs(updated, &previous); err != nil {
+		t.Errorf("expected kept reference to be accepted, got %v", err)
+	}
+	updated.Password = "${env:AWS_SECRET_ACCESS_KEY}"
+	if err := CheckSecretRefs(updated, &previous); err == nil {
+		t.Error("expected changed reference to be rejected")
+	}
+}
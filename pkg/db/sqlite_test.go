@@ -2,7 +2,9 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	intLogger "github.com/FreePeak/db-mcp-server/internal/logger"
@@ -379,3 +381,175 @@ func TestSQLitePerformanceSettings(t *testing.T) {
 		t.Logf("Journal mode: %s", journalMode)
 	}
 }
+
+func TestSQLiteAttach(t *testing.T) {
+	initLoggerForTests()
+
+	tempDir := t.TempDir()
+	ctx := context.Background()
+
+	// Prepare the attached database file
+	archive, err := NewDatabase(Config{Type: "sqlite", DatabasePath: filepath.Join(tempDir, "archive.db"), UseModerncDriver: true})
+	if err != nil {
+		t.Fatalf("Failed to create archive database: %v", err)
+	}
+	if err := archive.Connect(); err != nil {
+		t.Fatalf("Failed to connect to archive database: %v", err)
+	}
+	for _, stmt := range []string{
+		"CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT)",
+		"CREATE TABLE orders (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES customers(id), total REAL)",
+		"INSERT INTO orders (customer_id, total) VALUES (1, 12.5), (1, 7.5), (2, 3)",
+	} {
+		if _, err := archive.Exec(ctx, stmt); err != nil {
+			t.Fatalf("Failed to prepare archive database: %v", err)
+		}
+	}
+	_ = archive.Close()
+
+	config := Config{
+		Type:             "sqlite",
+		DatabasePath:     filepath.Join(tempDir, "app.db"),
+		UseModerncDriver: true,
+		Attach:           map[string]string{"archive": filepath.Join(tempDir, "archive.db")},
+		MaxOpenConns:     3,
+	}
+	db, err := NewDatabase(config)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec(ctx, "CREATE TABLE customers (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	if _, err := db.Exec(ctx, "INSERT INTO customers (id, name) VALUES (1, 'Ada'), (2, 'Grace')"); err != nil {
+		t.Fatalf("Failed to insert: %v", err)
+	}
+
+	// Every connection of the pool has the database attached
+	conns := make([]*sql.Conn, 0, config.MaxOpenConns)
+	for i := 0; i < config.MaxOpenConns; i++ {
+		conn, err := db.DB().Conn(ctx)
+		if err != nil {
+			t.Fatalf("Failed to get connection: %v", err)
+		}
+		conns = append(conns, conn)
+
+		var total float64
+		err = conn.QueryRowContext(ctx, `
+			SELECT SUM(o.total) FROM customers c
+			JOIN archive.orders o ON o.customer_id = c.id
+			WHERE c.name = 'Ada'
+		`).Scan(&total)
+		if err != nil {
+			t.Fatalf("Cross-file join failed on connection %d: %v", i, err)
+		}
+		if total != 20 {
+			t.Errorf("Expected total 20, got %v", total)
+		}
+	}
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+
+	// The catalog lists the tables of both files
+	tables := db.Dialect().TablesQueries()[0]
+	rows, err := db.Query(ctx, tables.SQL, tables.Args...)
+	if err != nil {
+		t.Fatalf("Failed to list tables: %v", err)
+	}
+	var names []string
+	for rows.Next() {
+		var name, schema string
+		if err := rows.Scan(&name, &schema); err != nil {
+			t.Fatalf("Failed to scan table: %v", err)
+		}
+		names = append(names, name)
+	}
+	_ = rows.Close()
+	expected := []string{"customers", "archive.customers", "archive.orders"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected tables %v, got %v", expected, names)
+	}
+
+	columns := db.Dialect().ColumnsQueries("archive.orders")[1]
+	var count int
+	if err := db.QueryRow(ctx, "SELECT COUNT(*) FROM ("+columns.SQL+")", columns.Args...).Scan(&count); err != nil {
+		t.Fatalf("Failed to get columns: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("Expected 3 columns of archive.orders, got %d", count)
+	}
+
+	relationships := db.Dialect().RelationshipsQueries("")[0]
+	var schema, table, foreignTable string
+	err = db.QueryRow(ctx, "SELECT table_schema, table_name, foreign_table_name FROM ("+relationships.SQL+")").Scan(&schema, &table, &foreignTable)
+	if err != nil {
+		t.Fatalf("Failed to get relationships: %v", err)
+	}
+	if schema != "archive" || table != "orders" || foreignTable != "customers" {
+		t.Errorf("Unexpected relationship %s.%s -> %s", schema, table, foreignTable)
+	}
+
+	// Invalid attachments are rejected
+	for _, attach := range []map[string]string{
+		{"main": "other.db"},
+		{"bad name": "other.db"},
+		{"archive": ""},
+	} {
+		if err := validateConnectionConfig(DatabaseConnectionConfig{ID: "app", Type: "sqlite", DatabasePath: "app.db", Attach: attach}); err == nil {
+			t.Errorf("Expected attach %v to be rejected", attach)
+		}
+	}
+}
+
+func TestSQLiteAttachReadOnly(t *testing.T) {
+	initLoggerForTests()
+
+	tempDir := t.TempDir()
+	ctx := context.Background()
+
+	archive, err := NewDatabase(Config{Type: "sqlite", DatabasePath: filepath.Join(tempDir, "archive.db"), UseModerncDriver: true})
+	if err != nil {
+		t.Fatalf("Failed to create archive database: %v", err)
+	}
+	if err := archive.Connect(); err != nil {
+		t.Fatalf("Failed to connect to archive database: %v", err)
+	}
+	if _, err := archive.Exec(ctx, "CREATE TABLE events (id INTEGER PRIMARY KEY)"); err != nil {
+		t.Fatalf("Failed to create table: %v", err)
+	}
+	_ = archive.Close()
+
+	main, err := NewDatabase(Config{Type: "sqlite", DatabasePath: filepath.Join(tempDir, "app.db"), UseModerncDriver: true})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := main.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	_ = main.Close()
+
+	db, err := NewDatabase(Config{
+		Type:             "sqlite",
+		DatabasePath:     filepath.Join(tempDir, "app.db"),
+		UseModerncDriver: true,
+		ReadOnly:         true,
+		Attach:           map[string]string{"archive": filepath.Join(tempDir, "archive.db")},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if err := db.Connect(); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer func() { _ = db.Close() }()
+
+	if _, err := db.Exec(ctx, "INSERT INTO archive.events (id) VALUES (1)"); err == nil {
+		t.Error("Expected writes to an attached database of a read-only connection to fail")
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"strings"
 	"testing"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
 )
 
 // MockDB simulates a database for testing purposes
@@ -189,6 +191,12 @@ func (m *MockDB) DriverName() string {
 	return "postgres"
 }
 
+// Dialect implements db.Database.Dialect
+func (m *MockDB) Dialect() dialect.Dialect {
+	d, _ := dialect.Lookup("postgres")
+	return d
+}
+
 // QueryTimeout implements db.Database.QueryTimeout
 func (m *MockDB) QueryTimeout() int {
 	return 30
@@ -0,0 +1,369 @@
+package db
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Default SSH tunnel settings
+const (
+	defaultSSHPort          = 22
+	defaultSSHKeepAlive     = 30 * time.Second
+	defaultSSHDialTimeout   = 10 * time.Second
+	defaultKnownHostsSuffix = ".ssh/known_hosts"
+)
+
+// SSHTunnelConfig describes a bastion host through which a connection is forwarded
+type SSHTunnelConfig struct {
+	Host             string `json:"host"`
+	Port             int    `json:"port,omitempty"` // defaults to 22
+	User             string `json:"user"`
+	PrivateKeyPath   string `json:"private_key_path,omitempty"`
+	Passphrase       string `json:"passphrase,omitempty"`        // for an encrypted private key, secret references allowed
+	UseAgent         bool   `json:"use_agent,omitempty"`         // authenticate with the agent at SSH_AUTH_SOCK
+	KnownHosts       string `json:"known_hosts,omitempty"`       // defaults to ~/.ssh/known_hosts
+	KeepAliveSeconds int    `json:"keepalive_seconds,omitempty"` // defaults to 30
+}
+
+// validate checks a tunnel configuration for a connection
+func (c *SSHTunnelConfig) validate(conn DatabaseConnectionConfig) error {
+	if c.Host == "" || c.User == "" {
+		return fmt.Errorf("host and user are required")
+	}
+	if c.PrivateKeyPath == "" && !c.UseAgent {
+		return fmt.Errorf("private_key_path or use_agent is required")
+	}
+	if IsFileDatabase(conn.Type) {
+		return fmt.Errorf("%s databases cannot be reached through a tunnel", conn.Type)
+	}
+	if conn.Host == "" {
+		return fmt.Errorf("the database host is required, as seen from the bastion")
+	}
+	return validateSecretRef("passphrase", c.Passphrase)
+}
+
+// address returns the bastion's host:port
+func (c *SSHTunnelConfig) address() string {
+	port := c.Port
+	if port == 0 {
+		port = defaultSSHPort
+	}
+	return net.JoinHostPort(c.Host, strconv.Itoa(port))
+}
+
+// sshTunnel forwards a local port to a database behind a bastion host. The
+// SSH connection is kept alive and re-established when it drops.
+type sshTunnel struct {
+	cfg      SSHTunnelConfig
+	remote   string // database address as seen from the bastion
+	config   *ssh.ClientConfig
+	listener net.Listener
+	agent    net.Conn // connection to the SSH agent, if used
+
+	mu     sync.Mutex
+	client *ssh.Client
+
+	done      chan struct{}
+	closeOnce sync.Once
+	wg        sync.WaitGroup
+}
+
+// openSSHTunnel connects to the bastion and listens on a local port that
+// forwards to remoteHost:remotePort
+func openSSHTunnel(cfg SSHTunnelConfig, remoteHost string, remotePort int) (*sshTunnel, error) {
+	t := &sshTunnel{
+		cfg:    cfg,
+		remote: net.JoinHostPort(remoteHost, strconv.Itoa(remotePort)),
+		done:   make(chan struct{}),
+	}
+
+	auth, err := t.authMethods()
+	if err != nil {
+		t.closeAgent()
+		return nil, err
+	}
+	hostKeyCallback, err := knownHostsCallback(cfg.KnownHosts)
+	if err != nil {
+		t.closeAgent()
+		return nil, err
+	}
+	t.config = &ssh.ClientConfig{
+		User:            cfg.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         defaultSSHDialTimeout,
+	}
+
+	// Connect now so that configuration errors surface immediately
+	if _, err := t.sshClient(); err != nil {
+		t.closeAgent()
+		return nil, err
+	}
+
+	t.listener, err = net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		_ = t.Close()
+		return nil, fmt.Errorf("failed to listen for ssh tunnel: %w", err)
+	}
+
+	t.wg.Add(2)
+	go t.acceptLoop()
+	go t.keepAlive()
+
+	logger.Info("Opened SSH tunnel 127.0.0.1:%d -> %s via %s@%s", t.LocalPort(), t.remote, cfg.User, cfg.address())
+	return t, nil
+}
+
+// LocalPort returns the local port forwarded to the database
+func (t *sshTunnel) LocalPort() int {
+	return t.listener.Addr().(*net.TCPAddr).Port
+}
+
+// Close stops forwarding and disconnects from the bastion
+func (t *sshTunnel) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.done)
+		if t.listener != nil {
+			_ = t.listener.Close()
+		}
+		t.mu.Lock()
+		if t.client != nil {
+			_ = t.client.Close()
+			t.client = nil
+		}
+		t.mu.Unlock()
+		t.wg.Wait()
+		t.closeAgent()
+	})
+	return nil
+}
+
+// authMethods returns the configured SSH authentication methods
+func (t *sshTunnel) authMethods() ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+
+	if t.cfg.PrivateKeyPath != "" {
+		key, err := os.ReadFile(expandHome(t.cfg.PrivateKeyPath))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ssh private key: %w", err)
+		}
+		var signer ssh.Signer
+		if t.cfg.Passphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(t.cfg.Passphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ssh private key %s: %w", t.cfg.PrivateKeyPath, err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if t.cfg.UseAgent {
+		socket := os.Getenv("SSH_AUTH_SOCK")
+		if socket == "" {
+			return nil, fmt.Errorf("use_agent is set but SSH_AUTH_SOCK is not")
+		}
+		conn, err := net.Dial("unix", socket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to ssh agent: %w", err)
+		}
+		t.agent = conn
+		methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	return methods, nil
+}
+
+// closeAgent closes the connection to the SSH agent
+func (t *sshTunnel) closeAgent() {
+	if t.agent != nil {
+		_ = t.agent.Close()
+	}
+}
+
+// sshClient returns the current SSH client, connecting when there is none
+func (t *sshTunnel) sshClient() (*ssh.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	select {
+	case <-t.done:
+		return nil, fmt.Errorf("ssh tunnel is closed")
+	default:
+	}
+
+	if t.client != nil {
+		return t.client, nil
+	}
+	client, err := ssh.Dial("tcp", t.cfg.address(), t.config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to ssh host %s: %w", t.cfg.address(), err)
+	}
+	t.client = client
+	return client, nil
+}
+
+// resetClient drops a broken SSH client so that the next use reconnects
+func (t *sshTunnel) resetClient(client *ssh.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.client == client {
+		_ = client.Close()
+		t.client = nil
+	}
+}
+
+// acceptLoop forwards local connections until the tunnel is closed
+func (t *sshTunnel) acceptLoop() {
+	defer t.wg.Done()
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			select {
+			case <-t.done:
+			default:
+				logger.Error("SSH tunnel to %s stopped accepting connections: %v", t.remote, err)
+			}
+			return
+		}
+		go t.forward(local)
+	}
+}
+
+// forward connects a local connection to the database through the bastion,
+// reconnecting once if the SSH connection dropped
+func (t *sshTunnel) forward(local net.Conn) {
+	remote, err := t.dialRemote()
+	if err != nil {
+		logger.Error("SSH tunnel failed to reach %s: %v", t.remote, err)
+		_ = local.Close()
+		return
+	}
+
+	var once sync.Once
+	closeBoth := func() {
+		_ = local.Close()
+		_ = remote.Close()
+	}
+	go func() {
+		_, _ = io.Copy(remote, local)
+		once.Do(closeBoth)
+	}()
+	_, _ = io.Copy(local, remote)
+	once.Do(closeBoth)
+}
+
+// dialRemote opens a forwarded connection to the database
+func (t *sshTunnel) dialRemote() (net.Conn, error) {
+	client, err := t.sshClient()
+	if err != nil {
+		return nil, err
+	}
+	remote, err := client.Dial("tcp", t.remote)
+	if err == nil {
+		return remote, nil
+	}
+
+	t.resetClient(client)
+	if client, err = t.sshClient(); err != nil {
+		return nil, err
+	}
+	return client.Dial("tcp", t.remote)
+}
+
+// keepAlive checks the SSH connection periodically and reconnects it when
+// it dropped, so that the next database connection does not wait for it
+func (t *sshTunnel) keepAlive() {
+	defer t.wg.Done()
+
+	interval := time.Duration(t.cfg.KeepAliveSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultSSHKeepAlive
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-t.done:
+			return
+		case <-ticker.C:
+		}
+
+		client, err := t.sshClient()
+		if err != nil {
+			logger.Warn("SSH tunnel to %s is down: %v", t.cfg.address(), err)
+			continue
+		}
+		if _, _, err := client.SendRequest("keepalive@openssh.com", true, nil); err != nil {
+			logger.Warn("SSH tunnel to %s dropped, reconnecting: %v", t.cfg.address(), err)
+			t.resetClient(client)
+			if _, err := t.sshClient(); err != nil {
+				logger.Warn("SSH tunnel to %s is down: %v", t.cfg.address(), err)
+			}
+		}
+	}
+}
+
+// knownHostsCallback verifies host keys against a known_hosts file
+func knownHostsCallback(path string) (ssh.HostKeyCallback, error) {
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate known_hosts: %w", err)
+		}
+		path = filepath.Join(home, defaultKnownHostsSuffix)
+	}
+	callback, err := knownhosts.New(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load known_hosts %s: %w", path, err)
+	}
+	return callback, nil
+}
+
+// expandHome expands a leading ~/ to the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// tunneledDatabase is a database reached through an SSH tunnel, which is
+// closed together with the database
+type tunneledDatabase struct {
+	Database
+	tunnel *sshTunnel
+}
+
+// Close closes the database and then its tunnel
+func (d *tunneledDatabase) Close() error {
+	err := d.Database.Close()
+	if tunnelErr := d.tunnel.Close(); err == nil {
+		err = tunnelErr
+	}
+	return err
+}
+
+// ConnectionString describes the connection including the bastion host
+func (d *tunneledDatabase) ConnectionString() string {
+	return fmt.Sprintf("%s via ssh %s@%s", d.Database.ConnectionString(), d.tunnel.cfg.User, d.tunnel.cfg.address())
+}
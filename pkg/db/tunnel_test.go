@@ -0,0 +1,259 @@
+package db
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+// testBastion is an SSH server that forwards direct-tcpip channels
+type testBastion struct {
+	listener net.Listener
+	config   *ssh.ServerConfig
+
+	mu    sync.Mutex
+	conns []*ssh.ServerConn
+}
+
+// startTestBastion starts a bastion accepting clientKey and writes a
+// known_hosts file for it
+func startTestBastion(t *testing.T, clientKey ssh.PublicKey) (*testBastion, string) {
+	_, hostKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate host key: %v", err)
+	}
+	hostSigner, err := ssh.NewSignerFromKey(hostKey)
+	if err != nil {
+		t.Fatalf("failed to create host signer: %v", err)
+	}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(_ ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if string(key.Marshal()) != string(clientKey.Marshal()) {
+				return nil, io.EOF
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(hostSigner)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	b := &testBastion{listener: listener, config: config}
+	go b.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	knownHosts := filepath.Join(t.TempDir(), "known_hosts")
+	line := knownhosts.Line([]string{listener.Addr().String()}, hostSigner.PublicKey())
+	if err := os.WriteFile(knownHosts, []byte(line+"\n"), 0600); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+	return b, knownHosts
+}
+
+func (b *testBastion) serve() {
+	for {
+		conn, err := b.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			serverConn, channels, requests, err := ssh.NewServerConn(conn, b.config)
+			if err != nil {
+				return
+			}
+			b.mu.Lock()
+			b.conns = append(b.conns, serverConn)
+			b.mu.Unlock()
+
+			go ssh.DiscardRequests(requests)
+			for newChannel := range channels {
+				go forwardTestChannel(newChannel)
+			}
+		}()
+	}
+}
+
+// dropConnections closes every SSH connection, as a restarted bastion would
+func (b *testBastion) dropConnections() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, conn := range b.conns {
+		_ = conn.Close()
+	}
+	b.conns = nil
+}
+
+func forwardTestChannel(newChannel ssh.NewChannel) {
+	var target struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}
+	if newChannel.ChannelType() != "direct-tcpip" || ssh.Unmarshal(newChannel.ExtraData(), &target) != nil {
+		_ = newChannel.Reject(ssh.UnknownChannelType, "unsupported")
+		return
+	}
+	remote, err := net.Dial("tcp", net.JoinHostPort(target.Host, strconv.Itoa(int(target.Port))))
+	if err != nil {
+		_ = newChannel.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+	channel, requests, err := newChannel.Accept()
+	if err != nil {
+		_ = remote.Close()
+		return
+	}
+	go ssh.DiscardRequests(requests)
+	go func() {
+		_, _ = io.Copy(channel, remote)
+		_ = channel.Close()
+	}()
+	_, _ = io.Copy(remote, channel)
+	_ = remote.Close()
+}
+
+// startEchoServer starts a TCP server standing in for a database
+func startEchoServer(t *testing.T) *net.TCPAddr {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr)
+}
+
+// echoThrough sends a message through the tunnel and returns the reply
+func echoThrough(t *testing.T, tunnel *sshTunnel, message string) string {
+	conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(tunnel.LocalPort())))
+	if err != nil {
+		t.Fatalf("failed to dial tunnel: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	if _, err := conn.Write([]byte(message)); err != nil {
+		t.Fatalf("failed to write through tunnel: %v", err)
+	}
+	reply := make([]byte, len(message))
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		t.Fatalf("failed to read through tunnel: %v", err)
+	}
+	return string(reply)
+}
+
+func TestSSHTunnel(t *testing.T) {
+	_, clientKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate client key: %v", err)
+	}
+	clientSigner, err := ssh.NewSignerFromKey(clientKey)
+	if err != nil {
+		t.Fatalf("failed to create client signer: %v", err)
+	}
+	block, err := ssh.MarshalPrivateKey(clientKey, "")
+	if err != nil {
+		t.Fatalf("failed to marshal client key: %v", err)
+	}
+	keyPath := filepath.Join(t.TempDir(), "id_ed25519")
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("failed to write client key: %v", err)
+	}
+
+	bastion, knownHosts := startTestBastion(t, clientSigner.PublicKey())
+	database := startEchoServer(t)
+	bastionAddr := bastion.listener.Addr().(*net.TCPAddr)
+
+	cfg := SSHTunnelConfig{
+		Host:           "127.0.0.1",
+		Port:           bastionAddr.Port,
+		User:           "tunnel",
+		PrivateKeyPath: keyPath,
+		KnownHosts:     knownHosts,
+	}
+
+	tunnel, err := openSSHTunnel(cfg, "127.0.0.1", database.Port)
+	if err != nil {
+		t.Fatalf("failed to open tunnel: %v", err)
+	}
+	if reply := echoThrough(t, tunnel, "ping"); reply != "ping" {
+		t.Errorf("expected echo through tunnel, got %q", reply)
+	}
+
+	// The tunnel reconnects after the bastion dropped the SSH connection
+	bastion.dropConnections()
+	if reply := echoThrough(t, tunnel, "again"); reply != "again" {
+		t.Errorf("expected echo after reconnect, got %q", reply)
+	}
+
+	if err := tunnel.Close(); err != nil {
+		t.Errorf("failed to close tunnel: %v", err)
+	}
+	if conn, err := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(tunnel.LocalPort()))); err == nil {
+		_ = conn.Close()
+		t.Error("expected tunnel port to be closed")
+	}
+
+	// An unknown host key is rejected
+	cfg.KnownHosts = filepath.Join(t.TempDir(), "empty_known_hosts")
+	if err := os.WriteFile(cfg.KnownHosts, nil, 0600); err != nil {
+		t.Fatalf("failed to write known_hosts: %v", err)
+	}
+	if tunnel, err := openSSHTunnel(cfg, "127.0.0.1", database.Port); err == nil {
+		_ = tunnel.Close()
+		t.Error("expected unknown host key to be rejected")
+	}
+}
+
+func TestSSHTunnelValidation(t *testing.T) {
+	base := DatabaseConnectionConfig{ID: "pg", Type: "postgres", Host: "db.internal", Port: 5432}
+
+	tests := []struct {
+		name   string
+		conn   DatabaseConnectionConfig
+		tunnel SSHTunnelConfig
+		valid  bool
+	}{
+		{"key", base, SSHTunnelConfig{Host: "bastion", User: "me", PrivateKeyPath: "~/.ssh/id_ed25519"}, true},
+		{"agent", base, SSHTunnelConfig{Host: "bastion", User: "me", UseAgent: true}, true},
+		{"no auth", base, SSHTunnelConfig{Host: "bastion", User: "me"}, false},
+		{"no user", base, SSHTunnelConfig{Host: "bastion", UseAgent: true}, false},
+		{"sqlite", DatabaseConnectionConfig{ID: "lite", Type: "sqlite", DatabasePath: "a.db", Host: "x"}, SSHTunnelConfig{Host: "bastion", User: "me", UseAgent: true}, false},
+	}
+	for _, tt := range tests {
+		conn := tt.conn
+		tunnel := tt.tunnel
+		conn.SSHTunnel = &tunnel
+		err := validateConnectionConfig(conn)
+		if tt.valid && err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected validation error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package dbtools
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// TestClickHouseIntegration tests the schema and plan tools against the
+// ClickHouse of docker-compose.clickhouse-test.yml
+func TestClickHouseIntegration(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping ClickHouse integration test in short mode")
+	}
+
+	host := os.Getenv("CLICKHOUSE_TEST_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+
+	for _, protocol := range []struct {
+		name string
+		port int
+	}{
+		{"native", 19000},
+		{"http", 18123},
+	} {
+		t.Run(protocol.name, func(t *testing.T) {
+			config := db.Config{
+				Type:     "clickhouse",
+				Host:     host,
+				Port:     protocol.port,
+				User:     "clickhouse_user",
+				Password: "clickhouse_password",
+				Name:     "events_test",
+				Protocol: protocol.name,
+			}
+
+			database, err := db.NewDatabase(config)
+			require.NoError(t, err)
+			defer func() {
+				_ = database.Close()
+			}()
+
+			if err := database.Connect(); err != nil {
+				t.Skipf("Skipping ClickHouse integration test: database not available (%v)", err)
+				return
+			}
+
+			ctx := context.Background()
+
+			// Tables come with their engine and keys
+			result, err := getTables(ctx, database)
+			require.NoError(t, err)
+			var events map[string]interface{}
+			for _, table := range result.(map[string]interface{})["tables"].([]map[string]interface{}) {
+				if table["table_name"] == "page_views" {
+					events = table
+				}
+			}
+			require.NotNil(t, events, "page_views table not found")
+			assert.Equal(t, "MergeTree", events["engine"])
+			assert.Equal(t, "toYYYYMM(event_time)", events["partition_key"])
+			assert.Equal(t, "site_id, event_time", events["sorting_key"])
+
+			// Columns report their part in the keys
+			result, err = getColumns(ctx, database, "page_views")
+			require.NoError(t, err)
+			columns := result.(map[string]interface{})["columns"].([]map[string]interface{})
+			require.NotEmpty(t, columns)
+			assert.Equal(t, "site_id", columns[0]["column_name"])
+
+			// ClickHouse has no foreign keys
+			result, err = getRelationships(ctx, database, "page_views")
+			require.NoError(t, err)
+			assert.Empty(t, result.(map[string]interface{})["relationships"])
+
+			// Plans come from EXPLAIN PLAN
+			plan, err := analyzeQueryPlan(ctx, database, "SELECT count() FROM page_views WHERE site_id = 1")
+			require.NoError(t, err)
+			assert.Contains(t, plan.(map[string]interface{})["plan"], "ReadFromMergeTree")
+		})
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
 	"github.com/FreePeak/db-mcp-server/pkg/logger"
 	"github.com/FreePeak/db-mcp-server/pkg/tools"
 )
@@ -37,6 +38,9 @@ type Config struct {
 	ConfigFile  string
 	Connections []ConnectionConfig
 	LazyLoading bool // Enable lazy loading: connections established on first use
+
+	Filter   db.ConnectionFilter   // Connections to use, others in the config file are ignored
+	Policies []db.ConnectionPolicy // Defaults for the connections each policy matches
 }
 
 // ConnectionConfig represents a single database connection configuration
@@ -49,6 +53,11 @@ type ConnectionConfig struct {
 	User     string       `json:"user"`
 	Password string       `json:"password"`
 
+	// Labels for listing, filtering and policies
+	Description string   `json:"description,omitempty"`
+	Environment string   `json:"environment,omitempty"` // such as dev, staging or prod
+	Tags        []string `json:"tags,omitempty"`
+
 	// PostgreSQL specific options
 	SSLMode            string            `json:"ssl_mode,omitempty"`
 	SSLCert            string            `json:"ssl_cert,omitempty"`
@@ -63,7 +72,7 @@ type ConnectionConfig struct {
 	// SQLite specific options
 	DatabasePath     string `json:"database_path,omitempty"`      // Path to SQLite database file
 	EncryptionKey    string `json:"encryption_key,omitempty"`     // Key for SQLCipher encryption
-	ReadOnly         bool   `json:"read_only,omitempty"`          // Open database in read-only mode
+	ReadOnly         bool   `json:"read_only,omitempty"`          // Open database in read-only mode (also PostgreSQL and MySQL sessions)
 	CacheSize        int    `json:"cache_size,omitempty"`         // SQLite cache size (in pages)
 	JournalMode      string `json:"journal_mode,omitempty"`       // Journal mode for SQLite
 	UseModerncDriver bool   `json:"use_modernc_driver,omitempty"` // Use modernc.org/sqlite driver instead of mattn/go-sqlite3
@@ -73,6 +82,20 @@ type ConnectionConfig struct {
 	MaxIdleConns    int `json:"max_idle_conns,omitempty"`
 	ConnMaxLifetime int `json:"conn_max_lifetime_seconds,omitempty"`  // in seconds
 	ConnMaxIdleTime int `json:"conn_max_idle_time_seconds,omitempty"` // in seconds
+
+	// Rate limits for tool calls against this connection
+	RateLimit *db.RateLimitConfig `json:"rate_limit,omitempty"`
+
+	// Planner cost thresholds for queries against this connection
+	CostGuard *db.CostGuardConfig `json:"cost_guard,omitempty"`
+
+	// Bastion host the connection is forwarded through
+	SSHTunnel *db.SSHTunnelConfig `json:"ssh_tunnel,omitempty"`
+
+	// Read replicas for the query and schema tools
+	Replicas      []db.ReplicaConfig `json:"replicas,omitempty"`
+	ReplicaPolicy string             `json:"replica_policy,omitempty"`  // round_robin (default) or least_latency
+	MaxReplicaLag int                `json:"max_replica_lag,omitempty"` // in seconds, replicas further behind are skipped
 }
 
 // MultiDBConfig represents configuration for multiple database connections
@@ -100,6 +123,11 @@ type DatabaseConnectionInfo struct {
 func InitDatabase(cfg *Config) error {
 	// Create database manager
 	dbManager = db.NewDBManager()
+	if cfg != nil {
+		if err := dbManager.SetConnectionRules(cfg.Filter, cfg.Policies); err != nil {
+			return fmt.Errorf("invalid connection policies: %w", err)
+		}
+	}
 
 	var multiDBConfig *MultiDBConfig
 
@@ -219,6 +247,59 @@ func InitDatabase(cfg *Config) error {
 	return nil
 }
 
+// ReloadDatabases re-reads the config file and applies its connections to
+// the running database manager, returning what changed
+func ReloadDatabases(configFile string) (db.ConfigDiff, error) {
+	if dbManager == nil {
+		return db.ConfigDiff{}, fmt.Errorf("database manager not initialized")
+	}
+
+	configData, err := os.ReadFile(configFile)
+	if err != nil {
+		return db.ConfigDiff{}, fmt.Errorf("failed to read config file %s: %w", configFile, err)
+	}
+
+	diff, err := dbManager.Reload(configData)
+	if err != nil {
+		return db.ConfigDiff{}, fmt.Errorf("failed to reload database config: %w", err)
+	}
+	return diff, nil
+}
+
+// AddDatabase adds a connection to the running database manager
+func AddDatabase(conn db.DatabaseConnectionConfig) error {
+	if dbManager == nil {
+		return fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.AddConnection(conn)
+}
+
+// UpdateDatabase replaces the configuration of a connection in the running
+// database manager and reconnects it
+func UpdateDatabase(conn db.DatabaseConnectionConfig) error {
+	if dbManager == nil {
+		return fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.UpdateConnection(conn)
+}
+
+// RemoveDatabase closes a connection and removes it from the running database manager
+func RemoveDatabase(id string) error {
+	if dbManager == nil {
+		return fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.RemoveConnection(id)
+}
+
+// GetRawDatabaseConfig returns the configuration of a connection, including
+// plaintext secrets
+func GetRawDatabaseConfig(id string) (db.DatabaseConnectionConfig, error) {
+	if dbManager == nil {
+		return db.DatabaseConnectionConfig{}, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.GetRawDatabaseConfig(id)
+}
+
 // CloseDatabase closes all database connections
 func CloseDatabase() error {
 	if dbManager == nil {
@@ -227,6 +308,106 @@ func CloseDatabase() error {
 	return dbManager.CloseAll()
 }
 
+// StartHealthMonitor starts pinging the connections periodically and
+// reconnecting those that are down
+func StartHealthMonitor(cfg db.HealthConfig) error {
+	if dbManager == nil {
+		return fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.StartHealthMonitor(cfg)
+}
+
+// StartEviction closes idle connections and caps the number of open
+// connections; requires lazy loading
+func StartEviction(cfg db.EvictionConfig) error {
+	if dbManager == nil {
+		return fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.StartEviction(cfg)
+}
+
+// ListDatabaseHealth returns the health of every configured connection
+func ListDatabaseHealth() []db.DatabaseHealth {
+	if dbManager == nil {
+		return nil
+	}
+	return dbManager.Health()
+}
+
+// GetDatabaseHealth returns the health of a connection
+func GetDatabaseHealth(id string) (db.DatabaseHealth, error) {
+	if dbManager == nil {
+		return db.DatabaseHealth{}, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.DatabaseHealth(id)
+}
+
+// ListPoolStats returns the connection pool statistics of every configured connection
+func ListPoolStats() []db.PoolStats {
+	if dbManager == nil {
+		return nil
+	}
+	return dbManager.PoolStats()
+}
+
+// GetPoolStats returns the connection pool statistics of a connection
+func GetPoolStats(id string) (db.PoolStats, error) {
+	if dbManager == nil {
+		return db.PoolStats{}, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.DatabasePoolStats(id)
+}
+
+// TunePool changes the connection pool settings of a connection at runtime
+func TunePool(id string, settings db.PoolSettings) (db.PoolStats, error) {
+	if dbManager == nil {
+		return db.PoolStats{}, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.TunePool(id, settings)
+}
+
+// BackupDatabase writes a consistent copy of a SQLite connection into dir
+func BackupDatabase(ctx context.Context, id, dir string) (db.Backup, error) {
+	if dbManager == nil {
+		return db.Backup{}, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.Backup(ctx, id, dir)
+}
+
+// RestoreBackup copies a backup of a connection and returns the settings of
+// a new connection opening the copy
+func RestoreBackup(id, backup, newID, dir string) (db.DatabaseConnectionConfig, error) {
+	if dbManager == nil {
+		return db.DatabaseConnectionConfig{}, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.RestoreBackup(id, backup, newID, dir)
+}
+
+// FTSTables returns the FTS5 tables of a SQLite connection
+func FTSTables(ctx context.Context, id string) ([]db.FTSTable, error) {
+	if dbManager == nil {
+		return nil, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.FTSTables(ctx, id)
+}
+
+// Search runs a full-text query against an FTS5 table of a SQLite connection
+func Search(ctx context.Context, id string, req db.SearchRequest) ([]db.SearchMatch, error) {
+	if dbManager == nil {
+		return nil, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.Search(ctx, id, req)
+}
+
+// CreateFTSIndex creates an FTS5 table over text columns of a table of a
+// SQLite connection
+func CreateFTSIndex(ctx context.Context, id, table string, columns []string) (db.FTSTable, error) {
+	if dbManager == nil {
+		return db.FTSTable{}, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.CreateFTSIndex(ctx, id, table, columns)
+}
+
 // GetDatabase returns a database instance by ID
 func GetDatabase(id string) (db.Database, error) {
 	if dbManager == nil {
@@ -235,6 +416,24 @@ func GetDatabase(id string) (db.Database, error) {
 	return dbManager.GetDatabase(id)
 }
 
+// InspectDatabase runs fn on a database without opening its connection for
+// good when lazy loading is enabled
+func InspectDatabase(id string, fn func(db.Database) error) error {
+	if dbManager == nil {
+		return fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.InspectDatabase(id, fn)
+}
+
+// GetReadDatabase returns a database instance for reads by ID, a replica
+// when the connection has healthy replicas
+func GetReadDatabase(id string) (db.Database, error) {
+	if dbManager == nil {
+		return nil, fmt.Errorf("database manager not initialized")
+	}
+	return dbManager.GetReadDatabase(id)
+}
+
 // ListDatabases returns a list of available database connections
 func ListDatabases() []string {
 	if dbManager == nil {
@@ -259,6 +458,15 @@ func GetDatabaseType(id string) (string, error) {
 	return dbManager.GetDatabaseType(id)
 }
 
+// SupportsTransactions reports whether the engine of a database can run
+// transactions, without establishing a connection
+func SupportsTransactions(id string) bool {
+	if dbManager == nil {
+		return true
+	}
+	return dbManager.SupportsTransactions(id)
+}
+
 // showConnectedDatabases returns information about all connected databases
 func showConnectedDatabases(ctx context.Context, _ map[string]interface{}) (interface{}, error) {
 	if dbManager == nil {
@@ -632,44 +840,46 @@ func RegisterMCPDatabaseTools(registry *tools.Registry) error {
 			},
 		})
 
-		// Register transaction tool for this database
-		registry.RegisterTool(&tools.Tool{
-			Name:        fmt.Sprintf("transaction_%s", dbID),
-			Description: fmt.Sprintf("Manage transactions on %s database", dbID),
-			InputSchema: tools.ToolInputSchema{
-				Type: "object",
-				Properties: map[string]interface{}{
-					"action": map[string]interface{}{
-						"type":        "string",
-						"description": "Transaction action (begin, commit, rollback, execute)",
-						"enum":        []string{"begin", "commit", "rollback", "execute"},
-					},
-					"transactionId": map[string]interface{}{
-						"type":        "string",
-						"description": "Transaction ID (required for commit, rollback, execute)",
-					},
-					"statement": map[string]interface{}{
-						"type":        "string",
-						"description": "SQL statement to execute within transaction (required for execute)",
-					},
-					"params": map[string]interface{}{
-						"type":        "array",
-						"description": "Statement parameters",
-						"items": map[string]interface{}{
-							"type": "string",
+		// Register transaction tool for this database, unless its engine has none
+		if SupportsTransactions(dbID) {
+			registry.RegisterTool(&tools.Tool{
+				Name:        fmt.Sprintf("transaction_%s", dbID),
+				Description: fmt.Sprintf("Manage transactions on %s database", dbID),
+				InputSchema: tools.ToolInputSchema{
+					Type: "object",
+					Properties: map[string]interface{}{
+						"action": map[string]interface{}{
+							"type":        "string",
+							"description": "Transaction action (begin, commit, rollback, execute)",
+							"enum":        []string{"begin", "commit", "rollback", "execute"},
+						},
+						"transactionId": map[string]interface{}{
+							"type":        "string",
+							"description": "Transaction ID (required for commit, rollback, execute)",
+						},
+						"statement": map[string]interface{}{
+							"type":        "string",
+							"description": "SQL statement to execute within transaction (required for execute)",
+						},
+						"params": map[string]interface{}{
+							"type":        "array",
+							"description": "Statement parameters",
+							"items": map[string]interface{}{
+								"type": "string",
+							},
+						},
+						"readOnly": map[string]interface{}{
+							"type":        "boolean",
+							"description": "Whether the transaction is read-only (for begin)",
 						},
 					},
-					"readOnly": map[string]interface{}{
-						"type":        "boolean",
-						"description": "Whether the transaction is read-only (for begin)",
-					},
+					Required: []string{"action"},
 				},
-				Required: []string{"action"},
-			},
-			Handler: func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
-				return handleTransactionForDatabase(ctx, params, dbID)
-			},
-		})
+				Handler: func(ctx context.Context, params map[string]interface{}) (interface{}, error) {
+					return handleTransactionForDatabase(ctx, params, dbID)
+				},
+			})
+		}
 
 		// Register performance tool for this database
 		registry.RegisterTool(&tools.Tool{
@@ -998,9 +1208,28 @@ func handleTransactionForDatabase(ctx context.Context, params map[string]interfa
 	}
 }
 
-func handlePerformanceForDatabase(_ context.Context, params map[string]interface{}, dbID string) (interface{}, error) {
+func handlePerformanceForDatabase(ctx context.Context, params map[string]interface{}, dbID string) (interface{}, error) {
 	action, _ := getStringParam(params, "action")
 
+	// Show the plan of the query on engines that plan queries themselves
+	if query, _ := getStringParam(params, "query"); action == "analyzeQuery" && query != "" {
+		if db, err := GetDatabase(dbID); err == nil {
+			if d, ok := dialect.Lookup(db.DriverName()); ok {
+				if planner, ok := d.(dialect.Planner); ok {
+					plan, err := planner.Plan(ctx, db.DB(), query)
+					if err != nil {
+						return createErrorResponse(fmt.Sprintf("Failed to analyze query: %v", err)), nil
+					}
+					return map[string]interface{}{
+						"content": []map[string]interface{}{
+							{"type": "text", "text": fmt.Sprintf("Query plan on database '%s':\n\n%s", dbID, plan)},
+						},
+					}, nil
+				}
+			}
+		}
+	}
+
 	// Create response with basic info about the action
 	limitVal, hasLimit := params["limit"].(float64)
 	limit := 10
@@ -1027,14 +1256,9 @@ func handleSchemaForDatabase(ctx context.Context, _ map[string]interface{}, dbID
 	}
 
 	// Get database type for more accurate schema reporting
-	var dbType string
-	switch db.DriverName() {
-	case "mysql":
-		dbType = "mysql"
-	case "postgres":
-		dbType = "postgres"
-	default:
-		dbType = "unknown"
+	dbType := "unknown"
+	if d, ok := dialect.Lookup(db.DriverName()); ok {
+		dbType = d.Name()
 	}
 
 	// Get schema information
@@ -1058,19 +1282,8 @@ func getBasicSchemaInfo(ctx context.Context, db db.Database, dbID, dbType string
 		"tables":   []map[string]string{},
 	}
 
-	// Try to get table list - a simple query that should work on most databases
-	var query string
-	switch dbType {
-	case "mysql":
-		query = "SHOW TABLES"
-	case "postgres":
-		query = "SELECT tablename AS TABLE_NAME FROM pg_catalog.pg_tables WHERE schemaname NOT IN ('pg_catalog', 'information_schema')"
-	default:
-		// Generic query that might work
-		query = "SELECT name FROM sqlite_master WHERE type='table'"
-	}
-
-	rows, err := db.Query(ctx, query)
+	// List the tables with the catalog queries of the database type
+	rows, err := executeWithFallbacks(ctx, db, strategyFor(db).GetTablesQueries(), "getBasicSchemaInfo")
 	if err != nil {
 		// Log the error but return empty schema to avoid breaking the tool
 		logger.Warn("Failed to get basic schema for database %s: %v", dbID, err)
@@ -1082,10 +1295,22 @@ func getBasicSchemaInfo(ctx context.Context, db db.Database, dbID, dbType string
 		}
 	}()
 
+	// The table name comes first, engines may add details after it
+	columns, err := rows.Columns()
+	if err != nil || len(columns) == 0 {
+		logger.Warn("Failed to get schema columns for database %s: %v", dbID, err)
+		return result
+	}
+	var tableName string
+	dest := make([]interface{}, len(columns))
+	dest[0] = &tableName
+	for i := 1; i < len(dest); i++ {
+		dest[i] = new(interface{})
+	}
+
 	tables := []map[string]string{}
 	for rows.Next() {
-		var tableName string
-		if err := rows.Scan(&tableName); err != nil {
+		if err := rows.Scan(dest...); err != nil {
 			continue
 		}
 		tables = append(tables, map[string]string{"TABLE_NAME": tableName})
@@ -67,11 +67,12 @@ func handleQuery(ctx context.Context, params map[string]interface{}) (interface{
 		return nil, fmt.Errorf("database parameter is required")
 	}
 
-	// Get database instance
-	db, err := dbManager.GetDatabase(databaseID)
+	// Get database instance, kept open until the call returns
+	db, release, err := dbManager.AcquireDatabase(databaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database: %w", err)
 	}
+	defer release()
 
 	// Extract timeout
 	dbTimeout := db.QueryTimeout() * 1000 // Convert from seconds to milliseconds
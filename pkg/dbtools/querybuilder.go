@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
 	"github.com/FreePeak/db-mcp-server/pkg/logger"
 	"github.com/FreePeak/db-mcp-server/pkg/tools"
 )
@@ -196,11 +197,12 @@ func handleQueryBuilder(ctx context.Context, params map[string]interface{}) (int
 		return nil, fmt.Errorf("database parameter is required")
 	}
 
-	// Get database instance
-	db, err := dbManager.GetDatabase(databaseID)
+	// Get database instance, kept open until the call returns
+	db, release, err := dbManager.AcquireDatabase(databaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database: %w", err)
 	}
+	defer release()
 
 	// Extract query parameter
 	query, _ := getStringParam(params, "query")
@@ -491,6 +493,20 @@ func validateQuery(ctx context.Context, db db.Database, query string) (interface
 
 // analyzeQueryPlan analyzes a specific query for performance
 func analyzeQueryPlan(ctx context.Context, db db.Database, query string) (interface{}, error) {
+	// Engines without an EXPLAIN statement plan the query themselves
+	if d, ok := dialect.Lookup(db.DriverName()); ok {
+		if planner, ok := d.(dialect.Planner); ok {
+			plan, err := planner.Plan(ctx, db.DB(), query)
+			if err != nil {
+				return nil, fmt.Errorf("failed to analyze query: %w", err)
+			}
+			return map[string]interface{}{
+				"query": query,
+				"plan":  plan,
+			}, nil
+		}
+	}
+
 	explainQuery := "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) " + query
 	rows, err := db.Query(ctx, explainQuery)
 	if err != nil {
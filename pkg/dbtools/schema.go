@@ -4,33 +4,14 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
-	"regexp"
-	"strings"
 	"time"
 
 	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/db/dialect"
 	"github.com/FreePeak/db-mcp-server/pkg/logger"
 	"github.com/FreePeak/db-mcp-server/pkg/tools"
 )
 
-// validIdentifier matches valid SQL identifiers (alphanumeric and underscores)
-var validIdentifier = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
-
-// sanitizeIdentifier validates and escapes a SQL identifier for SQLite
-func sanitizeIdentifier(identifier string) string {
-	if identifier == "" {
-		return ""
-	}
-	if strings.Contains(identifier, "\x00") {
-		return ""
-	}
-	if validIdentifier.MatchString(identifier) {
-		return identifier
-	}
-	// Quote the identifier to handle special characters
-	return "\"" + strings.ReplaceAll(identifier, "\"", "\"\"") + "\""
-}
-
 // DatabaseStrategy defines the interface for database-specific query strategies
 type DatabaseStrategy interface {
 	GetTablesQueries() []QueryWithArgs
@@ -38,434 +19,74 @@ type DatabaseStrategy interface {
 	GetRelationshipsQueries(table string) []QueryWithArgs
 }
 
-// NewDatabaseStrategy creates the appropriate strategy for the given database type
+// NewDatabaseStrategy creates the strategy for a database driver from its
+// registered dialect
 func NewDatabaseStrategy(driverName string) DatabaseStrategy {
-	switch driverName {
-	case "postgres":
-		return &PostgresStrategy{}
-	case "mysql":
-		return &MySQLStrategy{}
-	case "sqlite", "sqlite3":
-		return &SQLiteStrategy{}
-	default:
+	d, ok := dialect.Lookup(driverName)
+	if !ok {
 		logger.Warn("Unknown database driver: %s, will use generic strategy", driverName)
 		return &GenericStrategy{}
 	}
+	return dialectStrategy{d}
 }
 
-// PostgresStrategy implements DatabaseStrategy for PostgreSQL
-type PostgresStrategy struct{}
-
-// GetTablesQueries returns queries for retrieving tables in PostgreSQL
-func (s *PostgresStrategy) GetTablesQueries() []QueryWithArgs {
-	return []QueryWithArgs{
-		// Primary: pg_catalog approach
-		{Query: "SELECT tablename as table_name FROM pg_catalog.pg_tables WHERE schemaname = 'public'"},
-		// Secondary: information_schema approach
-		{Query: "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'"},
-		// Tertiary: pg_class approach
-		{Query: "SELECT relname as table_name FROM pg_catalog.pg_class WHERE relkind = 'r' AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public')"},
-	}
-}
-
-// GetColumnsQueries returns queries for retrieving columns in PostgreSQL
-func (s *PostgresStrategy) GetColumnsQueries(table string) []QueryWithArgs {
-	return []QueryWithArgs{
-		// Primary: information_schema approach for PostgreSQL
-		{
-			Query: `
-				SELECT column_name, data_type,
-				CASE WHEN is_nullable = 'YES' THEN 'YES' ELSE 'NO' END as is_nullable,
-				column_default
-				FROM information_schema.columns
-				WHERE table_name = $1 AND table_schema = 'public'
-				ORDER BY ordinal_position
-			`,
-			Args: []interface{}{table},
-		},
-		// Secondary: pg_catalog approach for PostgreSQL
-		{
-			Query: `
-				SELECT a.attname as column_name,
-				pg_catalog.format_type(a.atttypid, a.atttypmod) as data_type,
-				CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END as is_nullable,
-				pg_catalog.pg_get_expr(d.adbin, d.adrelid) as column_default
-				FROM pg_catalog.pg_attribute a
-				LEFT JOIN pg_catalog.pg_attrdef d ON (a.attrelid = d.adrelid AND a.attnum = d.adnum)
-				WHERE a.attrelid = (SELECT oid FROM pg_catalog.pg_class WHERE relname = $1 AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public'))
-				AND a.attnum > 0 AND NOT a.attisdropped
-				ORDER BY a.attnum
-			`,
-			Args: []interface{}{table},
-		},
-	}
-}
-
-// GetRelationshipsQueries returns queries for retrieving relationships in PostgreSQL
-func (s *PostgresStrategy) GetRelationshipsQueries(table string) []QueryWithArgs {
-	baseQueries := []QueryWithArgs{
-		// Primary: Standard information_schema approach for PostgreSQL
-		{
-			Query: `
-				SELECT
-					tc.table_schema,
-					tc.constraint_name,
-					tc.table_name,
-					kcu.column_name,
-					ccu.table_schema AS foreign_table_schema,
-					ccu.table_name AS foreign_table_name,
-					ccu.column_name AS foreign_column_name
-				FROM information_schema.table_constraints AS tc
-				JOIN information_schema.key_column_usage AS kcu
-					ON tc.constraint_name = kcu.constraint_name
-					AND tc.table_schema = kcu.table_schema
-				JOIN information_schema.constraint_column_usage AS ccu
-					ON ccu.constraint_name = tc.constraint_name
-					AND ccu.table_schema = tc.table_schema
-				WHERE tc.constraint_type = 'FOREIGN KEY'
-					AND tc.table_schema = 'public'
-			`,
-			Args: []interface{}{},
-		},
-		// Alternate: Using pg_catalog for older PostgreSQL versions
-		{
-			Query: `
-				SELECT
-					ns.nspname AS table_schema,
-					c.conname AS constraint_name,
-					cl.relname AS table_name,
-					att.attname AS column_name,
-					ns2.nspname AS foreign_table_schema,
-					cl2.relname AS foreign_table_name,
-					att2.attname AS foreign_column_name
-				FROM pg_constraint c
-				JOIN pg_class cl ON c.conrelid = cl.oid
-				JOIN pg_attribute att ON att.attrelid = cl.oid AND att.attnum = ANY(c.conkey)
-				JOIN pg_namespace ns ON ns.oid = cl.relnamespace
-				JOIN pg_class cl2 ON c.confrelid = cl2.oid
-				JOIN pg_attribute att2 ON att2.attrelid = cl2.oid AND att2.attnum = ANY(c.confkey)
-				JOIN pg_namespace ns2 ON ns2.oid = cl2.relnamespace
-				WHERE c.contype = 'f'
-				AND ns.nspname = 'public'
-			`,
-			Args: []interface{}{},
-		},
-	}
-
-	if table == "" {
-		return baseQueries
-	}
-
-	queries := make([]QueryWithArgs, len(baseQueries))
-
-	// Add table filter
-	queries[0] = QueryWithArgs{
-		Query: baseQueries[0].Query + " AND (tc.table_name = $1 OR ccu.table_name = $1)",
-		Args:  []interface{}{table},
-	}
-
-	queries[1] = QueryWithArgs{
-		Query: baseQueries[1].Query + " AND (cl.relname = $1 OR cl2.relname = $1)",
-		Args:  []interface{}{table},
-	}
-
-	return queries
-}
-
-// MySQLStrategy implements DatabaseStrategy for MySQL
-type MySQLStrategy struct{}
-
-// GetTablesQueries returns queries for retrieving tables in MySQL
-func (s *MySQLStrategy) GetTablesQueries() []QueryWithArgs {
-	return []QueryWithArgs{
-		// Primary: information_schema approach
-		{Query: "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()"},
-		// Secondary: SHOW TABLES approach
-		{Query: "SHOW TABLES"},
+// strategyFor creates the strategy for a connected database from its
+// dialect, scoped to the connection settings such as the Oracle schema owners
+func strategyFor(database db.Database) DatabaseStrategy {
+	if d := database.Dialect(); d != nil {
+		return dialectStrategy{d}
 	}
+	return NewDatabaseStrategy(database.DriverName())
 }
 
-// GetColumnsQueries returns queries for retrieving columns in MySQL
-func (s *MySQLStrategy) GetColumnsQueries(table string) []QueryWithArgs {
-	return []QueryWithArgs{
-		// MySQL query for columns
-		{
-			Query: `
-				SELECT column_name, data_type, is_nullable, column_default
-				FROM information_schema.columns
-				WHERE table_name = ? AND table_schema = DATABASE()
-				ORDER BY ordinal_position
-			`,
-			Args: []interface{}{table},
-		},
-		// Fallback for older MySQL versions
-		{
-			Query: `SHOW COLUMNS FROM ` + table,
-			Args:  []interface{}{},
-		},
-	}
+// dialectStrategy runs the catalog queries of a dialect
+type dialectStrategy struct {
+	dialect dialect.Dialect
 }
 
-// GetRelationshipsQueries returns queries for retrieving relationships in MySQL
-func (s *MySQLStrategy) GetRelationshipsQueries(table string) []QueryWithArgs {
-	baseQueries := []QueryWithArgs{
-		// Primary approach for MySQL
-		{
-			Query: `
-				SELECT
-					tc.table_schema,
-					tc.constraint_name,
-					tc.table_name,
-					kcu.column_name,
-					kcu.referenced_table_schema AS foreign_table_schema,
-					kcu.referenced_table_name AS foreign_table_name,
-					kcu.referenced_column_name AS foreign_column_name
-				FROM information_schema.table_constraints AS tc
-				JOIN information_schema.key_column_usage AS kcu
-					ON tc.constraint_name = kcu.constraint_name
-					AND tc.table_schema = kcu.table_schema
-				WHERE tc.constraint_type = 'FOREIGN KEY'
-					AND tc.table_schema = DATABASE()
-			`,
-			Args: []interface{}{},
-		},
-		// Fallback using simpler query for older MySQL versions
-		{
-			Query: `
-				SELECT
-					kcu.constraint_schema AS table_schema,
-					kcu.constraint_name,
-					kcu.table_name,
-					kcu.column_name,
-					kcu.referenced_table_schema AS foreign_table_schema,
-					kcu.referenced_table_name AS foreign_table_name,
-					kcu.referenced_column_name AS foreign_column_name
-				FROM information_schema.key_column_usage kcu
-				WHERE kcu.referenced_table_name IS NOT NULL
-					AND kcu.constraint_schema = DATABASE()
-			`,
-			Args: []interface{}{},
-		},
-	}
-
-	if table == "" {
-		return baseQueries
-	}
-
-	queries := make([]QueryWithArgs, len(baseQueries))
-
-	// Add table filter
-	queries[0] = QueryWithArgs{
-		Query: baseQueries[0].Query + " AND (tc.table_name = ? OR kcu.referenced_table_name = ?)",
-		Args:  []interface{}{table, table},
-	}
-
-	queries[1] = QueryWithArgs{
-		Query: baseQueries[1].Query + " AND (kcu.table_name = ? OR kcu.referenced_table_name = ?)",
-		Args:  []interface{}{table, table},
-	}
-
-	return queries
+// GetTablesQueries returns queries for retrieving tables
+func (s dialectStrategy) GetTablesQueries() []QueryWithArgs {
+	return toQueriesWithArgs(s.dialect.TablesQueries())
 }
 
-// SQLiteStrategy implements DatabaseStrategy for SQLite
-type SQLiteStrategy struct{}
-
-// GetTablesQueries returns queries for retrieving tables in SQLite
-func (s *SQLiteStrategy) GetTablesQueries() []QueryWithArgs {
-	return []QueryWithArgs{
-		// Primary: sqlite_master approach
-		{Query: "SELECT name as table_name FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'"},
-		// Secondary: sqlite_master with different filter
-		{Query: "SELECT name as table_name FROM sqlite_master WHERE type='table' AND name NOT IN ('sqlite_sequence', 'sqlite_stat1')"},
-		// Tertiary: PRAGMA approach
-		{Query: "SELECT name as table_name FROM pragma_table_list() WHERE type='table' AND schema='main' AND name NOT LIKE 'sqlite_%'"},
-	}
+// GetColumnsQueries returns queries for retrieving the columns of a table
+func (s dialectStrategy) GetColumnsQueries(table string) []QueryWithArgs {
+	return toQueriesWithArgs(s.dialect.ColumnsQueries(table))
 }
 
-// GetColumnsQueries returns queries for retrieving columns in SQLite
-func (s *SQLiteStrategy) GetColumnsQueries(table string) []QueryWithArgs {
-	return []QueryWithArgs{
-		// Primary: PRAGMA table_info approach
-		{
-			Query: "PRAGMA table_info(" + sanitizeIdentifier(table) + ")",
-			Args:  []interface{}{},
-		},
-		// Secondary: sqlite_master approach for column info
-		{
-			Query: `
-				SELECT p.name as column_name, p.type as data_type,
-				CASE WHEN p."notnull" = 0 THEN 'YES' ELSE 'NO' END as is_nullable,
-				p.dflt_value as column_default
-				FROM pragma_table_info(?) p
-				ORDER BY p.cid
-			`,
-			Args: []interface{}{table},
-		},
-		// Tertiary: Using sqlite_master with parsing
-		{
-			Query: `
-				SELECT
-					CASE
-						WHEN sql LIKE 'CREATE TABLE % (' THEN
-							SUBSTR(SUBSTR(sql, INSTR(sql, '(') + 1),
-								INSTR(SUBSTR(sql, INSTR(sql, '(') + 1), ',') + 1)
-					END as column_info
-				FROM sqlite_master
-				WHERE type='table' AND name=?
-			`,
-			Args: []interface{}{table},
-		},
-	}
+// GetRelationshipsQueries returns queries for retrieving relationships
+func (s dialectStrategy) GetRelationshipsQueries(table string) []QueryWithArgs {
+	return toQueriesWithArgs(s.dialect.RelationshipsQueries(table))
 }
 
-// GetRelationshipsQueries returns queries for retrieving relationships in SQLite
-func (s *SQLiteStrategy) GetRelationshipsQueries(table string) []QueryWithArgs {
-	baseQueries := []QueryWithArgs{
-		// Primary: PRAGMA foreign_key_list approach
-		{
-			Query: `
-				SELECT
-					'main' as table_schema,
-					'fk_' || m.name || '_' || f.id as constraint_name,
-					m.name as table_name,
-					f."from" as column_name,
-					'main' as foreign_table_schema,
-					f."table" as foreign_table_name,
-					f."to" as foreign_column_name
-				FROM sqlite_master m
-				JOIN pragma_foreign_key_list(m.name) f
-				WHERE m.type = 'table' AND m.name NOT LIKE 'sqlite_%'
-			`,
-			Args: []interface{}{},
-		},
-		// Secondary: Using sqlite_master with foreign_key_list
-		{
-			Query: `
-				SELECT
-					'main' as table_schema,
-					'fk_' || name || '_' || id as constraint_name,
-					name as table_name,
-					"from" as column_name,
-					'main' as foreign_table_schema,
-					"table" as foreign_table_name,
-					"to" as foreign_column_name
-				FROM pragma_foreign_key_list(?)
-			`,
-			Args: []interface{}{table},
-		},
-		// Tertiary: Using table_info to check for foreign keys
-		{
-			Query: `
-				SELECT
-					'main' as table_schema,
-					'fk_check_' || name as constraint_name,
-					name as table_name,
-					name as column_name,
-					'main' as foreign_table_schema,
-					'' as foreign_table_name,
-					'' as foreign_column_name
-				FROM pragma_table_info(?)
-				WHERE pk > 0
-			`,
-			Args: []interface{}{table},
-		},
-	}
-
-	if table == "" {
-		return baseQueries[:1] // Only use the first query for all tables
-	}
-
-	return baseQueries
+// GenericStrategy implements DatabaseStrategy for unknown database types with
+// information_schema queries
+type GenericStrategy struct {
+	dialect.Base
 }
 
-// GenericStrategy implements DatabaseStrategy for unknown database types
-type GenericStrategy struct{}
-
 // GetTablesQueries returns generic queries for retrieving tables
 func (s *GenericStrategy) GetTablesQueries() []QueryWithArgs {
-	return []QueryWithArgs{
-		{Query: "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'"},
-		{Query: "SELECT table_name FROM information_schema.tables"},
-		{Query: "SHOW TABLES"}, // Last resort
-	}
+	return toQueriesWithArgs(s.TablesQueries())
 }
 
 // GetColumnsQueries returns generic queries for retrieving columns
 func (s *GenericStrategy) GetColumnsQueries(table string) []QueryWithArgs {
-	return []QueryWithArgs{
-		// Try PostgreSQL-style query first
-		{
-			Query: `
-				SELECT column_name, data_type, is_nullable, column_default
-				FROM information_schema.columns
-				WHERE table_name = $1
-				ORDER BY ordinal_position
-			`,
-			Args: []interface{}{table},
-		},
-		// Try MySQL-style query
-		{
-			Query: `
-				SELECT column_name, data_type, is_nullable, column_default
-				FROM information_schema.columns
-				WHERE table_name = ?
-				ORDER BY ordinal_position
-			`,
-			Args: []interface{}{table},
-		},
-	}
+	return toQueriesWithArgs(s.ColumnsQueries(table))
 }
 
 // GetRelationshipsQueries returns generic queries for retrieving relationships
 func (s *GenericStrategy) GetRelationshipsQueries(table string) []QueryWithArgs {
-	pgQuery := QueryWithArgs{
-		Query: `
-			SELECT
-				tc.table_schema,
-				tc.constraint_name,
-				tc.table_name,
-				kcu.column_name,
-				ccu.table_schema AS foreign_table_schema,
-				ccu.table_name AS foreign_table_name,
-				ccu.column_name AS foreign_column_name
-			FROM information_schema.table_constraints AS tc
-			JOIN information_schema.key_column_usage AS kcu
-				ON tc.constraint_name = kcu.constraint_name
-				AND tc.table_schema = kcu.table_schema
-			JOIN information_schema.constraint_column_usage AS ccu
-				ON ccu.constraint_name = tc.constraint_name
-				AND ccu.table_schema = tc.table_schema
-			WHERE tc.constraint_type = 'FOREIGN KEY'
-		`,
-		Args: []interface{}{},
-	}
-
-	mysqlQuery := QueryWithArgs{
-		Query: `
-			SELECT
-				kcu.constraint_schema AS table_schema,
-				kcu.constraint_name,
-				kcu.table_name,
-				kcu.column_name,
-				kcu.referenced_table_schema AS foreign_table_schema,
-				kcu.referenced_table_name AS foreign_table_name,
-				kcu.referenced_column_name AS foreign_column_name
-			FROM information_schema.key_column_usage kcu
-			WHERE kcu.referenced_table_name IS NOT NULL
-		`,
-		Args: []interface{}{},
-	}
-
-	if table != "" {
-		pgQuery.Query += " AND (tc.table_name = $1 OR ccu.table_name = $1)"
-		pgQuery.Args = append(pgQuery.Args, table)
+	return toQueriesWithArgs(s.RelationshipsQueries(table))
+}
 
-		mysqlQuery.Query += " AND (kcu.table_name = ? OR kcu.referenced_table_name = ?)"
-		mysqlQuery.Args = append(mysqlQuery.Args, table, table)
+// toQueriesWithArgs converts dialect catalog queries
+func toQueriesWithArgs(queries []dialect.Query) []QueryWithArgs {
+	converted := make([]QueryWithArgs, len(queries))
+	for i, q := range queries {
+		converted[i] = QueryWithArgs{Query: q.SQL, Args: q.Args}
 	}
-
-	return []QueryWithArgs{pgQuery, mysqlQuery}
+	return converted
 }
 
 // createSchemaExplorerTool creates a tool for exploring database schema
@@ -520,11 +141,12 @@ func handleSchemaExplorer(ctx context.Context, params map[string]interface{}) (i
 		return nil, fmt.Errorf("database parameter is required")
 	}
 
-	// Get database instance
-	db, err := dbManager.GetDatabase(databaseID)
+	// Get database instance, kept open until the call returns
+	db, release, err := dbManager.AcquireDatabase(databaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database: %w", err)
 	}
+	defer release()
 
 	// Extract table parameter (optional depending on component)
 	table, _ := getStringParam(params, "table")
@@ -585,11 +207,10 @@ func executeWithFallbacks(ctx context.Context, db db.Database, queries []QueryWi
 // getTables retrieves the list of tables in the database
 func getTables(ctx context.Context, db db.Database) (interface{}, error) {
 	// Get database type from connected database
-	driverName := db.DriverName()
-	dbType := driverName
+	dbType := db.DriverName()
 
-	// Create the appropriate strategy
-	strategy := NewDatabaseStrategy(driverName)
+	// Create the strategy of the connection
+	strategy := strategyFor(db)
 
 	// Get queries from strategy
 	queries := strategy.GetTablesQueries()
@@ -623,11 +244,10 @@ func getTables(ctx context.Context, db db.Database) (interface{}, error) {
 // getColumns retrieves the columns for a specific table
 func getColumns(ctx context.Context, db db.Database, table string) (interface{}, error) {
 	// Get database type from connected database
-	driverName := db.DriverName()
-	dbType := driverName
+	dbType := db.DriverName()
 
-	// Create the appropriate strategy
-	strategy := NewDatabaseStrategy(driverName)
+	// Create the strategy of the connection
+	strategy := strategyFor(db)
 
 	// Get queries from strategy
 	queries := strategy.GetColumnsQueries(table)
@@ -662,11 +282,10 @@ func getColumns(ctx context.Context, db db.Database, table string) (interface{},
 // getRelationships retrieves the relationships for a table or all tables
 func getRelationships(ctx context.Context, db db.Database, table string) (interface{}, error) {
 	// Get database type from connected database
-	driverName := db.DriverName()
-	dbType := driverName
+	dbType := db.DriverName()
 
-	// Create the appropriate strategy
-	strategy := NewDatabaseStrategy(driverName)
+	// Create the strategy of the connection
+	strategy := strategyFor(db)
 
 	// Get queries from strategy
 	queries := strategy.GetRelationshipsQueries(table)
@@ -91,6 +91,12 @@ func Error(format string, v ...interface{}) {
 	logMessage("ERROR", format, v...)
 }
 
+// AddSecret masks a value, such as a resolved database password, in every
+// log message written afterwards
+func AddSecret(secret string) {
+	intLogger.AddSecret(secret)
+}
+
 // shouldLog determines if we should log a message based on the level
 func shouldLog(msgLevel string) bool {
 	// Always try to use the internal logger first as it's more sophisticated
@@ -111,7 +117,7 @@ func shouldLog(msgLevel string) bool {
 // logMessage sends a log message to the appropriate destination
 func logMessage(level string, format string, v ...interface{}) {
 	// Forward to the internal logger if possible
-	message := fmt.Sprintf(format, v...)
+	message := intLogger.Redact(fmt.Sprintf(format, v...))
 
 	// If we're in stdio mode, avoid stdout completely
 	if os.Getenv("TRANSPORT_MODE") == "stdio" {